@@ -0,0 +1,301 @@
+// Command replay is a prompt regression harness: it replays stored call
+// transcripts turn by turn through the LLM with candidate prompts and reports
+// how replies and screening labels differ from the original calls.
+//
+// Corpus sources (one of):
+//
+//	-corpus calls.jsonl           JSON Lines corpus (see internal/replay.Call)
+//	-db-query "SELECT ..."        SQL returning provider_call_id values (uses DATABASE_URL,
+//	                              and ENCRYPTION_KEY_FILE for encrypted transcripts)
+//
+// Candidate prompts (files; default: the prompts in internal/llm):
+//
+//	-system-prompt, -guardrails, -analysis-prompt
+//
+// LLM modes:
+//
+//	-mode live     call OpenAI (OPENAI_API_KEY)
+//	-mode record   call OpenAI and save responses to -cassette
+//	-mode replay   serve responses from -cassette only (offline)
+//
+// Examples:
+//
+//	go run ./cmd/replay -db-query "SELECT provider_call_id FROM calls ORDER BY started_at DESC LIMIT 50" -export corpus.jsonl
+//	go run ./cmd/replay -corpus corpus.jsonl -system-prompt new_prompt.txt -out report.json
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lukasbauer/karen/internal/envelope"
+	"github.com/lukasbauer/karen/internal/experiments"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/replay"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func main() {
+	corpusPath := flag.String("corpus", "", "corpus file (JSON Lines)")
+	dbQuery := flag.String("db-query", "", "SQL query returning provider_call_id values to load from DATABASE_URL")
+	exportPath := flag.String("export", "", "write the loaded corpus to this file and exit")
+	systemPromptPath := flag.String("system-prompt", "", "candidate system prompt file (default: each call's original prompt)")
+	guardrailsPath := flag.String("guardrails", "", "candidate voice guardrails file (default: llm.VoiceGuardrailsCzech)")
+	analysisPromptPath := flag.String("analysis-prompt", "", "candidate analysis prompt file (default: llm.AnalysisPromptCzech)")
+	mode := flag.String("mode", "live", "LLM mode: live, record or replay")
+	cassettePath := flag.String("cassette", "replay_cassette.json", "recorded responses file for record/replay modes")
+	model := flag.String("model", "", "OpenAI model (default: the client default)")
+	outPath := flag.String("out", "", "write the JSON report to this file")
+	threshold := flag.Float64("threshold", 0.3, "print turns whose reply similarity is below this value")
+	limit := flag.Int("limit", 0, "replay at most this many calls (0 = all)")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, options{
+		corpusPath:         *corpusPath,
+		dbQuery:            *dbQuery,
+		exportPath:         *exportPath,
+		systemPromptPath:   *systemPromptPath,
+		guardrailsPath:     *guardrailsPath,
+		analysisPromptPath: *analysisPromptPath,
+		mode:               *mode,
+		cassettePath:       *cassettePath,
+		model:              *model,
+		outPath:            *outPath,
+		threshold:          *threshold,
+		limit:              *limit,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	corpusPath, dbQuery, exportPath                      string
+	systemPromptPath, guardrailsPath, analysisPromptPath string
+	mode, cassettePath, model, outPath                   string
+	threshold                                            float64
+	limit                                                int
+}
+
+func run(ctx context.Context, opts options) error {
+	calls, err := loadCorpus(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if opts.limit > 0 && len(calls) > opts.limit {
+		calls = calls[:opts.limit]
+	}
+
+	if opts.exportPath != "" {
+		f, err := os.Create(opts.exportPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := replay.WriteCorpus(f, calls); err != nil {
+			return err
+		}
+		fmt.Printf("Exported %d calls to %s\n", len(calls), opts.exportPath)
+		return nil
+	}
+
+	systemPrompt, err := readOptionalFile(opts.systemPromptPath)
+	if err != nil {
+		return err
+	}
+	guardrails, err := readOptionalFile(opts.guardrailsPath)
+	if err != nil {
+		return err
+	}
+	analysisPrompt, err := readOptionalFile(opts.analysisPromptPath)
+	if err != nil {
+		return err
+	}
+
+	client, cassette, err := newClient(opts, guardrails, analysisPrompt)
+	if err != nil {
+		return err
+	}
+
+	runner := &replay.Runner{Client: client, SystemPrompt: systemPrompt}
+	report := runner.Run(ctx, calls)
+
+	if cassette != nil && opts.mode == "record" {
+		if err := cassette.Save(opts.cassettePath); err != nil {
+			return fmt.Errorf("save cassette: %w", err)
+		}
+	}
+
+	if opts.outPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(opts.outPath, data, 0o644); err != nil {
+			return err
+		}
+	}
+	report.WriteText(os.Stdout, opts.threshold)
+	return nil
+}
+
+// loadCorpus reads the corpus file or loads the calls selected by the DB query.
+func loadCorpus(ctx context.Context, opts options) ([]replay.Call, error) {
+	switch {
+	case opts.corpusPath != "" && opts.dbQuery != "":
+		return nil, errors.New("use either -corpus or -db-query, not both")
+	case opts.corpusPath != "":
+		f, err := os.Open(opts.corpusPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return replay.ReadCorpus(f)
+	case opts.dbQuery != "":
+		return loadFromDB(ctx, opts.dbQuery)
+	default:
+		return nil, errors.New("-corpus or -db-query is required")
+	}
+}
+
+// loadFromDB loads the transcripts of the calls returned by query, each with
+// the prompt version it was answered with (or the tenant's current prompt for
+// calls recorded before prompt versioning).
+func loadFromDB(ctx context.Context, query string) ([]replay.Call, error) {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return nil, errors.New("DATABASE_URL is required for -db-query")
+	}
+	db, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("db query: %w", err)
+	}
+	var callSids []string
+	for rows.Next() {
+		var sid string
+		if err := rows.Scan(&sid); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("db query must return provider_call_id values: %w", err)
+		}
+		callSids = append(callSids, sid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	st := store.New(db)
+	if keyFile := os.Getenv("ENCRYPTION_KEY_FILE"); keyFile != "" {
+		kms, err := envelope.LoadFileKMS(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		st.EnableEncryption(kms)
+	}
+	calls := make([]replay.Call, 0, len(callSids))
+	for _, sid := range callSids {
+		detail, tenantID, err := st.GetCallDetailWithTenantCheck(ctx, sid)
+		if err != nil {
+			return nil, fmt.Errorf("load call %s: %w", sid, err)
+		}
+
+		prompt := ""
+		if detail.ExperimentID != nil && detail.ExperimentVariant != nil {
+			// The variant's prompt replaced the tenant's for this call
+			if exp, err := st.GetExperiment(ctx, *detail.ExperimentID); err == nil {
+				prompt = experiments.VariantPrompt(exp.Variants, *detail.ExperimentVariant)
+			}
+		}
+		if tenantID != nil && prompt == "" {
+			if detail.PromptVersion != nil {
+				if v, err := st.GetPromptVersion(ctx, *tenantID, *detail.PromptVersion); err == nil {
+					prompt = v.SystemPrompt
+				}
+			}
+			if prompt == "" {
+				if t, err := st.GetTenantByID(ctx, *tenantID); err == nil {
+					prompt = t.SystemPrompt
+				}
+			}
+		}
+		calls = append(calls, replay.FromCallDetail(detail, prompt))
+	}
+	return calls, nil
+}
+
+// newClient builds the LLM client for the selected mode. The cassette is
+// returned in record and replay modes.
+func newClient(opts options, guardrails, analysisPrompt string) (llm.Client, *replay.Cassette, error) {
+	salt := cassetteSalt(opts.model, guardrails, analysisPrompt)
+
+	if opts.mode == "replay" {
+		c, err := replay.LoadCassette(opts.cassettePath, salt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load cassette: %w", err)
+		}
+		return c, c, nil
+	}
+	if opts.mode != "live" && opts.mode != "record" {
+		return nil, nil, fmt.Errorf("unknown mode %q (want live, record or replay)", opts.mode)
+	}
+
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, nil, errors.New("OPENAI_API_KEY is required in live and record modes")
+	}
+	live := llm.NewOpenAIClient(llm.OpenAIConfig{
+		APIKey:         apiKey,
+		Model:          opts.model,
+		Guardrails:     guardrails,
+		AnalysisPrompt: analysisPrompt,
+		HTTPClient:     &http.Client{Timeout: 60 * time.Second},
+	})
+	if opts.mode == "live" {
+		return live, nil, nil
+	}
+	c := replay.NewRecordingCassette(live, salt)
+	return c, c, nil
+}
+
+// cassetteSalt identifies the non-system-prompt inputs that shape responses,
+// so recordings made with other prompts or models are not reused.
+func cassetteSalt(model, guardrails, analysisPrompt string) string {
+	if guardrails == "" {
+		guardrails = llm.VoiceGuardrailsCzech
+	}
+	if analysisPrompt == "" {
+		analysisPrompt = llm.AnalysisPromptCzech
+	}
+	h := sha256.Sum256([]byte(model + "\x00" + guardrails + "\x00" + analysisPrompt))
+	return hex.EncodeToString(h[:])
+}
+
+func readOptionalFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
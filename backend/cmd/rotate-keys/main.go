@@ -0,0 +1,122 @@
+// Command rotate-keys manages the keys call transcripts are encrypted with at
+// rest (see internal/envelope and migration 029). It uses DATABASE_URL and
+// the master key file in ENCRYPTION_KEY_FILE.
+//
+// Rotating the master key: add a new key to the key file, make it "active",
+// deploy, then run
+//
+//	go run ./cmd/rotate-keys
+//
+// to re-wrap every tenant data key with the active master key. The retired
+// master key can be removed from the file once this reports no errors.
+//
+// Options:
+//
+//	-new-data-keys      also add a new data key version for every tenant; new
+//	                    values are encrypted with it (servers pick it up within
+//	                    10 minutes), older values keep their version
+//	-encrypt-existing   also encrypt transcripts, summaries and call field
+//	                    values stored in plaintext before encryption was
+//	                    enabled
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lukasbauer/karen/internal/envelope"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func main() {
+	newDataKeys := flag.Bool("new-data-keys", false, "add a new data key version for every tenant")
+	encryptExisting := flag.Bool("encrypt-existing", false, "encrypt transcripts, summaries and call field values stored in plaintext")
+	batchSize := flag.Int("batch", 500, "rows encrypted per batch with -encrypt-existing")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, *newDataKeys, *encryptExisting, *batchSize); err != nil {
+		fmt.Fprintln(os.Stderr, "rotate-keys:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, newDataKeys, encryptExisting bool, batchSize int) error {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return errors.New("DATABASE_URL is required")
+	}
+	keyFile := os.Getenv("ENCRYPTION_KEY_FILE")
+	if keyFile == "" {
+		return errors.New("ENCRYPTION_KEY_FILE is required")
+	}
+	if batchSize < 1 {
+		return errors.New("-batch must be positive")
+	}
+
+	kms, err := envelope.LoadFileKMS(keyFile)
+	if err != nil {
+		return err
+	}
+	db, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	st := store.New(db)
+	st.EnableEncryption(kms)
+
+	n, err := st.RewrapDataKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("re-wrap data keys (%d done): %w", n, err)
+	}
+	fmt.Printf("Re-wrapped %d data keys with master key %s\n", n, kms.ActiveKeyID())
+
+	if newDataKeys {
+		n, err := st.RotateDataKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("rotate data keys (%d done): %w", n, err)
+		}
+		fmt.Printf("Added a new data key version for %d tenants\n", n)
+	}
+
+	if encryptExisting {
+		var utterances, results, events int
+		for {
+			u, r, e, err := st.EncryptStoredTranscripts(ctx, batchSize)
+			utterances += u
+			results += r
+			events += e
+			if err != nil {
+				return fmt.Errorf("encrypt transcripts (%d utterances, %d screening results, %d call events done): %w", utterances, results, events, err)
+			}
+			if u < batchSize && r < batchSize && e < batchSize {
+				break
+			}
+		}
+		fmt.Printf("Encrypted %d utterances, %d screening results and %d call events\n", utterances, results, events)
+
+		var summaries, fieldValues int
+		for {
+			n, v, err := st.EncryptStoredSummaries(ctx, batchSize)
+			summaries += n
+			fieldValues += v
+			if err != nil {
+				return fmt.Errorf("encrypt summaries (%d summaries, %d call field values done): %w", summaries, fieldValues, err)
+			}
+			if n < batchSize && v < batchSize {
+				break
+			}
+		}
+		fmt.Printf("Encrypted %d summaries and %d call field values\n", summaries, fieldValues)
+	}
+	return nil
+}
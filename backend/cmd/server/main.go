@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,12 +12,16 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/lukasbauer/karen/internal/app"
 	"github.com/lukasbauer/karen/internal/httpapi"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/metrics"
+	"github.com/lukasbauer/karen/internal/tracing"
 )
 
 func main() {
 	cfg := app.LoadConfigFromEnv()
 
-	logger := log.New(os.Stdout, "", log.LstdFlags)
+	logger := logging.New(os.Stdout, logging.ParseLevel(cfg.LogLevel))
+	slog.SetDefault(logger)
 
 	// Initialize Sentry for error monitoring
 	if cfg.SentryDSN != "" {
@@ -25,23 +29,37 @@ func main() {
 			Dsn:              cfg.SentryDSN,
 			EnableTracing:    true,
 			TracesSampleRate: 0.2, // 20% of requests for performance monitoring
-			Environment:      getEnvironment(),
+			Environment:      cfg.Environment,
 		})
 		if err != nil {
-			logger.Printf("sentry init failed: %v", err)
+			logger.Error("sentry init failed", "error", err)
 		} else {
-			logger.Printf("sentry initialized")
+			logger.Info("sentry initialized")
 			defer sentry.Flush(2 * time.Second)
 		}
 	}
 
+	// Initialize OpenTelemetry tracing (no-op unless TRACING_EXPORTER is set)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.TracingServiceName,
+		Environment: cfg.Environment,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Error("tracing init failed", "error", err)
+	} else if cfg.TracingExporter != tracing.ExporterNone {
+		logger.Info("tracing initialized", "exporter", cfg.TracingExporter)
+	}
+
 	a, err := app.New(cfg, logger)
 	if err != nil {
 		if cfg.SentryDSN != "" {
 			sentry.CaptureException(err)
 			sentry.Flush(2 * time.Second)
 		}
-		logger.Fatalf("init app: %v", err)
+		logger.Error("init app", "error", err)
+		os.Exit(1)
 	}
 
 	calls := httpapi.NewCallRegistry()
@@ -55,13 +73,35 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Background purge of call data past its retention period
+	go a.RunRetentionPurger(ctx)
+
 	go func() {
-		logger.Printf("listening on %s", cfg.HTTPAddr)
+		logger.Info("listening", "http_addr", cfg.HTTPAddr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatalf("listen: %v", err)
+			logger.Error("listen", "error", err)
+			os.Exit(1)
 		}
 	}()
 
+	// Internal listener for Prometheus; the public /metrics needs METRICS_TOKEN
+	// outside development
+	var metricsSrv *http.Server
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		metricsSrv = &http.Server{Addr: cfg.MetricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			logger.Info("listening for metrics", "metrics_addr", cfg.MetricsAddr)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics listen", "error", err)
+				os.Exit(1)
+			}
+		}()
+	} else if cfg.MetricsToken == "" && cfg.Environment != "development" {
+		logger.Warn("metrics: /metrics is disabled; set METRICS_TOKEN or METRICS_ADDR to scrape it")
+	}
+
 	<-ctx.Done()
 
 	// Phase 1: Stop accepting new HTTP connections. This closes the listener
@@ -70,12 +110,12 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(shutdownCtx)
-	logger.Printf("shutdown: HTTP listener closed")
+	logger.Info("shutdown: HTTP listener closed")
 
 	// Phase 2: Mark as draining. With the listener closed, no new Add() calls
 	// can arrive, so there is no TOCTOU race between StartDraining and Wait.
 	calls.StartDraining()
-	logger.Printf("shutdown: draining started, %d active call(s)", calls.ActiveCount())
+	logger.Warn("shutdown: draining started", "active_count", calls.ActiveCount())
 
 	// Phase 3: Wait for active calls to finish (max 10 minutes)
 	drainDone := make(chan struct{})
@@ -86,18 +126,18 @@ func main() {
 
 	select {
 	case <-drainDone:
-		logger.Printf("shutdown: all calls completed")
+		logger.Info("shutdown: all calls completed")
 	case <-time.After(10 * time.Minute):
-		logger.Printf("shutdown: drain timeout reached, %d call(s) still active", calls.ActiveCount())
+		logger.Warn("shutdown: drain timeout reached, calls still active", "active_count", calls.ActiveCount())
 	}
 
-	// Phase 4: Close DB pool
-	_ = a.Close()
-}
-
-func getEnvironment() string {
-	if env := os.Getenv("ENVIRONMENT"); env != "" {
-		return env
+	// Phase 4: Flush pending spans, stop the metrics listener and close DB pool
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer tracingCancel()
+	_ = shutdownTracing(tracingCtx)
+	if metricsSrv != nil {
+		// Kept up while draining, so active calls stay observable
+		_ = metricsSrv.Shutdown(tracingCtx)
 	}
-	return "development"
+	_ = a.Close()
 }
@@ -3,27 +3,30 @@ package app
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lukasbauer/karen/internal/envelope"
 	"github.com/lukasbauer/karen/internal/eventlog"
 	"github.com/lukasbauer/karen/internal/httpapi"
+	"github.com/lukasbauer/karen/internal/retention"
 	"github.com/lukasbauer/karen/internal/store"
+	"github.com/lukasbauer/karen/internal/tracing"
 )
 
 type App struct {
 	cfg        Config
-	logger     *log.Logger
+	logger     *slog.Logger
 	db         *pgxpool.Pool
 	store      *store.Store
 	eventLog   *eventlog.Logger
 	httpClient *http.Client // Shared HTTP client with connection pooling for TTS
 }
 
-func New(cfg Config, logger *log.Logger) (*App, error) {
+func New(cfg Config, logger *slog.Logger) (*App, error) {
 	if cfg.DatabaseURL == "" {
 		return nil, errors.New("DATABASE_URL is required")
 	}
@@ -40,16 +43,30 @@ func New(cfg Config, logger *log.Logger) (*App, error) {
 	}
 
 	s := store.New(db)
+	s.SetPhoneHashKey([]byte(cfg.PhoneHashKey))
+	if cfg.EncryptionKeyFile != "" {
+		kms, err := envelope.LoadFileKMS(cfg.EncryptionKeyFile)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		s.EnableEncryption(kms)
+		logger.Info("encryption: tenants can encrypt calls at rest", "master_key", kms.ActiveKeyID())
+	}
 	el := eventlog.New(db)
+	if s.EncryptionEnabled() {
+		el.SetSealer(s)
+	}
 
 	// Migrations are applied externally by the CI deploy job (docker exec psql).
 	// No automatic migration runner at startup.
 
 	// Shared HTTP client with connection pooling for TTS.
 	// Keeps TCP connections alive to reduce latency for repeated TTS calls to ElevenLabs.
+	// The tracing transport propagates the call's trace context to the provider.
 	httpClient := &http.Client{
 		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
+		Transport: tracing.Transport(&http.Transport{
 			DialContext: (&net.Dialer{
 				Timeout:   5 * time.Second,
 				KeepAlive: 30 * time.Second,
@@ -59,7 +76,7 @@ func New(cfg Config, logger *log.Logger) (*App, error) {
 			IdleConnTimeout:       90 * time.Second,
 			TLSHandshakeTimeout:   5 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
-		},
+		}),
 	}
 
 	return &App{
@@ -74,29 +91,55 @@ func New(cfg Config, logger *log.Logger) (*App, error) {
 
 func (a *App) Router(calls *httpapi.CallRegistry) http.Handler {
 	routerCfg := httpapi.RouterConfig{
-		PublicBaseURL:         a.cfg.PublicBaseURL,
-		TwilioAuthToken:       a.cfg.TwilioAuthTok,
-		TwilioAccountSID:      a.cfg.TwilioAccountSID,
-		TwilioVerifyServiceID: a.cfg.TwilioVerifyServiceID,
-		DeepgramAPIKey:        a.cfg.DeepgramAPIKey,
-		OpenAIAPIKey:          a.cfg.OpenAIAPIKey,
-		ElevenLabsAPIKey:      a.cfg.ElevenLabsAPIKey,
-		STTEndpointingMs:      a.cfg.STTEndpointingMs,
-		STTUtteranceEndMs:     a.cfg.STTUtteranceEndMs,
-		GreetingText:          a.cfg.GreetingText,
-		TTSVoiceID:            a.cfg.TTSVoiceID,
-		TTSStability:          a.cfg.TTSStability,
-		TTSSimilarity:         a.cfg.TTSSimilarity,
-		TTSHTTPClient:         a.httpClient,
-		JWTSecret:             a.cfg.JWTSecret,
-		JWTExpiry:             a.cfg.JWTExpiry,
-		AdminPhones:           a.cfg.AdminPhones,
-		DiscordWebhookURL:     a.cfg.DiscordWebhookURL,
-		AIDebugAPIKey:         a.cfg.AIDebugAPIKey,
+		PublicBaseURL:           a.cfg.PublicBaseURL,
+		TwilioAuthToken:         a.cfg.TwilioAuthTok,
+		TwilioAccountSID:        a.cfg.TwilioAccountSID,
+		TwilioVerifyServiceID:   a.cfg.TwilioVerifyServiceID,
+		DeepgramAPIKey:          a.cfg.DeepgramAPIKey,
+		OpenAIAPIKey:            a.cfg.OpenAIAPIKey,
+		ElevenLabsAPIKey:        a.cfg.ElevenLabsAPIKey,
+		STTFallbackAPIKey:       a.cfg.STTFallbackAPIKey,
+		STTFallbackURL:          a.cfg.STTFallbackURL,
+		LLMFallbackAPIKey:       a.cfg.LLMFallbackAPIKey,
+		LLMFallbackBaseURL:      a.cfg.LLMFallbackBaseURL,
+		LLMFallbackModel:        a.cfg.LLMFallbackModel,
+		TTSFallbackAPIKey:       a.cfg.TTSFallbackAPIKey,
+		TTSFallbackBaseURL:      a.cfg.TTSFallbackBaseURL,
+		TTSFallbackModelID:      a.cfg.TTSFallbackModelID,
+		ProviderBreakerFailures: a.cfg.ProviderBreakerFailures,
+		ProviderBreakerCooldown: a.cfg.ProviderBreakerCooldown,
+		LLMSlowFirstTokenMs:     a.cfg.LLMSlowFirstTokenMs,
+		TTSSlowFirstChunkMs:     a.cfg.TTSSlowFirstChunkMs,
+		STTEndpointingMs:        a.cfg.STTEndpointingMs,
+		STTUtteranceEndMs:       a.cfg.STTUtteranceEndMs,
+		GreetingText:            a.cfg.GreetingText,
+		TTSVoiceID:              a.cfg.TTSVoiceID,
+		TTSModelID:              a.cfg.TTSModelID,
+		TTSStability:            a.cfg.TTSStability,
+		TTSSimilarity:           a.cfg.TTSSimilarity,
+		TTSHTTPClient:           a.httpClient,
+		JWTSecret:               a.cfg.JWTSecret,
+		JWTExpiry:               a.cfg.JWTExpiry,
+		AdminPhones:             a.cfg.AdminPhones,
+		DiscordWebhookURL:       a.cfg.DiscordWebhookURL,
+		AIDebugAPIKey:           a.cfg.AIDebugAPIKey,
+		MetricsToken:            a.cfg.MetricsToken,
+		Development:             a.cfg.Environment == "development",
 	}
 	return httpapi.NewRouter(routerCfg, a.logger, a.store, a.eventLog, calls)
 }
 
+// RunRetentionPurger purges expired call data every RetentionPurgeInterval
+// until ctx is cancelled. It returns immediately if the purger is disabled.
+func (a *App) RunRetentionPurger(ctx context.Context) {
+	if a.cfg.RetentionPurgeInterval <= 0 {
+		a.logger.Info("retention: purger disabled")
+		return
+	}
+	a.logger.Info("retention: purger started", "interval", a.cfg.RetentionPurgeInterval)
+	retention.NewPurger(a.store, a.logger).Run(ctx, a.cfg.RetentionPurgeInterval)
+}
+
 func (a *App) Close() error {
 	if a.db != nil {
 		a.db.Close()
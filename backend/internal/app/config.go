@@ -13,6 +13,7 @@ type Config struct {
 	DatabaseURL   string
 	TwilioAuthTok string
 	LogLevel      string
+	Environment   string // "development" (default) or the deployment's name, e.g. "production"
 
 	// Error monitoring
 	SentryDSN string
@@ -22,6 +23,22 @@ type Config struct {
 	OpenAIAPIKey     string
 	ElevenLabsAPIKey string
 
+	// Secondary voice AI providers (optional, used when the primary's breaker is open)
+	STTFallbackAPIKey  string
+	STTFallbackURL     string
+	LLMFallbackAPIKey  string
+	LLMFallbackBaseURL string
+	LLMFallbackModel   string
+	TTSFallbackAPIKey  string
+	TTSFallbackBaseURL string
+	TTSFallbackModelID string
+
+	// Provider circuit breakers
+	ProviderBreakerFailures int           // Consecutive failures before a breaker opens
+	ProviderBreakerCooldown time.Duration // How long a breaker stays open before probing
+	LLMSlowFirstTokenMs     int           // First-token latency counted as a failure (0 = disabled)
+	TTSSlowFirstChunkMs     int           // First-chunk latency counted as a failure (0 = disabled)
+
 	// STT settings
 	STTEndpointingMs  int // Deepgram endpointing in ms (silence threshold)
 	STTUtteranceEndMs int // Hard timeout after last speech, regardless of noise
@@ -29,6 +46,7 @@ type Config struct {
 	// Voice settings (defaults, overridden by tenant config)
 	GreetingText  string
 	TTSVoiceID    string  // ElevenLabs voice ID
+	TTSModelID    string  // ElevenLabs model ID
 	TTSStability  float64 // ElevenLabs voice stability (0.0-1.0, default 0.5)
 	TTSSimilarity float64 // ElevenLabs voice similarity boost (0.0-1.0, default 0.75)
 
@@ -48,6 +66,24 @@ type Config struct {
 
 	// AI Debug API
 	AIDebugAPIKey string
+
+	// Prometheus metrics
+	MetricsToken string
+	MetricsAddr  string // Internal listener serving /metrics without a token (e.g. 127.0.0.1:9090)
+
+	// Data retention
+	RetentionPurgeInterval time.Duration // How often expired call data is purged (0 = disabled)
+
+	// Data subject requests
+	PhoneHashKey string // HMAC key of the phone number hashes in the request trail
+
+	// Transcript encryption
+	EncryptionKeyFile string // JSON file with the master keys ("" = transcripts stored in plaintext)
+
+	// OpenTelemetry tracing
+	TracingExporter    string  // "none", "otlp" or "stdout"
+	TracingSampleRatio float64 // Fraction of calls traced (0.0-1.0)
+	TracingServiceName string
 }
 
 func LoadConfigFromEnv() Config {
@@ -56,12 +92,23 @@ func LoadConfigFromEnv() Config {
 		jwtExpiry = 24 * time.Hour
 	}
 
+	breakerCooldown, err := time.ParseDuration(getenv("PROVIDER_BREAKER_COOLDOWN", "30s"))
+	if err != nil || breakerCooldown <= 0 {
+		breakerCooldown = 30 * time.Second
+	}
+
+	retentionPurgeInterval, err := time.ParseDuration(getenv("RETENTION_PURGE_INTERVAL", "1h"))
+	if err != nil || retentionPurgeInterval < 0 {
+		retentionPurgeInterval = time.Hour
+	}
+
 	return Config{
 		HTTPAddr:      getenv("HTTP_ADDR", ":8080"),
 		PublicBaseURL: getenv("PUBLIC_BASE_URL", "http://localhost:8080"),
 		DatabaseURL:   getenv("DATABASE_URL", ""),
 		TwilioAuthTok: getenv("TWILIO_AUTH_TOKEN", ""),
 		LogLevel:      getenv("LOG_LEVEL", "info"),
+		Environment:   getenv("ENVIRONMENT", "development"),
 
 		// Error monitoring
 		SentryDSN: os.Getenv("SENTRY_DSN"),
@@ -71,6 +118,24 @@ func LoadConfigFromEnv() Config {
 		OpenAIAPIKey:     getenv("OPENAI_API_KEY", ""),
 		ElevenLabsAPIKey: getenv("ELEVENLABS_API_KEY", ""),
 
+		// Secondary voice AI providers
+		STTFallbackAPIKey:  os.Getenv("STT_FALLBACK_API_KEY"),
+		STTFallbackURL:     os.Getenv("STT_FALLBACK_URL"),
+		LLMFallbackAPIKey:  os.Getenv("LLM_FALLBACK_API_KEY"),
+		LLMFallbackBaseURL: os.Getenv("LLM_FALLBACK_BASE_URL"),
+		LLMFallbackModel:   os.Getenv("LLM_FALLBACK_MODEL"),
+		TTSFallbackAPIKey:  os.Getenv("TTS_FALLBACK_API_KEY"),
+		TTSFallbackBaseURL: os.Getenv("TTS_FALLBACK_BASE_URL"),
+		TTSFallbackModelID: os.Getenv("TTS_FALLBACK_MODEL_ID"),
+
+		// Provider circuit breakers
+		// Slow thresholds sit well above normal first-token/first-chunk latency
+		// (~500ms) so only real degradation trips the breaker.
+		ProviderBreakerFailures: getenvIntClamped("PROVIDER_BREAKER_FAILURES", 3, 1, 100),
+		ProviderBreakerCooldown: breakerCooldown,
+		LLMSlowFirstTokenMs:     getenvIntClamped("LLM_SLOW_FIRST_TOKEN_MS", 3000, 0, 60000),
+		TTSSlowFirstChunkMs:     getenvIntClamped("TTS_SLOW_FIRST_CHUNK_MS", 2000, 0, 60000),
+
 		// STT settings
 		// Deepgram endpointing controls how quickly we decide the caller finished speaking.
 		// Too low -> fragmented utterances and interruptive back-and-forth; too high -> sluggish turns.
@@ -83,6 +148,7 @@ func LoadConfigFromEnv() Config {
 		// Voice settings (defaults, overridden by tenant config)
 		GreetingText:  getenv("GREETING_TEXT", "Dobrý den, tady asistentka Karen. Majitel telefonu teď nemůže přijmout hovor, ale můžu vám pro něj zanechat vzkaz - co od něj potřebujete?"),
 		TTSVoiceID:    getenv("TTS_VOICE_ID", ""),                           // ElevenLabs voice ID
+		TTSModelID:    getenv("TTS_MODEL_ID", "eleven_flash_v2_5"),          // ElevenLabs model ID
 		TTSStability:  getenvFloatClamped("TTS_STABILITY", 0.5, 0.0, 1.0),   // Voice stability (0.0-1.0)
 		TTSSimilarity: getenvFloatClamped("TTS_SIMILARITY", 0.75, 0.0, 1.0), // Voice similarity boost (0.0-1.0)
 
@@ -102,6 +168,24 @@ func LoadConfigFromEnv() Config {
 
 		// AI Debug API
 		AIDebugAPIKey: os.Getenv("AI_DEBUG_API_KEY"),
+
+		// Prometheus metrics
+		MetricsToken: os.Getenv("METRICS_TOKEN"),
+		MetricsAddr:  os.Getenv("METRICS_ADDR"),
+
+		// Data retention
+		RetentionPurgeInterval: retentionPurgeInterval,
+
+		// Data subject requests (falls back to the JWT secret)
+		PhoneHashKey: getenv("PHONE_HASH_KEY", os.Getenv("JWT_SECRET")),
+
+		// Transcript encryption
+		EncryptionKeyFile: os.Getenv("ENCRYPTION_KEY_FILE"),
+
+		// OpenTelemetry tracing (OTLP endpoint via standard OTEL_EXPORTER_OTLP_ENDPOINT)
+		TracingExporter:    getenv("TRACING_EXPORTER", "none"),
+		TracingSampleRatio: getenvFloatClamped("TRACING_SAMPLE_RATIO", 1.0, 0.0, 1.0),
+		TracingServiceName: getenv("OTEL_SERVICE_NAME", "karen-backend"),
 	}
 }
 
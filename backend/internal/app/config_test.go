@@ -3,6 +3,7 @@ package app
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestGetenv(t *testing.T) {
@@ -294,7 +295,7 @@ func TestLoadConfigFromEnvDefaults(t *testing.T) {
 	keysToClean := []string{
 		"HTTP_ADDR", "PUBLIC_BASE_URL", "DATABASE_URL", "LOG_LEVEL",
 		"STT_ENDPOINTING_MS", "STT_UTTERANCE_END_MS",
-		"TTS_STABILITY", "TTS_SIMILARITY",
+		"TTS_STABILITY", "TTS_SIMILARITY", "RETENTION_PURGE_INTERVAL",
 	}
 	for _, key := range keysToClean {
 		os.Unsetenv(key)
@@ -332,6 +333,28 @@ func TestLoadConfigFromEnvDefaults(t *testing.T) {
 	if cfg.TTSSimilarity != 0.75 {
 		t.Errorf("TTSSimilarity = %f, want %f", cfg.TTSSimilarity, 0.75)
 	}
+
+	if cfg.RetentionPurgeInterval != time.Hour {
+		t.Errorf("RetentionPurgeInterval = %v, want %v", cfg.RetentionPurgeInterval, time.Hour)
+	}
+}
+
+func TestLoadConfigFromEnvRetentionPurgeInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"15m", 15 * time.Minute},
+		{"0", 0},
+		{"-1h", time.Hour},
+		{"soon", time.Hour},
+	}
+	for _, tt := range tests {
+		t.Setenv("RETENTION_PURGE_INTERVAL", tt.value)
+		if got := LoadConfigFromEnv().RetentionPurgeInterval; got != tt.want {
+			t.Errorf("RETENTION_PURGE_INTERVAL=%q: got %v, want %v", tt.value, got, tt.want)
+		}
+	}
 }
 
 func TestLoadConfigFromEnvCustomValues(t *testing.T) {
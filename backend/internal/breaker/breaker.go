@@ -0,0 +1,223 @@
+// Package breaker provides circuit breakers used to fail over between
+// voice AI providers (STT, LLM, TTS).
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the current state of a circuit breaker.
+type State string
+
+const (
+	// StateClosed means the provider is healthy and receives traffic.
+	StateClosed State = "closed"
+	// StateOpen means the provider tripped and is skipped until the cooldown elapses.
+	StateOpen State = "open"
+	// StateHalfOpen means the cooldown elapsed and a single probe request is allowed through.
+	StateHalfOpen State = "half_open"
+)
+
+// Config controls when a breaker trips and how long it stays open.
+type Config struct {
+	FailureThreshold int           // Consecutive failures (errors or slow responses) before opening
+	SlowThreshold    time.Duration // First-token/first-chunk latency above this counts as a failure (0 = disabled)
+	OpenDuration     time.Duration // How long to stay open before allowing a probe
+}
+
+// DefaultConfig returns the default breaker configuration.
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold: 3,
+		SlowThreshold:    0,
+		OpenDuration:     30 * time.Second,
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker for a single provider.
+// It is safe for concurrent use and is shared across all calls.
+type Breaker struct {
+	name string
+	kind string
+	cfg  Config
+
+	mu                  sync.Mutex
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	probeInFlight       bool
+	lastError           string
+	lastFailureAt       time.Time
+	totalSuccesses      int64
+	totalFailures       int64
+	timesOpened         int64
+
+	now func() time.Time // for tests
+}
+
+// New creates a closed breaker for the named provider.
+// kind is the pipeline stage the provider serves ("stt", "llm" or "tts").
+func New(kind, name string, cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultConfig().FailureThreshold
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = DefaultConfig().OpenDuration
+	}
+	return &Breaker{
+		name:  name,
+		kind:  kind,
+		cfg:   cfg,
+		state: StateClosed,
+		now:   time.Now,
+	}
+}
+
+// Name returns the provider name.
+func (b *Breaker) Name() string { return b.name }
+
+// Kind returns the pipeline stage of the provider.
+func (b *Breaker) Kind() string { return b.kind }
+
+// Allow reports whether a request may be sent to the provider.
+// An open breaker whose cooldown has elapsed moves to half-open and lets
+// exactly one probe through; further requests are refused until the probe
+// reports success or failure.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenDuration {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probeInFlight = true
+		return true
+	case StateHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	}
+	return false
+}
+
+// RecordSuccess records a successful request and closes the breaker.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.totalSuccesses++
+	b.consecutiveFailures = 0
+	b.probeInFlight = false
+	b.state = StateClosed
+}
+
+// RecordFailure records a failed request. The breaker opens once the
+// consecutive failure threshold is reached, or immediately if a half-open
+// probe fails.
+func (b *Breaker) RecordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.totalFailures++
+	b.consecutiveFailures++
+	b.lastFailureAt = b.now()
+	if err != nil {
+		b.lastError = err.Error()
+	}
+
+	if b.state == StateHalfOpen || b.consecutiveFailures >= b.cfg.FailureThreshold {
+		if b.state != StateOpen {
+			b.timesOpened++
+		}
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+	b.probeInFlight = false
+}
+
+// RecordLatency records the first-token/first-chunk latency of a successful
+// request. Latency above the slow threshold counts as a failure.
+func (b *Breaker) RecordLatency(latency time.Duration) {
+	if b.cfg.SlowThreshold > 0 && latency > b.cfg.SlowThreshold {
+		b.RecordFailure(&SlowError{Latency: latency, Threshold: b.cfg.SlowThreshold})
+		return
+	}
+	b.RecordSuccess()
+}
+
+// Release gives up a half-open probe without recording an outcome, e.g. when
+// the request was cancelled by barge-in before the provider answered.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probeInFlight = false
+}
+
+// Reset force-closes the breaker (admin override).
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.consecutiveFailures = 0
+	b.probeInFlight = false
+}
+
+// Snapshot is a point-in-time view of a breaker, suitable for JSON output.
+type Snapshot struct {
+	Kind                string     `json:"kind"`
+	Name                string     `json:"name"`
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	TotalSuccesses      int64      `json:"total_successes"`
+	TotalFailures       int64      `json:"total_failures"`
+	TimesOpened         int64      `json:"times_opened"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// Snapshot returns the current breaker state.
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Snapshot{
+		Kind:                b.kind,
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		TotalSuccesses:      b.totalSuccesses,
+		TotalFailures:       b.totalFailures,
+		TimesOpened:         b.timesOpened,
+		LastError:           b.lastError,
+	}
+	if b.state != StateClosed && !b.openedAt.IsZero() {
+		openedAt := b.openedAt.UTC()
+		s.OpenedAt = &openedAt
+	}
+	if !b.lastFailureAt.IsZero() {
+		lastFailureAt := b.lastFailureAt.UTC()
+		s.LastFailureAt = &lastFailureAt
+	}
+	return s
+}
+
+// SlowError is recorded when a provider responds but too slowly.
+type SlowError struct {
+	Latency   time.Duration
+	Threshold time.Duration
+}
+
+func (e *SlowError) Error() string {
+	return "slow response: " + e.Latency.Round(time.Millisecond).String() + " > " + e.Threshold.String()
+}
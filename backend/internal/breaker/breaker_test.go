@@ -0,0 +1,135 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(cfg Config) (*Breaker, *time.Time) {
+	b := New("llm", "openai", cfg)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 3, OpenDuration: 30 * time.Second})
+
+	for i := 0; i < 2; i++ {
+		if !b.Allow() {
+			t.Fatalf("attempt %d: Allow() = false, want true", i)
+		}
+		b.RecordFailure(errors.New("boom"))
+	}
+	if got := b.Snapshot().State; got != StateClosed {
+		t.Fatalf("state after 2 failures = %q, want %q", got, StateClosed)
+	}
+
+	b.RecordFailure(errors.New("boom"))
+	snap := b.Snapshot()
+	if snap.State != StateOpen {
+		t.Fatalf("state after 3 failures = %q, want %q", snap.State, StateOpen)
+	}
+	if snap.TimesOpened != 1 {
+		t.Errorf("TimesOpened = %d, want 1", snap.TimesOpened)
+	}
+	if snap.LastError != "boom" {
+		t.Errorf("LastError = %q, want %q", snap.LastError, "boom")
+	}
+	if b.Allow() {
+		t.Error("Allow() on open breaker = true, want false")
+	}
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 2})
+
+	b.RecordFailure(errors.New("boom"))
+	b.RecordSuccess()
+	b.RecordFailure(errors.New("boom"))
+
+	if got := b.Snapshot().State; got != StateClosed {
+		t.Errorf("state = %q, want %q", got, StateClosed)
+	}
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	b, now := newTestBreaker(Config{FailureThreshold: 1, OpenDuration: 10 * time.Second})
+
+	b.RecordFailure(errors.New("boom"))
+	if b.Allow() {
+		t.Fatal("Allow() during cooldown = true, want false")
+	}
+
+	*now = now.Add(11 * time.Second)
+	if !b.Allow() {
+		t.Fatal("Allow() after cooldown = false, want true (probe)")
+	}
+	if got := b.Snapshot().State; got != StateHalfOpen {
+		t.Fatalf("state = %q, want %q", got, StateHalfOpen)
+	}
+	if b.Allow() {
+		t.Error("second Allow() while probe in flight = true, want false")
+	}
+
+	// Failed probe re-opens immediately
+	b.RecordFailure(errors.New("still down"))
+	if got := b.Snapshot().State; got != StateOpen {
+		t.Fatalf("state after failed probe = %q, want %q", got, StateOpen)
+	}
+
+	// Successful probe closes
+	*now = now.Add(11 * time.Second)
+	if !b.Allow() {
+		t.Fatal("Allow() after second cooldown = false, want true")
+	}
+	b.RecordSuccess()
+	if got := b.Snapshot().State; got != StateClosed {
+		t.Errorf("state after successful probe = %q, want %q", got, StateClosed)
+	}
+}
+
+func TestBreaker_ReleaseFreesProbe(t *testing.T) {
+	b, now := newTestBreaker(Config{FailureThreshold: 1, OpenDuration: time.Second})
+
+	b.RecordFailure(errors.New("boom"))
+	*now = now.Add(2 * time.Second)
+	if !b.Allow() {
+		t.Fatal("Allow() after cooldown = false, want true")
+	}
+	b.Release()
+	if !b.Allow() {
+		t.Error("Allow() after Release() = false, want true")
+	}
+}
+
+func TestBreaker_SlowLatencyCountsAsFailure(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 2, SlowThreshold: time.Second})
+
+	b.RecordLatency(500 * time.Millisecond)
+	b.RecordLatency(2 * time.Second)
+	b.RecordLatency(3 * time.Second)
+
+	snap := b.Snapshot()
+	if snap.State != StateOpen {
+		t.Fatalf("state = %q, want %q", snap.State, StateOpen)
+	}
+	if snap.TotalSuccesses != 1 || snap.TotalFailures != 2 {
+		t.Errorf("successes/failures = %d/%d, want 1/2", snap.TotalSuccesses, snap.TotalFailures)
+	}
+}
+
+func TestBreaker_Reset(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 1})
+
+	b.RecordFailure(errors.New("boom"))
+	b.Reset()
+
+	if !b.Allow() {
+		t.Error("Allow() after Reset() = false, want true")
+	}
+	if got := b.Snapshot().State; got != StateClosed {
+		t.Errorf("state = %q, want %q", got, StateClosed)
+	}
+}
@@ -0,0 +1,49 @@
+package costs
+
+// Budget states, ordered by severity.
+const (
+	BudgetUnlimited = "unlimited" // No budget configured
+	BudgetOK        = "ok"        // Below the degradation threshold
+	BudgetDegraded  = "degraded"  // Approaching the budget: calls run in cheaper mode
+	BudgetExceeded  = "exceeded"  // Over budget: calls stay degraded, admin is alerted
+)
+
+// Budget is a tenant's monthly provider-cost budget and how much of it is used.
+type Budget struct {
+	LimitCents     int     `json:"limit_cents"` // 0 = unlimited
+	SpentCents     int     `json:"spent_cents"`
+	PercentUsed    float64 `json:"percent_used"`
+	DegradePercent int     `json:"degrade_percent"`
+	State          string  `json:"state"`
+	Source         string  `json:"source"` // "tenant" (override) or "plan" (default for the plan)
+}
+
+// EvaluateBudget computes the budget state for the month-to-date spend.
+// Calls degrade once spend reaches degradePercent of the limit.
+func EvaluateBudget(limitCents, spentCents, degradePercent int) Budget {
+	b := Budget{
+		LimitCents:     limitCents,
+		SpentCents:     spentCents,
+		DegradePercent: degradePercent,
+		State:          BudgetUnlimited,
+	}
+	if limitCents <= 0 {
+		return b
+	}
+
+	b.PercentUsed = float64(spentCents) * 100 / float64(limitCents)
+	switch {
+	case spentCents >= limitCents:
+		b.State = BudgetExceeded
+	case b.PercentUsed >= float64(degradePercent):
+		b.State = BudgetDegraded
+	default:
+		b.State = BudgetOK
+	}
+	return b
+}
+
+// Degraded reports whether calls should run in the cheaper degraded mode.
+func (b Budget) Degraded() bool {
+	return b.State == BudgetDegraded || b.State == BudgetExceeded
+}
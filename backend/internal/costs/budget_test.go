@@ -0,0 +1,37 @@
+package costs
+
+import "testing"
+
+func TestEvaluateBudget(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit, spent int
+		wantState    string
+		wantDegraded bool
+	}{
+		{"no budget", 0, 5000, BudgetUnlimited, false},
+		{"well below", 1000, 100, BudgetOK, false},
+		{"at threshold", 1000, 800, BudgetDegraded, true},
+		{"at limit", 1000, 1000, BudgetExceeded, true},
+		{"over limit", 1000, 1500, BudgetExceeded, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := EvaluateBudget(tt.limit, tt.spent, 80)
+			if b.State != tt.wantState {
+				t.Errorf("State = %q, want %q", b.State, tt.wantState)
+			}
+			if b.Degraded() != tt.wantDegraded {
+				t.Errorf("Degraded() = %v, want %v", b.Degraded(), tt.wantDegraded)
+			}
+		})
+	}
+}
+
+func TestEvaluateBudget_PercentUsed(t *testing.T) {
+	b := EvaluateBudget(2000, 500, 80)
+	if b.PercentUsed != 25 {
+		t.Errorf("PercentUsed = %v, want 25", b.PercentUsed)
+	}
+}
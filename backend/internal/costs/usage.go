@@ -0,0 +1,97 @@
+package costs
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// Usage accumulates billed provider usage over the lifetime of a call.
+// Provider clients report what the provider actually billed (OpenAI usage,
+// Deepgram metadata duration, ElevenLabs character-cost header); the totals
+// flow into CallMetrics when the call ends. A nil *Usage ignores all reports.
+type Usage struct {
+	mu              sync.Mutex
+	llmInputTokens  int
+	llmOutputTokens int
+	sttSeconds      float64
+	ttsCharacters   int
+}
+
+// NewUsage creates an empty usage accumulator.
+func NewUsage() *Usage {
+	return &Usage{}
+}
+
+// AddLLMTokens records prompt and completion tokens for one LLM request.
+func (u *Usage) AddLLMTokens(input, output int) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	u.llmInputTokens += input
+	u.llmOutputTokens += output
+	u.mu.Unlock()
+}
+
+// AddSTTSeconds records billed audio duration for one STT stream.
+func (u *Usage) AddSTTSeconds(seconds float64) {
+	if u == nil || seconds <= 0 {
+		return
+	}
+	u.mu.Lock()
+	u.sttSeconds += seconds
+	u.mu.Unlock()
+}
+
+// AddTTSCharacters records billed characters for one TTS request.
+func (u *Usage) AddTTSCharacters(chars int) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	u.ttsCharacters += chars
+	u.mu.Unlock()
+}
+
+// Metrics returns the accumulated usage as CallMetrics for a call of the
+// given duration. If no STT duration was reported (e.g. the stream closed
+// before Deepgram sent its metadata), the call duration is used instead.
+func (u *Usage) Metrics(callDurationSeconds int) CallMetrics {
+	m := CallMetrics{
+		CallDurationSeconds: callDurationSeconds,
+		STTDurationSeconds:  callDurationSeconds,
+	}
+	if u == nil {
+		return m
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.sttSeconds > 0 {
+		m.STTDurationSeconds = int(math.Ceil(u.sttSeconds))
+	}
+	m.LLMInputTokens = u.llmInputTokens
+	m.LLMOutputTokens = u.llmOutputTokens
+	m.TTSCharacters = u.ttsCharacters
+	return m
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context that carries the call's usage accumulator.
+// Provider clients report usage to the accumulator found in the request context.
+func WithUsage(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageKey{}, u)
+}
+
+// UsageFromContext returns the usage accumulator in ctx, or nil.
+func UsageFromContext(ctx context.Context) *Usage {
+	u, _ := ctx.Value(usageKey{}).(*Usage)
+	return u
+}
+
+// EstimateTokens approximates a token count from text length (~4 chars per
+// token). Used only when a provider does not report usage.
+func EstimateTokens(chars int) int {
+	return (chars + 3) / 4
+}
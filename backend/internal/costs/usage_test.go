@@ -0,0 +1,68 @@
+package costs
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUsage_Metrics(t *testing.T) {
+	u := NewUsage()
+	u.AddLLMTokens(1200, 80)
+	u.AddLLMTokens(300, 20)
+	u.AddSTTSeconds(61.2)
+	u.AddTTSCharacters(140)
+
+	got := u.Metrics(65)
+	want := CallMetrics{
+		CallDurationSeconds: 65,
+		STTDurationSeconds:  62, // Rounded up like Deepgram billing
+		LLMInputTokens:      1500,
+		LLMOutputTokens:     100,
+		TTSCharacters:       140,
+	}
+	if got != want {
+		t.Errorf("Metrics() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUsage_MetricsWithoutSTTFallsBackToCallDuration(t *testing.T) {
+	u := NewUsage()
+	u.AddSTTSeconds(0)
+
+	if got := u.Metrics(90).STTDurationSeconds; got != 90 {
+		t.Errorf("STTDurationSeconds = %d, want 90", got)
+	}
+}
+
+func TestUsage_NilSafe(t *testing.T) {
+	var u *Usage
+	u.AddLLMTokens(1, 1)
+	u.AddSTTSeconds(1)
+	u.AddTTSCharacters(1)
+
+	got := u.Metrics(30)
+	if got.CallDurationSeconds != 30 || got.STTDurationSeconds != 30 || got.LLMInputTokens != 0 {
+		t.Errorf("nil Metrics() = %+v, want durations only", got)
+	}
+}
+
+func TestUsageFromContext(t *testing.T) {
+	if UsageFromContext(context.Background()) != nil {
+		t.Error("UsageFromContext(empty) != nil")
+	}
+
+	u := NewUsage()
+	ctx := WithUsage(context.Background(), u)
+	if UsageFromContext(ctx) != u {
+		t.Error("UsageFromContext() did not return the stored accumulator")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(9); got != 3 {
+		t.Errorf("EstimateTokens(9) = %d, want 3", got)
+	}
+	if got := EstimateTokens(0); got != 0 {
+		t.Errorf("EstimateTokens(0) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,146 @@
+// Package envelope implements envelope encryption of call data at rest. Each
+// tenant has AES-256 data keys that encrypt its values (AES-GCM); the data
+// keys are stored wrapped (encrypted) by a master key held by a KMS. Rotating
+// the master key only re-wraps the data keys, the data stays as it is.
+//
+// An encrypted value starts with a header naming the tenant and the data key
+// version, so it can be decrypted without knowing where it came from. The
+// header is authenticated, so a value can't be moved to another tenant.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size of data and master keys (AES-256).
+const KeySize = 32
+
+const (
+	formatVersion = 1
+	headerSize    = 1 + 16 + 4 // format version, tenant UUID, data key version
+)
+
+// ErrMalformed is returned for values that are not envelope ciphertexts.
+var ErrMalformed = errors.New("envelope: malformed ciphertext")
+
+// KeyRef names a tenant's data key.
+type KeyRef struct {
+	TenantID string
+	Version  int
+}
+
+// AAD returns the additional data a wrapped data key is bound to, so a
+// wrapped key can't be swapped for another tenant's or version's.
+func (r KeyRef) AAD() []byte {
+	return []byte(fmt.Sprintf("%s/%d", r.TenantID, r.Version))
+}
+
+// NewDataKey returns a random data key.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Encrypt encrypts plaintext with the data key ref names.
+func Encrypt(dataKey []byte, ref KeyRef, plaintext []byte) ([]byte, error) {
+	tenant, err := parseUUID(ref.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Version < 1 || int64(ref.Version) > int64(^uint32(0)) {
+		return nil, fmt.Errorf("envelope: invalid data key version %d", ref.Version)
+	}
+	header := make([]byte, headerSize)
+	header[0] = formatVersion
+	copy(header[1:17], tenant[:])
+	binary.BigEndian.PutUint32(header[17:], uint32(ref.Version))
+	return Seal(dataKey, plaintext, header, header)
+}
+
+// ParseRef returns the data key ciphertext was encrypted with.
+func ParseRef(ciphertext []byte) (KeyRef, error) {
+	if len(ciphertext) < headerSize || ciphertext[0] != formatVersion {
+		return KeyRef{}, ErrMalformed
+	}
+	return KeyRef{
+		TenantID: formatUUID(ciphertext[1:17]),
+		Version:  int(binary.BigEndian.Uint32(ciphertext[17:headerSize])),
+	}, nil
+}
+
+// Decrypt decrypts a value encrypted by Encrypt with dataKey, the key named
+// by ParseRef(ciphertext).
+func Decrypt(dataKey, ciphertext []byte) ([]byte, error) {
+	if _, err := ParseRef(ciphertext); err != nil {
+		return nil, err
+	}
+	header := ciphertext[:headerSize]
+	return Open(dataKey, ciphertext[headerSize:], header)
+}
+
+// Seal encrypts plaintext with AES-256-GCM under key, binding aad, and
+// appends nonce and ciphertext to dst.
+func Seal(key, plaintext, aad, dst []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(dst[:len(dst):len(dst)], nonce...)
+	return gcm.Seal(out, nonce, plaintext, aad), nil
+}
+
+// Open decrypts a value sealed by Seal (without dst).
+func Open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("envelope: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func parseUUID(s string) ([16]byte, error) {
+	var out [16]byte
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != 16 || len(s) != 36 {
+		return out, fmt.Errorf("envelope: invalid tenant id %q", s)
+	}
+	copy(out[:], b)
+	return out, nil
+}
+
+func formatUUID(b []byte) string {
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
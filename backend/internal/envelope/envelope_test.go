@@ -0,0 +1,142 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testTenant = "6f1c2a3b-4d5e-4f60-8a9b-0c1d2e3f4a5b"
+
+func mustKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	key := mustKey(t)
+	ref := KeyRef{TenantID: testTenant, Version: 3}
+
+	ct, err := Encrypt(key, ref, []byte("Dobrý den, volám ohledně faktury."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ct, []byte("faktury")) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+	got, err := ParseRef(ct)
+	if err != nil || got != ref {
+		t.Fatalf("ParseRef = %+v, %v; want %+v", got, err, ref)
+	}
+	pt, err := Decrypt(key, ct)
+	if err != nil || string(pt) != "Dobrý den, volám ohledně faktury." {
+		t.Fatalf("Decrypt = %q, %v", pt, err)
+	}
+
+	// Empty values round-trip too
+	ct, _ = Encrypt(key, ref, nil)
+	if pt, err := Decrypt(key, ct); err != nil || len(pt) != 0 {
+		t.Fatalf("Decrypt(empty) = %q, %v", pt, err)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	key := mustKey(t)
+	ct, err := Encrypt(key, KeyRef{TenantID: testTenant, Version: 1}, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving the value to another tenant (rewriting the header) fails
+	moved := bytes.Clone(ct)
+	moved[1] ^= 0xff
+	if _, err := Decrypt(key, moved); err == nil {
+		t.Error("expected an error for a rewritten header")
+	}
+	flipped := bytes.Clone(ct)
+	flipped[len(flipped)-1] ^= 0x01
+	if _, err := Decrypt(key, flipped); err == nil {
+		t.Error("expected an error for a modified ciphertext")
+	}
+	if _, err := Decrypt(mustKey(t), ct); err == nil {
+		t.Error("expected an error for the wrong key")
+	}
+	if _, err := Decrypt(key, []byte("plain")); err != ErrMalformed {
+		t.Errorf("Decrypt(short) = %v, want ErrMalformed", err)
+	}
+}
+
+func TestEncryptValidatesRef(t *testing.T) {
+	key := mustKey(t)
+	if _, err := Encrypt(key, KeyRef{TenantID: "not-a-uuid", Version: 1}, []byte("x")); err == nil {
+		t.Error("expected an error for an invalid tenant id")
+	}
+	if _, err := Encrypt(key, KeyRef{TenantID: testTenant, Version: 0}, []byte("x")); err == nil {
+		t.Error("expected an error for version 0")
+	}
+	if _, err := Encrypt(key[:16], KeyRef{TenantID: testTenant, Version: 1}, []byte("x")); err == nil {
+		t.Error("expected an error for a short key")
+	}
+}
+
+func TestFileKMS(t *testing.T) {
+	k1, k2 := mustKey(t), mustKey(t)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `{"active": "k2", "keys": {"k1": "` + base64.StdEncoding.EncodeToString(k1) +
+		`", "k2": "` + base64.StdEncoding.EncodeToString(k2) + `"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	kms, err := LoadFileKMS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kms.ActiveKeyID() != "k2" {
+		t.Errorf("ActiveKeyID = %q", kms.ActiveKeyID())
+	}
+
+	ctx := context.Background()
+	dataKey := mustKey(t)
+	aad := KeyRef{TenantID: testTenant, Version: 1}.AAD()
+	wrapped, err := kms.Wrap(ctx, "k1", dataKey, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := kms.Unwrap(ctx, "k1", wrapped, aad); err != nil || !bytes.Equal(got, dataKey) {
+		t.Fatalf("Unwrap = %x, %v", got, err)
+	}
+	if _, err := kms.Unwrap(ctx, "k2", wrapped, aad); err == nil {
+		t.Error("expected an error unwrapping with another master key")
+	}
+	if _, err := kms.Unwrap(ctx, "k1", wrapped, KeyRef{TenantID: testTenant, Version: 2}.AAD()); err == nil {
+		t.Error("expected an error unwrapping for another key ref")
+	}
+	if _, err := kms.Wrap(ctx, "k3", dataKey, aad); err == nil {
+		t.Error("expected an error for an unknown master key")
+	}
+}
+
+func TestLoadFileKMSValidates(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"missing active": `{"active": "k9", "keys": {"k1": "` + base64.StdEncoding.EncodeToString(make([]byte, KeySize)) + `"}}`,
+		"short key":      `{"active": "k1", "keys": {"k1": "` + base64.StdEncoding.EncodeToString(make([]byte, 16)) + `"}}`,
+		"not json":       `active=k1`,
+	} {
+		path := filepath.Join(dir, "keys.json")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFileKMS(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package envelope
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// KMS wraps data keys with master keys. Master keys are never handed out;
+// a hosted KMS can implement this with its encrypt/decrypt calls.
+type KMS interface {
+	// ActiveKeyID is the master key new data keys are wrapped with.
+	ActiveKeyID() string
+	// Wrap encrypts dataKey with the master key keyID, binding aad.
+	Wrap(ctx context.Context, keyID string, dataKey, aad []byte) ([]byte, error)
+	// Unwrap decrypts a data key wrapped by Wrap.
+	Unwrap(ctx context.Context, keyID string, wrapped, aad []byte) ([]byte, error)
+}
+
+// FileKMS is a KMS whose master keys are read from a JSON file:
+//
+//	{"active": "2026-10", "keys": {"2026-01": "<base64>", "2026-10": "<base64>"}}
+//
+// Keys are 32 random bytes, base64-encoded (e.g. `openssl rand -base64 32`).
+// Retired keys stay in the file until every data key has been re-wrapped.
+type FileKMS struct {
+	active string
+	keys   map[string][]byte
+}
+
+type kmsFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// LoadFileKMS reads the master key file at path.
+func LoadFileKMS(path string) (*FileKMS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f kmsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("envelope: invalid key file %s: %w", path, err)
+	}
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("envelope: master key %q in %s must be %d base64-encoded bytes", id, path, KeySize)
+		}
+		keys[id] = key
+	}
+	return NewFileKMS(f.Active, keys)
+}
+
+// NewFileKMS returns a KMS with the given master keys.
+func NewFileKMS(active string, keys map[string][]byte) (*FileKMS, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("envelope: active master key %q not found", active)
+	}
+	return &FileKMS{active: active, keys: keys}, nil
+}
+
+// ActiveKeyID implements KMS.
+func (k *FileKMS) ActiveKeyID() string {
+	return k.active
+}
+
+// KeyIDs returns the IDs of the master keys in the file, sorted.
+func (k *FileKMS) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Wrap implements KMS.
+func (k *FileKMS) Wrap(_ context.Context, keyID string, dataKey, aad []byte) ([]byte, error) {
+	master, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("envelope: unknown master key %q", keyID)
+	}
+	return Seal(master, dataKey, aad, nil)
+}
+
+// Unwrap implements KMS.
+func (k *FileKMS) Unwrap(_ context.Context, keyID string, wrapped, aad []byte) ([]byte, error) {
+	master, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("envelope: unknown master key %q", keyID)
+	}
+	return Open(master, wrapped, aad)
+}
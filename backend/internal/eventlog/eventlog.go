@@ -54,11 +54,34 @@ const (
 	// STT diagnostic events
 	EventSTTEmptyStreak       EventType = "stt_empty_streak"
 	EventAudioSilenceDetected EventType = "audio_silence_detected"
+
+	// Provider failover events
+	EventProviderFailure EventType = "provider_failure"
+
+	// Cost budget events
+	EventCostBudgetDegraded EventType = "cost_budget_degraded"
+
+	// Experiment events
+	EventExperimentAssigned EventType = "experiment_assigned"
+
+	// Knowledge base events
+	EventKnowledgeRetrieved EventType = "knowledge_retrieved"
+
+	// Call field (slot) events
+	EventCallFieldsUpdated EventType = "call_fields_updated"
 )
 
+// Sealer encrypts the caller speech in event data before it is stored. It
+// returns the data to store in plaintext and the encrypted fields, or data
+// unchanged and nil if the call isn't encrypted.
+type Sealer interface {
+	SealEventData(ctx context.Context, callID string, data map[string]any) (map[string]any, []byte, error)
+}
+
 // Logger provides async event logging to the database
 type Logger struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	sealer Sealer
 }
 
 // New creates a new event logger
@@ -66,21 +89,35 @@ func New(db *pgxpool.Pool) *Logger {
 	return &Logger{db: db}
 }
 
+// SetSealer makes the logger encrypt caller speech in event data with s.
+func (l *Logger) SetSealer(s Sealer) {
+	l.sealer = s
+}
+
 // Log writes an event to the database synchronously
 func (l *Logger) Log(ctx context.Context, callID string, eventType EventType, data map[string]any) error {
 	if l.db == nil || callID == "" {
 		return nil // Silently skip if no DB or call ID
 	}
 
+	var enc []byte
+	if l.sealer != nil {
+		var err error
+		// An event that can't be sealed is dropped rather than stored in plaintext
+		if data, enc, err = l.sealer.SealEventData(ctx, callID, data); err != nil {
+			return err
+		}
+	}
+
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
 		dataJSON = []byte("{}")
 	}
 
 	_, err = l.db.Exec(ctx, `
-		INSERT INTO call_events (call_id, event_type, event_data)
-		VALUES ($1, $2, $3)
-	`, callID, string(eventType), dataJSON)
+		INSERT INTO call_events (call_id, event_type, event_data, event_data_enc)
+		VALUES ($1, $2, $3, $4)
+	`, callID, string(eventType), dataJSON, enc)
 
 	return err
 }
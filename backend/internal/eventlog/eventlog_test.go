@@ -28,6 +28,7 @@ func TestEventTypeConstants(t *testing.T) {
 		EventTTSStarted:       "tts_started",
 		EventTTSCompleted:     "tts_completed",
 		EventTTSError:         "tts_error",
+		EventProviderFailure:  "provider_failure",
 	}
 
 	for eventType, expectedValue := range expectedEvents {
@@ -0,0 +1,160 @@
+// Package experiments assigns calls to A/B experiment variants.
+//
+// An experiment has weighted variants; each variant overrides tenant config
+// fields (prompt, voice, turn-taking parameters) and/or global config keys for
+// the calls assigned to it. Assignment is a deterministic hash of the
+// experiment ID and call SID, so a call always lands in the same variant.
+package experiments
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Experiment statuses.
+const (
+	StatusDraft   = "draft"
+	StatusRunning = "running"
+	StatusStopped = "stopped"
+)
+
+// Variant is one arm of an experiment.
+type Variant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"` // Relative share of traffic
+	// TenantConfig overrides fields of the call's tenant config (see TenantConfigFields)
+	TenantConfig map[string]any `json:"tenant_config,omitempty"`
+	// GlobalConfig overrides global config keys for the call
+	GlobalConfig map[string]string `json:"global_config,omitempty"`
+}
+
+// TenantConfigFields are the tenant config fields a variant may override,
+// mapped to their JSON type ("string" or "int").
+var TenantConfigFields = map[string]string{
+	"system_prompt":       "string",
+	"greeting_text":       "string",
+	"voice_id":            "string",
+	"language":            "string",
+	"endpointing":         "int",
+	"utterance_end":       "int",
+	"max_turn_timeout_ms": "int",
+}
+
+// SystemPrompt returns the variant's system prompt override, or "" if it
+// keeps the tenant's prompt.
+func (v Variant) SystemPrompt() string {
+	prompt, _ := v.TenantConfig["system_prompt"].(string)
+	return prompt
+}
+
+// VariantPrompt returns the system prompt override of the named variant, or
+// "" if it has none.
+func VariantPrompt(variants []Variant, name string) string {
+	for _, v := range variants {
+		if v.Name == name {
+			return v.SystemPrompt()
+		}
+	}
+	return ""
+}
+
+// ValidateVariants checks variant names, weights and tenant config overrides.
+// Global config keys are validated by the caller, which knows the key set.
+// A global experiment (global = true, no tenant) can't override system_prompt:
+// it would replace every tenant's own prompt.
+func ValidateVariants(variants []Variant, global bool) error {
+	if len(variants) < 2 {
+		return fmt.Errorf("at least two variants are required")
+	}
+	seen := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		name := strings.TrimSpace(v.Name)
+		if name == "" {
+			return fmt.Errorf("variant name is required")
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate variant %q", name)
+		}
+		seen[name] = true
+		if v.Weight <= 0 {
+			return fmt.Errorf("variant %q: weight must be positive", name)
+		}
+		for field, value := range v.TenantConfig {
+			switch TenantConfigFields[field] {
+			case "string":
+				if _, ok := value.(string); !ok {
+					return fmt.Errorf("variant %q: %s must be a string", name, field)
+				}
+			case "int":
+				n, ok := value.(float64) // JSON numbers
+				if !ok || n != float64(int(n)) || n <= 0 {
+					return fmt.Errorf("variant %q: %s must be a positive integer", name, field)
+				}
+			default:
+				return fmt.Errorf("variant %q: tenant config field %s cannot be overridden", name, field)
+			}
+			if field == "system_prompt" && global {
+				return fmt.Errorf("variant %q: system_prompt can only be overridden in a tenant experiment", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Assign picks the variant for a call. The same experiment and call SID
+// always yield the same variant; across calls, traffic follows the weights.
+// Returns nil if there are no variants with positive weight.
+func Assign(experimentID, callSid string, variants []Variant) *Variant {
+	total := 0
+	for _, v := range variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(experimentID))
+	h.Write([]byte{0})
+	h.Write([]byte(callSid))
+	bucket := int(h.Sum32() % uint32(total))
+
+	for i := range variants {
+		if variants[i].Weight <= 0 {
+			continue
+		}
+		if bucket < variants[i].Weight {
+			return &variants[i]
+		}
+		bucket -= variants[i].Weight
+	}
+	return nil
+}
+
+// ApplyTenantConfig overlays a variant's tenant config overrides onto cfg,
+// which must be a pointer to a JSON-tagged struct (the call's tenant config).
+func ApplyTenantConfig(cfg any, overrides map[string]any) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	for k, v := range overrides {
+		fields[k] = v
+	}
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, cfg)
+}
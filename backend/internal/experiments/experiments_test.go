@@ -0,0 +1,135 @@
+package experiments
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAssign_DeterministicAndWeighted(t *testing.T) {
+	variants := []Variant{
+		{Name: "control", Weight: 3},
+		{Name: "fast_turns", Weight: 1},
+	}
+
+	first := Assign("exp-1", "CA123", variants)
+	if first == nil {
+		t.Fatal("Assign() = nil, want a variant")
+	}
+	for i := 0; i < 10; i++ {
+		if got := Assign("exp-1", "CA123", variants); got.Name != first.Name {
+			t.Fatalf("Assign() = %q, then %q; want stable assignment", first.Name, got.Name)
+		}
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[Assign("exp-1", fmt.Sprintf("CA%06d", i), variants).Name]++
+	}
+	// Expect ~3000/1000; allow generous slack
+	if counts["control"] < 2700 || counts["control"] > 3300 {
+		t.Errorf("control got %d of 4000 calls, want ~3000", counts["control"])
+	}
+}
+
+func TestAssign_NoWeight(t *testing.T) {
+	if got := Assign("exp-1", "CA1", []Variant{{Name: "a"}}); got != nil {
+		t.Errorf("Assign() = %v, want nil", got)
+	}
+}
+
+func TestValidateVariants(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+		wantErr  string
+	}{
+		{
+			name: "valid",
+			variants: []Variant{
+				{Name: "control", Weight: 1},
+				{Name: "b", Weight: 1, TenantConfig: map[string]any{"endpointing": float64(600), "voice_id": "abc"}},
+			},
+		},
+		{"single variant", []Variant{{Name: "a", Weight: 1}}, "at least two"},
+		{"duplicate", []Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}, "duplicate"},
+		{"zero weight", []Variant{{Name: "a", Weight: 1}, {Name: "b"}}, "weight"},
+		{
+			"unknown field",
+			[]Variant{{Name: "a", Weight: 1}, {Name: "b", Weight: 1, TenantConfig: map[string]any{"owner_phone": "+420"}}},
+			"cannot be overridden",
+		},
+		{
+			"wrong type",
+			[]Variant{{Name: "a", Weight: 1}, {Name: "b", Weight: 1, TenantConfig: map[string]any{"endpointing": "fast"}}},
+			"positive integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVariants(tt.variants, false)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateVariants() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateVariants() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVariants_GlobalPrompt(t *testing.T) {
+	variants := []Variant{
+		{Name: "a", Weight: 1},
+		{Name: "b", Weight: 1, TenantConfig: map[string]any{"system_prompt": "Jsi Karen."}},
+	}
+	if err := ValidateVariants(variants, false); err != nil {
+		t.Errorf("tenant experiment: error = %v, want nil", err)
+	}
+	if err := ValidateVariants(variants, true); err == nil || !strings.Contains(err.Error(), "tenant experiment") {
+		t.Errorf("global experiment: error = %v, want system_prompt rejected", err)
+	}
+}
+
+func TestVariantPrompt(t *testing.T) {
+	variants := []Variant{
+		{Name: "a", Weight: 1, TenantConfig: map[string]any{"voice_id": "v"}},
+		{Name: "b", Weight: 1, TenantConfig: map[string]any{"system_prompt": "Jsi Karen."}},
+	}
+	if got := VariantPrompt(variants, "b"); got != "Jsi Karen." {
+		t.Errorf("VariantPrompt(b) = %q", got)
+	}
+	if got := VariantPrompt(variants, "a"); got != "" {
+		t.Errorf("VariantPrompt(a) = %q, want empty", got)
+	}
+	if got := VariantPrompt(variants, "missing"); got != "" {
+		t.Errorf("VariantPrompt(missing) = %q, want empty", got)
+	}
+}
+
+func TestApplyTenantConfig(t *testing.T) {
+	type config struct {
+		TenantID    string  `json:"tenant_id,omitempty"`
+		VoiceID     *string `json:"voice_id,omitempty"`
+		Endpointing *int    `json:"endpointing,omitempty"`
+		Language    string  `json:"language,omitempty"`
+	}
+	cfg := config{TenantID: "t1", Language: "cs"}
+
+	err := ApplyTenantConfig(&cfg, map[string]any{"voice_id": "voice-b", "endpointing": float64(600)})
+	if err != nil {
+		t.Fatalf("ApplyTenantConfig() error = %v", err)
+	}
+	if cfg.TenantID != "t1" || cfg.Language != "cs" {
+		t.Errorf("untouched fields changed: %+v", cfg)
+	}
+	if cfg.VoiceID == nil || *cfg.VoiceID != "voice-b" {
+		t.Errorf("VoiceID = %v, want voice-b", cfg.VoiceID)
+	}
+	if cfg.Endpointing == nil || *cfg.Endpointing != 600 {
+		t.Errorf("Endpointing = %v, want 600", cfg.Endpointing)
+	}
+}
@@ -0,0 +1,169 @@
+// Package export writes tabular data as CSV, NDJSON or XLSX, one row at a
+// time, so large exports can be streamed without holding them in memory.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// Writer writes rows under a header. Values are strings, ints, float64s,
+// *time.Time or time.Time, or nil for an empty cell.
+type Writer interface {
+	WriteRow(values []any) error
+	// Close flushes the output; it doesn't close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a writer for the format that writes to w, starting with
+// the header (the column names; NDJSON uses them as object keys).
+func NewWriter(format string, w io.Writer, header []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, header)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w), header: header}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, header)
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ValidFormat reports whether format is supported.
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatNDJSON || format == FormatXLSX
+}
+
+// formatText returns the text form of a value (times in RFC 3339).
+func formatText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	// UTF-8 BOM so Excel shows Czech characters correctly
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteRow writes the row, escaping text cells that spreadsheets would read
+// as formulas or numbers (see csvText).
+func (c *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			record[i] = csvText(s)
+		} else {
+			record[i] = formatText(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+// csvText prefixes text starting with a formula character (=, +, -, @, tab
+// or CR) with ', so spreadsheets show caller and model text as text instead
+// of evaluating it (CSV injection), and keep +420... numbers as typed. XLSX
+// cells are typed and need no escaping.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	w      *bufio.Writer
+	header []string
+}
+
+// WriteRow writes the row as a JSON object with keys in header order.
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	n.w.WriteByte('{')
+	for i, key := range n.header {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		n.w.Write(k)
+		n.w.WriteByte(':')
+
+		var v any
+		if i < len(values) {
+			v = values[i]
+		}
+		if t, ok := v.(*time.Time); ok && t == nil {
+			v = nil
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		n.w.Write(raw)
+	}
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
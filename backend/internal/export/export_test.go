@@ -0,0 +1,147 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testHeader = []string{"call", "started_at", "duration_seconds", "confidence", "note"}
+	testTime   = time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	testRows   = [][]any{
+		{"CA1", testTime, 95, 0.9, "Oprava střechy, \"zatéká\""},
+		{"CA2", (*time.Time)(nil), nil, nil, "řádek\ndruhý"},
+	}
+)
+
+func writeAll(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, testHeader)
+	if err != nil {
+		t.Fatalf("NewWriter(%s) failed: %v", format, err)
+	}
+	for _, row := range testRows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	got := string(writeAll(t, FormatCSV))
+	want := "\ufeffcall,started_at,duration_seconds,confidence,note\n" +
+		"CA1,2025-03-14T09:30:00Z,95,0.9,\"Oprava střechy, \"\"zatéká\"\"\"\n" +
+		"CA2,,,,\"řádek\ndruhý\"\n"
+	if got != want {
+		t.Errorf("CSV =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCSVWriter_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, []string{"from_number", "intent_text", "summary", "note", "count", "plain"})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.WriteRow([]any{"+420777123456", "=HYPERLINK(\"http://x\")", "@SUM(A1)", "-1+1", -3, "Oprava"}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	if err := w.WriteRow([]any{"\tx", "\rx", "", nil, 0, "a=b"}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		`'+420777123456,"'=HYPERLINK(""http://x"")",'@SUM(A1),'-1+1,-3,Oprava`,
+		"'\tx,\"'\rx\",,,0,a=b",
+	}
+	if len(lines) != 3 || lines[1] != want[0] || lines[2] != want[1] {
+		t.Errorf("CSV rows =\n%q\nwant\n%q", lines[1:], want)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(string(writeAll(t, FormatNDJSON)), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], `{"call":"CA1","started_at":"2025-03-14T09:30:00Z","duration_seconds":95`) {
+		t.Errorf("keys should follow the header order: %s", lines[0])
+	}
+	var second map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("invalid JSON %q: %v", lines[1], err)
+	}
+	if second["started_at"] != nil || second["note"] != "řádek\ndruhý" {
+		t.Errorf("unexpected object %v", second)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := writeAll(t, FormatXLSX)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip: %v", err)
+	}
+
+	var sheet string
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(b)
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if !names[name] {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">call</t></is></c>`,
+		`<c r="C2"><v>95</v></c>`,
+		`<c r="B2" s="1"><v>45730.395833</v></c>`,
+		`Oprava střechy, &#34;zatéká&#34;`,
+		`<row r="3"><c r="A3"`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet doesn't contain %q:\n%s", want, sheet)
+		}
+	}
+	if strings.Contains(sheet, `r="B3"`) {
+		t.Error("nil values should produce no cell")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard, testHeader); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if ValidFormat("pdf") || !ValidFormat(FormatXLSX) {
+		t.Error("ValidFormat mismatch")
+	}
+}
@@ -3,6 +3,7 @@ package httpapi
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/getsentry/sentry-go"
 	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/retention"
+	"github.com/lukasbauer/karen/internal/store"
 )
 
 // withAdmin is middleware that requires admin authentication.
@@ -37,7 +40,7 @@ func (r *Router) withAdmin(next http.HandlerFunc) http.HandlerFunc {
 func (r *Router) handleAdminListPhoneNumbers(w http.ResponseWriter, req *http.Request) {
 	numbers, err := r.store.ListAllPhoneNumbers(req.Context())
 	if err != nil {
-		r.logger.Printf("admin: failed to list phone numbers: %v", err)
+		r.logger.Error("admin: failed to list phone numbers", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list phone numbers"}`, http.StatusInternalServerError)
 		return
@@ -70,41 +73,72 @@ func (r *Router) handleAdminAddPhoneNumber(w http.ResponseWriter, req *http.Requ
 			http.Error(w, `{"error": "phone number already exists"}`, http.StatusConflict)
 			return
 		}
-		r.logger.Printf("admin: failed to add phone number: %v", err)
+		r.logger.Error("admin: failed to add phone number", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to add phone number"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("admin: added phone number %s to pool", body.TwilioNumber)
+	r.logger.Info("admin: added phone number to pool", "twilio_number", body.TwilioNumber)
 	writeJSON(w, http.StatusCreated, pn)
 }
 
 // handleAdminDeletePhoneNumber removes a phone number from the system.
 func (r *Router) handleAdminDeletePhoneNumber(w http.ResponseWriter, req *http.Request) {
 	id := req.PathValue("id")
-	if id == "" {
-		http.Error(w, `{"error": "missing id"}`, http.StatusBadRequest)
+	before, ok := r.loadAdminPhoneNumber(w, req, id)
+	if !ok {
 		return
 	}
 
 	err := r.store.DeletePhoneNumber(req.Context(), id)
 	if err != nil {
-		r.logger.Printf("admin: failed to delete phone number %s: %v", id, err)
+		r.logger.Error("admin: failed to delete phone number", "id", id, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to delete phone number"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("admin: deleted phone number %s", id)
+	r.logger.Info("admin: deleted phone number", "id", id)
+	r.recordAudit(req, phoneNumberAuditEntry(store.AuditActionPhoneNumberDelete, id, before.TenantID), before, nil)
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
 
+// loadAdminPhoneNumber returns the phone number with the given ID, writing a
+// 404 or 500 response if it can't be loaded.
+func (r *Router) loadAdminPhoneNumber(w http.ResponseWriter, req *http.Request, id string) (*store.AdminPhoneNumber, bool) {
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "phone number not found"}`, http.StatusNotFound)
+		return nil, false
+	}
+	pn, err := r.store.GetPhoneNumber(req.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, `{"error": "phone number not found"}`, http.StatusNotFound)
+			return nil, false
+		}
+		r.logger.Error("admin: failed to load phone number", "id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to load phone number"}`, http.StatusInternalServerError)
+		return nil, false
+	}
+	return pn, true
+}
+
+// phoneNumberAuditEntry is an admin change to a phone number, shown to the
+// tenant it is (or was) assigned to.
+func phoneNumberAuditEntry(action, id string, tenantID *string) store.AuditEntry {
+	return store.AuditEntry{
+		ActorType: store.AuditActorAdmin, Action: action,
+		TargetType: store.AuditTargetPhoneNumber, TargetID: id, TenantID: tenantID,
+	}
+}
+
 // handleAdminListTenants returns all tenants for admin dropdowns.
 func (r *Router) handleAdminListTenants(w http.ResponseWriter, req *http.Request) {
 	tenants, err := r.store.ListAllTenants(req.Context())
 	if err != nil {
-		r.logger.Printf("admin: failed to list tenants: %v", err)
+		r.logger.Error("admin: failed to list tenants", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list tenants"}`, http.StatusInternalServerError)
 		return
@@ -116,8 +150,8 @@ func (r *Router) handleAdminListTenants(w http.ResponseWriter, req *http.Request
 // handleAdminUpdatePhoneNumber updates a phone number's assignment.
 func (r *Router) handleAdminUpdatePhoneNumber(w http.ResponseWriter, req *http.Request) {
 	id := req.PathValue("id")
-	if id == "" {
-		http.Error(w, `{"error": "missing id"}`, http.StatusBadRequest)
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "phone number not found"}`, http.StatusNotFound)
 		return
 	}
 
@@ -136,15 +170,31 @@ func (r *Router) handleAdminUpdatePhoneNumber(w http.ResponseWriter, req *http.R
 		tenantID = body.TenantID
 	}
 
+	before, ok := r.loadAdminPhoneNumber(w, req, id)
+	if !ok {
+		return
+	}
+
 	err := r.store.UpdatePhoneNumber(req.Context(), id, tenantID)
 	if err != nil {
-		r.logger.Printf("admin: failed to update phone number %s: %v", id, err)
+		r.logger.Error("admin: failed to update phone number", "id", id, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to update phone number"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("admin: updated phone number %s", id)
+	r.logger.Info("admin: updated phone number", "id", id)
+	if after, err := r.store.GetPhoneNumber(req.Context(), id); err == nil {
+		// Shown to the tenant the number moved to, or the one it left
+		auditTenant := after.TenantID
+		if auditTenant == nil {
+			auditTenant = before.TenantID
+		}
+		r.recordAudit(req, phoneNumberAuditEntry(store.AuditActionPhoneNumberUpdate, id, auditTenant), before, after)
+	} else {
+		r.logger.Error("audit: failed to load updated phone number", "id", id, "error", err)
+		sentry.CaptureException(err)
+	}
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
 
@@ -159,7 +209,7 @@ func (r *Router) handleAdminListCalls(w http.ResponseWriter, req *http.Request)
 
 	calls, err := r.store.ListCalls(req.Context(), limit)
 	if err != nil {
-		r.logger.Printf("admin: failed to list calls: %v", err)
+		r.logger.Error("admin: failed to list calls", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list calls"}`, http.StatusInternalServerError)
 		return
@@ -182,7 +232,7 @@ func (r *Router) handleAdminGetCallDetail(w http.ResponseWriter, req *http.Reque
 			http.Error(w, `{"error": "call not found"}`, http.StatusNotFound)
 			return
 		}
-		r.logger.Printf("admin: failed to get call detail %s: %v", providerCallID, err)
+		r.logger.Error("admin: failed to get call detail", "provider_call_id", providerCallID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get call detail"}`, http.StatusInternalServerError)
 		return
@@ -206,7 +256,7 @@ func (r *Router) handleAdminGetCallEvents(w http.ResponseWriter, req *http.Reque
 			http.Error(w, `{"error": "call not found"}`, http.StatusNotFound)
 			return
 		}
-		r.logger.Printf("admin: failed to get call ID %s: %v", providerCallID, err)
+		r.logger.Error("admin: failed to get call ID", "provider_call_id", providerCallID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get call"}`, http.StatusInternalServerError)
 		return
@@ -214,7 +264,7 @@ func (r *Router) handleAdminGetCallEvents(w http.ResponseWriter, req *http.Reque
 
 	events, err := r.store.ListCallEvents(req.Context(), callID, 1000)
 	if err != nil {
-		r.logger.Printf("admin: failed to list events for %s: %v", callID, err)
+		r.logger.Error("admin: failed to list events", "call_id", callID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list events"}`, http.StatusInternalServerError)
 		return
@@ -231,7 +281,7 @@ func (r *Router) handleAdminGetCallEvents(w http.ResponseWriter, req *http.Reque
 func (r *Router) handleAdminListTenantsWithDetails(w http.ResponseWriter, req *http.Request) {
 	tenants, err := r.store.ListAllTenantsWithDetails(req.Context())
 	if err != nil {
-		r.logger.Printf("admin: failed to list tenants with details: %v", err)
+		r.logger.Error("admin: failed to list tenants with details", "error", err)
 		http.Error(w, `{"error": "failed to list tenants"}`, http.StatusInternalServerError)
 		return
 	}
@@ -249,7 +299,7 @@ func (r *Router) handleAdminGetTenantUsers(w http.ResponseWriter, req *http.Requ
 
 	users, err := r.store.ListUsersByTenant(req.Context(), tenantID)
 	if err != nil {
-		r.logger.Printf("admin: failed to list users for tenant %s: %v", tenantID, err)
+		r.logger.Error("admin: failed to list users", "tenant_id", tenantID, "error", err)
 		http.Error(w, `{"error": "failed to list users"}`, http.StatusInternalServerError)
 		return
 	}
@@ -274,7 +324,7 @@ func (r *Router) handleAdminGetTenantCalls(w http.ResponseWriter, req *http.Requ
 
 	calls, err := r.store.ListCallsByTenantWithDetails(req.Context(), tenantID, limit)
 	if err != nil {
-		r.logger.Printf("admin: failed to list calls for tenant %s: %v", tenantID, err)
+		r.logger.Error("admin: failed to list calls", "tenant_id", tenantID, "error", err)
 		http.Error(w, `{"error": "failed to list calls"}`, http.StatusInternalServerError)
 		return
 	}
@@ -297,6 +347,8 @@ func (r *Router) handleAdminUpdateTenant(w http.ResponseWriter, req *http.Reques
 		TrialEndsAt        *time.Time `json:"trial_ends_at,omitempty"`
 		CurrentPeriodCalls *int       `json:"current_period_calls,omitempty"`
 		AdminNotes         *string    `json:"admin_notes,omitempty"`
+		// Monthly provider-cost budget override in cents; -1 reverts to the plan default
+		MonthlyCostBudgetCents *int `json:"monthly_cost_budget_cents,omitempty"`
 	}
 
 	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
@@ -332,9 +384,26 @@ func (r *Router) handleAdminUpdateTenant(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	// Validate monthly_cost_budget_cents if provided (>= 0, or -1 to clear)
+	if body.MonthlyCostBudgetCents != nil && *body.MonthlyCostBudgetCents < -1 {
+		http.Error(w, `{"error": "monthly_cost_budget_cents must be >= 0, or -1 for the plan default"}`, http.StatusBadRequest)
+		return
+	}
+
+	before, err := r.store.GetTenantAdminSettings(req.Context(), tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		r.logger.Error("admin: failed to load tenant", "tenant_id", tenantID, "error", err)
+		http.Error(w, `{"error": "failed to update tenant"}`, http.StatusInternalServerError)
+		return
+	}
+
 	rowsAffected, err := r.store.UpdateTenantPlanStatus(req.Context(), tenantID, body.Plan, body.Status)
 	if err != nil {
-		r.logger.Printf("admin: failed to update tenant %s: %v", tenantID, err)
+		r.logger.Error("admin: failed to update tenant", "tenant_id", tenantID, "error", err)
 		http.Error(w, `{"error": "failed to update tenant"}`, http.StatusInternalServerError)
 		return
 	}
@@ -350,7 +419,7 @@ func (r *Router) handleAdminUpdateTenant(w http.ResponseWriter, req *http.Reques
 			"max_turn_timeout_ms": *body.MaxTurnTimeoutMs,
 		})
 		if err != nil {
-			r.logger.Printf("admin: failed to update tenant config %s: %v", tenantID, err)
+			r.logger.Error("admin: failed to update tenant config", "tenant_id", tenantID, "error", err)
 			http.Error(w, `{"error": "failed to update tenant config"}`, http.StatusInternalServerError)
 			return
 		}
@@ -371,17 +440,33 @@ func (r *Router) handleAdminUpdateTenant(w http.ResponseWriter, req *http.Reques
 	if body.AdminNotes != nil {
 		billingUpdates["admin_notes"] = *body.AdminNotes
 	}
+	if body.MonthlyCostBudgetCents != nil {
+		if *body.MonthlyCostBudgetCents < 0 {
+			billingUpdates["monthly_cost_budget_cents"] = nil
+		} else {
+			billingUpdates["monthly_cost_budget_cents"] = *body.MonthlyCostBudgetCents
+		}
+	}
 
 	if len(billingUpdates) > 0 {
 		err := r.store.AdminUpdateTenantBilling(req.Context(), tenantID, billingUpdates)
 		if err != nil {
-			r.logger.Printf("admin: failed to update tenant billing %s: %v", tenantID, err)
+			r.logger.Error("admin: failed to update tenant billing", "tenant_id", tenantID, "error", err)
 			http.Error(w, `{"error": "failed to update tenant billing"}`, http.StatusInternalServerError)
 			return
 		}
 	}
 
-	r.logger.Printf("admin: updated tenant %s plan=%s status=%s", tenantID, body.Plan, body.Status)
+	r.logger.Info("admin: updated tenant", "tenant_id", tenantID, "plan", body.Plan, "status", body.Status)
+	if after, err := r.store.GetTenantAdminSettings(req.Context(), tenantID); err == nil {
+		r.recordAudit(req, store.AuditEntry{
+			ActorType: store.AuditActorAdmin, Action: store.AuditActionTenantUpdate,
+			TargetType: store.AuditTargetTenant, TargetID: tenantID, TenantID: &tenantID,
+		}, before, after)
+	} else {
+		r.logger.Error("audit: failed to load updated tenant", "tenant_id", tenantID, "error", err)
+		sentry.CaptureException(err)
+	}
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
 
@@ -402,7 +487,7 @@ func (r *Router) handleAdminResetUserOnboarding(w http.ResponseWriter, req *http
 			http.Error(w, `{"error": "user not found"}`, http.StatusNotFound)
 			return
 		}
-		r.logger.Printf("admin: failed to get user %s: %v", userID, err)
+		r.logger.Error("admin: failed to get user", "user_id", userID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get user"}`, http.StatusInternalServerError)
 		return
@@ -417,7 +502,7 @@ func (r *Router) handleAdminResetUserOnboarding(w http.ResponseWriter, req *http
 	// Perform the reset
 	previousTenantID, err := r.store.ResetUserOnboarding(req.Context(), userID)
 	if err != nil {
-		r.logger.Printf("admin: failed to reset onboarding for user %s: %v", userID, err)
+		r.logger.Error("admin: failed to reset onboarding", "user_id", userID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to reset onboarding"}`, http.StatusInternalServerError)
 		return
@@ -427,8 +512,7 @@ func (r *Router) handleAdminResetUserOnboarding(w http.ResponseWriter, req *http
 	if previousTenantID != nil {
 		tenantIDStr = *previousTenantID
 	}
-	r.logger.Printf("admin: reset onboarding for user %s (phone: %s, previous tenant: %s)",
-		userID, user.Phone, tenantIDStr)
+	r.logger.Info("admin: reset onboarding", "user_id", userID, "phone", user.Phone, "previous_tenant", tenantIDStr)
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"success":            true,
@@ -445,23 +529,40 @@ func (r *Router) handleAdminDeleteTenant(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	err := r.store.DeleteTenant(req.Context(), tenantID)
+	tenant, err := r.store.GetTenantByID(req.Context(), tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		r.logger.Error("admin: failed to load tenant", "tenant_id", tenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to delete tenant"}`, http.StatusInternalServerError)
+		return
+	}
+
+	err = r.store.DeleteTenant(req.Context(), tenantID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
 			return
 		}
-		r.logger.Printf("admin: failed to delete tenant %s: %v", tenantID, err)
+		r.logger.Error("admin: failed to delete tenant", "tenant_id", tenantID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to delete tenant"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("admin: deleted tenant %s and all associated data", tenantID)
+	r.logger.Info("admin: deleted tenant and all associated data", "tenant_id", tenantID)
+	r.recordAudit(req, store.AuditEntry{
+		ActorType: store.AuditActorAdmin, Action: store.AuditActionTenantDelete,
+		TargetType: store.AuditTargetTenant, TargetID: tenantID, TenantID: &tenantID,
+	}, map[string]any{"name": tenant.Name, "plan": tenant.Plan, "status": tenant.Status}, nil)
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
 
-// handleAdminGetTenantCosts returns cost summary for a tenant for a specific period.
+// handleAdminGetTenantCosts returns cost summary for a tenant for a specific period,
+// including the provider-cost budget state for that period.
 // Query param: period (YYYY-MM format, defaults to current month)
 func (r *Router) handleAdminGetTenantCosts(w http.ResponseWriter, req *http.Request) {
 	tenantID := req.PathValue("tenantId")
@@ -484,12 +585,20 @@ func (r *Router) handleAdminGetTenantCosts(w http.ResponseWriter, req *http.Requ
 
 	summary, err := r.store.GetTenantCostSummary(req.Context(), tenantID, period)
 	if err != nil {
-		r.logger.Printf("admin: failed to get cost summary for tenant %s: %v", tenantID, err)
+		r.logger.Error("admin: failed to get cost summary", "tenant_id", tenantID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get cost summary"}`, http.StatusInternalServerError)
 		return
 	}
 
+	budget, err := costBudgetFor(req.Context(), r.store, tenantID, summary.TotalAPICostCents)
+	if err != nil {
+		r.logger.Error("admin: failed to get cost budget", "tenant_id", tenantID, "error", err)
+		sentry.CaptureException(err)
+	} else {
+		summary.Budget = &budget
+	}
+
 	writeJSON(w, http.StatusOK, summary)
 }
 
@@ -501,7 +610,7 @@ func (r *Router) handleAdminGetTenantCosts(w http.ResponseWriter, req *http.Requ
 func (r *Router) handleAdminListGlobalConfig(w http.ResponseWriter, req *http.Request) {
 	entries, err := r.store.ListGlobalConfig(req.Context())
 	if err != nil {
-		r.logger.Printf("admin: failed to list global config: %v", err)
+		r.logger.Error("admin: failed to list global config", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list global config"}`, http.StatusInternalServerError)
 		return
@@ -510,6 +619,61 @@ func (r *Router) handleAdminListGlobalConfig(w http.ResponseWriter, req *http.Re
 	writeJSON(w, http.StatusOK, map[string]any{"config": entries})
 }
 
+// globalConfigNumericKeys are global config keys whose values must be integers.
+var globalConfigNumericKeys = map[string]bool{
+	"max_turn_timeout_ms":                       true,
+	"adaptive_min_timeout_ms":                   true,
+	"adaptive_text_decay_rate_ms":               true,
+	"adaptive_sentence_end_bonus_ms":            true,
+	"robocall_max_call_duration_ms":             true,
+	"robocall_silence_threshold_ms":             true,
+	"robocall_barge_in_threshold":               true,
+	"robocall_barge_in_window_ms":               true,
+	"robocall_repetition_threshold":             true,
+	"cost_budget_trial_cents":                   true,
+	"cost_budget_basic_cents":                   true,
+	"cost_budget_pro_cents":                     true,
+	"cost_budget_degrade_percent":               true,
+	"cost_budget_degraded_max_call_duration_ms": true,
+	"retention_transcript_days":                 true,
+	"retention_event_days":                      true,
+	"retention_call_days":                       true,
+}
+
+// globalConfigRetentionKeys are the default retention periods in days.
+var globalConfigRetentionKeys = map[string]bool{
+	retention.ConfigTranscriptDays: true,
+	retention.ConfigEventDays:      true,
+	retention.ConfigCallDays:       true,
+}
+
+// globalConfigBoolKeys are global config keys whose values must be "true" or "false".
+var globalConfigBoolKeys = map[string]bool{
+	"adaptive_turn_enabled":      true,
+	"robocall_detection_enabled": true,
+	"stt_debug_enabled":          true,
+}
+
+// validateGlobalConfigValue checks the value format of known numeric and
+// boolean keys. Returns an error message, or "" if the value is valid.
+func validateGlobalConfigValue(key, value string) string {
+	if globalConfigNumericKeys[key] {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "value must be a number"
+		}
+		if globalConfigRetentionKeys[key] && !retention.ValidDays(n) {
+			return fmt.Sprintf("value must be between 0 and %d days", retention.MaxDays)
+		}
+	}
+	if globalConfigBoolKeys[key] {
+		if value != "true" && value != "false" {
+			return "value must be 'true' or 'false'"
+		}
+	}
+	return ""
+}
+
 // handleAdminUpdateGlobalConfig updates a global config value.
 func (r *Router) handleAdminUpdateGlobalConfig(w http.ResponseWriter, req *http.Request) {
 	key := req.PathValue("key")
@@ -528,50 +692,49 @@ func (r *Router) handleAdminUpdateGlobalConfig(w http.ResponseWriter, req *http.
 	}
 
 	// Validate the key exists
-	_, err := r.store.GetGlobalConfig(req.Context(), key)
+	oldValue, err := r.store.GetGlobalConfig(req.Context(), key)
 	if err != nil {
 		http.Error(w, `{"error": "config key not found"}`, http.StatusNotFound)
 		return
 	}
 
-	// Validate numeric values for known numeric keys
-	numericKeys := map[string]bool{
-		"max_turn_timeout_ms":            true,
-		"adaptive_min_timeout_ms":        true,
-		"adaptive_text_decay_rate_ms":    true,
-		"adaptive_sentence_end_bonus_ms": true,
-		"robocall_max_call_duration_ms":  true,
-		"robocall_silence_threshold_ms":  true,
-		"robocall_barge_in_threshold":    true,
-		"robocall_barge_in_window_ms":    true,
-		"robocall_repetition_threshold":  true,
-	}
-	if numericKeys[key] {
-		if _, err := strconv.Atoi(body.Value); err != nil {
-			http.Error(w, `{"error": "value must be a number"}`, http.StatusBadRequest)
-			return
-		}
-	}
-
-	// Validate boolean values for known boolean keys
-	boolKeys := map[string]bool{
-		"adaptive_turn_enabled":      true,
-		"robocall_detection_enabled": true,
-	}
-	if boolKeys[key] {
-		if body.Value != "true" && body.Value != "false" {
-			http.Error(w, `{"error": "value must be 'true' or 'false'"}`, http.StatusBadRequest)
-			return
-		}
+	if msg := validateGlobalConfigValue(key, body.Value); msg != "" {
+		http.Error(w, `{"error": "`+msg+`"}`, http.StatusBadRequest)
+		return
 	}
 
 	if err := r.store.SetGlobalConfig(req.Context(), key, body.Value); err != nil {
-		r.logger.Printf("admin: failed to update global config %s: %v", key, err)
+		r.logger.Error("admin: failed to update global config", "key", key, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to update config"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("admin: updated global config %s = %s", key, body.Value)
+	r.logger.Info("admin: updated global config", "key", key, "value", body.Value)
+	r.recordAudit(req, store.AuditEntry{
+		ActorType: store.AuditActorAdmin, Action: store.AuditActionConfigUpdate,
+		TargetType: store.AuditTargetGlobalConfig, TargetID: key,
+	}, map[string]string{"value": oldValue}, map[string]string{"value": body.Value})
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
+
+// handleAdminListProviders returns the circuit breaker state of every voice AI provider.
+func (r *Router) handleAdminListProviders(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"providers": r.providers.snapshots()})
+}
+
+// handleAdminResetProvider force-closes a provider's circuit breaker.
+func (r *Router) handleAdminResetProvider(w http.ResponseWriter, req *http.Request) {
+	kind := req.PathValue("kind")
+	name := req.PathValue("name")
+
+	b := r.providers.find(kind, name)
+	if b == nil {
+		http.Error(w, `{"error": "provider not found"}`, http.StatusNotFound)
+		return
+	}
+
+	b.Reset()
+	r.logger.Info("admin: reset provider breaker", "kind", kind, "name", name)
+	writeJSON(w, http.StatusOK, map[string]any{"provider": b.Snapshot()})
+}
@@ -1,9 +1,15 @@
 package httpapi
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
 )
 
 func TestIsAdminPhone(t *testing.T) {
@@ -273,3 +279,36 @@ func TestCostDefaultPeriod(t *testing.T) {
 		}
 	}
 }
+
+func TestAdminPhoneNumberHandlers_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard(), store: store.New(nil)}
+	const id = "8b0c5a6e-3f1d-4c2a-9e7b-1d2f3a4b5c6d"
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		id      string
+		body    string
+		want    int
+	}{
+		{"update invalid id", r.handleAdminUpdatePhoneNumber, http.MethodPatch, "1", `{}`, http.StatusNotFound},
+		{"update invalid body", r.handleAdminUpdatePhoneNumber, http.MethodPatch, id, `{`, http.StatusBadRequest},
+		{"delete invalid id", r.handleAdminDeletePhoneNumber, http.MethodDelete, "1", "", http.StatusNotFound},
+		{"delete missing id", r.handleAdminDeletePhoneNumber, http.MethodDelete, "", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/admin/phone-numbers/"+tt.id, strings.NewReader(tt.body))
+			req.SetPathValue("id", tt.id)
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/getsentry/sentry-go"
 	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/store"
 )
 
 // ============================================================================
@@ -47,7 +48,7 @@ func (r *Router) handleAIListCalls(w http.ResponseWriter, req *http.Request) {
 
 	calls, err := r.store.ListCallsFiltered(req.Context(), tenantID, since, limit)
 	if err != nil {
-		r.logger.Printf("ai: failed to list calls: %v", err)
+		r.logger.Error("ai: failed to list calls", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list calls"}`, http.StatusInternalServerError)
 		return
@@ -74,7 +75,7 @@ func (r *Router) handleAIGetCallEvents(w http.ResponseWriter, req *http.Request)
 			http.Error(w, `{"error": "call not found"}`, http.StatusNotFound)
 			return
 		}
-		r.logger.Printf("ai: failed to get call ID for %s: %v", callSid, err)
+		r.logger.Error("ai: failed to get call ID", "call_sid", callSid, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get call"}`, http.StatusInternalServerError)
 		return
@@ -83,7 +84,7 @@ func (r *Router) handleAIGetCallEvents(w http.ResponseWriter, req *http.Request)
 	// Get call details
 	call, err := r.store.GetCallDetail(req.Context(), callSid)
 	if err != nil {
-		r.logger.Printf("ai: failed to get call detail for %s: %v", callSid, err)
+		r.logger.Error("ai: failed to get call detail", "call_sid", callSid, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get call detail"}`, http.StatusInternalServerError)
 		return
@@ -92,7 +93,7 @@ func (r *Router) handleAIGetCallEvents(w http.ResponseWriter, req *http.Request)
 	// Get events
 	events, err := r.store.ListCallEvents(req.Context(), callID, 1000)
 	if err != nil {
-		r.logger.Printf("ai: failed to list events for %s: %v", callID, err)
+		r.logger.Error("ai: failed to list events", "call_id", callID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list events"}`, http.StatusInternalServerError)
 		return
@@ -123,7 +124,7 @@ func (r *Router) handleAIGetTenantCalls(w http.ResponseWriter, req *http.Request
 	// Get calls for this tenant
 	calls, err := r.store.ListCallsByTenantWithDetails(req.Context(), tenantID, limit)
 	if err != nil {
-		r.logger.Printf("ai: failed to list calls for tenant %s: %v", tenantID, err)
+		r.logger.Error("ai: failed to list calls", "tenant_id", tenantID, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list tenant calls"}`, http.StatusInternalServerError)
 		return
@@ -150,15 +151,16 @@ func (r *Router) handleAIGetStats(w http.ResponseWriter, req *http.Request) {
 	// Get event statistics from database
 	stats, err := r.store.GetEventStats(req.Context(), since)
 	if err != nil {
-		r.logger.Printf("ai: failed to get event stats: %v", err)
+		r.logger.Error("ai: failed to get event stats", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to get stats"}`, http.StatusInternalServerError)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"stats": stats,
-		"since": since.Format(time.RFC3339),
+		"stats":     stats,
+		"since":     since.Format(time.RFC3339),
+		"providers": r.providers.snapshots(),
 	})
 }
 
@@ -166,7 +168,7 @@ func (r *Router) handleAIGetStats(w http.ResponseWriter, req *http.Request) {
 func (r *Router) handleAIListConfig(w http.ResponseWriter, req *http.Request) {
 	entries, err := r.store.ListGlobalConfig(req.Context())
 	if err != nil {
-		r.logger.Printf("ai: failed to list global config: %v", err)
+		r.logger.Error("ai: failed to list global config", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to list config"}`, http.StatusInternalServerError)
 		return
@@ -193,58 +195,35 @@ func (r *Router) handleAIUpdateConfig(w http.ResponseWriter, req *http.Request)
 	}
 
 	// Validate the key exists
-	_, err := r.store.GetGlobalConfig(req.Context(), key)
+	oldValue, err := r.store.GetGlobalConfig(req.Context(), key)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			http.Error(w, `{"error": "config key not found"}`, http.StatusNotFound)
 			return
 		}
-		r.logger.Printf("ai: failed to check config key %s: %v", key, err)
+		r.logger.Error("ai: failed to check config key", "key", key, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to check config key"}`, http.StatusInternalServerError)
 		return
 	}
 
-	// Validate numeric values for known numeric keys
-	numericKeys := map[string]bool{
-		"max_turn_timeout_ms":            true,
-		"adaptive_min_timeout_ms":        true,
-		"adaptive_text_decay_rate_ms":    true,
-		"adaptive_sentence_end_bonus_ms": true,
-		"robocall_max_call_duration_ms":  true,
-		"robocall_silence_threshold_ms":  true,
-		"robocall_barge_in_threshold":    true,
-		"robocall_barge_in_window_ms":    true,
-		"robocall_repetition_threshold":  true,
-	}
-	if numericKeys[key] {
-		if _, err := strconv.Atoi(body.Value); err != nil {
-			http.Error(w, `{"error": "value must be a number"}`, http.StatusBadRequest)
-			return
-		}
-	}
-
-	// Validate boolean values for known boolean keys
-	boolKeys := map[string]bool{
-		"adaptive_turn_enabled":      true,
-		"robocall_detection_enabled": true,
-		"stt_debug_enabled":          true,
-	}
-	if boolKeys[key] {
-		if body.Value != "true" && body.Value != "false" {
-			http.Error(w, `{"error": "value must be 'true' or 'false'"}`, http.StatusBadRequest)
-			return
-		}
+	if msg := validateGlobalConfigValue(key, body.Value); msg != "" {
+		http.Error(w, `{"error": "`+msg+`"}`, http.StatusBadRequest)
+		return
 	}
 
 	if err := r.store.SetGlobalConfig(req.Context(), key, body.Value); err != nil {
-		r.logger.Printf("ai: failed to update global config %s: %v", key, err)
+		r.logger.Error("ai: failed to update global config", "key", key, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to update config"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("ai: updated global config %s = %s", key, body.Value)
+	r.logger.Info("ai: updated global config", "key", key, "value", body.Value)
+	r.recordAudit(req, store.AuditEntry{
+		ActorType: store.AuditActorAIKey, Action: store.AuditActionConfigUpdate,
+		TargetType: store.AuditTargetGlobalConfig, TargetID: key,
+	}, map[string]string{"value": oldValue}, map[string]string{"value": body.Value})
 	writeJSON(w, http.StatusOK, map[string]any{
 		"success": true,
 		"key":     key,
@@ -0,0 +1,219 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+const (
+	// apiTokenPrefix starts every API token, telling them apart from JWTs.
+	apiTokenPrefix = "krn_"
+	// apiTokenPrefixLen is how much of a token is stored to recognise it.
+	apiTokenPrefixLen = len(apiTokenPrefix) + 8
+
+	apiTokenMaxNameLen    = 100
+	apiTokenMaxExpiryDays = 365
+)
+
+// newAPIToken returns a new random API token.
+func newAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// authenticateAPIToken validates an API token. It returns the user the token
+// acts for, or nil and the error message for the 401 response.
+func (r *Router) authenticateAPIToken(ctx context.Context, token string) (*AuthUser, string) {
+	t, role, err := r.store.AuthenticateAPIToken(ctx, hashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "invalid, expired or revoked API token"
+	}
+	if err != nil {
+		r.logger.Error("api_tokens: failed to authenticate", "error", err)
+		sentry.CaptureException(err)
+		return nil, "failed to check API token"
+	}
+	return &AuthUser{
+		ID:         t.UserID,
+		TenantID:   &t.TenantID,
+		Role:       role,
+		APITokenID: t.ID,
+		Scopes:     t.Scopes,
+	}, ""
+}
+
+// requireScope rejects requests made with an API token without the scope.
+// Requests with a JWT pass.
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		authUser := getAuthUser(req.Context())
+		if authUser == nil {
+			http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
+			return
+		}
+		if authUser.APITokenID != "" && !slices.Contains(authUser.Scopes, scope) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "API token lacks the " + scope + " scope"})
+			return
+		}
+		next.ServeHTTP(w, req)
+	}
+}
+
+// apiTokenOwnerFilter limits token listing and revocation to the user's own
+// tokens, unless the user is an owner.
+func apiTokenOwnerFilter(authUser *AuthUser) *string {
+	if store.RoleAtLeast(authUser.Role, store.RoleOwner) {
+		return nil
+	}
+	return &authUser.ID
+}
+
+// handleListAPITokens returns the user's API tokens, or all of the tenant's
+// for owners.
+func (r *Router) handleListAPITokens(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	tokens, err := r.store.ListAPITokens(req.Context(), *authUser.TenantID, apiTokenOwnerFilter(authUser))
+	if err != nil {
+		r.logger.Error("api_tokens: failed to list", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tokens": tokens})
+}
+
+// decodeAPITokenRequest reads and validates a token creation body against
+// the scopes the role can grant. Returns the token to create, or an error
+// message and status.
+func decodeAPITokenRequest(req *http.Request, role string) (store.APIToken, string, int) {
+	var body struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays *int     `json:"expires_in_days"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return store.APIToken{}, "invalid request body", http.StatusBadRequest
+	}
+
+	t := store.APIToken{Name: strings.TrimSpace(body.Name)}
+	if t.Name == "" || len(t.Name) > apiTokenMaxNameLen {
+		return t, "name is required (at most 100 characters)", http.StatusBadRequest
+	}
+	if len(body.Scopes) == 0 {
+		return t, "at least one scope is required", http.StatusBadRequest
+	}
+	for _, scope := range body.Scopes {
+		minRole, ok := store.ScopeRoles[scope]
+		if !ok {
+			return t, "unknown scope " + scope, http.StatusBadRequest
+		}
+		if !store.RoleAtLeast(role, minRole) {
+			return t, "the " + scope + " scope requires the " + minRole + " role", http.StatusForbidden
+		}
+		if !slices.Contains(t.Scopes, scope) {
+			t.Scopes = append(t.Scopes, scope)
+		}
+	}
+	if body.ExpiresInDays != nil {
+		days := *body.ExpiresInDays
+		if days < 1 || days > apiTokenMaxExpiryDays {
+			return t, "expires_in_days must be between 1 and 365", http.StatusBadRequest
+		}
+		expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+		t.ExpiresAt = &expiresAt
+	}
+	return t, "", 0
+}
+
+// handleCreateAPIToken creates an API token acting for the user. The token
+// is returned only in this response.
+func (r *Router) handleCreateAPIToken(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	t, msg, status := decodeAPITokenRequest(req, authUser.Role)
+	if msg != "" {
+		writeJSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	secret, err := newAPIToken()
+	if err != nil {
+		r.logger.Error("api_tokens: failed to generate token", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to create token"}`, http.StatusInternalServerError)
+		return
+	}
+	t.TenantID, t.UserID, t.Prefix = *authUser.TenantID, authUser.ID, secret[:apiTokenPrefixLen]
+
+	t, err = r.store.CreateAPIToken(req.Context(), t, hashToken(secret))
+	if err != nil {
+		r.logger.Error("api_tokens: failed to create", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to create token"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("api_tokens: created", "tenant_id", t.TenantID, "user_id", t.UserID, "token_id", t.ID, "scopes", t.Scopes)
+	e := tenantAuditEntry(authUser, store.AuditActionAPITokenCreate)
+	e.TargetType, e.TargetID = store.AuditTargetAPIToken, t.ID
+	r.recordAudit(req, e, nil, map[string]any{"name": t.Name, "scopes": t.Scopes, "expires_at": t.ExpiresAt})
+	writeJSON(w, http.StatusCreated, struct {
+		store.APIToken
+		Token string `json:"token"`
+	}{t, secret})
+}
+
+// handleRevokeAPIToken revokes one of the user's API tokens, or any of the
+// tenant's for owners.
+func (r *Router) handleRevokeAPIToken(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	id := req.PathValue("id")
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "token not found"}`, http.StatusNotFound)
+		return
+	}
+
+	t, err := r.store.RevokeAPIToken(req.Context(), *authUser.TenantID, id, apiTokenOwnerFilter(authUser))
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "token not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		r.logger.Error("api_tokens: failed to revoke", "tenant_id", *authUser.TenantID, "token_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to revoke token"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("api_tokens: revoked", "tenant_id", t.TenantID, "token_id", t.ID, "by", authUser.ID)
+	e := tenantAuditEntry(authUser, store.AuditActionAPITokenRevoke)
+	e.TargetType, e.TargetID = store.AuditTargetAPIToken, t.ID
+	r.recordAudit(req, e, map[string]any{"name": t.Name, "scopes": t.Scopes}, nil)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
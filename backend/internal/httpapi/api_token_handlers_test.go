@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestNewAPIToken(t *testing.T) {
+	a, err := newAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := newAPIToken()
+	if !strings.HasPrefix(a, apiTokenPrefix) || len(a) != len(apiTokenPrefix)+43 || a == b {
+		t.Errorf("newAPIToken = %q, %q", a, b)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tenantID := "tenant-1"
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	tests := []struct {
+		name string
+		user *AuthUser
+		want int
+	}{
+		{"jwt", &AuthUser{ID: "u", TenantID: &tenantID, Role: store.RoleViewer}, http.StatusOK},
+		{"token with scope", &AuthUser{ID: "u", TenantID: &tenantID, APITokenID: "t", Scopes: []string{store.ScopeCallsWrite, store.ScopeCallsRead}}, http.StatusOK},
+		{"token without scope", &AuthUser{ID: "u", TenantID: &tenantID, APITokenID: "t", Scopes: []string{store.ScopeCallsWrite}}, http.StatusForbidden},
+		{"token without scopes", &AuthUser{ID: "u", TenantID: &tenantID, APITokenID: "t"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), userContextKey, tt.user)
+			req := httptest.NewRequest(http.MethodGet, "/api/calls", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			requireScope(store.ScopeCallsRead, ok)(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWithAuthRejectsAPITokens(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	protected := r.withAuth(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("handler should not be called")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+apiTokenPrefix+"abc")
+	rec := httptest.NewRecorder()
+
+	protected(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestDecodeAPITokenRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		body   string
+		status int
+	}{
+		{"valid", store.RoleMember, `{"name":"CRM","scopes":["calls:read","calls:write"]}`, 0},
+		{"with expiry", store.RoleViewer, `{"name":"CRM","scopes":["calls:read"],"expires_in_days":30}`, 0},
+		{"invalid body", store.RoleOwner, `{`, http.StatusBadRequest},
+		{"no name", store.RoleOwner, `{"name":" ","scopes":["calls:read"]}`, http.StatusBadRequest},
+		{"long name", store.RoleOwner, `{"name":"` + strings.Repeat("x", 101) + `","scopes":["calls:read"]}`, http.StatusBadRequest},
+		{"no scopes", store.RoleOwner, `{"name":"CRM"}`, http.StatusBadRequest},
+		{"unknown scope", store.RoleOwner, `{"name":"CRM","scopes":["admin"]}`, http.StatusBadRequest},
+		{"scope above role", store.RoleMember, `{"name":"CRM","scopes":["settings:write"]}`, http.StatusForbidden},
+		{"viewer writing calls", store.RoleViewer, `{"name":"CRM","scopes":["calls:write"]}`, http.StatusForbidden},
+		{"expiry too long", store.RoleOwner, `{"name":"CRM","scopes":["calls:read"],"expires_in_days":366}`, http.StatusBadRequest},
+		{"expiry zero", store.RoleOwner, `{"name":"CRM","scopes":["calls:read"],"expires_in_days":0}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(tt.body))
+			_, msg, status := decodeAPITokenRequest(req, tt.role)
+			if status != tt.status {
+				t.Errorf("status = %d (%q), want %d", status, msg, tt.status)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(`{"name":" CRM ","scopes":["calls:read","calls:read"],"expires_in_days":1}`))
+	tok, _, _ := decodeAPITokenRequest(req, store.RoleViewer)
+	if tok.Name != "CRM" || !slices.Equal(tok.Scopes, []string{"calls:read"}) || tok.ExpiresAt == nil {
+		t.Errorf("decodeAPITokenRequest = %+v", tok)
+	}
+}
+
+func TestAPITokenHandlers_NoTenant(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	for name, h := range map[string]http.HandlerFunc{
+		"list": r.handleListAPITokens, "create": r.handleCreateAPIToken, "revoke": r.handleRevokeAPIToken,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(`{}`))
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", name, rec.Code)
+		}
+	}
+}
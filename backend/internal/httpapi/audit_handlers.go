@@ -0,0 +1,157 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 500
+)
+
+// auditAdminOnlyFields are tenant fields only admins see; they are removed
+// from the entries shown to tenants.
+var auditAdminOnlyFields = []string{"admin_notes"}
+
+// recordAudit appends a change made by the request to the audit log, with
+// the fields of before and after that differ. The change itself is already
+// made, so failures are logged rather than returned.
+func (r *Router) recordAudit(req *http.Request, e store.AuditEntry, before, after any, ignore ...string) {
+	if authUser := getAuthUser(req.Context()); authUser != nil && e.ActorType != store.AuditActorAIKey {
+		e.ActorID = &authUser.ID
+		if authUser.APITokenID != "" && e.ActorType == store.AuditActorUser {
+			e.ActorType = store.AuditActorAPIToken
+		}
+	}
+	var err error
+	if e.Before, e.After, err = store.AuditDiff(before, after, ignore...); err == nil {
+		_, err = r.store.InsertAuditEntry(req.Context(), e)
+	}
+	if err != nil {
+		r.logger.Error("audit: failed to record entry", "action", e.Action, "target_id", e.TargetID, "error", err)
+		sentry.CaptureException(err)
+	}
+}
+
+// tenantAuditEntry starts an entry for a change a user made to their own
+// tenant's settings.
+func tenantAuditEntry(authUser *AuthUser, action string) store.AuditEntry {
+	return store.AuditEntry{
+		ActorType:  store.AuditActorUser,
+		Action:     action,
+		TargetType: store.AuditTargetTenant,
+		TargetID:   *authUser.TenantID,
+		TenantID:   authUser.TenantID,
+	}
+}
+
+// parseAuditQuery reads the limit, until and filter query params shared by
+// the audit endpoints. Returns an error message, or "".
+func parseAuditQuery(req *http.Request) (store.AuditFilter, int, string) {
+	query := req.URL.Query()
+	limit, msg := parseLimit(query, auditDefaultLimit, auditMaxLimit)
+	if msg != "" {
+		return store.AuditFilter{}, 0, msg
+	}
+	f := store.AuditFilter{
+		ActorType:  query.Get("actor_type"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+	}
+	if v := query.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return f, 0, "until must be an RFC 3339 time"
+		}
+		f.Until = &t
+	}
+	return f, limit, ""
+}
+
+// handleAdminListAudit returns the newest audit log entries, filtered by
+// tenant_id, actor_type, action, target_type and target_id if given. Pass
+// the created_at of the last entry as until for the next page.
+func (r *Router) handleAdminListAudit(w http.ResponseWriter, req *http.Request) {
+	f, limit, msg := parseAuditQuery(req)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	if tenantID := req.URL.Query().Get("tenant_id"); tenantID != "" {
+		if !store.IsUUID(tenantID) {
+			http.Error(w, `{"error": "invalid tenant_id"}`, http.StatusBadRequest)
+			return
+		}
+		f.TenantID = &tenantID
+	}
+
+	entries, err := r.store.ListAuditEntries(req.Context(), f, limit)
+	if err != nil {
+		r.logger.Error("audit: failed to list entries", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
+
+// handleListTenantAudit returns the audit log entries of the user's tenant.
+// Admin-only fields are removed, and admins are not identified.
+func (r *Router) handleListTenantAudit(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	f, limit, msg := parseAuditQuery(req)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	f.TenantID = authUser.TenantID
+
+	entries, err := r.store.ListAuditEntries(req.Context(), f, limit)
+	if err != nil {
+		r.logger.Error("audit: failed to list entries", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	for i := range entries {
+		tenantAuditView(&entries[i])
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"entries": entries})
+}
+
+// tenantAuditView prepares an entry for the tenant's view: admin-only fields
+// are dropped from the diff and admin actors are not identified.
+func tenantAuditView(e *store.AuditEntry) {
+	if e.ActorType != store.AuditActorUser && e.ActorType != store.AuditActorAPIToken {
+		e.ActorID = nil
+	}
+	e.Before = withoutFields(e.Before, auditAdminOnlyFields)
+	e.After = withoutFields(e.After, auditAdminOnlyFields)
+}
+
+// withoutFields returns the JSON object raw without the given fields; other
+// values are returned as they are.
+func withoutFields(raw json.RawMessage, fields []string) json.RawMessage {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil || m == nil {
+		return raw
+	}
+	for _, f := range fields {
+		delete(m, f)
+	}
+	out, err := json.Marshal(m)
+	if err != nil {
+		return raw
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestHandleListTenantAudit_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		query string
+		want  int
+	}{
+		{"no tenant", context.Background(), "", http.StatusNotFound},
+		{"invalid limit", authCtx, "?limit=0", http.StatusBadRequest},
+		{"limit too high", authCtx, "?limit=501", http.StatusBadRequest},
+		{"invalid until", authCtx, "?until=yesterday", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/tenant/audit"+tt.query, nil).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleListTenantAudit(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleAdminListAudit_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+
+	for _, query := range []string{"?tenant_id=not-a-uuid", "?limit=abc", "?until=2024-13-01"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit"+query, nil)
+		rec := httptest.NewRecorder()
+
+		r.handleAdminListAudit(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestTenantAuditView(t *testing.T) {
+	adminID := "admin-1"
+	e := store.AuditEntry{
+		ActorType: store.AuditActorAdmin,
+		ActorID:   &adminID,
+		Before:    json.RawMessage(`{"plan": "trial", "admin_notes": "late payer"}`),
+		After:     json.RawMessage(`null`),
+	}
+	tenantAuditView(&e)
+
+	if e.ActorID != nil {
+		t.Errorf("admin actor should not be identified, got %q", *e.ActorID)
+	}
+	if string(e.Before) != `{"plan":"trial"}` {
+		t.Errorf("Before = %s, want admin notes removed", e.Before)
+	}
+	if string(e.After) != `null` {
+		t.Errorf("After = %s, want null", e.After)
+	}
+}
@@ -38,6 +38,12 @@ type AuthUser struct {
 	ID       string
 	TenantID *string
 	Phone    string
+	Role     string // Role in the tenant (store.RoleOwner, ...)
+
+	// Set when authenticated with an API token instead of a JWT (Phone is
+	// then empty)
+	APITokenID string
+	Scopes     []string
 }
 
 // E.164 phone number validation (international format)
@@ -55,6 +61,12 @@ func hashToken(token string) string {
 
 // withAuth is middleware that requires valid JWT authentication
 func (r *Router) withAuth(next http.HandlerFunc) http.HandlerFunc {
+	return r.authenticate(false, next)
+}
+
+// authenticate is middleware that requires a valid JWT, or an API token if
+// allowTokens is set (the caller checks its scopes).
+func (r *Router) authenticate(allowTokens bool, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		// Get token from Authorization header
 		authHeader := req.Header.Get("Authorization")
@@ -70,43 +82,87 @@ func (r *Router) withAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-
-		// Parse and validate JWT
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		var user *AuthUser
+		var errMsg string
+		if strings.HasPrefix(parts[1], apiTokenPrefix) {
+			if !allowTokens {
+				http.Error(w, `{"error": "API tokens can't be used for this endpoint"}`, http.StatusForbidden)
+				return
 			}
-			return []byte(r.cfg.JWTSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, `{"error": "invalid token"}`, http.StatusUnauthorized)
+			user, errMsg = r.authenticateAPIToken(req.Context(), parts[1])
+		} else {
+			user, errMsg = r.authenticateToken(req.Context(), parts[1])
+		}
+		if user == nil {
+			http.Error(w, `{"error": "`+errMsg+`"}`, http.StatusUnauthorized)
 			return
 		}
 
-		claims, ok := token.Claims.(*JWTClaims)
-		if !ok {
-			http.Error(w, `{"error": "invalid token claims"}`, http.StatusUnauthorized)
-			return
+		// Add user to context
+		ctx := context.WithValue(req.Context(), userContextKey, user)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	}
+}
+
+// authenticateToken validates a JWT and its session. It returns the user, or
+// nil and the error message for the 401 response.
+func (r *Router) authenticateToken(ctx context.Context, tokenString string) (*AuthUser, string) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
+		return []byte(r.cfg.JWTSecret), nil
+	})
 
-		// Check if session is valid (not revoked)
-		tokenHash := hashToken(tokenString)
-		valid, err := r.store.IsSessionValid(req.Context(), tokenHash)
-		if err != nil || !valid {
-			http.Error(w, `{"error": "session expired or revoked"}`, http.StatusUnauthorized)
+	if err != nil || !token.Valid {
+		return nil, "invalid token"
+	}
+
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok {
+		return nil, "invalid token claims"
+	}
+
+	// Check if session is valid (not revoked). The tenant and role come from
+	// the database so membership changes apply to existing tokens.
+	user, err := r.store.GetSessionUser(ctx, hashToken(tokenString))
+	if err != nil || user.ID != claims.UserID {
+		return nil, "session expired or revoked"
+	}
+
+	return &AuthUser{
+		ID:       user.ID,
+		TenantID: user.TenantID,
+		Phone:    user.Phone,
+		Role:     user.Role,
+	}, ""
+}
+
+// withRole is middleware that requires at least the tenant role (viewer <
+// member < owner). It wraps withAuth.
+func (r *Router) withRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return r.withAuth(requireRole(role, next))
+}
+
+// withScope is withRole for endpoints API tokens can use: it also accepts
+// API tokens that have the scope and whose user has the role.
+func (r *Router) withScope(scope, role string, next http.HandlerFunc) http.HandlerFunc {
+	return r.authenticate(true, requireScope(scope, requireRole(role, next)))
+}
+
+// requireRole rejects requests of users whose role grants less than role.
+func requireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		authUser := getAuthUser(req.Context())
+		if authUser == nil {
+			http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
 			return
 		}
-
-		// Add user to context
-		user := &AuthUser{
-			ID:       claims.UserID,
-			TenantID: claims.TenantID,
-			Phone:    claims.Phone,
+		if !store.RoleAtLeast(authUser.Role, role) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": role + " role required"})
+			return
 		}
-		ctx := context.WithValue(req.Context(), userContextKey, user)
-		next.ServeHTTP(w, req.WithContext(ctx))
+		next.ServeHTTP(w, req)
 	}
 }
 
@@ -161,7 +217,7 @@ func (r *Router) handleSendCode(w http.ResponseWriter, req *http.Request) {
 
 	// Check if Twilio Verify is configured
 	if r.cfg.TwilioAccountSID == "" || r.cfg.TwilioVerifyServiceID == "" {
-		r.logger.Printf("auth: Twilio Verify not configured")
+		r.logger.Warn("auth: Twilio Verify not configured")
 		http.Error(w, `{"error": "SMS verification not configured"}`, http.StatusServiceUnavailable)
 		return
 	}
@@ -169,7 +225,7 @@ func (r *Router) handleSendCode(w http.ResponseWriter, req *http.Request) {
 	// Call Twilio Verify API to send SMS
 	err := r.sendTwilioVerifyCode(req.Context(), body.Phone)
 	if err != nil {
-		r.logger.Printf("auth: failed to send verification code to %s: %v", body.Phone, err)
+		r.logger.Error("auth: failed to send verification code", "phone", body.Phone, "error", err)
 		captureError(req, err, "failed to send verification code via Twilio")
 		http.Error(w, `{"error": "failed to send verification code"}`, http.StatusInternalServerError)
 		return
@@ -207,7 +263,7 @@ func (r *Router) handleVerifyCode(w http.ResponseWriter, req *http.Request) {
 
 	// Check if Twilio Verify is configured
 	if r.cfg.TwilioAccountSID == "" || r.cfg.TwilioVerifyServiceID == "" {
-		r.logger.Printf("auth: Twilio Verify not configured")
+		r.logger.Warn("auth: Twilio Verify not configured")
 		http.Error(w, `{"error": "SMS verification not configured"}`, http.StatusServiceUnavailable)
 		return
 	}
@@ -215,7 +271,7 @@ func (r *Router) handleVerifyCode(w http.ResponseWriter, req *http.Request) {
 	// Verify code with Twilio
 	valid, err := r.verifyTwilioCode(req.Context(), body.Phone, body.Code)
 	if err != nil {
-		r.logger.Printf("auth: verification check failed for %s: %v", body.Phone, err)
+		r.logger.Error("auth: verification check failed", "phone", body.Phone, "error", err)
 		captureError(req, err, "Twilio verification check failed")
 		http.Error(w, `{"error": "verification failed"}`, http.StatusInternalServerError)
 		return
@@ -231,7 +287,7 @@ func (r *Router) handleVerifyCode(w http.ResponseWriter, req *http.Request) {
 	// Find or create user
 	user, isNew, err := r.store.FindOrCreateUser(req.Context(), body.Phone)
 	if err != nil {
-		r.logger.Printf("auth: failed to find/create user for %s: %v", body.Phone, err)
+		r.logger.Error("auth: failed to find/create user", "phone", body.Phone, "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
 		return
@@ -240,7 +296,7 @@ func (r *Router) handleVerifyCode(w http.ResponseWriter, req *http.Request) {
 	// Generate JWT
 	token, expiresAt, err := r.generateJWT(user)
 	if err != nil {
-		r.logger.Printf("auth: failed to generate JWT: %v", err)
+		r.logger.Error("auth: failed to generate JWT", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to create session"}`, http.StatusInternalServerError)
 		return
@@ -249,13 +305,13 @@ func (r *Router) handleVerifyCode(w http.ResponseWriter, req *http.Request) {
 	// Store session for logout/revocation
 	tokenHash := hashToken(token)
 	if err := r.store.CreateSession(req.Context(), user.ID, tokenHash, expiresAt); err != nil {
-		r.logger.Printf("auth: failed to store session: %v", err)
+		r.logger.Error("auth: failed to store session", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to create session"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("auth: user %s logged in (new: %v)", body.Phone, isNew)
+	r.logger.Info("auth: user logged in", "phone", body.Phone, "is_new", isNew)
 
 	if isNew {
 		r.discord.NotifyNewUser(req.Context(), body.Phone)
@@ -499,6 +555,12 @@ func (r *Router) handleUpdateTenant(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Why the prompt changed, recorded with the new prompt version
+	promptReason := "edited"
+	if reason, ok := rawUpdates["prompt_change_reason"].(string); ok && strings.TrimSpace(reason) != "" {
+		promptReason = strings.TrimSpace(reason)
+	}
+
 	// Check if we need to regenerate system prompt
 	// (when name, vip_names, or marketing_email changes and system_prompt is not explicitly set)
 	if _, hasExplicitPrompt := updates["system_prompt"]; !hasExplicitPrompt {
@@ -543,16 +605,37 @@ func (r *Router) handleUpdateTenant(w http.ResponseWriter, req *http.Request) {
 		if needsRegeneration {
 			newPrompt := llm.GenerateSystemPromptWithVIPs(newName, newVIPNames, newMarketingEmail)
 			updates["system_prompt"] = newPrompt
-			r.logger.Printf("auth: auto-regenerated system prompt for tenant %s", *authUser.TenantID)
+			promptReason = "auto-regenerated after name, VIP or marketing email change"
+			r.logger.Info("auth: auto-regenerated system prompt", "tenant_id", *authUser.TenantID)
 		}
 	}
 
-	// Apply updates
-	if err := r.store.UpdateTenant(req.Context(), *authUser.TenantID, updates); err != nil {
-		r.logger.Printf("auth: failed to update tenant %s: %v", *authUser.TenantID, err)
-		sentry.CaptureException(err)
-		http.Error(w, `{"error": "failed to update tenant"}`, http.StatusInternalServerError)
-		return
+	// The system prompt is never overwritten in place; a changed prompt is saved as a new version
+	var newPrompt *string
+	if v, ok := updates["system_prompt"]; ok {
+		delete(updates, "system_prompt")
+		prompt, ok := v.(string)
+		if !ok {
+			http.Error(w, `{"error": "system_prompt must be a string"}`, http.StatusBadRequest)
+			return
+		}
+		if prompt != currentTenant.SystemPrompt {
+			newPrompt = &prompt
+		}
+	}
+
+	// Apply updates and the new prompt version together
+	if len(updates) > 0 || newPrompt != nil {
+		version, err := r.store.UpdateTenantWithPrompt(req.Context(), *authUser.TenantID, updates, newPrompt, &authUser.ID, promptReason)
+		if err != nil {
+			r.logger.Error("auth: failed to update tenant", "tenant_id", *authUser.TenantID, "error", err)
+			sentry.CaptureException(err)
+			http.Error(w, `{"error": "failed to update tenant"}`, http.StatusInternalServerError)
+			return
+		}
+		if newPrompt != nil {
+			r.logger.Info("auth: saved system prompt version", "tenant_id", *authUser.TenantID, "version", version, "reason", promptReason)
+		}
 	}
 
 	// Return updated tenant
@@ -562,6 +645,7 @@ func (r *Router) handleUpdateTenant(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionTenantUpdate), currentTenant, tenant, "updated_at")
 	writeJSON(w, http.StatusOK, tenant)
 }
 
@@ -584,14 +668,14 @@ func (r *Router) handleCompleteOnboarding(w http.ResponseWriter, req *http.Reque
 		// Only clear the tenant reference if the tenant truly doesn't exist (not a DB error)
 		if errors.Is(err, pgx.ErrNoRows) {
 			if err := r.store.ClearUserTenant(req.Context(), authUser.ID); err != nil {
-				r.logger.Printf("auth: failed to clear stale tenant reference: %v", err)
+				r.logger.Error("auth: failed to clear stale tenant reference", "error", err)
 				sentry.CaptureException(err)
 				http.Error(w, `{"error": "failed to clear stale tenant reference"}`, http.StatusInternalServerError)
 				return
 			}
 		} else if err != nil {
 			// Database error - don't proceed, return error
-			r.logger.Printf("auth: failed to check existing tenant: %v", err)
+			r.logger.Error("auth: failed to check existing tenant", "error", err)
 			sentry.CaptureException(err)
 			http.Error(w, `{"error": "failed to check tenant status"}`, http.StatusInternalServerError)
 			return
@@ -623,21 +707,29 @@ func (r *Router) handleCompleteOnboarding(w http.ResponseWriter, req *http.Reque
 	// Create tenant
 	tenant, err := r.store.CreateTenant(req.Context(), body.Name, systemPrompt, body.GreetingText)
 	if err != nil {
-		r.logger.Printf("auth: failed to create tenant: %v", err)
+		r.logger.Error("auth: failed to create tenant", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to create tenant"}`, http.StatusInternalServerError)
 		return
 	}
 
+	// Record the initial prompt as version 1
+	if version, err := r.store.SaveTenantPrompt(req.Context(), tenant.ID, systemPrompt, &authUser.ID, "onboarding"); err != nil {
+		r.logger.Error("auth: failed to save initial system prompt version", "tenant_id", tenant.ID, "error", err)
+		sentry.CaptureException(err)
+	} else {
+		tenant.PromptVersion = version
+	}
+
 	// Update user name
 	if err := r.store.UpdateUserName(req.Context(), authUser.ID, body.Name); err != nil {
-		r.logger.Printf("auth: failed to update user name: %v", err)
+		r.logger.Error("auth: failed to update user name", "error", err)
 		sentry.CaptureException(err)
 	}
 
 	// Assign user to tenant
 	if err := r.store.AssignUserToTenant(req.Context(), authUser.ID, tenant.ID); err != nil {
-		r.logger.Printf("auth: failed to assign user to tenant: %v", err)
+		r.logger.Error("auth: failed to assign user to tenant", "error", err)
 		sentry.CaptureException(err)
 		http.Error(w, `{"error": "failed to assign tenant"}`, http.StatusInternalServerError)
 		return
@@ -647,25 +739,25 @@ func (r *Router) handleCompleteOnboarding(w http.ResponseWriter, req *http.Reque
 	var phoneNumber *store.TenantPhoneNumber
 	existingNumbers, err := r.store.GetTenantPhoneNumbers(req.Context(), tenant.ID)
 	if err != nil {
-		r.logger.Printf("auth: failed to get existing phone numbers: %v", err)
+		r.logger.Error("auth: failed to get existing phone numbers", "error", err)
 		sentry.CaptureException(err)
 	}
 
 	if len(existingNumbers) > 0 {
 		// Tenant already has a phone number (e.g., from a previous onboarding attempt)
 		phoneNumber = &existingNumbers[0]
-		r.logger.Printf("auth: tenant %s already has phone number %s", tenant.ID, phoneNumber.TwilioNumber)
+		r.logger.Info("auth: tenant already has phone number", "tenant_id", tenant.ID, "twilio_number", phoneNumber.TwilioNumber)
 	} else {
 		// Claim a new phone number from the pool
 		phoneNumber, err = r.store.ClaimAvailablePhoneNumber(req.Context(), tenant.ID)
 		if err != nil {
-			r.logger.Printf("auth: failed to claim phone number: %v", err)
+			r.logger.Error("auth: failed to claim phone number", "error", err)
 			sentry.CaptureException(err)
 			// Continue without phone number - not a fatal error
 		} else if phoneNumber != nil {
-			r.logger.Printf("auth: assigned phone number %s to tenant %s", phoneNumber.TwilioNumber, tenant.ID)
+			r.logger.Info("auth: assigned phone number", "twilio_number", phoneNumber.TwilioNumber, "tenant_id", tenant.ID)
 		} else {
-			r.logger.Printf("auth: no available phone numbers for tenant %s", tenant.ID)
+			r.logger.Warn("auth: no available phone numbers", "tenant_id", tenant.ID)
 			r.discord.NotifyPhoneNumbersExhausted(req.Context(), tenant.ID, tenant.Name)
 		}
 	}
@@ -676,7 +768,7 @@ func (r *Router) handleCompleteOnboarding(w http.ResponseWriter, req *http.Reque
 	// Generate new JWT with tenant ID
 	token, expiresAt, err := r.generateJWT(user)
 	if err != nil {
-		r.logger.Printf("auth: failed to generate JWT: %v", err)
+		r.logger.Error("auth: failed to generate JWT", "error", err)
 		sentry.CaptureException(err)
 	}
 
@@ -686,7 +778,7 @@ func (r *Router) handleCompleteOnboarding(w http.ResponseWriter, req *http.Reque
 		_ = r.store.CreateSession(req.Context(), user.ID, tokenHash, expiresAt)
 	}
 
-	r.logger.Printf("auth: onboarding complete for user %s, tenant %s", user.ID, tenant.ID)
+	r.logger.Info("auth: onboarding complete", "user_id", user.ID, "tenant_id", tenant.ID)
 
 	// Include phone number in response if assigned
 	response := map[string]any{
@@ -3,8 +3,6 @@ package httpapi
 import (
 	"context"
 	"encoding/json"
-	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -15,6 +13,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
 	"github.com/lukasbauer/karen/internal/store"
 )
 
@@ -145,7 +144,7 @@ func TestWithAuthMiddleware(t *testing.T) {
 			JWTSecret: "test-secret-key",
 			JWTExpiry: 1 * time.Hour,
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	// Create a test handler that checks for auth user
@@ -202,7 +201,7 @@ func TestWithAuthMiddleware(t *testing.T) {
 func TestSendCodeValidation(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	t.Run("invalid phone format", func(t *testing.T) {
@@ -241,7 +240,7 @@ func TestSendCodeValidation(t *testing.T) {
 func TestVerifyCodeValidation(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	t.Run("invalid phone format", func(t *testing.T) {
@@ -338,7 +337,7 @@ func getTestRouterWithDB(t *testing.T) (*Router, *pgxpool.Pool, func()) {
 			JWTSecret: "test-secret-key-for-integration",
 			JWTExpiry: 1 * time.Hour,
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		store:  s,
 	}
 
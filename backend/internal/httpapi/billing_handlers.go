@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
@@ -36,9 +36,9 @@ func init() {
 
 	// Log webhook secret status at startup for debugging
 	if stripeWebhookSecret == "" {
-		log.Println("WARNING: STRIPE_WEBHOOK_SECRET is not set - webhooks will fail")
+		slog.Warn("billing: STRIPE_WEBHOOK_SECRET is not set - webhooks will fail")
 	} else {
-		log.Printf("Stripe webhook secret configured (length: %d)", len(stripeWebhookSecret))
+		slog.Info("billing: Stripe webhook secret configured", "length", len(stripeWebhookSecret))
 	}
 }
 
@@ -77,7 +77,7 @@ func (r *Router) handleCreateCheckout(w http.ResponseWriter, req *http.Request)
 	// Get or create Stripe customer
 	customerID, err := r.getOrCreateStripeCustomer(req.Context(), tenant, authUser.Phone)
 	if err != nil {
-		r.logger.Printf("billing: failed to get/create customer: %v", err)
+		r.logger.Error("billing: failed to get/create customer", "error", err)
 		http.Error(w, `{"error": "failed to create customer"}`, http.StatusInternalServerError)
 		return
 	}
@@ -111,12 +111,12 @@ func (r *Router) handleCreateCheckout(w http.ResponseWriter, req *http.Request)
 
 	s, err := checkoutsession.New(params)
 	if err != nil {
-		r.logger.Printf("billing: failed to create checkout session: %v", err)
+		r.logger.Error("billing: failed to create checkout session", "error", err)
 		http.Error(w, `{"error": "failed to create checkout session"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("billing: created checkout session %s for tenant %s", s.ID, tenant.ID)
+	r.logger.Info("billing: created checkout session", "session_id", s.ID, "tenant_id", tenant.ID)
 
 	writeJSON(w, http.StatusOK, map[string]string{
 		"checkout_url": s.URL,
@@ -155,7 +155,7 @@ func (r *Router) handleCreatePortal(w http.ResponseWriter, req *http.Request) {
 
 	s, err := session.New(params)
 	if err != nil {
-		r.logger.Printf("billing: failed to create portal session: %v", err)
+		r.logger.Error("billing: failed to create portal session", "error", err)
 		http.Error(w, `{"error": "failed to create portal session"}`, http.StatusInternalServerError)
 		return
 	}
@@ -172,7 +172,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		r.logger.Printf("billing webhook: failed to read body: %v", err)
+		r.logger.Error("billing webhook: failed to read body", "error", err)
 		http.Error(w, "failed to read body", http.StatusBadRequest)
 		return
 	}
@@ -182,7 +182,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 
 	// Check if webhook secret is configured
 	if stripeWebhookSecret == "" {
-		r.logger.Printf("billing webhook: ERROR - webhook secret not configured")
+		r.logger.Error("billing webhook: webhook secret not configured")
 		http.Error(w, "webhook not configured", http.StatusInternalServerError)
 		return
 	}
@@ -191,21 +191,20 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 		IgnoreAPIVersionMismatch: true,
 	})
 	if err != nil {
-		r.logger.Printf("billing webhook: signature verification failed: %v", err)
-		r.logger.Printf("billing webhook: secret length=%d, sig header length=%d, body length=%d",
-			len(stripeWebhookSecret), len(sigHeader), len(body))
+		r.logger.Error("billing webhook: signature verification failed", "error", err)
+		r.logger.Debug("billing webhook: verifying signature", "secret_length", len(stripeWebhookSecret), "sig_header_length", len(sigHeader), "body_length", len(body))
 		http.Error(w, "signature verification failed", http.StatusBadRequest)
 		return
 	}
 
-	r.logger.Printf("billing webhook: received event %s (type=%s)", event.ID, event.Type)
+	r.logger.Info("billing webhook: received event", "event_id", event.ID, "type", event.Type)
 
 	// Handle different event types
 	switch event.Type {
 	case "checkout.session.completed":
 		var session stripe.CheckoutSession
 		if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
-			r.logger.Printf("billing webhook: failed to parse session: %v", err)
+			r.logger.Error("billing webhook: failed to parse session", "error", err)
 			http.Error(w, "failed to parse event", http.StatusBadRequest)
 			return
 		}
@@ -214,7 +213,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 	case "customer.subscription.updated":
 		var subscription stripe.Subscription
 		if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
-			r.logger.Printf("billing webhook: failed to parse subscription: %v", err)
+			r.logger.Error("billing webhook: failed to parse subscription", "error", err)
 			http.Error(w, "failed to parse event", http.StatusBadRequest)
 			return
 		}
@@ -223,7 +222,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 	case "customer.subscription.deleted":
 		var subscription stripe.Subscription
 		if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
-			r.logger.Printf("billing webhook: failed to parse subscription: %v", err)
+			r.logger.Error("billing webhook: failed to parse subscription", "error", err)
 			http.Error(w, "failed to parse event", http.StatusBadRequest)
 			return
 		}
@@ -232,7 +231,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 	case "invoice.payment_succeeded":
 		var invoice stripe.Invoice
 		if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
-			r.logger.Printf("billing webhook: failed to parse invoice: %v", err)
+			r.logger.Error("billing webhook: failed to parse invoice", "error", err)
 			http.Error(w, "failed to parse event", http.StatusBadRequest)
 			return
 		}
@@ -241,7 +240,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 	case "invoice.payment_failed":
 		var invoice stripe.Invoice
 		if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
-			r.logger.Printf("billing webhook: failed to parse invoice: %v", err)
+			r.logger.Error("billing webhook: failed to parse invoice", "error", err)
 			http.Error(w, "failed to parse event", http.StatusBadRequest)
 			return
 		}
@@ -255,7 +254,7 @@ func (r *Router) handleStripeWebhook(w http.ResponseWriter, req *http.Request) {
 func (r *Router) handleCheckoutCompleted(session *stripe.CheckoutSession) {
 	tenantID, ok := session.Metadata["tenant_id"]
 	if !ok {
-		r.logger.Printf("billing webhook: checkout session missing tenant_id")
+		r.logger.Warn("billing webhook: checkout session missing tenant_id")
 		return
 	}
 
@@ -277,11 +276,11 @@ func (r *Router) handleCheckoutCompleted(session *stripe.CheckoutSession) {
 	})
 
 	if err != nil {
-		r.logger.Printf("billing webhook: failed to update tenant %s: %v", tenantID, err)
+		r.logger.Error("billing webhook: failed to update tenant", "tenant_id", tenantID, "error", err)
 		return
 	}
 
-	r.logger.Printf("billing webhook: upgraded tenant %s to plan %s", tenantID, plan)
+	r.logger.Info("billing webhook: upgraded tenant to plan", "tenant_id", tenantID, "plan", plan)
 }
 
 // handleSubscriptionUpdated processes subscription updates
@@ -290,7 +289,7 @@ func (r *Router) handleSubscriptionUpdated(subscription *stripe.Subscription) {
 	// Find tenant by Stripe customer ID
 	tenantID, err := r.store.GetTenantIDByStripeCustomer(ctx, subscription.Customer.ID)
 	if err != nil {
-		r.logger.Printf("billing webhook: tenant not found for customer %s: %v", subscription.Customer.ID, err)
+		r.logger.Warn("billing webhook: tenant not found", "customer_id", subscription.Customer.ID, "error", err)
 		return
 	}
 
@@ -311,11 +310,11 @@ func (r *Router) handleSubscriptionUpdated(subscription *stripe.Subscription) {
 	})
 
 	if err != nil {
-		r.logger.Printf("billing webhook: failed to update tenant %s: %v", tenantID, err)
+		r.logger.Error("billing webhook: failed to update tenant", "tenant_id", tenantID, "error", err)
 		return
 	}
 
-	r.logger.Printf("billing webhook: updated subscription for tenant %s (plan=%s, status=%s)", tenantID, plan, status)
+	r.logger.Info("billing webhook: updated subscription", "tenant_id", tenantID, "plan", plan, "status", status)
 }
 
 // handleSubscriptionDeleted processes subscription cancellations
@@ -324,7 +323,7 @@ func (r *Router) handleSubscriptionDeleted(subscription *stripe.Subscription) {
 	// Find tenant by Stripe customer ID
 	tenantID, err := r.store.GetTenantIDByStripeCustomer(ctx, subscription.Customer.ID)
 	if err != nil {
-		r.logger.Printf("billing webhook: tenant not found for customer %s: %v", subscription.Customer.ID, err)
+		r.logger.Warn("billing webhook: tenant not found", "customer_id", subscription.Customer.ID, "error", err)
 		return
 	}
 
@@ -336,11 +335,11 @@ func (r *Router) handleSubscriptionDeleted(subscription *stripe.Subscription) {
 	})
 
 	if err != nil {
-		r.logger.Printf("billing webhook: failed to update tenant %s: %v", tenantID, err)
+		r.logger.Error("billing webhook: failed to update tenant", "tenant_id", tenantID, "error", err)
 		return
 	}
 
-	r.logger.Printf("billing webhook: subscription cancelled for tenant %s", tenantID)
+	r.logger.Info("billing webhook: subscription cancelled", "tenant_id", tenantID)
 }
 
 // handlePaymentSucceeded processes successful payments
@@ -353,18 +352,18 @@ func (r *Router) handlePaymentSucceeded(invoice *stripe.Invoice) {
 	// Find tenant by Stripe customer ID
 	tenantID, err := r.store.GetTenantIDByStripeCustomer(ctx, invoice.Customer.ID)
 	if err != nil {
-		r.logger.Printf("billing webhook: tenant not found for customer %s: %v", invoice.Customer.ID, err)
+		r.logger.Warn("billing webhook: tenant not found", "customer_id", invoice.Customer.ID, "error", err)
 		return
 	}
 
 	// Reset period calls for new billing period
 	err = r.store.ResetTenantPeriodCalls(ctx, tenantID)
 	if err != nil {
-		r.logger.Printf("billing webhook: failed to reset period calls for tenant %s: %v", tenantID, err)
+		r.logger.Error("billing webhook: failed to reset period calls", "tenant_id", tenantID, "error", err)
 		return
 	}
 
-	r.logger.Printf("billing webhook: payment succeeded for tenant %s, reset period calls", tenantID)
+	r.logger.Info("billing webhook: payment succeeded, reset period calls", "tenant_id", tenantID)
 }
 
 // handlePaymentFailed processes failed payments
@@ -377,7 +376,7 @@ func (r *Router) handlePaymentFailed(invoice *stripe.Invoice) {
 	// Find tenant by Stripe customer ID
 	tenantID, err := r.store.GetTenantIDByStripeCustomer(ctx, invoice.Customer.ID)
 	if err != nil {
-		r.logger.Printf("billing webhook: tenant not found for customer %s: %v", invoice.Customer.ID, err)
+		r.logger.Warn("billing webhook: tenant not found", "customer_id", invoice.Customer.ID, "error", err)
 		return
 	}
 
@@ -387,11 +386,11 @@ func (r *Router) handlePaymentFailed(invoice *stripe.Invoice) {
 	})
 
 	if err != nil {
-		r.logger.Printf("billing webhook: failed to update tenant %s: %v", tenantID, err)
+		r.logger.Error("billing webhook: failed to update tenant", "tenant_id", tenantID, "error", err)
 		return
 	}
 
-	r.logger.Printf("billing webhook: payment failed for tenant %s", tenantID)
+	r.logger.Error("billing webhook: payment failed", "tenant_id", tenantID)
 
 	// TODO: Send SMS notification about failed payment
 }
@@ -422,7 +421,7 @@ func (r *Router) getOrCreateStripeCustomer(ctx context.Context, tenant *store.Te
 	if err := r.store.UpdateTenantBilling(ctx, tenant.ID, map[string]any{
 		"stripe_customer_id": c.ID,
 	}); err != nil {
-		r.logger.Printf("billing: failed to save Stripe customer ID for tenant %s: %v", tenant.ID, err)
+		r.logger.Error("billing: failed to save Stripe customer ID", "tenant_id", tenant.ID, "error", err)
 		// Continue anyway - customer was created successfully in Stripe
 	}
 
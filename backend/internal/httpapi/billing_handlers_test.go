@@ -3,8 +3,6 @@ package httpapi
 import (
 	"context"
 	"encoding/json"
-	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -13,6 +11,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lukasbauer/karen/internal/logging"
 	"github.com/lukasbauer/karen/internal/store"
 )
 
@@ -114,7 +113,7 @@ func TestHandleCreateCheckoutValidation(t *testing.T) {
 			JWTSecret: "test-secret-key",
 			JWTExpiry: 1 * time.Hour,
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	tests := []struct {
@@ -186,7 +185,7 @@ func TestHandleCreatePortalValidation(t *testing.T) {
 			JWTSecret: "test-secret-key",
 			JWTExpiry: 1 * time.Hour,
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	tests := []struct {
@@ -237,7 +236,7 @@ func TestHandleCreatePortalValidation(t *testing.T) {
 func TestHandleStripeWebhookValidation(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	// When STRIPE_WEBHOOK_SECRET is not configured, the handler returns 500
@@ -299,7 +298,7 @@ func TestHandleGetBillingValidation(t *testing.T) {
 		cfg: RouterConfig{
 			JWTSecret: "test-secret-key",
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	tests := []struct {
@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/costs"
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// costBudgetFor evaluates a tenant's monthly provider-cost budget against the
+// given spend. The per-tenant override wins over the plan default from global
+// config (cost_budget_<plan>_cents).
+func costBudgetFor(ctx context.Context, st *store.Store, tenantID string, spentCents int) (costs.Budget, error) {
+	settings, err := st.GetTenantCostBudget(ctx, tenantID)
+	if err != nil {
+		return costs.Budget{}, err
+	}
+
+	limit := st.GetGlobalConfigInt(ctx, "cost_budget_"+settings.Plan+"_cents", 0)
+	source := "plan"
+	if settings.BudgetCents != nil {
+		limit = *settings.BudgetCents
+		source = "tenant"
+	}
+
+	budget := costs.EvaluateBudget(limit, spentCents, st.GetGlobalConfigInt(ctx, "cost_budget_degrade_percent", 80))
+	budget.Source = source
+	return budget, nil
+}
+
+// currentCostBudget evaluates the tenant's budget for the current month.
+func (s *callSession) currentCostBudget(ctx context.Context) (costs.Budget, error) {
+	summary, err := s.store.GetTenantCostSummary(ctx, s.tenantCfg.TenantID, time.Now().Format("2006-01"))
+	if err != nil {
+		return costs.Budget{}, err
+	}
+	return costBudgetFor(ctx, s.store, s.tenantCfg.TenantID, summary.TotalAPICostCents)
+}
+
+// loadCostBudget checks the tenant's budget at call start. Calls of tenants
+// near or over their budget run in degraded mode: cheaper TTS model, no
+// fillers and a shorter max call duration.
+func (s *callSession) loadCostBudget() {
+	if s.tenantCfg.TenantID == "" {
+		return
+	}
+
+	budget, err := s.currentCostBudget(s.ctx)
+	if err != nil {
+		s.logger.Error("media_ws: failed to load cost budget", "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+	s.budget = budget
+
+	if budget.Degraded() {
+		s.logger.Warn("media_ws: cost budget degraded mode", "state", budget.State,
+			"spent_cents", budget.SpentCents, "limit_cents", budget.LimitCents)
+		s.eventLog.LogAsync(s.callID, eventlog.EventCostBudgetDegraded, map[string]any{
+			"state":        budget.State,
+			"spent_cents":  budget.SpentCents,
+			"limit_cents":  budget.LimitCents,
+			"percent_used": budget.PercentUsed,
+		})
+	}
+}
+
+// checkCostBudgetExceeded alerts the admin via Discord when the tenant is over
+// its budget. The alert is sent once per tenant per month.
+func (s *callSession) checkCostBudgetExceeded(ctx context.Context) {
+	if s.tenantCfg.TenantID == "" {
+		return
+	}
+
+	budget, err := s.currentCostBudget(ctx)
+	if err != nil {
+		s.logger.Error("media_ws: failed to check cost budget", "error", err)
+		return
+	}
+	if budget.State != costs.BudgetExceeded {
+		return
+	}
+
+	period := time.Now().Format("2006-01")
+	first, err := s.store.MarkCostBudgetAlerted(ctx, s.tenantCfg.TenantID, period)
+	if err != nil {
+		s.logger.Error("media_ws: failed to mark cost budget alert", "error", err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	s.logger.Warn("media_ws: cost budget exceeded", "spent_cents", budget.SpentCents, "limit_cents", budget.LimitCents)
+	s.discord.NotifyCostBudgetExceeded(ctx, s.tenantCfg.TenantID, period, budget.SpentCents, budget.LimitCents)
+}
@@ -0,0 +1,155 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/export"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// callExportColumns are the fixed export columns; entity, call field and
+// transcript columns follow.
+var callExportColumns = []string{
+	"call_id", "started_at", "ended_at", "duration_seconds", "from_number", "to_number", "status", "ended_by",
+	"first_viewed_at", "resolved_at", "legitimacy_label", "legitimacy_confidence", "lead_label",
+	"intent_category", "intent_text", "summary", "urgency",
+}
+
+// handleExportCalls streams the tenant's calls matching the call list filters
+// as CSV, NDJSON or XLSX (format, default csv), with screening entities and
+// call fields flattened to columns and the transcript if transcript=true.
+func (r *Router) handleExportCalls(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	tenantID := *authUser.TenantID
+
+	query := req.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if !export.ValidFormat(format) {
+		http.Error(w, `{"error": "format must be csv, ndjson or xlsx"}`, http.StatusBadRequest)
+		return
+	}
+	withTranscript := false
+	if v := query.Get("transcript"); v != "" {
+		var err error
+		if withTranscript, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, `{"error": "transcript must be true or false"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	filter, msg := parseCallFilterParams(query)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	fields, err := r.store.GetCallFields(req.Context(), tenantID)
+	if err != nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if hasCallFieldFilters(query) {
+		if filter.Fields, msg = parseCallFieldFilters(query, fields); msg != "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+			return
+		}
+	}
+	entityKeys, err := r.store.ListCallEntityKeys(req.Context(), tenantID, filter)
+	if errors.Is(err, store.ErrFieldFilterUnavailable) {
+		http.Error(w, `{"error": "call field value filters are unavailable for tenants with encrypted calls"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	header := append([]string(nil), callExportColumns...)
+	for _, k := range entityKeys {
+		header = append(header, "entity."+k)
+	}
+	for _, f := range fields {
+		header = append(header, "field."+f.Key)
+	}
+	if withTranscript {
+		header = append(header, "transcript")
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="calls-%s.%s"`, time.Now().UTC().Format("2006-01-02"), format))
+	ew, err := export.NewWriter(format, w, header)
+	if err != nil {
+		r.logger.Error("calls_export: failed to start export", "tenant_id", tenantID, "error", err)
+		return
+	}
+
+	// The status is sent with the first bytes, so errors from here on can
+	// only be logged and the export cut short.
+	rows := 0
+	err = r.store.ExportCalls(req.Context(), tenantID, filter, withTranscript, func(row store.CallExportRow) error {
+		rows++
+		values := callExportValues(row)
+		for _, k := range entityKeys {
+			values = append(values, emptyToNil(row.Entities[k]))
+		}
+		for _, f := range fields {
+			values = append(values, emptyToNil(row.Fields[f.Key]))
+		}
+		if withTranscript {
+			values = append(values, emptyToNil(row.Transcript))
+		}
+		return ew.WriteRow(values)
+	})
+	if err == nil {
+		err = ew.Close()
+	}
+	if err != nil {
+		r.logger.Error("calls_export: export failed", "tenant_id", tenantID, "format", format, "rows", rows, "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+	r.logger.Info("calls_export: exported calls", "tenant_id", tenantID, "format", format, "rows", rows)
+}
+
+// callExportValues returns the values of the fixed export columns.
+func callExportValues(row store.CallExportRow) []any {
+	var duration any
+	if row.EndedAt != nil {
+		duration = int(row.EndedAt.Sub(row.StartedAt).Seconds())
+	}
+	values := []any{
+		row.ProviderCallID, row.StartedAt, row.EndedAt, duration, row.FromNumber, row.ToNumber, row.Status, derefOrNil(row.EndedBy),
+		row.FirstViewedAt, row.ResolvedAt,
+	}
+	if sr := row.Screening; sr != nil {
+		values = append(values, sr.LegitimacyLabel, sr.LegitimacyConfidence, sr.LeadLabel, sr.IntentCategory, sr.IntentText)
+	} else {
+		values = append(values, nil, nil, nil, nil, nil)
+	}
+	return append(values, derefOrNil(row.Summary), derefOrNil(row.Urgency))
+}
+
+func derefOrNil(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func emptyToNil(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
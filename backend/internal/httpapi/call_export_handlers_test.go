@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestCallExportValues(t *testing.T) {
+	started := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	ended := started.Add(95 * time.Second)
+	summary := "Volal pan Novák."
+
+	row := store.CallExportRow{
+		CallListItem: store.CallListItem{
+			Call: store.Call{ProviderCallID: "CA1", StartedAt: started, EndedAt: &ended, Status: "completed"},
+			Screening: &store.ScreeningResult{
+				LegitimacyLabel: "legitimní", LegitimacyConfidence: 0.9, LeadLabel: "hot_lead",
+				IntentCategory: "obchodní", IntentText: "Oprava střechy", EntitiesJSON: json.RawMessage(`{}`),
+			},
+		},
+		Summary: &summary,
+	}
+	values := callExportValues(row)
+	if len(values) != len(callExportColumns) {
+		t.Fatalf("got %d values for %d columns", len(values), len(callExportColumns))
+	}
+	if values[3] != 95 {
+		t.Errorf("duration = %v, want 95", values[3])
+	}
+	if values[7] != nil || values[15] != summary || values[16] != nil {
+		t.Errorf("unexpected values %v", values)
+	}
+
+	// Unscreened, unfinished call
+	values = callExportValues(store.CallExportRow{CallListItem: store.CallListItem{Call: store.Call{ProviderCallID: "CA2", StartedAt: started}}})
+	if len(values) != len(callExportColumns) || values[3] != nil || values[10] != nil {
+		t.Errorf("unexpected values %v", values)
+	}
+}
+
+func TestHandleExportCalls_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+
+	tests := []struct {
+		name   string
+		target string
+		user   *AuthUser
+		want   int
+	}{
+		{"no tenant", "/api/calls/export", &AuthUser{ID: "user-1"}, http.StatusNotFound},
+		{"bad format", "/api/calls/export?format=pdf", &AuthUser{ID: "user-1", TenantID: &tenantID}, http.StatusBadRequest},
+		{"bad transcript", "/api/calls/export?transcript=yes", &AuthUser{ID: "user-1", TenantID: &tenantID}, http.StatusBadRequest},
+		{"bad filter", "/api/calls/export?from=yesterday", &AuthUser{ID: "user-1", TenantID: &tenantID}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req = req.WithContext(context.WithValue(req.Context(), userContextKey, tt.user))
+			rec := httptest.NewRecorder()
+			r.handleExportCalls(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/costs"
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/slots"
+)
+
+// Call field extraction runs off the hot path: in the background after each
+// caller turn (its result steers the next turn) and once more after the call.
+const (
+	callFieldsExtractTimeout = 15 * time.Second
+	callFieldsFinalTimeout   = 30 * time.Second
+)
+
+// withCallFieldGuidance returns msgs with a system message listing the call
+// fields still missing, before the caller's message. msgs is not modified.
+func withCallFieldGuidance(msgs []llm.Message, state *slots.State) []llm.Message {
+	if state == nil || len(msgs) == 0 {
+		return msgs
+	}
+	guidance := slots.Guidance(state.Missing())
+	if guidance == "" {
+		return msgs
+	}
+	return withSystemBeforeLast(msgs, guidance)
+}
+
+// loadCallFields starts slot tracking when the tenant has call fields.
+func (s *callSession) loadCallFields() {
+	if s.tenantCfg.TenantID == "" {
+		return
+	}
+	fields, err := s.store.GetCallFields(s.ctx, s.tenantCfg.TenantID)
+	if err != nil {
+		s.logger.Error("media_ws: failed to load call fields", "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+	if len(fields) > 0 {
+		s.callFields = slots.NewState(fields)
+	}
+}
+
+// extractCallFieldsAsync extracts the call field values from the conversation
+// so far in the background. A turn is skipped while an extraction is still
+// running; the final extraction after the call covers the whole transcript.
+func (s *callSession) extractCallFieldsAsync(turnID uint64, msgs []llm.Message) {
+	if s.callFields == nil || !s.callFieldsExtracting.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.callFieldsExtracting.Store(false)
+
+		ctx, cancel := context.WithTimeout(costs.WithUsage(s.ctx, s.usage), callFieldsExtractTimeout)
+		defer cancel()
+		s.extractCallFields(ctx, turnID, msgs)
+	}()
+}
+
+// extractCallFields runs one extraction and records the values found.
+func (s *callSession) extractCallFields(ctx context.Context, turnID uint64, msgs []llm.Message) {
+	start := time.Now()
+	raw, err := slots.Extract(ctx, s.llmClient, s.callFields.Fields(), msgs)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("media_ws: call field extraction failed", "error", err)
+		}
+		return
+	}
+
+	changed := s.callFields.Merge(raw)
+	if len(changed) == 0 {
+		return
+	}
+	missing := []string{}
+	for _, f := range s.callFields.Missing() {
+		if f.Required {
+			missing = append(missing, f.Key)
+		}
+	}
+	s.logger.Info("media_ws: call fields collected", "changed", changed, "missing_required", missing)
+	s.eventLog.LogAsync(s.callID, eventlog.EventCallFieldsUpdated, map[string]any{
+		"turn_id":          turnID,
+		"changed":          changed,
+		"missing_required": missing,
+		"duration_ms":      time.Since(start).Milliseconds(),
+	})
+}
+
+// saveCallFields runs the final extraction over the whole conversation and
+// stores the collected values with the call.
+func (s *callSession) saveCallFields() {
+	if s.callFields == nil || s.callID == "" {
+		return
+	}
+
+	// Use background context since call context may be cancelled
+	ctx, cancel := context.WithTimeout(costs.WithUsage(context.Background(), s.usage), callFieldsFinalTimeout)
+	defer cancel()
+
+	s.messagesMu.Lock()
+	msgs := append([]llm.Message(nil), s.messages...)
+	s.messagesMu.Unlock()
+
+	s.extractCallFields(ctx, atomic.LoadUint64(&s.turnSeq), msgs)
+
+	values := s.callFields.Values()
+	if err := s.store.SaveCallFieldValues(ctx, s.callID, values); err != nil {
+		s.logger.Error("media_ws: failed to store call fields", "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+	if len(values) > 0 {
+		s.logger.Info("media_ws: stored call fields", "count", len(values))
+	}
+}
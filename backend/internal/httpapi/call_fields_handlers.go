@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/slots"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// callFieldQueryPrefix prefixes call list query params that filter by a
+// collected call field: field.<key>=<value> (equals; * = collected),
+// field.<key>.min= and field.<key>.max= (number, date and time fields).
+const callFieldQueryPrefix = "field."
+
+// handleGetCallFields returns the fields the assistant collects on calls.
+func (r *Router) handleGetCallFields(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	fields, err := r.store.GetCallFields(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("call_fields: failed to load", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to load call fields"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"fields": fields})
+}
+
+// handleSetCallFields replaces the tenant's call field definitions. Applies
+// to new calls; values collected earlier are kept.
+func (r *Router) handleSetCallFields(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Fields []slots.Field `json:"fields"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if err := slots.ValidateFields(body.Fields); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	before, err := r.store.GetCallFields(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("call_fields: failed to load", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save call fields"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := r.store.SetCallFields(req.Context(), *authUser.TenantID, body.Fields, &authUser.ID); err != nil {
+		r.logger.Error("call_fields: failed to save", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save call fields"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("call_fields: saved", "tenant_id", *authUser.TenantID, "fields", len(body.Fields))
+	if body.Fields == nil {
+		body.Fields = []slots.Field{}
+	}
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionCallFieldsUpdate), before, body.Fields)
+	writeJSON(w, http.StatusOK, map[string]any{"fields": body.Fields})
+}
+
+// hasCallFieldFilters reports whether the query filters by call fields.
+func hasCallFieldFilters(query url.Values) bool {
+	for param := range query {
+		if strings.HasPrefix(param, callFieldQueryPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCallFieldFilters converts field.* query params into filters, parsing
+// values with the field types. It returns an error message or "".
+func parseCallFieldFilters(query url.Values, fields []slots.Field) ([]store.CallFieldFilter, string) {
+	byKey := make(map[string]slots.Field, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	var filters []store.CallFieldFilter
+	for param, values := range query {
+		if !strings.HasPrefix(param, callFieldQueryPrefix) || len(values) == 0 {
+			continue
+		}
+		key, op := strings.TrimPrefix(param, callFieldQueryPrefix), store.FieldFilterEq
+		if k, suffix, ok := strings.Cut(key, "."); ok {
+			key = k
+			switch suffix {
+			case store.FieldFilterMin, store.FieldFilterMax:
+				op = suffix
+			default:
+				return nil, "unknown filter " + param
+			}
+		}
+		field, ok := byKey[key]
+		if !ok {
+			return nil, "unknown call field " + key
+		}
+
+		raw := values[0]
+		if op == store.FieldFilterEq && raw == "*" {
+			filters = append(filters, store.CallFieldFilter{Key: key, Op: store.FieldFilterPresent})
+			continue
+		}
+		if op != store.FieldFilterEq && field.Type != slots.TypeNumber && field.Type != slots.TypeDate && field.Type != slots.TypeTime {
+			return nil, "range filters need a number, date or time field: " + key
+		}
+		v, err := field.Parse(raw)
+		if err != nil {
+			return nil, "invalid value for " + param
+		}
+		filters = append(filters, store.CallFieldFilter{Key: key, Op: op, Value: v})
+	}
+	return filters, ""
+}
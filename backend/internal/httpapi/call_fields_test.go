@@ -0,0 +1,145 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/slots"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func testCallFields() []slots.Field {
+	return []slots.Field{
+		{Key: "address", Label: "Adresa", Type: slots.TypeText, Required: true},
+		{Key: "order_number", Label: "Číslo objednávky", Type: slots.TypeNumber},
+		{Key: "visit_date", Label: "Datum návštěvy", Type: slots.TypeDate},
+	}
+}
+
+// entitiesLLM answers analysis requests with fixed entities.
+type entitiesLLM struct {
+	playgroundLLM
+	entities map[string]string
+}
+
+func (e *entitiesLLM) AnalyzeCall(context.Context, []llm.Message) (*llm.ScreeningResult, error) {
+	return &llm.ScreeningResult{Entities: e.entities}, nil
+}
+
+func TestWithCallFieldGuidance(t *testing.T) {
+	msgs := []llm.Message{{Role: "assistant", Content: "Dobrý den"}, {Role: "user", Content: "Potřebuji opravu"}}
+
+	if got := withCallFieldGuidance(msgs, nil); len(got) != 2 {
+		t.Errorf("expected no guidance without call fields, got %+v", got)
+	}
+
+	state := slots.NewState(testCallFields())
+	got := withCallFieldGuidance(msgs, state)
+	if len(got) != 3 || got[1].Role != "system" || !strings.Contains(got[1].Content, "Adresa") || got[2] != msgs[1] {
+		t.Errorf("expected guidance before the caller message, got %+v", got)
+	}
+
+	state.Merge(map[string]string{"address": "Dlouhá 5", "order_number": "12", "visit_date": "2025-03-14"})
+	if got := withCallFieldGuidance(msgs, state); len(got) != 2 {
+		t.Errorf("expected no guidance once all fields are collected, got %+v", got)
+	}
+}
+
+func TestExtractCallFields(t *testing.T) {
+	s := &callSession{
+		logger:     logging.Discard(),
+		eventLog:   eventlog.New(nil),
+		llmClient:  &entitiesLLM{entities: map[string]string{"address": "Dlouhá 5, Praha", "order_number": "null"}},
+		callFields: slots.NewState(testCallFields()),
+	}
+
+	s.extractCallFields(context.Background(), 1, []llm.Message{{Role: "user", Content: "Bydlím v Dlouhé 5 v Praze"}})
+
+	values := s.callFields.Values()
+	if len(values) != 1 || values[0].Key != "address" || values[0].Text != "Dlouhá 5, Praha" {
+		t.Errorf("unexpected values %+v", values)
+	}
+}
+
+func TestParseCallFieldFilters(t *testing.T) {
+	fields := testCallFields()
+
+	filters, msg := parseCallFieldFilters(url.Values{
+		"field.address":          {"Dlouhá 5"},
+		"field.order_number.min": {"10"},
+		"field.visit_date":       {"*"},
+		"limit":                  {"5"},
+	}, fields)
+	if msg != "" {
+		t.Fatalf("unexpected error %q", msg)
+	}
+	if len(filters) != 3 {
+		t.Fatalf("expected 3 filters, got %+v", filters)
+	}
+	byKey := map[string]store.CallFieldFilter{}
+	for _, f := range filters {
+		byKey[f.Key] = f
+	}
+	if f := byKey["address"]; f.Op != store.FieldFilterEq || f.Value.Text != "Dlouhá 5" {
+		t.Errorf("unexpected address filter %+v", f)
+	}
+	if f := byKey["order_number"]; f.Op != store.FieldFilterMin || f.Value.Number == nil || *f.Value.Number != 10 {
+		t.Errorf("unexpected order_number filter %+v", f)
+	}
+	if f := byKey["visit_date"]; f.Op != store.FieldFilterPresent {
+		t.Errorf("unexpected visit_date filter %+v", f)
+	}
+
+	for name, query := range map[string]url.Values{
+		"unknown field":    {"field.color": {"red"}},
+		"unknown operator": {"field.order_number.gt": {"1"}},
+		"range on text":    {"field.address.min": {"a"}},
+		"invalid value":    {"field.visit_date": {"tomorrow"}},
+	} {
+		if _, msg := parseCallFieldFilters(query, fields); msg == "" {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if hasCallFieldFilters(url.Values{"limit": {"5"}}) || !hasCallFieldFilters(url.Values{"field.address": {"x"}}) {
+		t.Error("hasCallFieldFilters mismatch")
+	}
+}
+
+func TestHandleSetCallFields_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"invalid key", authCtx, `{"fields":[{"key":"Order Number","label":"Objednávka","type":"text"}]}`, http.StatusBadRequest},
+		{"unknown type", authCtx, `{"fields":[{"key":"order","label":"Objednávka","type":"uuid"}]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/tenant/call-fields", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleSetCallFields(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -1,13 +1,13 @@
 package httpapi
 
 import (
-	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
 )
 
 func TestCallRegistry_AddAndDone(t *testing.T) {
@@ -180,7 +180,7 @@ func TestCallRegistry_DrainDuringConcurrentAdds(t *testing.T) {
 func TestReadyzEndpoint(t *testing.T) {
 	cr := NewCallRegistry()
 	r := &Router{
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		calls:  cr,
 	}
 
@@ -218,7 +218,7 @@ func TestInboundRejectsDuringDrain(t *testing.T) {
 	cr.StartDraining()
 
 	r := &Router{
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		calls:  cr,
 	}
 
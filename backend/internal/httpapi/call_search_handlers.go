@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// Call search limits.
+const (
+	callSearchDefaultLimit  = 20
+	callSearchMaxLimit      = 50
+	callSearchMaxQueryChars = 200
+)
+
+// handleSearchCalls searches the tenant's calls (transcripts, intent text,
+// entities and summaries), best matches first.
+func (r *Router) handleSearchCalls(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	query := req.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		http.Error(w, `{"error": "q is required"}`, http.StatusBadRequest)
+		return
+	}
+	if len(q) > callSearchMaxQueryChars {
+		http.Error(w, `{"error": "q is too long"}`, http.StatusBadRequest)
+		return
+	}
+	filter, msg := parseCallFilterParams(query)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	limit, msg := parseLimit(query, callSearchDefaultLimit, callSearchMaxLimit)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	results, err := r.store.SearchCalls(req.Context(), *authUser.TenantID, q, filter, limit)
+	if errors.Is(err, store.ErrSearchUnavailable) {
+		http.Error(w, `{"error": "search is unavailable for tenants with encrypted calls"}`, http.StatusConflict)
+		return
+	}
+	if errors.Is(err, store.ErrFieldFilterUnavailable) {
+		http.Error(w, `{"error": "call field value filters are unavailable for tenants with encrypted calls"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		r.logger.Error("call_search: search failed", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
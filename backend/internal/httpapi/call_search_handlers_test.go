@@ -0,0 +1,37 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+)
+
+func TestHandleSearchCalls_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+
+	tests := []struct {
+		name   string
+		target string
+		user   *AuthUser
+		want   int
+	}{
+		{"no tenant", "/api/calls/search?q=strecha", &AuthUser{ID: "user-1"}, http.StatusNotFound},
+		{"missing q", "/api/calls/search", &AuthUser{ID: "user-1", TenantID: &tenantID}, http.StatusBadRequest},
+		{"bad filter", "/api/calls/search?q=strecha&resolved=maybe", &AuthUser{ID: "user-1", TenantID: &tenantID}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req = req.WithContext(context.WithValue(req.Context(), userContextKey, tt.user))
+			rec := httptest.NewRecorder()
+			r.handleSearchCalls(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
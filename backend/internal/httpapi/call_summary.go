@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/costs"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// callSummaryFromResult returns the post-call summary in an analysis result.
+// ok is false if the model returned no summary.
+func callSummaryFromResult(result *llm.ScreeningResult) (store.CallSummary, bool) {
+	summary := strings.TrimSpace(result.Summary)
+	if summary == "" {
+		return store.CallSummary{}, false
+	}
+	items := make([]llm.ActionItem, 0, len(result.ActionItems))
+	for _, item := range result.ActionItems {
+		item.Text = strings.TrimSpace(item.Text)
+		if item.Text == "" {
+			continue
+		}
+		item.Owner = strings.TrimSpace(item.Owner)
+		item.Due = strings.TrimSpace(item.Due)
+		items = append(items, item)
+	}
+	return store.CallSummary{
+		Summary:     summary,
+		ActionItems: items,
+		Urgency:     llm.NormalizeUrgency(result.Urgency),
+	}, true
+}
+
+// pushBody returns the push notification text for an analysis result: the
+// summary, or the intent text if the model returned no summary.
+func pushBody(result *llm.ScreeningResult) string {
+	if summary := strings.TrimSpace(result.Summary); summary != "" {
+		return summary
+	}
+	return result.IntentText
+}
+
+// utteranceMessages converts stored utterances into the LLM conversation.
+func utteranceMessages(utterances []store.Utterance) []llm.Message {
+	msgs := make([]llm.Message, 0, len(utterances))
+	for _, u := range utterances {
+		role := "assistant"
+		if u.Speaker == "caller" {
+			role = "user"
+		}
+		msgs = append(msgs, llm.Message{Role: role, Content: u.Text})
+	}
+	return msgs
+}
+
+// handleRegenerateCallSummary generates the post-call summary again from the
+// stored transcript and returns it.
+func (r *Router) handleRegenerateCallSummary(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	call, callTenantID, err := r.store.GetCallDetailWithTenantCheck(req.Context(), req.PathValue("id"))
+	if err != nil || callTenantID == nil || *callTenantID != *authUser.TenantID {
+		// Return 404 (not 403) to prevent information leakage about call existence
+		http.Error(w, `{"error": "not found"}`, http.StatusNotFound)
+		return
+	}
+	if len(call.Utterances) < 2 {
+		http.Error(w, `{"error": "call has no conversation to summarize"}`, http.StatusConflict)
+		return
+	}
+
+	if r.cfg.OpenAIAPIKey == "" || r.providers == nil {
+		http.Error(w, `{"error": "voice AI not configured"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	tenant, err := r.store.GetTenantByID(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+
+	client := r.providers.newLLMClient(func(provider string, err error) {
+		r.logger.Error("call_summary: provider failed", "provider", provider, "error", err)
+	})
+	if tenant.SystemPrompt != "" {
+		client.SetSystemPrompt(tenant.SystemPrompt)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), 60*time.Second)
+	defer cancel()
+
+	// The call's classification is kept; only the summary is asked for, and its
+	// tokens are billed to the call
+	usage := costs.NewUsage()
+	result, err := client.AnalyzeCall(llm.WithAnalysisPrompt(costs.WithUsage(ctx, usage), llm.SummaryPromptCzech),
+		utteranceMessages(call.Utterances))
+	if metrics := usage.Metrics(0); metrics.LLMInputTokens > 0 || metrics.LLMOutputTokens > 0 {
+		if err := r.store.AddCallLLMUsage(ctx, call.ID, metrics.LLMInputTokens, metrics.LLMOutputTokens); err != nil {
+			r.logger.Error("call_summary: failed to record costs", "call_id", call.ID, "error", err)
+			sentry.CaptureException(err)
+		}
+	}
+	if err != nil {
+		r.logger.Error("call_summary: LLM error", "call_id", call.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to generate summary"}`, http.StatusBadGateway)
+		return
+	}
+	cs, ok := callSummaryFromResult(result)
+	if !ok {
+		http.Error(w, `{"error": "failed to generate summary"}`, http.StatusBadGateway)
+		return
+	}
+
+	if err := r.store.UpsertCallSummary(ctx, call.ID, cs); err != nil {
+		r.logger.Error("call_summary: failed to store summary", "call_id", call.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	summary, err := r.store.GetCallSummary(ctx, call.ID)
+	if err != nil || summary == nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
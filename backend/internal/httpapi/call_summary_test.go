@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestCallSummaryFromResult(t *testing.T) {
+	if _, ok := callSummaryFromResult(&llm.ScreeningResult{Summary: "  "}); ok {
+		t.Error("expected no summary for an empty summary")
+	}
+
+	cs, ok := callSummaryFromResult(&llm.ScreeningResult{
+		Summary: " Volala paní Dvořáková kvůli reklamaci. ",
+		ActionItems: []llm.ActionItem{
+			{Text: " Zavolat zpět ", Owner: " majitel ", Due: "zítra"},
+			{Text: ""},
+		},
+		Urgency: "VYSOKA",
+	})
+	if !ok {
+		t.Fatal("expected a summary")
+	}
+	if cs.Summary != "Volala paní Dvořáková kvůli reklamaci." {
+		t.Errorf("summary = %q", cs.Summary)
+	}
+	if len(cs.ActionItems) != 1 || cs.ActionItems[0].Text != "Zavolat zpět" || cs.ActionItems[0].Owner != "majitel" {
+		t.Errorf("action items = %+v", cs.ActionItems)
+	}
+	if cs.Urgency != llm.UrgencyHigh {
+		t.Errorf("urgency = %q, want %q", cs.Urgency, llm.UrgencyHigh)
+	}
+}
+
+func TestPushBody(t *testing.T) {
+	if got := pushBody(&llm.ScreeningResult{Summary: "Shrnutí.", IntentText: "Účel"}); got != "Shrnutí." {
+		t.Errorf("pushBody = %q, want the summary", got)
+	}
+	if got := pushBody(&llm.ScreeningResult{IntentText: "Účel"}); got != "Účel" {
+		t.Errorf("pushBody = %q, want the intent text", got)
+	}
+}
+
+func TestUtteranceMessages(t *testing.T) {
+	msgs := utteranceMessages([]store.Utterance{
+		{Speaker: "agent", Text: "Dobrý den"},
+		{Speaker: "caller", Text: "Ahoj"},
+	})
+	if len(msgs) != 2 || msgs[0].Role != "assistant" || msgs[1].Role != "user" || msgs[1].Content != "Ahoj" {
+		t.Errorf("unexpected messages %+v", msgs)
+	}
+}
+
+func TestHandleRegenerateCallSummary_NoTenant(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/calls/CA123/summary", nil)
+	req.SetPathValue("id", "CA123")
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, &AuthUser{ID: "user-1"}))
+	rec := httptest.NewRecorder()
+	r.handleRegenerateCallSummary(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
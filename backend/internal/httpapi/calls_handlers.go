@@ -1,8 +1,15 @@
 package httpapi
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/store"
 )
 
 // handleCallPatch dispatches PATCH requests for calls based on path suffix
@@ -29,6 +36,18 @@ func (r *Router) handleCallDelete(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// Call list limits.
+const (
+	callListLegacyLimit  = 100 // Unpaginated list (clients that don't pass limit or cursor)
+	callListDefaultLimit = 50
+	callListMaxLimit     = 100
+)
+
+// handleListCalls lists the tenant's calls, newest first, filtered by the
+// call filters and call field filters. Clients opt into cursor pagination by
+// passing limit or cursor: the response is then a page with the next cursor
+// (and the total and facet counts on the first page) instead of a plain array
+// of the latest calls.
 func (r *Router) handleListCalls(w http.ResponseWriter, req *http.Request) {
 	authUser := getAuthUser(req.Context())
 	if authUser == nil {
@@ -36,19 +55,77 @@ func (r *Router) handleListCalls(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// If user has a tenant, filter by tenant
-	if authUser.TenantID != nil {
-		calls, err := r.store.ListCallsByTenant(req.Context(), *authUser.TenantID, 100)
+	// User without tenant sees no calls
+	if authUser.TenantID == nil {
+		writeJSON(w, http.StatusOK, []any{})
+		return
+	}
+
+	query := req.URL.Query()
+	filter, msg := parseCallFilterParams(query)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	paginated := query.Has("limit") || query.Has("cursor")
+	limit, msg := parseLimit(query, callListDefaultLimit, callListMaxLimit)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	if !paginated {
+		limit = callListLegacyLimit
+	}
+
+	if hasCallFieldFilters(query) {
+		fields, err := r.store.GetCallFields(req.Context(), *authUser.TenantID)
 		if err != nil {
 			http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
 			return
 		}
-		writeJSON(w, http.StatusOK, calls)
+		if filter.Fields, msg = parseCallFieldFilters(query, fields); msg != "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+			return
+		}
+	}
+
+	cursor := query.Get("cursor")
+	page, err := r.store.ListCallsPage(req.Context(), *authUser.TenantID, filter, cursor, limit)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		http.Error(w, `{"error": "invalid cursor"}`, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, store.ErrFieldFilterUnavailable) {
+		http.Error(w, `{"error": "call field value filters are unavailable for tenants with encrypted calls"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !paginated {
+		writeJSON(w, http.StatusOK, page.Calls)
 		return
 	}
 
-	// User without tenant sees no calls
-	writeJSON(w, http.StatusOK, []any{})
+	resp := callListResponse{CallPage: page}
+	if cursor == "" {
+		facets, err := r.store.CountCallFacets(req.Context(), *authUser.TenantID, filter)
+		if err != nil {
+			r.logger.Error("calls: failed to count facets", "tenant_id", *authUser.TenantID, "error", err)
+			sentry.CaptureException(err)
+			http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+			return
+		}
+		resp.CallFacets = &facets
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// callListResponse is a page of the paginated call list.
+type callListResponse struct {
+	store.CallPage
+	*store.CallFacets // First page only
 }
 
 func (r *Router) handleGetCall(w http.ResponseWriter, req *http.Request) {
@@ -216,3 +293,78 @@ func (r *Router) handleGetUnresolvedCount(w http.ResponseWriter, req *http.Reque
 
 	writeJSON(w, http.StatusOK, map[string]int{"count": count})
 }
+
+// parseCallFilterParams reads the call filters shared by listing and search:
+// from/to (RFC 3339 or YYYY-MM-DD; a date "to" includes that day),
+// legitimacy_label, lead_label, intent_category, from_number, ended_by,
+// status, and resolved/viewed (true/false). It returns an error message or "".
+func parseCallFilterParams(query url.Values) (store.CallFilter, string) {
+	f := store.CallFilter{
+		LegitimacyLabel: query.Get("legitimacy_label"),
+		LeadLabel:       query.Get("lead_label"),
+		IntentCategory:  query.Get("intent_category"),
+		FromNumber:      query.Get("from_number"),
+		EndedBy:         query.Get("ended_by"),
+		Status:          query.Get("status"),
+	}
+
+	if v := query.Get("from"); v != "" {
+		t, _, ok := parseFilterTime(v)
+		if !ok {
+			return f, "from must be an RFC 3339 time or YYYY-MM-DD"
+		}
+		f.From = &t
+	}
+	if v := query.Get("to"); v != "" {
+		t, dateOnly, ok := parseFilterTime(v)
+		if !ok {
+			return f, "to must be an RFC 3339 time or YYYY-MM-DD"
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.To = &t
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return f, "from must be before to"
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **bool
+	}{{"resolved", &f.Resolved}, {"viewed", &f.Viewed}} {
+		if v := query.Get(p.name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return f, p.name + " must be true or false"
+			}
+			*p.dst = &b
+		}
+	}
+	return f, ""
+}
+
+// parseLimit reads the limit parameter (1 to maxLimit, def if absent). It returns
+// an error message or "".
+func parseLimit(query url.Values, def, maxLimit int) (int, string) {
+	v := query.Get("limit")
+	if v == "" {
+		return def, ""
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxLimit {
+		return 0, "limit must be between 1 and " + strconv.Itoa(maxLimit)
+	}
+	return n, ""
+}
+
+// parseFilterTime parses an RFC 3339 time or a date (UTC midnight).
+func parseFilterTime(v string) (t time.Time, dateOnly bool, ok bool) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, true
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, true
+	}
+	return time.Time{}, false, false
+}
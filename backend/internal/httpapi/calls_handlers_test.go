@@ -1,8 +1,15 @@
 package httpapi
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/logging"
 )
 
 func TestCallPathParsing(t *testing.T) {
@@ -224,3 +231,72 @@ func TestTenantAccessCheck(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestParseCallFilterParams(t *testing.T) {
+	f, msg := parseCallFilterParams(url.Values{
+		"from":             {"2025-03-01"},
+		"to":               {"2025-03-31"},
+		"legitimacy_label": {"legitimní"},
+		"resolved":         {"false"},
+		"viewed":           {"true"},
+		"ended_by":         {"caller"},
+	})
+	if msg != "" {
+		t.Fatalf("unexpected error %q", msg)
+	}
+	if f.From == nil || !f.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("from = %v", f.From)
+	}
+	// A date "to" includes the whole day
+	if f.To == nil || !f.To.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("to = %v", f.To)
+	}
+	if f.LegitimacyLabel != "legitimní" || f.EndedBy != "caller" || f.Resolved == nil || *f.Resolved || f.Viewed == nil || !*f.Viewed {
+		t.Errorf("unexpected filter %+v", f)
+	}
+
+	for name, query := range map[string]url.Values{
+		"bad from":      {"from": {"yesterday"}},
+		"bad to":        {"to": {"2025-13-01"}},
+		"from after to": {"from": {"2025-03-02T00:00:00Z"}, "to": {"2025-03-01T00:00:00Z"}},
+		"bad resolved":  {"resolved": {"maybe"}},
+		"bad viewed":    {"viewed": {"1x"}},
+	} {
+		if _, msg := parseCallFilterParams(query); msg == "" {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	if n, msg := parseLimit(url.Values{}, 20, 50); n != 20 || msg != "" {
+		t.Errorf("default: %d, %q", n, msg)
+	}
+	if n, msg := parseLimit(url.Values{"limit": {"50"}}, 20, 50); n != 50 || msg != "" {
+		t.Errorf("limit=50: %d, %q", n, msg)
+	}
+	for _, v := range []string{"0", "51", "abc"} {
+		if _, msg := parseLimit(url.Values{"limit": {v}}, 20, 50); msg == "" {
+			t.Errorf("limit=%s: expected an error", v)
+		}
+	}
+}
+
+func TestHandleListCalls_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+
+	for _, target := range []string{
+		"/api/calls?limit=1000",
+		"/api/calls?resolved=maybe",
+		"/api/calls?from=yesterday",
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID}))
+		rec := httptest.NewRecorder()
+		r.handleListCalls(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
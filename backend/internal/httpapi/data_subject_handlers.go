@@ -0,0 +1,200 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+const (
+	subjectRequestsDefaultLimit = 50
+	subjectRequestsMaxLimit     = 500
+)
+
+// subjectRequestBody is the body of export and erase requests. TenantID is
+// only read by the admin endpoints (empty = all tenants); Mode only by erase.
+type subjectRequestBody struct {
+	PhoneNumber string `json:"phone_number"`
+	Mode        string `json:"mode"`
+	TenantID    string `json:"tenant_id"`
+}
+
+// decodeSubjectRequest reads and validates an export or erase request body.
+// Returns an error message, or "" if the body is valid.
+func decodeSubjectRequest(req *http.Request, erase bool) (subjectRequestBody, string) {
+	var body subjectRequestBody
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return body, "invalid request body"
+	}
+	body.PhoneNumber = strings.TrimSpace(body.PhoneNumber)
+	if !isValidE164(body.PhoneNumber) {
+		return body, "phone_number must be in E.164 format"
+	}
+	if erase && body.Mode != store.SubjectActionDelete && body.Mode != store.SubjectActionAnonymize {
+		return body, "mode must be delete or anonymize"
+	}
+	if body.TenantID != "" && !store.IsUUID(body.TenantID) {
+		return body, "invalid tenant_id"
+	}
+	return body, ""
+}
+
+// handleSubjectExport returns everything stored about the tenant's calls from
+// a phone number as a JSON bundle.
+func (r *Router) handleSubjectExport(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	body, msg := decodeSubjectRequest(req, false)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	r.exportSubjectData(w, req, body.PhoneNumber, authUser.TenantID, &authUser.ID, false)
+}
+
+// handleSubjectErase deletes or anonymizes the tenant's calls from a phone
+// number.
+func (r *Router) handleSubjectErase(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	body, msg := decodeSubjectRequest(req, true)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	r.eraseSubjectData(w, req, body.PhoneNumber, body.Mode, authUser.TenantID, &authUser.ID, false)
+}
+
+// handleListSubjectRequests returns the tenant's data subject request trail.
+func (r *Router) handleListSubjectRequests(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	r.listSubjectRequests(w, req, authUser.TenantID)
+}
+
+// handleAdminSubjectExport exports a phone number's calls in one tenant
+// (tenant_id) or in all tenants.
+func (r *Router) handleAdminSubjectExport(w http.ResponseWriter, req *http.Request) {
+	body, msg := decodeSubjectRequest(req, false)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	authUser := getAuthUser(req.Context())
+	r.exportSubjectData(w, req, body.PhoneNumber, emptyToNilString(body.TenantID), &authUser.ID, true)
+}
+
+// handleAdminSubjectErase erases a phone number's calls in one tenant
+// (tenant_id) or in all tenants.
+func (r *Router) handleAdminSubjectErase(w http.ResponseWriter, req *http.Request) {
+	body, msg := decodeSubjectRequest(req, true)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	authUser := getAuthUser(req.Context())
+	r.eraseSubjectData(w, req, body.PhoneNumber, body.Mode, emptyToNilString(body.TenantID), &authUser.ID, true)
+}
+
+// handleAdminListSubjectRequests returns the data subject request trail,
+// filtered by tenant_id and phone_number if given.
+func (r *Router) handleAdminListSubjectRequests(w http.ResponseWriter, req *http.Request) {
+	tenantID := req.URL.Query().Get("tenant_id")
+	if tenantID != "" && !store.IsUUID(tenantID) {
+		http.Error(w, `{"error": "invalid tenant_id"}`, http.StatusBadRequest)
+		return
+	}
+	r.listSubjectRequests(w, req, emptyToNilString(tenantID))
+}
+
+func (r *Router) exportSubjectData(w http.ResponseWriter, req *http.Request, phone string, tenantID, userID *string, admin bool) {
+	data, err := r.store.ExportSubjectData(req.Context(), phone, tenantID)
+	if err != nil {
+		r.logger.Error("privacy: export failed", "phone", store.MaskPhone(phone), "tenant_id", tenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// The data is only handed out once the request is on record.
+	audit := r.store.NewSubjectRequest(store.SubjectActionExport, phone, tenantID, userID, admin)
+	audit.Calls = len(data.Calls)
+	for _, c := range data.Calls {
+		audit.Utterances += len(c.Utterances)
+		audit.Events += len(c.Events)
+	}
+	if audit, err = r.store.RecordSubjectRequest(req.Context(), audit); err != nil {
+		r.logger.Error("privacy: failed to record export", "phone", audit.PhoneMasked, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("privacy: exported subject data", "request_id", audit.ID, "phone", audit.PhoneMasked,
+		"tenant_id", tenantID, "admin", admin, "calls", audit.Calls)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="subject-data-%s.json"`, time.Now().UTC().Format("2006-01-02")))
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (r *Router) eraseSubjectData(w http.ResponseWriter, req *http.Request, phone, mode string, tenantID, userID *string, admin bool) {
+	audit, err := r.store.EraseSubjectData(req.Context(), phone, r.store.NewSubjectRequest(mode, phone, tenantID, userID, admin))
+	if err != nil {
+		r.logger.Error("privacy: erase failed", "phone", audit.PhoneMasked, "mode", mode, "tenant_id", tenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("privacy: erased subject data", "request_id", audit.ID, "phone", audit.PhoneMasked, "mode", mode,
+		"tenant_id", tenantID, "admin", admin, "calls", audit.Calls, "utterances", audit.Utterances, "events", audit.Events)
+	writeJSON(w, http.StatusOK, map[string]any{"request": audit})
+}
+
+func (r *Router) listSubjectRequests(w http.ResponseWriter, req *http.Request, tenantID *string) {
+	query := req.URL.Query()
+	limit, msg := parseLimit(query, subjectRequestsDefaultLimit, subjectRequestsMaxLimit)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	phoneHash := ""
+	if phone := query.Get("phone_number"); phone != "" {
+		// An unencoded "+" arrives as a space
+		if strings.HasPrefix(phone, " ") {
+			phone = "+" + strings.TrimSpace(phone)
+		}
+		if !isValidE164(phone) {
+			http.Error(w, `{"error": "phone_number must be in E.164 format"}`, http.StatusBadRequest)
+			return
+		}
+		phoneHash = r.store.HashPhone(phone)
+	}
+
+	requests, err := r.store.ListSubjectRequests(req.Context(), tenantID, phoneHash, limit)
+	if err != nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"requests": requests})
+}
+
+func emptyToNilString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
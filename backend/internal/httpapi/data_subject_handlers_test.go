@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+)
+
+func TestHandleSubjectErase_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{"phone_number":"+420777123456","mode":"delete"}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"missing phone", authCtx, `{"mode":"delete"}`, http.StatusBadRequest},
+		{"invalid phone", authCtx, `{"phone_number":"777123456","mode":"delete"}`, http.StatusBadRequest},
+		{"missing mode", authCtx, `{"phone_number":"+420777123456"}`, http.StatusBadRequest},
+		{"unknown mode", authCtx, `{"phone_number":"+420777123456","mode":"export"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/privacy/erase", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleSubjectErase(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDecodeSubjectRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		erase bool
+		valid bool
+	}{
+		{"export", `{"phone_number":" +420777123456 "}`, false, true},
+		{"export ignores mode", `{"phone_number":"+420777123456","mode":"x"}`, false, true},
+		{"anonymize", `{"phone_number":"+420777123456","mode":"anonymize"}`, true, true},
+		{"admin tenant", `{"phone_number":"+420777123456","tenant_id":"5f0c3a52-8d4e-4f7b-9a51-0b6c2f1e7d90"}`, false, true},
+		{"invalid tenant", `{"phone_number":"+420777123456","tenant_id":"abc"}`, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			body, msg := decodeSubjectRequest(req, tt.erase)
+			if (msg == "") != tt.valid {
+				t.Errorf("msg = %q, want valid=%v", msg, tt.valid)
+			}
+			if tt.valid && body.PhoneNumber != "+420777123456" {
+				t.Errorf("PhoneNumber = %q", body.PhoneNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// encryptionResponse is whether the tenant's calls are encrypted at rest and
+// whether the server can encrypt them.
+type encryptionResponse struct {
+	Enabled   bool `json:"enabled"`
+	Available bool `json:"available"` // false = no master key configured
+}
+
+// encryptionAuditState is a tenant's encryption setting as recorded in the
+// audit log.
+type encryptionAuditState struct {
+	Enabled bool `json:"enabled"`
+}
+
+// handleGetEncryption returns whether the tenant's new calls are encrypted.
+func (r *Router) handleGetEncryption(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	enabled, err := r.store.GetTenantEncryption(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("encryption: failed to load setting", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to load encryption setting"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, encryptionResponse{Enabled: enabled, Available: r.store.EncryptionEnabled()})
+}
+
+// handleSetEncryption turns encryption of the tenant's new calls on or off.
+// Call search and call field value filters are unavailable while it is on.
+func (r *Router) handleSetEncryption(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Enabled == nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if *body.Enabled && !r.store.EncryptionEnabled() {
+		http.Error(w, `{"error": "encryption is not configured on this server"}`, http.StatusConflict)
+		return
+	}
+
+	before, err := r.store.GetTenantEncryption(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("encryption: failed to load setting", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save encryption setting"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := r.store.SetTenantEncryption(req.Context(), *authUser.TenantID, *body.Enabled, &authUser.ID); err != nil {
+		r.logger.Error("encryption: failed to save setting", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save encryption setting"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("encryption: saved setting", "tenant_id", *authUser.TenantID, "enabled", *body.Enabled)
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionEncryptionUpdate),
+		encryptionAuditState{before}, encryptionAuditState{*body.Enabled})
+	writeJSON(w, http.StatusOK, encryptionResponse{Enabled: *body.Enabled, Available: r.store.EncryptionEnabled()})
+}
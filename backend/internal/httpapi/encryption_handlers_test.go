@@ -0,0 +1,43 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestHandleSetEncryption_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard(), store: store.New(nil)}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{"enabled":true}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"missing enabled", authCtx, `{}`, http.StatusBadRequest},
+		{"no master key", authCtx, `{"enabled":true}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/tenant/encryption", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleSetEncryption(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/experiments"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// defaultEarlyHangupSeconds is the call length below which a caller hangup
+// counts as early in experiment results.
+const defaultEarlyHangupSeconds = 20
+
+// handleAdminListExperiments returns all experiments.
+func (r *Router) handleAdminListExperiments(w http.ResponseWriter, req *http.Request) {
+	list, err := r.store.ListExperiments(req.Context())
+	if err != nil {
+		r.logger.Error("admin: failed to list experiments", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to list experiments"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"experiments": list})
+}
+
+// handleAdminCreateExperiment creates an experiment in draft status.
+func (r *Router) handleAdminCreateExperiment(w http.ResponseWriter, req *http.Request) {
+	var body struct {
+		Name        string                `json:"name"`
+		Description string                `json:"description"`
+		TenantID    *string               `json:"tenant_id"`
+		Variants    []experiments.Variant `json:"variants"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" {
+		http.Error(w, `{"error": "name is required"}`, http.StatusBadRequest)
+		return
+	}
+	if err := experiments.ValidateVariants(body.Variants, body.TenantID == nil); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Global config overrides must target existing keys with valid values
+	for _, v := range body.Variants {
+		for key, value := range v.GlobalConfig {
+			if _, err := r.store.GetGlobalConfig(req.Context(), key); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown global config key: " + key})
+					return
+				}
+				r.logger.Error("admin: failed to check config key", "key", key, "error", err)
+				sentry.CaptureException(err)
+				http.Error(w, `{"error": "failed to check config key"}`, http.StatusInternalServerError)
+				return
+			}
+			if msg := validateGlobalConfigValue(key, value); msg != "" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": key + ": " + msg})
+				return
+			}
+		}
+	}
+
+	if body.TenantID != nil {
+		if _, err := r.store.GetTenantByID(req.Context(), *body.TenantID); err != nil {
+			http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+			return
+		}
+	}
+
+	exp, err := r.store.CreateExperiment(req.Context(), body.Name, body.Description, body.TenantID, body.Variants)
+	if err != nil {
+		r.logger.Error("admin: failed to create experiment", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to create experiment"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("admin: created experiment", "experiment_id", exp.ID, "name", exp.Name)
+	r.recordAudit(req, experimentAuditEntry(store.AuditActionExperimentCreate, exp.ID), nil, exp)
+	writeJSON(w, http.StatusCreated, exp)
+}
+
+// handleAdminUpdateExperiment starts or stops an experiment.
+// Allowed transitions: draft -> running -> stopped.
+func (r *Router) handleAdminUpdateExperiment(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	exp, err := r.store.GetExperiment(req.Context(), id)
+	if err != nil {
+		http.Error(w, `{"error": "experiment not found"}`, http.StatusNotFound)
+		return
+	}
+
+	if !validExperimentTransition(exp.Status, body.Status) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "cannot change status from " + exp.Status + " to " + body.Status,
+		})
+		return
+	}
+
+	if err := r.store.UpdateExperimentStatus(req.Context(), id, body.Status); err != nil {
+		r.logger.Error("admin: failed to update experiment", "experiment_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to update experiment"}`, http.StatusInternalServerError)
+		return
+	}
+	r.logger.Info("admin: updated experiment status", "experiment_id", id, "status", body.Status)
+
+	before := exp
+	exp, err = r.store.GetExperiment(req.Context(), id)
+	if err != nil {
+		http.Error(w, `{"error": "experiment not found"}`, http.StatusNotFound)
+		return
+	}
+	r.recordAudit(req, experimentAuditEntry(store.AuditActionExperimentUpdate, id), before, exp)
+	writeJSON(w, http.StatusOK, exp)
+}
+
+// experimentAuditEntry is an admin change to an experiment. Experiments are
+// admin tooling, so the entries aren't shown to tenants.
+func experimentAuditEntry(action, id string) store.AuditEntry {
+	return store.AuditEntry{
+		ActorType: store.AuditActorAdmin, Action: action,
+		TargetType: store.AuditTargetExperiment, TargetID: id,
+	}
+}
+
+// validExperimentTransition reports whether an experiment may move between statuses.
+func validExperimentTransition(from, to string) bool {
+	switch from {
+	case experiments.StatusDraft:
+		return to == experiments.StatusRunning
+	case experiments.StatusRunning:
+		return to == experiments.StatusStopped
+	default:
+		return false
+	}
+}
+
+// handleAdminGetExperimentResults returns outcome metrics aggregated per variant.
+// Query param early_hangup_seconds sets the early hangup threshold (default 20).
+func (r *Router) handleAdminGetExperimentResults(w http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+
+	earlyHangup := defaultEarlyHangupSeconds
+	if v := req.URL.Query().Get("early_hangup_seconds"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error": "early_hangup_seconds must be a positive number"}`, http.StatusBadRequest)
+			return
+		}
+		earlyHangup = n
+	}
+
+	exp, err := r.store.GetExperiment(req.Context(), id)
+	if err != nil {
+		http.Error(w, `{"error": "experiment not found"}`, http.StatusNotFound)
+		return
+	}
+
+	outcomes, err := r.store.GetExperimentOutcomes(req.Context(), id, earlyHangup)
+	if err != nil {
+		r.logger.Error("admin: failed to get experiment outcomes", "experiment_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to get experiment results"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"experiment":           exp,
+		"early_hangup_seconds": earlyHangup,
+		"variants":             mergeVariantOutcomes(exp.Variants, outcomes),
+	})
+}
+
+// mergeVariantOutcomes returns one outcome per defined variant (in definition
+// order), including variants that have no calls yet.
+func mergeVariantOutcomes(variants []experiments.Variant, outcomes []store.VariantOutcome) []store.VariantOutcome {
+	byName := make(map[string]store.VariantOutcome, len(outcomes))
+	for _, o := range outcomes {
+		byName[o.Variant] = o
+	}
+	out := make([]store.VariantOutcome, 0, len(variants))
+	for _, v := range variants {
+		o, ok := byName[v.Name]
+		if !ok {
+			o = store.VariantOutcome{Variant: v.Name}
+		}
+		out = append(out, o)
+	}
+	return out
+}
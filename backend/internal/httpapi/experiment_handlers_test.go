@@ -0,0 +1,116 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/experiments"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestHandleAdminCreateExperiment_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"invalid json", `{`, "invalid request body"},
+		{"missing name", `{"variants": []}`, "name is required"},
+		{"one variant", `{"name": "x", "variants": [{"name": "a", "weight": 1}]}`, "at least two variants"},
+		{
+			"unknown tenant field",
+			`{"name": "x", "variants": [{"name": "a", "weight": 1}, {"name": "b", "weight": 1, "tenant_config": {"plan": "pro"}}]}`,
+			"cannot be overridden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/experiments", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			r.handleAdminCreateExperiment(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantErr) {
+				t.Errorf("body = %s, want error containing %q", rec.Body.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleAdminGetExperimentResults_InvalidThreshold(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/experiments/e1/results?early_hangup_seconds=-5", nil)
+	req.SetPathValue("id", "e1")
+	rec := httptest.NewRecorder()
+
+	r.handleAdminGetExperimentResults(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestValidExperimentTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{experiments.StatusDraft, experiments.StatusRunning, true},
+		{experiments.StatusRunning, experiments.StatusStopped, true},
+		{experiments.StatusDraft, experiments.StatusStopped, false},
+		{experiments.StatusStopped, experiments.StatusRunning, false},
+		{experiments.StatusRunning, "paused", false},
+	}
+	for _, tt := range tests {
+		if got := validExperimentTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("validExperimentTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestMergeVariantOutcomes(t *testing.T) {
+	variants := []experiments.Variant{{Name: "control"}, {Name: "fast_turns"}}
+	outcomes := []store.VariantOutcome{{Variant: "fast_turns", Calls: 4, Completed: 3}}
+
+	got := mergeVariantOutcomes(variants, outcomes)
+
+	if len(got) != 2 {
+		t.Fatalf("got %d outcomes, want 2", len(got))
+	}
+	if got[0].Variant != "control" || got[0].Calls != 0 {
+		t.Errorf("got[0] = %+v, want empty control", got[0])
+	}
+	if got[1].Variant != "fast_turns" || got[1].Calls != 4 {
+		t.Errorf("got[1] = %+v, want fast_turns with 4 calls", got[1])
+	}
+}
+
+func TestCallSessionGlobalConfig_ExperimentOverrides(t *testing.T) {
+	// Overridden keys are served without touching the store
+	s := &callSession{configOverrides: map[string]string{
+		"max_turn_timeout_ms":    "2500",
+		"adaptive_turn_enabled":  "false",
+		"robocall_hold_keywords": `["moment"]`,
+	}}
+	ctx := context.Background()
+
+	if got := s.globalConfigInt(ctx, "max_turn_timeout_ms", 4000); got != 2500 {
+		t.Errorf("globalConfigInt() = %d, want 2500", got)
+	}
+	if got := s.globalConfigBool(ctx, "adaptive_turn_enabled", true); got {
+		t.Error("globalConfigBool() = true, want false")
+	}
+	if got, err := s.globalConfig(ctx, "robocall_hold_keywords"); err != nil || got != `["moment"]` {
+		t.Errorf("globalConfig() = %q, %v", got, err)
+	}
+}
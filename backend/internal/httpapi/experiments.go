@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/experiments"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// assignExperiment assigns the call to a variant of the running experiment
+// for its tenant, applies the variant's tenant config overrides and records
+// the assignment on the call. Must run before the tenant config is used.
+func (s *callSession) assignExperiment() {
+	if s.tenantCfg.TenantID == "" || s.callSid == "" {
+		return
+	}
+
+	exp, err := s.store.GetRunningExperimentForTenant(s.ctx, s.tenantCfg.TenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		s.logger.Error("media_ws: failed to load running experiment", "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+
+	variant := experiments.Assign(exp.ID, s.callSid, exp.Variants)
+	if variant == nil {
+		return
+	}
+	if err := experiments.ApplyTenantConfig(&s.tenantCfg, variant.TenantConfig); err != nil {
+		s.logger.Error("media_ws: failed to apply experiment variant", "experiment_id", exp.ID, "variant", variant.Name, "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+	s.configOverrides = variant.GlobalConfig
+	s.experimentID = exp.ID
+	s.experimentVariant = variant.Name
+	s.logger = s.logger.With("experiment_id", exp.ID, "experiment_variant", variant.Name)
+	s.logger.Info("media_ws: assigned experiment variant", "experiment", exp.Name)
+
+	// A variant prompt replaces the tenant's prompt version: replay reads it
+	// from the variant
+	if err := s.store.SetCallExperiment(s.ctx, s.callSid, exp.ID, variant.Name, variant.SystemPrompt() != ""); err != nil {
+		s.logger.Error("media_ws: failed to record experiment variant", "error", err)
+		sentry.CaptureException(err)
+	}
+	s.eventLog.LogAsync(s.callID, eventlog.EventExperimentAssigned, map[string]any{
+		"experiment_id":   exp.ID,
+		"experiment_name": exp.Name,
+		"variant":         variant.Name,
+	})
+}
+
+// globalConfig reads a global config value, honouring the call's experiment overrides.
+func (s *callSession) globalConfig(ctx context.Context, key string) (string, error) {
+	if v, ok := s.configOverrides[key]; ok {
+		return v, nil
+	}
+	return s.store.GetGlobalConfig(ctx, key)
+}
+
+// globalConfigInt reads a global config value as int, honouring the call's experiment overrides.
+func (s *callSession) globalConfigInt(ctx context.Context, key string, defaultVal int) int {
+	if v, ok := s.configOverrides[key]; ok {
+		return store.ParseConfigInt(v, defaultVal)
+	}
+	return s.store.GetGlobalConfigInt(ctx, key, defaultVal)
+}
+
+// globalConfigBool reads a global config value as bool, honouring the call's experiment overrides.
+func (s *callSession) globalConfigBool(ctx context.Context, key string, defaultVal bool) bool {
+	if v, ok := s.configOverrides[key]; ok {
+		return store.ParseConfigBool(v)
+	}
+	return s.store.GetGlobalConfigBool(ctx, key, defaultVal)
+}
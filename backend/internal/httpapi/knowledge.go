@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// Knowledge base retrieval per caller turn. The search runs on the hot path
+// before the LLM request, so it has a tight timeout and is skipped on error.
+const (
+	knowledgeSnippetLimit  = 3
+	knowledgeSearchTimeout = 300 * time.Millisecond
+)
+
+// knowledgeContextHeader introduces retrieved snippets in the LLM context.
+const knowledgeContextHeader = "Informace od majitele telefonu, které můžeš použít k odpovědi na dotaz volajícího " +
+	"(odpovídej jen podle nich, nic si nevymýšlej; pokud odpověď neobsahují, nabídni předání vzkazu):"
+
+// withKnowledge returns msgs with the snippets inserted as a system message
+// before the last (caller) message. msgs is not modified.
+func withKnowledge(msgs []llm.Message, snippets []store.KnowledgeSnippet) []llm.Message {
+	if len(snippets) == 0 || len(msgs) == 0 {
+		return msgs
+	}
+
+	var sb strings.Builder
+	sb.WriteString(knowledgeContextHeader)
+	for _, sn := range snippets {
+		sb.WriteString("\n\n")
+		sb.WriteString(sn.Content)
+	}
+
+	return withSystemBeforeLast(msgs, sb.String())
+}
+
+// withSystemBeforeLast returns msgs with a system message inserted before the
+// last (caller) message. msgs is not modified.
+func withSystemBeforeLast(msgs []llm.Message, content string) []llm.Message {
+	out := make([]llm.Message, 0, len(msgs)+1)
+	out = append(out, msgs[:len(msgs)-1]...)
+	out = append(out, llm.Message{Role: "system", Content: content})
+	return append(out, msgs[len(msgs)-1])
+}
+
+// loadKnowledge enables per-turn retrieval when the tenant has a knowledge base.
+func (s *callSession) loadKnowledge() {
+	if s.tenantCfg.TenantID == "" {
+		return
+	}
+	has, err := s.store.HasKnowledge(s.ctx, s.tenantCfg.TenantID)
+	if err != nil {
+		s.logger.Error("media_ws: failed to check knowledge base", "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+	s.knowledgeEnabled = has
+}
+
+// retrieveKnowledge searches the tenant's knowledge base for the caller's
+// turn and returns msgs with the relevant snippets injected. The snippets
+// used are logged as a call event.
+func (s *callSession) retrieveKnowledge(ctx context.Context, turnID uint64, msgs []llm.Message, query string) []llm.Message {
+	if !s.knowledgeEnabled {
+		return msgs
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, knowledgeSearchTimeout)
+	defer cancel()
+	start := time.Now()
+	snippets, err := s.store.SearchKnowledge(searchCtx, s.tenantCfg.TenantID, query, knowledgeSnippetLimit)
+	if err != nil {
+		s.logger.WarnContext(ctx, "media_ws: knowledge search failed", "error", err)
+		return msgs
+	}
+	if len(snippets) == 0 {
+		return msgs
+	}
+
+	used := make([]map[string]any, len(snippets))
+	for i, sn := range snippets {
+		used[i] = map[string]any{
+			"chunk_id":    sn.ChunkID,
+			"document_id": sn.DocumentID,
+			"title":       sn.Title,
+			"score":       sn.Score,
+		}
+	}
+	s.logger.InfoContext(ctx, "media_ws: knowledge snippets retrieved", "count", len(snippets))
+	s.eventLog.LogAsync(s.callID, eventlog.EventKnowledgeRetrieved, map[string]any{
+		"turn_id":     turnID,
+		"query":       s.redact(query),
+		"snippets":    used,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	return withKnowledge(msgs, snippets)
+}
@@ -0,0 +1,222 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/knowledge"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// Knowledge base entry limits.
+const (
+	knowledgeMaxTitleLength    = 200
+	knowledgeMaxFAQLength      = 2000
+	knowledgeMaxDocumentLength = 50000
+)
+
+// validateKnowledgeEntry checks a knowledge base entry; it returns an error
+// message or "".
+func validateKnowledgeEntry(kind, title, content string) string {
+	if kind != knowledge.KindFAQ && kind != knowledge.KindDocument {
+		return "kind must be faq or document"
+	}
+	if title == "" {
+		return "title is required"
+	}
+	if utf8.RuneCountInString(title) > knowledgeMaxTitleLength {
+		return "title is too long"
+	}
+	if content == "" {
+		return "content is required"
+	}
+	maxContent := knowledgeMaxDocumentLength
+	if kind == knowledge.KindFAQ {
+		maxContent = knowledgeMaxFAQLength
+	}
+	if utf8.RuneCountInString(content) > maxContent {
+		return "content is too long"
+	}
+	return ""
+}
+
+// handleListKnowledge returns the tenant's knowledge base entries.
+func (r *Router) handleListKnowledge(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	docs, err := r.store.ListKnowledgeDocuments(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("knowledge: failed to list documents", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to list knowledge base"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"documents": docs})
+}
+
+// handleCreateKnowledge adds an FAQ entry (title = question, content = answer)
+// or a document, which is split into chunks and indexed.
+func (r *Router) handleCreateKnowledge(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Kind    string `json:"kind"`
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if body.Kind == "" {
+		body.Kind = knowledge.KindDocument
+	}
+	body.Title = strings.TrimSpace(body.Title)
+	body.Content = strings.TrimSpace(body.Content)
+	if msg := validateKnowledgeEntry(body.Kind, body.Title, body.Content); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	doc, err := r.store.CreateKnowledgeDocument(req.Context(), *authUser.TenantID, body.Kind, body.Title, body.Content)
+	if err != nil {
+		r.logger.Error("knowledge: failed to create document", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save knowledge base entry"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("knowledge: created document", "tenant_id", *authUser.TenantID, "document_id", doc.ID, "kind", doc.Kind, "chunks", doc.ChunkCount)
+	writeJSON(w, http.StatusCreated, doc)
+}
+
+// handleUpdateKnowledge replaces an entry's title and content and re-indexes
+// it.
+func (r *Router) handleUpdateKnowledge(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	id := req.PathValue("id")
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "knowledge base entry not found"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	body.Title = strings.TrimSpace(body.Title)
+	body.Content = strings.TrimSpace(body.Content)
+	// Reject malformed entries before loading the kind (which can't change);
+	// FAQ entries then get their lower size limit.
+	if msg := validateKnowledgeEntry(knowledge.KindDocument, body.Title, body.Content); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	kind, err := r.store.GetKnowledgeDocumentKind(req.Context(), *authUser.TenantID, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, `{"error": "knowledge base entry not found"}`, http.StatusNotFound)
+			return
+		}
+		r.logger.Error("knowledge: failed to load document", "document_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save knowledge base entry"}`, http.StatusInternalServerError)
+		return
+	}
+	if msg := validateKnowledgeEntry(kind, body.Title, body.Content); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	doc, err := r.store.UpdateKnowledgeDocument(req.Context(), *authUser.TenantID, id, body.Title, body.Content)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, `{"error": "knowledge base entry not found"}`, http.StatusNotFound)
+			return
+		}
+		r.logger.Error("knowledge: failed to update document", "document_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save knowledge base entry"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// handleDeleteKnowledge deletes an entry and its index.
+func (r *Router) handleDeleteKnowledge(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	id := req.PathValue("id")
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "knowledge base entry not found"}`, http.StatusNotFound)
+		return
+	}
+
+	deleted, err := r.store.DeleteKnowledgeDocument(req.Context(), *authUser.TenantID, id)
+	if err != nil {
+		r.logger.Error("knowledge: failed to delete document", "document_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to delete knowledge base entry"}`, http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, `{"error": "knowledge base entry not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSearchKnowledge returns the snippets a call turn with the text q
+// would retrieve, for checking the knowledge base.
+func (r *Router) handleSearchKnowledge(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	q := strings.TrimSpace(req.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, `{"error": "q is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	snippets, err := r.store.SearchKnowledge(req.Context(), *authUser.TenantID, q, knowledgeSnippetLimit)
+	if err != nil {
+		r.logger.Error("knowledge: search failed", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to search knowledge base"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"terms":    knowledge.Terms(q),
+		"snippets": snippets,
+	})
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestWithKnowledge(t *testing.T) {
+	msgs := []llm.Message{
+		{Role: "assistant", Content: "Dobrý den"},
+		{Role: "user", Content: "Kdy máte otevřeno?"},
+	}
+
+	if got := withKnowledge(msgs, nil); len(got) != 2 {
+		t.Errorf("expected messages unchanged without snippets, got %+v", got)
+	}
+
+	got := withKnowledge(msgs, []store.KnowledgeSnippet{
+		{Content: "Otevírací doba\nPo–Pá 8–16"},
+		{Content: "Parkování\nPřed budovou"},
+	})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 messages, got %+v", got)
+	}
+	if got[1].Role != "system" || !strings.HasPrefix(got[1].Content, knowledgeContextHeader) ||
+		!strings.Contains(got[1].Content, "Po–Pá 8–16") || !strings.Contains(got[1].Content, "Před budovou") {
+		t.Errorf("unexpected knowledge message %+v", got[1])
+	}
+	if got[2] != msgs[1] {
+		t.Errorf("expected the caller message last, got %+v", got[2])
+	}
+	if len(msgs) != 2 {
+		t.Error("input messages were modified")
+	}
+}
+
+func TestValidateKnowledgeEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		title   string
+		content string
+		wantErr bool
+	}{
+		{"valid faq", "faq", "Kde parkovat?", "Před budovou.", false},
+		{"valid document", "document", "Ceník", strings.Repeat("a", knowledgeMaxFAQLength+1), false},
+		{"invalid kind", "pdf", "Ceník", "x", true},
+		{"missing title", "faq", "", "x", true},
+		{"title too long", "faq", strings.Repeat("a", knowledgeMaxTitleLength+1), "x", true},
+		{"missing content", "document", "Ceník", "", true},
+		{"faq too long", "faq", "Kde?", strings.Repeat("a", knowledgeMaxFAQLength+1), true},
+		{"document too long", "document", "Ceník", strings.Repeat("a", knowledgeMaxDocumentLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateKnowledgeEntry(tt.kind, tt.title, tt.content)
+			if (msg != "") != tt.wantErr {
+				t.Errorf("validateKnowledgeEntry() = %q, wantErr %v", msg, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKnowledgeHandlers_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	const testEntryID = "8b0c5a6e-3f1d-4c2a-9e7b-1d2f3a4b5c6d"
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		ctx     context.Context
+		body    string
+		want    int
+	}{
+		{"list no tenant", r.handleListKnowledge, http.MethodGet, "/api/knowledge", context.Background(), "", http.StatusNotFound},
+		{"create no tenant", r.handleCreateKnowledge, http.MethodPost, "/api/knowledge", context.Background(), `{}`, http.StatusNotFound},
+		{"create invalid body", r.handleCreateKnowledge, http.MethodPost, "/api/knowledge", authCtx, `{`, http.StatusBadRequest},
+		{"create invalid kind", r.handleCreateKnowledge, http.MethodPost, "/api/knowledge", authCtx, `{"kind":"pdf","title":"a","content":"b"}`, http.StatusBadRequest},
+		{"create blank title", r.handleCreateKnowledge, http.MethodPost, "/api/knowledge", authCtx, `{"title":"  ","content":"b"}`, http.StatusBadRequest},
+		{"update invalid id", r.handleUpdateKnowledge, http.MethodPut, "/api/knowledge/1", authCtx, `{"title":"a","content":"b"}`, http.StatusNotFound},
+		{"update invalid body", r.handleUpdateKnowledge, http.MethodPut, "/api/knowledge/" + testEntryID, authCtx, `{`, http.StatusBadRequest},
+		{"update missing content", r.handleUpdateKnowledge, http.MethodPut, "/api/knowledge/" + testEntryID, authCtx, `{"title":"a"}`, http.StatusBadRequest},
+		{"delete no tenant", r.handleDeleteKnowledge, http.MethodDelete, "/api/knowledge/" + testEntryID, context.Background(), "", http.StatusNotFound},
+		{"delete invalid id", r.handleDeleteKnowledge, http.MethodDelete, "/api/knowledge/1", authCtx, "", http.StatusNotFound},
+		{"search missing q", r.handleSearchKnowledge, http.MethodGet, "/api/knowledge/search?q=+", authCtx, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)).WithContext(tt.ctx)
+			req.SetPathValue("id", strings.TrimPrefix(tt.target, "/api/knowledge/"))
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"math/rand"
 	"net/http"
@@ -19,13 +19,22 @@ import (
 
 	"github.com/getsentry/sentry-go"
 	"github.com/gorilla/websocket"
+	"github.com/lukasbauer/karen/internal/breaker"
 	"github.com/lukasbauer/karen/internal/costs"
 	"github.com/lukasbauer/karen/internal/eventlog"
 	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/metrics"
 	"github.com/lukasbauer/karen/internal/notifications"
+	"github.com/lukasbauer/karen/internal/redact"
+	"github.com/lukasbauer/karen/internal/slots"
 	"github.com/lukasbauer/karen/internal/store"
 	"github.com/lukasbauer/karen/internal/stt"
+	"github.com/lukasbauer/karen/internal/tracing"
 	"github.com/lukasbauer/karen/internal/tts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var upgrader = websocket.Upgrader{
@@ -229,21 +238,47 @@ type callSession struct {
 	conn   *websocket.Conn
 	connMu sync.Mutex
 
-	sttClient *stt.DeepgramClient
-	llmClient *llm.OpenAIClient
-	ttsClient *tts.ElevenLabsClient
+	sttClient  stt.Client
+	llmClient  llm.Client
+	ttsClient  tts.Client
+	sttBreaker *breaker.Breaker // Breaker of the connected STT provider
+	providers  *providerRegistry
 
 	store        *store.Store
-	logger       *log.Logger
+	logger       *slog.Logger
 	eventLog     *eventlog.Logger
 	cfg          RouterConfig
 	httpClient   *http.Client
 	apns         *notifications.APNsClient
+	discord      *notifications.Discord
 	callRegistry *CallRegistry
 
 	// Tenant-specific configuration
 	tenantCfg TenantConfig
 
+	// Browser softphone test call (nil for Twilio calls)
+	softphone *softphoneCall
+	pcm16     bool // Softphone audio is PCM16 at 16 kHz (converted to/from μ-law 8 kHz)
+
+	// A/B experiment assignment (variant overrides are applied to tenantCfg and configOverrides)
+	experimentID      string
+	experimentVariant string
+	configOverrides   map[string]string // Global config keys overridden for this call
+
+	// Knowledge base retrieval per turn (tenant has knowledge base entries)
+	knowledgeEnabled bool
+
+	// Tenant call fields collected on the call (nil if the tenant has none)
+	callFields           *slots.State
+	callFieldsExtracting atomic.Bool
+
+	// PII redaction applied to text before it is stored or logged
+	redactor *redact.Redactor
+
+	// Screening labels, loaded after the call (nil until loaded)
+	taxonomy       *llm.Taxonomy
+	taxonomyCustom bool
+
 	// Conversation state
 	messages   []llm.Message
 	messagesMu sync.Mutex
@@ -280,10 +315,8 @@ type callSession struct {
 	greetingMarkID     uint64 // mark ID for greeting audio; protected by audioMu
 
 	// Cost tracking metrics
-	costMetricsMu   sync.Mutex
-	ttsCharacters   int // Total characters sent to TTS
-	llmInputTokens  int // Estimated input tokens (chars / 4)
-	llmOutputTokens int // Estimated output tokens (chars / 4)
+	usage  *costs.Usage // Billed provider usage reported by the STT/LLM/TTS clients
+	budget costs.Budget // Tenant's monthly cost budget at call start (degraded mode when near it)
 
 	// Robocall detection
 	robocallDetector *RobocallDetector
@@ -299,6 +332,9 @@ type callSession struct {
 	lastEnergyCheckTime time.Time // last time we logged energy stats
 	silenceEventLogged  bool      // prevent duplicate silence events per call
 
+	// Tracing: root span covering the whole call
+	callSpan trace.Span
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -306,14 +342,14 @@ type callSession struct {
 func (r *Router) handleMediaWS(w http.ResponseWriter, req *http.Request) {
 	// Reject new calls when draining (graceful shutdown in progress)
 	if r.calls.IsDraining() {
-		r.logger.Printf("media_ws: rejecting new call, server is draining")
+		r.logger.Warn("media_ws: rejecting new call, server is draining")
 		http.Error(w, "server is draining", http.StatusServiceUnavailable)
 		return
 	}
 
 	// Check if we have required API keys
 	if r.cfg.DeepgramAPIKey == "" || r.cfg.OpenAIAPIKey == "" || r.cfg.ElevenLabsAPIKey == "" {
-		r.logger.Printf("media_ws: missing API keys")
+		r.logger.Warn("media_ws: missing API keys")
 		captureError(req, fmt.Errorf("voice AI not configured: missing API keys"), "media_ws: configuration error")
 		http.Error(w, "voice AI not configured", http.StatusServiceUnavailable)
 		return
@@ -321,20 +357,31 @@ func (r *Router) handleMediaWS(w http.ResponseWriter, req *http.Request) {
 
 	conn, err := upgrader.Upgrade(w, req, nil)
 	if err != nil {
-		r.logger.Printf("media_ws: upgrade failed: %v", err)
+		r.logger.Error("media_ws: upgrade failed", "error", err)
 		sentry.CaptureException(err)
 		return
 	}
 
+	r.runCallSession(req, conn, nil)
+}
+
+// runCallSession runs a voice AI session on an upgraded media websocket until
+// the call ends. softphone is set for browser test calls.
+func (r *Router) runCallSession(req *http.Request, conn *websocket.Conn, softphone *softphoneCall) {
 	// Register this call in the registry. If draining started between the check
 	// above and now, reject and close the connection.
 	if !r.calls.Add() {
-		r.logger.Printf("media_ws: rejecting new call, server is draining")
+		r.logger.Warn("media_ws: rejecting new call, server is draining")
 		conn.Close()
 		return
 	}
 
 	ctx, cancel := context.WithCancel(req.Context())
+	ctx, callSpan := tracing.Start(ctx, "call", trace.WithNewRoot())
+
+	// Provider clients report billed usage to the accumulator in the call context
+	usage := costs.NewUsage()
+	ctx = costs.WithUsage(ctx, usage)
 
 	session := &callSession{
 		conn:         conn,
@@ -344,31 +391,27 @@ func (r *Router) handleMediaWS(w http.ResponseWriter, req *http.Request) {
 		cfg:          r.cfg,
 		httpClient:   &http.Client{Timeout: 10 * time.Second},
 		apns:         r.apns,
+		discord:      r.discord,
 		callRegistry: r.calls,
+		providers:    r.providers,
 		messages:     []llm.Message{},
 		bargeInCh:    make(chan string, 1), // Buffered channel for barge-in
 		goodbyeDone:  make(chan struct{}),
+		callSpan:     callSpan,
+		usage:        usage,
+		softphone:    softphone,
 		ctx:          ctx,
 		cancel:       cancel,
 	}
 
 	// Create LLM client (doesn't require connection)
-	session.llmClient = llm.NewOpenAIClient(llm.OpenAIConfig{
-		APIKey: r.cfg.OpenAIAPIKey,
-		Model:  "gpt-4o-mini",
-	})
+	session.llmClient = r.providers.newLLMClient(session.providerFailureHandler("llm"))
 
 	// Create TTS client with shared HTTP client for connection pooling
-	session.ttsClient = tts.NewElevenLabsClient(tts.ElevenLabsConfig{
-		APIKey:     r.cfg.ElevenLabsAPIKey,
-		VoiceID:    r.cfg.TTSVoiceID,
-		ModelID:    "eleven_flash_v2_5",
-		Stability:  r.cfg.TTSStability,
-		Similarity: r.cfg.TTSSimilarity,
-		HTTPClient: r.cfg.TTSHTTPClient,
-	})
+	session.ttsClient = r.providers.newTTSClient(r.cfg.TTSVoiceID, "", r.cfg.TTSStability, r.cfg.TTSSimilarity,
+		r.cfg.TTSHTTPClient, session.providerFailureHandler("tts"))
 
-	r.logger.Printf("media_ws: connection established, waiting for start message")
+	r.logger.Info("media_ws: connection established, waiting for start message")
 
 	// Handle the WebSocket connection
 	session.run()
@@ -387,45 +430,46 @@ func (s *callSession) run() {
 		_, msg, err := s.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
-				s.logger.Printf("media_ws: connection closed for call %s", s.callSid)
+				s.logger.Info("media_ws: connection closed")
 			} else {
-				s.logger.Printf("media_ws: read error for call %s: %v", s.callSid, err)
+				s.logger.Error("media_ws: read error", "error", err)
 			}
 			return
 		}
 
 		var twilioMsg twilioMessage
 		if err := json.Unmarshal(msg, &twilioMsg); err != nil {
-			s.logger.Printf("media_ws: failed to parse message: %v", err)
+			s.logger.Error("media_ws: failed to parse message", "error", err)
 			continue
 		}
 
 		switch twilioMsg.Event {
 		case "connected":
-			s.logger.Printf("media_ws: Twilio connected for call %s", s.callSid)
+			s.logger.Info("media_ws: Twilio connected")
 
 		case "start":
 			if err := s.handleStart(twilioMsg.Start); err != nil {
-				s.logger.Printf("media_ws: start error: %v", err)
+				s.logger.Error("media_ws: start error", "error", err)
 				sentry.CaptureException(err)
 				return
 			}
 
 		case "media":
 			if err := s.handleMedia(twilioMsg.Media); err != nil {
-				s.logger.Printf("media_ws: media error: %v", err)
+				s.logger.Error("media_ws: media error", "error", err)
 			}
 
 		case "stop":
-			s.logger.Printf("media_ws: stream stopped for call %s", s.callSid)
+			s.logger.Info("media_ws: stream stopped")
 
 			// Mark call as ended by caller (only if agent didn't initiate the hangup)
 			if s.callID != "" && s.callSid != "" && !s.agentHungUp {
-				s.logger.Printf("media_ws: caller hung up")
+				s.logger.Info("media_ws: caller hung up")
+				metrics.Hangups.WithLabelValues("caller").Inc()
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 				if err := s.store.UpdateCallEndedBy(ctx, s.callSid, "caller"); err != nil {
-					s.logger.Printf("media_ws: failed to update ended_by: %v", err)
+					s.logger.Error("media_ws: failed to update ended_by", "error", err)
 					sentry.CaptureException(err)
 				}
 			}
@@ -443,6 +487,13 @@ func (s *callSession) handleStart(start *twilioStart) error {
 		return fmt.Errorf("nil start message")
 	}
 
+	if s.softphone != nil {
+		if err := s.softphone.applyStart(start); err != nil {
+			return err
+		}
+		s.pcm16 = start.MediaFormat.Encoding == softphoneEncodingL16
+	}
+
 	s.streamSid = start.StreamSid
 	s.accountSid = start.AccountSid
 
@@ -460,30 +511,51 @@ func (s *callSession) handleStart(start *twilioStart) error {
 	}
 	if configJSON, ok := start.CustomParams["tenantConfig"]; ok {
 		if err := json.Unmarshal([]byte(configJSON), &s.tenantCfg); err != nil {
-			s.logger.Printf("media_ws: failed to parse tenant config: %v", err)
+			s.logger.Error("media_ws: failed to parse tenant config", "error", err)
 			sentry.CaptureException(err)
 		}
 	}
 
+	// Scope the session logger to this call so every record carries its IDs
+	s.logger = s.logger.With("call_sid", s.callSid, "stream_sid", s.streamSid, "tenant_id", s.tenantCfg.TenantID)
+
+	// Per-tenant debug logging (toggled at runtime via global config)
+	if debugTenants, err := s.store.GetGlobalConfig(s.ctx, "debug_log_tenants"); err == nil &&
+		logging.ListContains(debugTenants, s.tenantCfg.TenantID) {
+		s.logger = logging.WithDebug(s.logger)
+	}
+
 	if s.tenantCfg.TenantID != "" {
-		s.logger.Printf("media_ws: stream started - StreamSid: %s, CallSid: %s, TenantID: %s",
-			start.StreamSid, s.callSid, s.tenantCfg.TenantID)
+		s.logger.Info("media_ws: stream started")
 	} else {
-		s.logger.Printf("media_ws: stream started - StreamSid: %s, CallSid: %s (no tenant)",
-			start.StreamSid, s.callSid)
+		s.logger.Info("media_ws: stream started (no tenant)")
+	}
+
+	if s.callSpan != nil {
+		s.callSpan.SetAttributes(
+			attribute.String("call_sid", s.callSid),
+			attribute.String("stream_sid", s.streamSid),
+			attribute.String("tenant_id", s.tenantCfg.TenantID),
+		)
 	}
 
 	// Get call ID from database now that we have callSid
 	if s.callSid != "" {
 		callID, err := s.store.GetCallID(s.ctx, s.callSid)
 		if err != nil {
-			s.logger.Printf("media_ws: failed to get call ID for %s: %v", s.callSid, err)
+			s.logger.Error("media_ws: failed to get call ID", "error", err)
 			sentry.CaptureException(err)
 		} else {
 			s.callID = callID
 		}
 	}
 
+	// Assign the call to a running A/B experiment (may override tenant and global config).
+	// Test calls are kept out of experiment results.
+	if s.softphone == nil {
+		s.assignExperiment()
+	}
+
 	// Determine language for STT (from tenant config or default)
 	language := "cs"
 	if s.tenantCfg.Language != "" {
@@ -497,7 +569,7 @@ func (s *callSession) handleStart(start *twilioStart) error {
 	}
 	if s.tenantCfg.Endpointing != nil && *s.tenantCfg.Endpointing > 0 {
 		endpointing = *s.tenantCfg.Endpointing
-		s.logger.Printf("media_ws: using tenant endpointing: %dms", endpointing)
+		s.logger.Debug("media_ws: using tenant endpointing", "endpointing_ms", endpointing)
 	}
 
 	// Determine utterance_end_ms (hard timeout after last speech, regardless of noise)
@@ -507,15 +579,14 @@ func (s *callSession) handleStart(start *twilioStart) error {
 	}
 	if s.tenantCfg.UtteranceEnd != nil && *s.tenantCfg.UtteranceEnd > 0 {
 		utteranceEnd = *s.tenantCfg.UtteranceEnd
-		s.logger.Printf("media_ws: using tenant utterance_end: %dms", utteranceEnd)
+		s.logger.Debug("media_ws: using tenant utterance_end", "utterance_end_ms", utteranceEnd)
 	}
 
 	// Check if STT debug logging is enabled (via global config)
-	sttDebug := s.store.GetGlobalConfigBool(s.ctx, "stt_debug_enabled", false)
+	sttDebug := s.globalConfigBool(s.ctx, "stt_debug_enabled", false)
 
-	// Connect to Deepgram STT
-	sttClient, err := stt.NewDeepgramClient(s.ctx, stt.DeepgramConfig{
-		APIKey:         s.cfg.DeepgramAPIKey,
+	// Connect to STT (first healthy provider)
+	sttClient, sttBreaker, err := s.providers.dialSTT(s.ctx, stt.DeepgramConfig{
 		Language:       language,
 		Model:          "nova-3",
 		SampleRate:     8000,
@@ -525,28 +596,41 @@ func (s *callSession) handleStart(start *twilioStart) error {
 		Endpointing:    endpointing,  // Silence-based turn detection
 		UtteranceEndMs: utteranceEnd, // Hard timeout after last speech (noise-resistant)
 		Debug:          sttDebug,     // Log raw Deepgram messages for diagnostics
-	})
+		Logger:         s.logger,
+	}, s.providerFailureHandler("stt"))
 	if err != nil {
-		return fmt.Errorf("failed to connect to Deepgram: %w", err)
+		return fmt.Errorf("failed to connect to STT: %w", err)
 	}
 	s.sttClient = sttClient
+	s.sttBreaker = sttBreaker
 
-	// Update TTS client with tenant's voice ID if specified (preserving shared HTTP client)
+	// Check the tenant's monthly cost budget; near it, the call runs in degraded mode
+	s.loadCostBudget()
+
+	// Per-turn knowledge base retrieval, if the tenant has one
+	s.loadKnowledge()
+	s.loadCallFields()
+	s.loadRedaction()
+
+	// Update TTS client with tenant's voice ID and/or the degraded-mode model (preserving shared HTTP client)
+	voiceID := s.cfg.TTSVoiceID
 	if s.tenantCfg.VoiceID != nil && *s.tenantCfg.VoiceID != "" {
-		s.ttsClient = tts.NewElevenLabsClient(tts.ElevenLabsConfig{
-			APIKey:     s.cfg.ElevenLabsAPIKey,
-			VoiceID:    *s.tenantCfg.VoiceID,
-			ModelID:    "eleven_flash_v2_5",
-			Stability:  s.cfg.TTSStability,
-			Similarity: s.cfg.TTSSimilarity,
-			HTTPClient: s.cfg.TTSHTTPClient,
-		})
+		voiceID = *s.tenantCfg.VoiceID
+	}
+	modelID := ""
+	if s.budget.Degraded() {
+		// Opt-in: empty keeps the normal model
+		modelID, _ = s.globalConfig(s.ctx, "cost_budget_degraded_tts_model")
+	}
+	if voiceID != s.cfg.TTSVoiceID || modelID != "" {
+		s.ttsClient = s.providers.newTTSClient(voiceID, modelID, s.cfg.TTSStability, s.cfg.TTSSimilarity,
+			s.cfg.TTSHTTPClient, s.providerFailureHandler("tts"))
 	}
 
 	// Set tenant's custom system prompt if available
 	if s.tenantCfg.SystemPrompt != "" {
 		s.llmClient.SetSystemPrompt(s.tenantCfg.SystemPrompt)
-		s.logger.Printf("media_ws: using tenant's custom system prompt")
+		s.logger.Debug("media_ws: using tenant's custom system prompt")
 	}
 
 	// Initialize robocall detector with global config
@@ -559,6 +643,7 @@ func (s *callSession) handleStart(start *twilioStart) error {
 	go s.speakGreeting()
 
 	// Log call started event
+	metrics.CallsStarted.Inc()
 	s.eventLog.LogAsync(s.callID, eventlog.EventCallStarted, map[string]any{
 		"stream_sid":       s.streamSid,
 		"call_sid":         s.callSid,
@@ -571,6 +656,20 @@ func (s *callSession) handleStart(start *twilioStart) error {
 	return nil
 }
 
+// providerFailureHandler returns a callback that logs provider failures of
+// the given kind ("stt", "llm" or "tts") for this call.
+func (s *callSession) providerFailureHandler(kind string) func(provider string, err error) {
+	return func(provider string, err error) {
+		s.logger.Error("media_ws: provider failed", "kind", kind, "provider", provider, "error", err)
+		metrics.ProviderErrors.WithLabelValues(kind, provider).Inc()
+		s.eventLog.LogAsync(s.callID, eventlog.EventProviderFailure, map[string]any{
+			"kind":     kind,
+			"provider": provider,
+			"error":    err.Error(),
+		})
+	}
+}
+
 func (s *callSession) handleMedia(media *twilioMedia) error {
 	if media == nil || s.sttClient == nil {
 		return nil
@@ -582,6 +681,10 @@ func (s *callSession) handleMedia(media *twilioMedia) error {
 		return fmt.Errorf("failed to decode audio: %w", err)
 	}
 
+	if s.pcm16 {
+		audio = pcm16ToMuLaw8k(audio)
+	}
+
 	// Track audio energy for diagnostics
 	s.trackAudioEnergy(audio)
 
@@ -609,8 +712,7 @@ func (s *callSession) trackAudioEnergy(audio []byte) {
 	// Log warning if we've had prolonged silence (and haven't logged yet)
 	if s.lowEnergyChunkCount >= lowEnergyChunkThreshold && !s.silenceEventLogged {
 		avgEnergy := s.audioEnergySum / float64(s.audioChunkCount)
-		s.logger.Printf("media_ws: AUDIO SILENCE DETECTED - %d consecutive low-energy chunks (avg energy: %.6f)",
-			s.lowEnergyChunkCount, avgEnergy)
+		s.logger.Warn("media_ws: AUDIO SILENCE DETECTED", "low_energy_chunk_count", s.lowEnergyChunkCount, "avg_energy", avgEnergy)
 		s.eventLog.LogAsync(s.callID, eventlog.EventAudioSilenceDetected, map[string]any{
 			"consecutive_low_energy_chunks": s.lowEnergyChunkCount,
 			"avg_energy":                    avgEnergy,
@@ -628,8 +730,7 @@ func (s *callSession) trackAudioEnergy(audio []byte) {
 		avgEnergy := s.audioEnergySum / float64(s.audioChunkCount)
 		// Only log if there's a notable low-energy streak
 		if s.lowEnergyChunkCount >= lowEnergyChunkThreshold/2 {
-			s.logger.Printf("media_ws: audio stats - chunks=%d avg_energy=%.6f low_energy_streak=%d",
-				s.audioChunkCount, avgEnergy, s.lowEnergyChunkCount)
+			s.logger.Debug("media_ws: audio stats", "chunks", s.audioChunkCount, "avg_energy", avgEnergy, "low_energy_streak", s.lowEnergyChunkCount)
 		}
 		s.lastEnergyCheckTime = now
 	}
@@ -684,9 +785,9 @@ func (s *callSession) handleMark(mark *twilioMarkData) {
 	pending := s.decAudioPending()
 
 	if ok && markID != 0 {
-		s.logger.Printf("media_ws: mark received %q (pending=%d)", mark.Name, pending)
+		s.logger.Debug("media_ws: mark received", "name", mark.Name, "pending", pending)
 	} else {
-		s.logger.Printf("media_ws: mark received (unparsed) %q (pending=%d)", mark.Name, pending)
+		s.logger.Debug("media_ws: mark received (unparsed)", "name", mark.Name, "pending", pending)
 	}
 
 	// Signal goodbye/forward completion only for the final mark we're waiting on.
@@ -696,7 +797,7 @@ func (s *callSession) handleMark(mark *twilioMarkData) {
 		if s.greetingMarkID != 0 && markID == s.greetingMarkID {
 			s.greetingMarkID = 0
 			s.greetingInProgress.Store(false)
-			s.logger.Printf("media_ws: greeting audio complete, barge-in now enabled")
+			s.logger.Info("media_ws: greeting audio complete, barge-in now enabled")
 		}
 		// Check if this is the goodbye/forward mark we're waiting on
 		awaitID := s.pendingDoneMarkID
@@ -823,6 +924,7 @@ func (s *callSession) processSTTResults() {
 	var currentUtterance strings.Builder
 	var utteranceStartTime *time.Time
 	var lastConfidence float64
+	var speechFinalAt time.Time // When the caller stopped speaking (turn latency start)
 
 	// Track consecutive empty STT results for diagnostics
 	var emptyResultCount int
@@ -835,17 +937,17 @@ func (s *callSession) processSTTResults() {
 	// Load adaptive turn config from global settings
 	ctx := context.Background()
 	adaptiveCfg := adaptiveTurnConfig{
-		enabled:            s.store.GetGlobalConfigBool(ctx, "adaptive_turn_enabled", true),
-		baseTimeout:        time.Duration(s.store.GetGlobalConfigInt(ctx, "max_turn_timeout_ms", 4000)) * time.Millisecond,
-		minTimeout:         time.Duration(s.store.GetGlobalConfigInt(ctx, "adaptive_min_timeout_ms", 500)) * time.Millisecond,
-		textDecayRateMs:    s.store.GetGlobalConfigInt(ctx, "adaptive_text_decay_rate_ms", 15),
-		sentenceEndBonusMs: s.store.GetGlobalConfigInt(ctx, "adaptive_sentence_end_bonus_ms", 1500),
+		enabled:            s.globalConfigBool(ctx, "adaptive_turn_enabled", true),
+		baseTimeout:        time.Duration(s.globalConfigInt(ctx, "max_turn_timeout_ms", 4000)) * time.Millisecond,
+		minTimeout:         time.Duration(s.globalConfigInt(ctx, "adaptive_min_timeout_ms", 500)) * time.Millisecond,
+		textDecayRateMs:    s.globalConfigInt(ctx, "adaptive_text_decay_rate_ms", 15),
+		sentenceEndBonusMs: s.globalConfigInt(ctx, "adaptive_sentence_end_bonus_ms", 1500),
 	}
 
 	// Per-tenant override for base timeout (if configured)
 	if s.tenantCfg.MaxTurnTimeoutMs != nil && *s.tenantCfg.MaxTurnTimeoutMs > 0 {
 		adaptiveCfg.baseTimeout = time.Duration(*s.tenantCfg.MaxTurnTimeoutMs) * time.Millisecond
-		s.logger.Printf("media_ws: using tenant max_turn_timeout: %v", adaptiveCfg.baseTimeout)
+		s.logger.Debug("media_ws: using tenant max_turn_timeout", "base_timeout", adaptiveCfg.baseTimeout)
 	}
 
 	// Czech language tuning: use longer timeouts to accommodate natural mid-sentence pauses
@@ -861,12 +963,10 @@ func (s *callSession) processSTTResults() {
 		if adaptiveCfg.sentenceEndBonusMs > 500 {
 			adaptiveCfg.sentenceEndBonusMs = 500 // Cap bonus: 500ms reduction vs default 1500ms
 		}
-		s.logger.Printf("media_ws: applied Czech language tuning for adaptive timeout")
+		s.logger.Info("media_ws: applied Czech language tuning for adaptive timeout")
 	}
 
-	s.logger.Printf("media_ws: adaptive turn config: enabled=%v base=%v min=%v decay=%dms/char bonus=%dms",
-		adaptiveCfg.enabled, adaptiveCfg.baseTimeout, adaptiveCfg.minTimeout,
-		adaptiveCfg.textDecayRateMs, adaptiveCfg.sentenceEndBonusMs)
+	s.logger.Debug("media_ws: adaptive turn config", "enabled", adaptiveCfg.enabled, "base", adaptiveCfg.baseTimeout, "min", adaptiveCfg.minTimeout, "decay_ms_per_char", adaptiveCfg.textDecayRateMs, "sentence_end_bonus_ms", adaptiveCfg.sentenceEndBonusMs)
 
 	var finalizeTimer *time.Timer
 	var finalizeC <-chan time.Time
@@ -937,6 +1037,7 @@ func (s *callSession) processSTTResults() {
 			currentUtterance.Reset()
 			utteranceStartTime = nil
 			lastConfidence = 0
+			speechFinalAt = time.Time{}
 			bargeInSent = false
 			return
 		}
@@ -954,9 +1055,10 @@ func (s *callSession) processSTTResults() {
 
 		if isSpeaking && !bargeInSent && !s.greetingInProgress.Load() {
 			// Ensure playback is stopped (safety net). We may have already cleared on interim results.
-			s.logger.Printf("media_ws: BARGE-IN detected - caller said: %s", text)
+			s.logger.Info("media_ws: BARGE-IN detected", "text", s.redact(text))
+			metrics.BargeIns.Inc()
 			if err := s.clearAudio(); err != nil {
-				s.logger.Printf("media_ws: failed to clear audio: %v", err)
+				s.logger.Error("media_ws: failed to clear audio", "error", err)
 				sentry.CaptureException(err)
 			}
 			s.cancelResponse()
@@ -972,10 +1074,11 @@ func (s *callSession) processSTTResults() {
 		}
 
 		turnID := atomic.AddUint64(&s.turnSeq, 1)
-		s.logger.Printf("media_ws: caller said (turn=%d): %s", turnID, text)
+		redacted := s.redact(text)
+		s.logger.Info("media_ws: caller said", "turn", turnID, "text", redacted)
 		s.eventLog.LogAsync(s.callID, eventlog.EventTurnFinalized, map[string]any{
 			"turn_id":     turnID,
-			"text":        text,
+			"text":        redacted,
 			"confidence":  lastConfidence,
 			"interrupted": isSpeaking,
 		})
@@ -987,7 +1090,7 @@ func (s *callSession) processSTTResults() {
 		if s.callID != "" {
 			_ = s.store.InsertUtterance(s.ctx, s.callID, store.Utterance{
 				Speaker:       "caller",
-				Text:          text,
+				Text:          redacted,
 				Sequence:      s.utteranceSeq,
 				StartedAt:     utteranceStartTime,
 				EndedAt:       &now,
@@ -1004,13 +1107,20 @@ func (s *callSession) processSTTResults() {
 		})
 		s.messagesMu.Unlock()
 
-		// Speak filler word immediately, then generate and speak response
-		go s.speakFillerAndGenerate(turnID, text)
+		// Speak filler word immediately, then generate and speak response.
+		// The turn span ends when the response finishes (or is cancelled).
+		turnCtx, _ := tracing.Start(s.ctx, "turn", trace.WithAttributes(
+			attribute.Int64("turn_id", int64(turnID)),
+			attribute.Int("text_length", len(text)),
+			attribute.Bool("interrupted", isSpeaking),
+		))
+		go s.speakFillerAndGenerate(turnCtx, turnID, text, turnEnd(speechFinalAt))
 
 		// Reset for next utterance
 		currentUtterance.Reset()
 		utteranceStartTime = nil
 		lastConfidence = 0
+		speechFinalAt = time.Time{}
 		bargeInSent = false
 	}
 
@@ -1022,7 +1132,8 @@ func (s *callSession) processSTTResults() {
 			// Save any pending utterance before exiting (call ended mid-speech)
 			text := strings.TrimSpace(currentUtterance.String())
 			if text != "" && s.callID != "" {
-				s.logger.Printf("media_ws: saving pending utterance on call end: %s", text)
+				text = s.redact(text)
+				s.logger.Info("media_ws: saving pending utterance on call end", "text", text)
 				s.eventLog.LogAsync(s.callID, eventlog.EventTurnFinalized, map[string]any{
 					"turn_id":     atomic.AddUint64(&s.turnSeq, 1),
 					"text":        text,
@@ -1046,8 +1157,12 @@ func (s *callSession) processSTTResults() {
 			return
 
 		case err := <-s.sttClient.Errors():
-			s.logger.Printf("media_ws: STT error: %v", err)
+			s.logger.Error("media_ws: STT error", "error", err)
 			sentry.CaptureException(err)
+			if s.sttBreaker != nil {
+				s.sttBreaker.RecordFailure(err)
+				s.providerFailureHandler("stt")(s.sttBreaker.Name(), err)
+			}
 			cancelFinalize()
 			cancelMaxTurn()
 			return
@@ -1071,10 +1186,10 @@ func (s *callSession) processSTTResults() {
 
 			// Optional instrumentation (keep it light; text is logged at finalize).
 			if result.SegmentFinal || result.SpeechFinal {
-				s.logger.Printf("media_ws: stt event (segment_final=%t speech_final=%t) text=%q",
-					result.SegmentFinal, result.SpeechFinal, strings.TrimSpace(result.Text))
+				sttText := s.redact(strings.TrimSpace(result.Text))
+				s.logger.Info("media_ws: stt event", "segment_final", result.SegmentFinal, "speech_final", result.SpeechFinal, "text", sttText)
 				s.eventLog.LogAsync(s.callID, eventlog.EventSTTResult, map[string]any{
-					"text":          strings.TrimSpace(result.Text),
+					"text":          sttText,
 					"confidence":    result.Confidence,
 					"segment_final": result.SegmentFinal,
 					"speech_final":  result.SpeechFinal,
@@ -1085,13 +1200,13 @@ func (s *callSession) processSTTResults() {
 			if strings.TrimSpace(result.Text) == "" && (result.SegmentFinal || result.SpeechFinal) {
 				emptyResultCount++
 				if emptyResultCount == emptyResultThreshold {
-					s.logger.Printf("media_ws: STT EMPTY STREAK - %d consecutive empty results", emptyResultCount)
+					s.logger.Info("media_ws: STT EMPTY STREAK", "empty_result_count", emptyResultCount)
 					s.eventLog.LogAsync(s.callID, eventlog.EventSTTEmptyStreak, map[string]any{
 						"count": emptyResultCount,
 					})
 				} else if emptyResultCount > emptyResultThreshold && emptyResultCount%10 == 0 {
 					// Log every 10 additional empty results
-					s.logger.Printf("media_ws: STT EMPTY STREAK continues - %d consecutive empty results", emptyResultCount)
+					s.logger.Info("media_ws: STT EMPTY STREAK continues", "empty_result_count", emptyResultCount)
 					s.eventLog.LogAsync(s.callID, eventlog.EventSTTEmptyStreak, map[string]any{
 						"count": emptyResultCount,
 					})
@@ -1099,7 +1214,7 @@ func (s *callSession) processSTTResults() {
 			} else if strings.TrimSpace(result.Text) != "" {
 				// Reset counter when we get valid text
 				if emptyResultCount >= emptyResultThreshold {
-					s.logger.Printf("media_ws: STT EMPTY STREAK ended after %d empty results", emptyResultCount)
+					s.logger.Info("media_ws: STT EMPTY STREAK ended", "empty_result_count", emptyResultCount)
 				}
 				emptyResultCount = 0
 			}
@@ -1110,17 +1225,15 @@ func (s *callSession) processSTTResults() {
 			if strings.TrimSpace(result.Text) != "" {
 				// Skip barge-in during greeting
 				if s.greetingInProgress.Load() {
-					s.logger.Printf("media_ws: skipping barge-in during greeting - caller: %s", strings.TrimSpace(result.Text))
+					s.logger.Info("media_ws: skipping barge-in during greeting", "text", s.redact(strings.TrimSpace(result.Text)))
 				} else {
 					isSpeaking := s.isAudioPlaying() || s.isResponseActive()
 					if isSpeaking && !bargeInSent {
-						s.logger.Printf("media_ws: early BARGE-IN (partial) - caller: %s", strings.TrimSpace(result.Text))
-						s.eventLog.LogAsync(s.callID, eventlog.EventBargeIn, map[string]any{
-							"partial_text":       strings.TrimSpace(result.Text),
-							"agent_was_speaking": true,
-						})
+						s.logger.Info("media_ws: early BARGE-IN (partial)", "text", s.redact(strings.TrimSpace(result.Text)))
+						metrics.BargeIns.Inc()
+						s.eventLog.LogAsync(s.callID, eventlog.EventBargeIn, s.partialBargeInEvent(strings.TrimSpace(result.Text)))
 						if err := s.clearAudio(); err != nil {
-							s.logger.Printf("media_ws: failed to clear audio: %v", err)
+							s.logger.Error("media_ws: failed to clear audio", "error", err)
 							sentry.CaptureException(err)
 						}
 						s.cancelResponse()
@@ -1148,6 +1261,8 @@ func (s *callSession) processSTTResults() {
 					}
 					currentUtterance.WriteString(strings.TrimSpace(result.Text))
 					lastConfidence = result.Confidence
+					// The caller is still speaking; the turn ends at the next speech_final.
+					speechFinalAt = time.Time{}
 					// Start/reset max turn timer - we have text, so ensure we finalize eventually.
 					scheduleMaxTurn()
 				}
@@ -1159,6 +1274,7 @@ func (s *callSession) processSTTResults() {
 
 			// Schedule finalization on end-of-speech (NOT on segment final).
 			if result.SpeechFinal {
+				speechFinalAt = time.Now()
 				cancelMaxTurn() // No longer need hard timeout; speech_final arrived.
 				scheduleFinalize()
 			}
@@ -1171,10 +1287,8 @@ func (s *callSession) processSTTResults() {
 
 		case <-maxTurnC:
 			// Hard timeout: speech_final didn't arrive in time (noisy environment).
-			s.logger.Printf("media_ws: MAX TURN TIMEOUT - forcing finalization after 4s")
-			s.eventLog.LogAsync(s.callID, eventlog.EventMaxTurnTimeout, map[string]any{
-				"pending_text": strings.TrimSpace(currentUtterance.String()),
-			})
+			s.logger.Info("media_ws: MAX TURN TIMEOUT - forcing finalization after 4s")
+			s.eventLog.LogAsync(s.callID, eventlog.EventMaxTurnTimeout, s.maxTurnTimeoutEvent(strings.TrimSpace(currentUtterance.String())))
 			cancelMaxTurn()
 			cancelFinalize()
 			finalizeUtterance()
@@ -1191,11 +1305,18 @@ func (s *callSession) isCurrentResponse(respID uint64) bool {
 
 // speakFillerAndGenerate starts a new response (cancelling any previous one),
 // optionally speaks a short filler after a brief delay, then streams the LLM
-// response via sentence-based TTS.
-func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string) {
+// response via sentence-based TTS. turnEnd is when the caller's turn ended,
+// the start of the turn latency.
+func (s *callSession) speakFillerAndGenerate(turnCtx context.Context, turnID uint64, lastUserText string, turnEnd time.Time) {
+	turnSpan := trace.SpanFromContext(turnCtx)
+	defer turnSpan.End()
+
 	ctx, respID := s.beginNewResponse()
 	defer s.endResponse(respID)
-	s.logger.Printf("media_ws: starting response (turn=%d resp=%d)", turnID, respID)
+	ctx = withTurnTiming(trace.ContextWithSpan(ctx, turnSpan), turnEnd)
+	turnSpan.SetAttributes(attribute.Int64("response_id", int64(respID)))
+	ctx = logging.WithAttrs(ctx, "turn_id", turnID, "response_id", respID)
+	s.logger.InfoContext(ctx, "media_ws: starting response")
 
 	// Snapshot messages for this response (avoid races with concurrent appends).
 	s.messagesMu.Lock()
@@ -1203,17 +1324,31 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 	lastFiller := s.lastFillerTime
 	s.messagesMu.Unlock()
 
+	// Collect call fields from the caller's turn in the background
+	s.extractCallFieldsAsync(turnID, msgs)
+
+	// Inject relevant knowledge base snippets and the call fields still
+	// missing (not kept in the conversation history)
+	msgs = s.retrieveKnowledge(ctx, turnID, msgs, lastUserText)
+	msgs = withCallFieldGuidance(msgs, s.callFields)
+
 	s.eventLog.LogAsync(s.callID, eventlog.EventLLMStarted, map[string]any{
 		"turn_id":       turnID,
 		"message_count": len(msgs),
 	})
 
 	llmStartTime := time.Now()
-	responseCh, err := s.llmClient.GenerateResponse(ctx, msgs)
+	llmCtx, llmSpan := tracing.Start(ctx, "llm.stream", trace.WithAttributes(
+		attribute.Int("message_count", len(msgs)),
+	))
+	responseCh, err := s.llmClient.GenerateResponse(llmCtx, msgs)
 	if err != nil {
+		llmSpan.RecordError(err)
+		llmSpan.SetStatus(codes.Error, "llm request failed")
+		llmSpan.End()
 		// Context canceled is expected during barge-in, not a real error
 		if !errors.Is(err, context.Canceled) {
-			s.logger.Printf("media_ws: LLM error: %v", err)
+			s.logger.ErrorContext(ctx, "media_ws: LLM error", "error", err)
 			sentry.CaptureException(err)
 			s.eventLog.LogAsync(s.callID, eventlog.EventLLMError, map[string]any{
 				"turn_id": turnID,
@@ -1227,7 +1362,13 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 	llmBuf := make(chan string, 200)
 	go func() {
 		defer close(llmBuf)
+		defer llmSpan.End()
+		first := true
 		for chunk := range responseCh {
+			if first {
+				first = false
+				llmSpan.AddEvent("first_token")
+			}
 			select {
 			case <-ctx.Done():
 				return
@@ -1260,6 +1401,7 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 	case chunk, ok := <-llmBuf:
 		if ok {
 			gotAnyChunk = true
+			metrics.ObserveDuration(metrics.LLMFirstToken, time.Since(llmStartTime))
 			s.eventLog.LogAsync(s.callID, eventlog.EventLLMFirstToken, map[string]any{
 				"turn_id":    turnID,
 				"latency_ms": time.Since(llmStartTime).Milliseconds(),
@@ -1277,9 +1419,11 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 	case <-timer.C:
 		// No LLM output yet: consider filler (also skip for very short utterances).
 		shortUtterance := len(strings.TrimSpace(lastUserText)) < 8
-		if !shortUtterance && shouldSpeakFiller(lastFiller) {
+		// Fillers cost TTS characters; calls over the cost budget skip them.
+		budgetDegraded := s.budget.Degraded()
+		if !shortUtterance && !budgetDegraded && shouldSpeakFiller(lastFiller) {
 			filler := getRandomFiller()
-			s.logger.Printf("media_ws: speaking filler: %s", filler)
+			s.logger.InfoContext(ctx, "media_ws: speaking filler", "filler", filler)
 			s.eventLog.LogAsync(s.callID, eventlog.EventFillerDecision, map[string]any{
 				"turn_id":  turnID,
 				"decision": "spoken",
@@ -1291,10 +1435,14 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 				"turn_id": turnID,
 				"filler":  filler,
 			})
-			if _, err := s.speakText(ctx, filler); err != nil && !errors.Is(err, context.Canceled) {
-				s.logger.Printf("media_ws: filler TTS error: %v", err)
+			fillerCtx, fillerSpan := tracing.Start(ctx, "filler", trace.WithAttributes(
+				attribute.String("filler", filler),
+			))
+			if _, err := s.speakText(fillerCtx, filler); err != nil && !errors.Is(err, context.Canceled) {
+				s.logger.ErrorContext(ctx, "media_ws: filler TTS error", "error", err)
 				sentry.CaptureException(err)
 			}
+			fillerSpan.End()
 			s.messagesMu.Lock()
 			s.lastFillerTime = time.Now()
 			s.messagesMu.Unlock()
@@ -1302,8 +1450,10 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 			reason := "variety_or_cooldown"
 			if shortUtterance {
 				reason = "short_utterance"
+			} else if budgetDegraded {
+				reason = "cost_budget"
 			}
-			s.logger.Printf("media_ws: skipping filler (variety/cooldown/short-utterance)")
+			s.logger.InfoContext(ctx, "media_ws: skipping filler", "reason", reason)
 			s.eventLog.LogAsync(s.callID, eventlog.EventFillerDecision, map[string]any{
 				"turn_id":  turnID,
 				"decision": "skipped",
@@ -1341,6 +1491,7 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 
 		// Track first token if it came after filler timer
 		if !gotAnyChunk {
+			metrics.ObserveDuration(metrics.LLMFirstToken, time.Since(llmStartTime))
 			s.eventLog.LogAsync(s.callID, eventlog.EventLLMFirstToken, map[string]any{
 				"turn_id":    turnID,
 				"latency_ms": time.Since(llmStartTime).Milliseconds(),
@@ -1353,6 +1504,14 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 		completeSentences, remaining := extractCompleteSentences(buffer.String())
 		if completeSentences != "" {
 			sentenceCount++
+			// Span covers the time spent buffering tokens for this sentence
+			_, sentenceSpan := tracing.Start(ctx, "sentence.extract",
+				trace.WithTimestamp(bufferStartTime),
+				trace.WithAttributes(
+					attribute.Int("sentence_num", sentenceCount),
+					attribute.Int("text_length", len(completeSentences)),
+				))
+			sentenceSpan.End()
 			// Log sentence extraction timing
 			s.eventLog.LogAsync(s.callID, eventlog.EventSentenceExtracted, map[string]any{
 				"turn_id":        turnID,
@@ -1363,11 +1522,11 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 			ttsText := stripForwardMarker(completeSentences)
 			if ttsText != "" {
 				if sentenceCount == 1 {
-					s.logger.Printf("media_ws: streaming first sentence: %s", ttsText)
+					s.logger.InfoContext(ctx, "media_ws: streaming first sentence", "tts_text", ttsText)
 				}
 				markID, err := s.speakText(ctx, ttsText)
 				if err != nil && !errors.Is(err, context.Canceled) {
-					s.logger.Printf("media_ws: TTS error: %v", err)
+					s.logger.ErrorContext(ctx, "media_ws: TTS error", "error", err)
 					sentry.CaptureException(err)
 				} else if markID != 0 {
 					lastResponseMarkID = markID
@@ -1386,7 +1545,7 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 		if ttsText != "" {
 			markID, err := s.speakText(ctx, ttsText)
 			if err != nil && !errors.Is(err, context.Canceled) {
-				s.logger.Printf("media_ws: TTS error: %v", err)
+				s.logger.ErrorContext(ctx, "media_ws: TTS error", "error", err)
 				sentry.CaptureException(err)
 			} else if markID != 0 {
 				lastResponseMarkID = markID
@@ -1403,20 +1562,7 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 		return
 	}
 
-	// Track LLM tokens for cost calculation (estimate: ~4 chars per token).
-	// Note: This approximation is reasonable for English but may underestimate tokens
-	// for Czech text (accented characters tokenize less efficiently). For exact costs,
-	// track actual token counts from OpenAI's usage field in the streaming response.
-	inputChars := 0
-	for _, m := range msgs {
-		inputChars += len(m.Content)
-	}
-	s.costMetricsMu.Lock()
-	s.llmInputTokens += (inputChars + 3) / 4 // Round up
-	s.llmOutputTokens += (len(responseText) + 3) / 4
-	s.costMetricsMu.Unlock()
-
-	s.logger.Printf("media_ws: agent response (full): %s", responseText)
+	s.logger.InfoContext(ctx, "media_ws: agent response (full)", "response_text", s.redact(responseText))
 	s.eventLog.LogAsync(s.callID, eventlog.EventLLMCompleted, map[string]any{
 		"turn_id":         turnID,
 		"response_length": len(responseText),
@@ -1437,7 +1583,7 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 	if s.callID != "" {
 		_ = s.store.InsertUtterance(s.ctx, s.callID, store.Utterance{
 			Speaker:     "agent",
-			Text:        responseText,
+			Text:        s.redact(responseText),
 			Sequence:    s.utteranceSeq,
 			StartedAt:   &startTime,
 			Interrupted: false,
@@ -1452,9 +1598,9 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 
 	// If we need to forward/hang up, wait for the final mark of the response.
 	if isForward(responseText) {
-		s.logger.Printf("media_ws: detected forward request, will forward after audio finishes")
+		s.logger.InfoContext(ctx, "media_ws: detected forward request, will forward after audio finishes")
 		s.eventLog.LogAsync(s.callID, eventlog.EventForwardDetected, map[string]any{
-			"response_text": responseText,
+			"response_text": s.redact(responseText),
 		})
 		if lastResponseMarkID != 0 {
 			s.audioMu.Lock()
@@ -1466,9 +1612,9 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 		return
 	}
 	if isGoodbye(responseText) {
-		s.logger.Printf("media_ws: detected goodbye, will hang up after audio finishes")
+		s.logger.InfoContext(ctx, "media_ws: detected goodbye, will hang up after audio finishes")
 		s.eventLog.LogAsync(s.callID, eventlog.EventGoodbyeDetected, map[string]any{
-			"response_text": responseText,
+			"response_text": s.redact(responseText),
 		})
 		if lastResponseMarkID != 0 {
 			s.audioMu.Lock()
@@ -1481,19 +1627,21 @@ func (s *callSession) speakFillerAndGenerate(turnID uint64, lastUserText string)
 }
 
 func (s *callSession) speakText(ctx context.Context, text string) (uint64, error) {
-	// Track TTS characters for cost calculation
-	s.costMetricsMu.Lock()
-	s.ttsCharacters += len(text)
-	s.costMetricsMu.Unlock()
-
 	ttsStartTime := time.Now()
 	s.eventLog.LogAsync(s.callID, eventlog.EventTTSStarted, map[string]any{
 		"text_length": len(text),
 	})
 
+	ctx, span := tracing.Start(ctx, "tts.speak", trace.WithAttributes(
+		attribute.Int("text_length", len(text)),
+	))
+	defer span.End()
+
 	// Get audio from TTS
 	audioCh, err := s.ttsClient.SynthesizeStream(ctx, text)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tts request failed")
 		s.eventLog.LogAsync(s.callID, eventlog.EventTTSError, map[string]any{
 			"error":       err.Error(),
 			"text_length": len(text),
@@ -1507,6 +1655,8 @@ func (s *callSession) speakText(ctx context.Context, text string) (uint64, error
 		// Log first chunk latency
 		if !firstChunkReceived {
 			firstChunkReceived = true
+			span.AddEvent("first_chunk")
+			metrics.ObserveDuration(metrics.TTSFirstChunk, time.Since(ttsStartTime))
 			s.eventLog.LogAsync(s.callID, eventlog.EventTTSFirstChunk, map[string]any{
 				"text_length": len(text),
 				"latency_ms":  time.Since(ttsStartTime).Milliseconds(),
@@ -1523,7 +1673,7 @@ func (s *callSession) speakText(ctx context.Context, text string) (uint64, error
 			return 0, ctx.Err()
 		case <-s.bargeInCh:
 			// Barge-in detected - stop sending audio
-			s.logger.Printf("media_ws: stopping audio send due to barge-in")
+			s.logger.Info("media_ws: stopping audio send due to barge-in")
 			// Drain remaining audio
 			for range audioCh {
 			}
@@ -1533,6 +1683,7 @@ func (s *callSession) speakText(ctx context.Context, text string) (uint64, error
 				"interrupted": true,
 				"reason":      "barge_in",
 			})
+			span.SetAttributes(attribute.Bool("barge_in", true))
 			return 0, nil
 		default:
 		}
@@ -1542,6 +1693,9 @@ func (s *callSession) speakText(ctx context.Context, text string) (uint64, error
 			Event:     "media",
 			StreamSid: s.streamSid,
 		}
+		if s.pcm16 {
+			chunk = muLaw8kToPCM16(chunk)
+		}
 		outMsg.Media.Payload = base64.StdEncoding.EncodeToString(chunk)
 
 		s.connMu.Lock()
@@ -1556,6 +1710,7 @@ func (s *callSession) speakText(ctx context.Context, text string) (uint64, error
 			})
 			return 0, fmt.Errorf("failed to send audio: %w", err)
 		}
+		observeTurnLatency(ctx)
 	}
 
 	// Send mark to track completion.
@@ -1586,6 +1741,41 @@ func (s *callSession) speakText(ctx context.Context, text string) (uint64, error
 	return markID, err
 }
 
+// turnTiming tracks when the caller's turn ended so the first audio chunk of
+// the response (filler or answer) can be recorded as turn latency once.
+type turnTiming struct {
+	start time.Time
+	once  sync.Once
+}
+
+type turnTimingKey struct{}
+
+// withTurnTiming attaches the turn start time to a response context.
+func withTurnTiming(ctx context.Context, start time.Time) context.Context {
+	return context.WithValue(ctx, turnTimingKey{}, &turnTiming{start: start})
+}
+
+// turnEnd returns when the caller's turn ended: at speech_final, or now if
+// the turn was finalized without one (max turn timeout).
+func turnEnd(speechFinalAt time.Time) time.Time {
+	if speechFinalAt.IsZero() {
+		return time.Now()
+	}
+	return speechFinalAt
+}
+
+// observeTurnLatency records turn latency on the first audio chunk sent for
+// the response. It is a no-op for audio outside a turn (e.g. the greeting).
+func observeTurnLatency(ctx context.Context) {
+	tt, ok := ctx.Value(turnTimingKey{}).(*turnTiming)
+	if !ok {
+		return
+	}
+	tt.once.Do(func() {
+		metrics.ObserveDuration(metrics.TurnLatency, time.Since(tt.start))
+	})
+}
+
 // clearAudio sends a clear event to Twilio to stop audio playback (for barge-in)
 func (s *callSession) clearAudio() error {
 	clearMsg := twilioClear{
@@ -1611,7 +1801,7 @@ func (s *callSession) clearAudio() error {
 	// Also cancel any pending post-audio action (hang-up/forward).
 	s.cancelPendingAction()
 
-	s.logger.Printf("media_ws: sent clear command (barge-in)")
+	s.logger.Info("media_ws: sent clear command (barge-in)")
 	s.eventLog.LogAsync(s.callID, eventlog.EventClearAudioSent, map[string]any{
 		"stream_sid": s.streamSid,
 	})
@@ -1628,17 +1818,9 @@ func (s *callSession) speakGreeting() {
 	// greeting mark is received.
 	s.greetingInProgress.Store(true)
 
-	// Use tenant's greeting if available, otherwise fall back to config default
-	var greeting string
-	if s.tenantCfg.GreetingText != nil && *s.tenantCfg.GreetingText != "" {
-		greeting = *s.tenantCfg.GreetingText
-	} else if s.cfg.GreetingText != "" {
-		greeting = s.cfg.GreetingText
-	} else {
-		greeting = "Dobrý den, tady asistentka Karen. Majitel telefonu teď nemůže přijmout hovor, ale můžu vám pro něj zanechat vzkaz - co od něj potřebujete?"
-	}
+	greeting := callGreeting(s.tenantCfg.GreetingText, s.cfg.GreetingText)
 
-	s.logger.Printf("media_ws: speaking greeting: %s", greeting)
+	s.logger.Info("media_ws: speaking greeting", "greeting", greeting)
 
 	// Add greeting to conversation history so LLM knows it was already said
 	s.messagesMu.Lock()
@@ -1661,7 +1843,7 @@ func (s *callSession) speakGreeting() {
 			StartedAt:   &startTime,
 			Interrupted: false,
 		}); err != nil {
-			s.logger.Printf("media_ws: failed to store greeting utterance: %v", err)
+			s.logger.Error("media_ws: failed to store greeting utterance", "error", err)
 			if !errors.Is(err, context.Canceled) {
 				sentry.CaptureException(err)
 			}
@@ -1671,7 +1853,7 @@ func (s *callSession) speakGreeting() {
 	markID, err := s.speakText(s.ctx, greeting)
 	if err != nil {
 		if !errors.Is(err, context.Canceled) {
-			s.logger.Printf("media_ws: greeting TTS error: %v", err)
+			s.logger.Error("media_ws: greeting TTS error", "error", err)
 			sentry.CaptureException(err)
 		}
 		// On error, clear the flag immediately since no mark will be received
@@ -1685,6 +1867,18 @@ func (s *callSession) speakGreeting() {
 	s.audioMu.Unlock()
 }
 
+// callGreeting returns the tenant's greeting if set, otherwise the configured
+// default, otherwise the built-in greeting.
+func callGreeting(tenantGreeting *string, defaultGreeting string) string {
+	if tenantGreeting != nil && *tenantGreeting != "" {
+		return *tenantGreeting
+	}
+	if defaultGreeting != "" {
+		return defaultGreeting
+	}
+	return "Dobrý den, tady asistentka Karen. Majitel telefonu teď nemůže přijmout hovor, ale můžu vám pro něj zanechat vzkaz - co od něj potřebujete?"
+}
+
 // isGoodbye checks if the response contains goodbye phrases
 func isGoodbye(text string) bool {
 	lower := strings.ToLower(text)
@@ -1714,15 +1908,21 @@ func stripForwardMarker(text string) string {
 
 // forwardCall forwards the call to the tenant owner's verified phone number
 func (s *callSession) forwardCall(ctx context.Context) {
+	if s.softphone != nil {
+		// Browser test calls can't be dialed through; end the call instead
+		s.logger.Info("media_ws: forwarding not available on softphone test call, hanging up")
+		s.hangUpCall(ctx)
+		return
+	}
 	if s.callSid == "" || s.accountSid == "" || s.cfg.TwilioAuthToken == "" {
-		s.logger.Printf("media_ws: cannot forward - missing callSid, accountSid, or auth token")
+		s.logger.Warn("media_ws: cannot forward - missing callSid, accountSid, or auth token")
 		return
 	}
 
 	// Use owner's verified phone number - no hardcoded fallback for security
 	forwardNumber := s.tenantCfg.OwnerPhone
 	if forwardNumber == "" {
-		s.logger.Printf("media_ws: cannot forward call %s - no owner phone configured for tenant", s.callSid)
+		s.logger.Info("media_ws: cannot forward call - no owner phone configured for tenant")
 		// Instead of forwarding to a random number, just hang up gracefully
 		s.hangUpCall(ctx)
 		return
@@ -1731,11 +1931,11 @@ func (s *callSession) forwardCall(ctx context.Context) {
 	// Wait for the forwarding message to finish playing
 	select {
 	case <-s.goodbyeDone:
-		s.logger.Printf("media_ws: forward audio finished, forwarding call to %s", forwardNumber)
+		s.logger.Info("media_ws: forward audio finished, forwarding call", "forward_number", forwardNumber)
 	case <-time.After(10 * time.Second):
-		s.logger.Printf("media_ws: timeout waiting for forward audio, forwarding anyway to %s", forwardNumber)
+		s.logger.Info("media_ws: timeout waiting for forward audio, forwarding anyway", "forward_number", forwardNumber)
 	case <-ctx.Done():
-		s.logger.Printf("media_ws: forwarding cancelled")
+		s.logger.Info("media_ws: forwarding cancelled")
 		return
 	case <-s.ctx.Done():
 		return
@@ -1756,7 +1956,7 @@ func (s *callSession) forwardCall(ctx context.Context) {
 
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, apiURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		s.logger.Printf("media_ws: failed to create forward request: %v", err)
+		s.logger.Error("media_ws: failed to create forward request", "error", err)
 		sentry.CaptureException(err)
 		return
 	}
@@ -1766,20 +1966,23 @@ func (s *callSession) forwardCall(ctx context.Context) {
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to forward call: %v", err)
+		s.logger.Error("media_ws: failed to forward call", "error", err)
 		sentry.CaptureException(err)
+		metrics.Forwards.WithLabelValues("failed").Inc()
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		s.logger.Printf("media_ws: call %s forwarded successfully to %s", s.callSid, forwardNumber)
+		s.logger.Info("media_ws: call forwarded successfully", "forward_number", forwardNumber)
+		metrics.Forwards.WithLabelValues("success").Inc()
 		s.eventLog.LogAsync(s.callID, eventlog.EventCallForwarded, map[string]any{
 			"forward_number": forwardNumber,
 			"success":        true,
 		})
 	} else {
-		s.logger.Printf("media_ws: forward returned status %d", resp.StatusCode)
+		s.logger.Warn("media_ws: forward returned status", "status_code", resp.StatusCode)
+		metrics.Forwards.WithLabelValues("failed").Inc()
 		s.eventLog.LogAsync(s.callID, eventlog.EventCallForwarded, map[string]any{
 			"forward_number": forwardNumber,
 			"success":        false,
@@ -1793,8 +1996,8 @@ func (s *callSession) hangUpCall(ctx context.Context) {
 	// Mark that agent is initiating hangup (prevents "caller" overwrite in stop handler)
 	s.agentHungUp = true
 
-	if s.callSid == "" || s.accountSid == "" || s.cfg.TwilioAuthToken == "" {
-		s.logger.Printf("media_ws: cannot hang up - missing callSid, accountSid, or auth token")
+	if s.softphone == nil && (s.callSid == "" || s.accountSid == "" || s.cfg.TwilioAuthToken == "") {
+		s.logger.Warn("media_ws: cannot hang up - missing callSid, accountSid, or auth token")
 		return
 	}
 
@@ -1803,17 +2006,17 @@ func (s *callSession) hangUpCall(ctx context.Context) {
 	s.eventLog.LogAsync(s.callID, eventlog.EventHangupWaitStart, nil)
 	select {
 	case <-s.goodbyeDone:
-		s.logger.Printf("media_ws: goodbye audio finished, hanging up")
+		s.logger.Info("media_ws: goodbye audio finished, hanging up")
 		s.eventLog.LogAsync(s.callID, eventlog.EventHangupWaitEnd, map[string]any{
 			"reason": "mark_received",
 		})
 	case <-time.After(3 * time.Second):
-		s.logger.Printf("media_ws: timeout waiting for goodbye audio, hanging up anyway")
+		s.logger.Info("media_ws: timeout waiting for goodbye audio, hanging up anyway")
 		s.eventLog.LogAsync(s.callID, eventlog.EventHangupWaitEnd, map[string]any{
 			"reason": "timeout",
 		})
 	case <-ctx.Done():
-		s.logger.Printf("media_ws: hangup cancelled")
+		s.logger.Info("media_ws: hangup cancelled")
 		s.eventLog.LogAsync(s.callID, eventlog.EventHangupWaitEnd, map[string]any{
 			"reason": "cancelled",
 		})
@@ -1825,6 +2028,11 @@ func (s *callSession) hangUpCall(ctx context.Context) {
 		return
 	}
 
+	if s.softphone != nil {
+		s.endSoftphoneCall()
+		return
+	}
+
 	// Small additional delay to ensure Twilio has flushed all audio
 	time.Sleep(500 * time.Millisecond)
 
@@ -1836,7 +2044,7 @@ func (s *callSession) hangUpCall(ctx context.Context) {
 
 	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, apiURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		s.logger.Printf("media_ws: failed to create hang up request: %v", err)
+		s.logger.Error("media_ws: failed to create hang up request", "error", err)
 		sentry.CaptureException(err)
 		return
 	}
@@ -1846,14 +2054,15 @@ func (s *callSession) hangUpCall(ctx context.Context) {
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to hang up call: %v", err)
+		s.logger.Error("media_ws: failed to hang up call", "error", err)
 		sentry.CaptureException(err)
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		s.logger.Printf("media_ws: call %s hung up successfully (agent initiated)", s.callSid)
+		s.logger.Info("media_ws: call hung up successfully (agent initiated)")
+		metrics.Hangups.WithLabelValues("agent").Inc()
 		s.eventLog.LogAsync(s.callID, eventlog.EventCallHangup, map[string]any{
 			"initiated_by": "agent",
 			"success":      true,
@@ -1863,18 +2072,18 @@ func (s *callSession) hangUpCall(ctx context.Context) {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 			if err := s.store.UpdateCallStatus(ctx, s.callSid, "completed", time.Now().UTC()); err != nil {
-				s.logger.Printf("media_ws: failed to update call status: %v", err)
+				s.logger.Error("media_ws: failed to update call status", "error", err)
 				sentry.CaptureException(err)
 			}
 
 			// Mark call as ended by agent
 			if err := s.store.UpdateCallEndedBy(ctx, s.callSid, "agent"); err != nil {
-				s.logger.Printf("media_ws: failed to update ended_by: %v", err)
+				s.logger.Error("media_ws: failed to update ended_by", "error", err)
 				sentry.CaptureException(err)
 			}
 		}
 	} else {
-		s.logger.Printf("media_ws: hang up returned status %d", resp.StatusCode)
+		s.logger.Warn("media_ws: hang up returned status", "status_code", resp.StatusCode)
 		s.eventLog.LogAsync(s.callID, eventlog.EventCallHangup, map[string]any{
 			"initiated_by": "agent",
 			"success":      false,
@@ -1889,30 +2098,24 @@ func (s *callSession) analyzeCall() {
 	}
 
 	// Use background context since call context may be cancelled
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(costs.WithUsage(context.Background(), s.usage), 30*time.Second)
 	defer cancel()
 
 	s.messagesMu.Lock()
 	msgs := append([]llm.Message(nil), s.messages...)
 	s.messagesMu.Unlock()
 
-	result, err := s.llmClient.AnalyzeCall(ctx, msgs)
+	taxonomy, custom := s.screeningTaxonomy(ctx)
+	result, err := s.llmClient.AnalyzeCall(analysisContext(ctx, taxonomy, custom), msgs)
 	if err != nil {
-		s.logger.Printf("media_ws: analysis error: %v", err)
+		s.logger.Error("media_ws: analysis error", "error", err)
 		sentry.CaptureException(err)
 		return
 	}
-
-	// Track LLM tokens for cost calculation (estimate: ~4 chars per token)
-	// AnalyzeCall uses msgs + analysis prompt (~500 chars) and returns JSON (~200 chars)
-	inputChars := 500 // Analysis prompt overhead
-	for _, m := range msgs {
-		inputChars += len(m.Content)
+	if rejected := taxonomy.Apply(result); len(rejected) > 0 {
+		s.logger.Warn("media_ws: analysis returned undefined labels", "labels", rejected, "custom_taxonomy", custom)
 	}
-	s.costMetricsMu.Lock()
-	s.llmInputTokens += (inputChars + 3) / 4
-	s.llmOutputTokens += 50 // Approximate JSON response tokens
-	s.costMetricsMu.Unlock()
+	redactScreeningResult(s.redactor, result)
 
 	// Convert entities to JSON
 	entitiesJSON, _ := json.Marshal(result.Entities)
@@ -1928,20 +2131,46 @@ func (s *callSession) analyzeCall() {
 		CreatedAt:            time.Now().UTC(),
 	}
 
+	if cs, ok := callSummaryFromResult(result); ok {
+		if err := s.store.UpsertCallSummary(ctx, s.callID, cs); err != nil {
+			s.logger.Error("media_ws: failed to store call summary", "error", err)
+			sentry.CaptureException(err)
+		}
+	}
+
 	if err := s.store.InsertScreeningResult(ctx, s.callID, sr); err != nil {
-		s.logger.Printf("media_ws: failed to store screening result: %v", err)
+		s.logger.Error("media_ws: failed to store screening result", "error", err)
 		sentry.CaptureException(err)
 	} else {
-		s.logger.Printf("media_ws: call classified as %s (%.0f%% confidence)",
-			result.LegitimacyLabel, result.LegitimacyConfidence*100)
+		s.logger.Info("media_ws: call classified", "label", result.LegitimacyLabel, "confidence", result.LegitimacyConfidence)
 
-		// Send push notifications to tenant devices
-		go s.sendPushNotifications(result.LegitimacyLabel, result.IntentText)
+		// Send push notifications to tenant devices (the summary is the body)
+		go s.sendPushNotifications(result.LegitimacyLabel, taxonomy.IsSpam(result.LegitimacyLabel), pushBody(result))
 	}
 }
 
-// sendPushNotifications sends push notifications to all devices registered for the tenant
-func (s *callSession) sendPushNotifications(legitimacyLabel, intentText string) {
+// screeningTaxonomy returns the tenant's screening labels (the built-in ones
+// if the tenant has none or loading fails) and whether they are custom.
+func (s *callSession) screeningTaxonomy(ctx context.Context) (llm.Taxonomy, bool) {
+	if s.taxonomy != nil {
+		return *s.taxonomy, s.taxonomyCustom
+	}
+	taxonomy, custom := llm.DefaultTaxonomy(), false
+	if s.tenantCfg.TenantID != "" {
+		var err error
+		taxonomy, custom, err = loadScreeningTaxonomy(ctx, s.store, s.tenantCfg.TenantID)
+		if err != nil {
+			s.logger.Error("media_ws: failed to load screening taxonomy", "error", err)
+			sentry.CaptureException(err)
+		}
+	}
+	s.taxonomy, s.taxonomyCustom = &taxonomy, custom
+	return taxonomy, custom
+}
+
+// sendPushNotifications sends push notifications to the devices of the tenant's members, following
+// their call notification settings
+func (s *callSession) sendPushNotifications(legitimacyLabel string, spam bool, body string) {
 	if s.apns == nil || s.tenantCfg.TenantID == "" {
 		return
 	}
@@ -1952,14 +2181,14 @@ func (s *callSession) sendPushNotifications(legitimacyLabel, intentText string)
 	// Get the call details to get the from_number
 	call, err := s.store.GetCallDetail(ctx, s.callSid)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to get call detail for push: %v", err)
+		s.logger.Error("media_ws: failed to get call detail for push", "error", err)
 		return
 	}
 
-	// Get all device tokens for the tenant
-	tokens, err := s.store.GetTenantPushTokens(ctx, s.tenantCfg.TenantID)
+	// Get the device tokens of members notified about this call
+	tokens, err := s.store.GetCallPushTokens(ctx, s.tenantCfg.TenantID, spam)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to get push tokens: %v", err)
+		s.logger.Error("media_ws: failed to get push tokens", "error", err)
 		return
 	}
 
@@ -1970,7 +2199,7 @@ func (s *callSession) sendPushNotifications(legitimacyLabel, intentText string)
 	notif := notifications.CallNotification{
 		CallID:          s.callSid,
 		FromNumber:      call.FromNumber,
-		IntentSummary:   intentText,
+		IntentSummary:   body,
 		LegitimacyLabel: legitimacyLabel,
 	}
 
@@ -1978,7 +2207,7 @@ func (s *callSession) sendPushNotifications(legitimacyLabel, intentText string)
 	for _, token := range tokens {
 		if token.Platform == "ios" {
 			if err := s.apns.SendCallNotification(token.Token, notif); err != nil {
-				s.logger.Printf("media_ws: failed to send push to %s...: %v", token.Token[:16], err)
+				s.logger.Error("media_ws: failed to send push", "token_prefix", token.Token[:16], "error", err)
 			}
 		}
 	}
@@ -1986,6 +2215,9 @@ func (s *callSession) sendPushNotifications(legitimacyLabel, intentText string)
 
 func (s *callSession) cleanup() {
 	defer s.callRegistry.Done()
+	if s.callSpan != nil {
+		defer s.callSpan.End()
+	}
 	s.cancel()
 
 	// Stop max duration timer if running
@@ -2007,6 +2239,7 @@ func (s *callSession) cleanup() {
 	s.messagesMu.Unlock()
 	if msgCount >= 2 {
 		s.analyzeCall()
+		s.saveCallFields()
 	}
 
 	// Mark call as completed (fallback in case hangUpCall didn't run or failed)
@@ -2014,12 +2247,18 @@ func (s *callSession) cleanup() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		_ = s.store.UpdateCallStatus(ctx, s.callSid, "completed", time.Now().UTC())
+
+		// Index the transcript for search once, now that it is complete
+		if err := s.store.RefreshCallSearch(ctx, s.callID); err != nil {
+			s.logger.Error("media_ws: failed to refresh call search", "error", err)
+			sentry.CaptureException(err)
+		}
 	}
 
 	// Track usage after call completes
 	s.trackUsage()
 
-	s.logger.Printf("media_ws: session cleaned up for call %s", s.callSid)
+	s.logger.Info("media_ws: session cleaned up")
 	s.eventLog.LogAsync(s.callID, eventlog.EventCallEnded, map[string]any{
 		"call_sid": s.callSid,
 	})
@@ -2037,7 +2276,7 @@ func (s *callSession) trackUsage() {
 	// Get call details to calculate duration
 	call, err := s.store.GetCallDetail(ctx, s.callSid)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to get call for usage tracking: %v", err)
+		s.logger.Error("media_ws: failed to get call for usage tracking", "error", err)
 		return
 	}
 
@@ -2048,24 +2287,33 @@ func (s *callSession) trackUsage() {
 		durationSeconds = int(duration.Seconds())
 	}
 
+	// Test calls record provider costs but count nothing against the tenant
+	if s.softphone != nil {
+		s.recordCallCosts(ctx, call.ID, durationSeconds)
+		return
+	}
+
 	// Check if call was spam/marketing (from screening result)
 	isSpam := false
 	if call.Screening != nil {
-		label := strings.ToLower(call.Screening.LegitimacyLabel)
-		isSpam = label == "spam" || label == "marketing" || label == "podvod"
+		taxonomy, _ := s.screeningTaxonomy(ctx)
+		isSpam = taxonomy.IsSpam(call.Screening.LegitimacyLabel)
 	}
 
 	// Increment usage
 	if err := s.store.IncrementTenantUsage(ctx, s.tenantCfg.TenantID, durationSeconds, isSpam); err != nil {
-		s.logger.Printf("media_ws: failed to track usage: %v", err)
+		s.logger.Error("media_ws: failed to track usage", "error", err)
 		sentry.CaptureException(err)
 	} else {
-		s.logger.Printf("media_ws: tracked usage for tenant %s: %ds (spam=%t)", s.tenantCfg.TenantID, durationSeconds, isSpam)
+		s.logger.Info("media_ws: tracked usage", "duration_seconds", durationSeconds, "spam", isSpam)
 	}
 
 	// Record call costs
 	s.recordCallCosts(ctx, call.ID, durationSeconds)
 
+	// Alert the admin if this call pushed the tenant over its cost budget
+	s.checkCostBudgetExceeded(ctx)
+
 	// Check if we need to send usage warnings
 	s.checkUsageWarnings(ctx)
 }
@@ -2076,21 +2324,8 @@ func (s *callSession) recordCallCosts(ctx context.Context, callID string, durati
 		return
 	}
 
-	// Get accumulated metrics from the session
-	s.costMetricsMu.Lock()
-	ttsChars := s.ttsCharacters
-	llmInput := s.llmInputTokens
-	llmOutput := s.llmOutputTokens
-	s.costMetricsMu.Unlock()
-
-	// Build metrics struct
-	metrics := costs.CallMetrics{
-		CallDurationSeconds: durationSeconds,
-		STTDurationSeconds:  durationSeconds, // Estimate: STT duration ≈ call duration
-		LLMInputTokens:      llmInput,
-		LLMOutputTokens:     llmOutput,
-		TTSCharacters:       ttsChars,
-	}
+	// Billed usage reported by the providers during the call
+	metrics := s.usage.Metrics(durationSeconds)
 
 	// Calculate costs
 	calculated := costs.CalculateCallCosts(metrics)
@@ -2114,13 +2349,10 @@ func (s *callSession) recordCallCosts(ctx context.Context, callID string, durati
 
 	// Record in database
 	if err := s.store.RecordCallCosts(ctx, callID, storeMetrics, storeCosts); err != nil {
-		s.logger.Printf("media_ws: failed to record call costs: %v", err)
+		s.logger.Error("media_ws: failed to record call costs", "error", err)
 		sentry.CaptureException(err)
 	} else {
-		s.logger.Printf("media_ws: recorded call costs for %s: %d cents (twilio=%d, stt=%d, llm=%d, tts=%d)",
-			callID, storeCosts.TotalCostCents,
-			storeCosts.TwilioCostCents, storeCosts.STTCostCents,
-			storeCosts.LLMCostCents, storeCosts.TTSCostCents)
+		s.logger.Info("media_ws: recorded call costs", "call_id", callID, "total_cents", storeCosts.TotalCostCents, "twilio", storeCosts.TwilioCostCents, "stt", storeCosts.STTCostCents, "llm", storeCosts.LLMCostCents, "tts", storeCosts.TTSCostCents)
 	}
 }
 
@@ -2133,7 +2365,7 @@ func (s *callSession) checkUsageWarnings(ctx context.Context) {
 	// Get the tenant to check current usage
 	tenant, err := s.store.GetTenantByID(ctx, s.tenantCfg.TenantID)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to get tenant for usage check: %v", err)
+		s.logger.Error("media_ws: failed to get tenant for usage check", "error", err)
 		return
 	}
 
@@ -2158,10 +2390,10 @@ func (s *callSession) checkUsageWarnings(ctx context.Context) {
 		return // No warning needed
 	}
 
-	// Get all push tokens for users in this tenant
-	tokens, err := s.store.GetTenantPushTokens(ctx, tenant.ID)
+	// Get the push tokens of members who want usage warnings
+	tokens, err := s.store.GetUsagePushTokens(ctx, tenant.ID)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to get push tokens for tenant %s: %v", tenant.ID, err)
+		s.logger.Error("media_ws: failed to get push tokens", "tenant_id", tenant.ID, "error", err)
 		return
 	}
 
@@ -2182,13 +2414,13 @@ func (s *callSession) checkUsageWarnings(ctx context.Context) {
 		if token.Platform == "ios" {
 			go func(deviceToken string) {
 				if err := s.apns.SendUsageWarning(deviceToken, warningType, callsUsed, limit); err != nil {
-					s.logger.Printf("media_ws: failed to send usage warning: %v", err)
+					s.logger.Error("media_ws: failed to send usage warning", "error", err)
 				}
 			}(token.Token)
 		}
 	}
 
-	s.logger.Printf("media_ws: sent %s warning to %d devices for tenant %s", warningType, len(tokens), tenant.ID)
+	s.logger.Info("media_ws: sent usage warning", "warning_type", warningType, "devices", len(tokens), "tenant_id", tenant.ID)
 }
 
 // initRobocallDetector initializes the robocall detector with global config settings.
@@ -2196,23 +2428,24 @@ func (s *callSession) initRobocallDetector() {
 	ctx := context.Background()
 
 	// Check if robocall detection is enabled
-	enabled := s.store.GetGlobalConfigBool(ctx, "robocall_detection_enabled", true)
+	enabled := s.globalConfigBool(ctx, "robocall_detection_enabled", true)
 	if !enabled {
-		s.logger.Printf("media_ws: robocall detection disabled")
+		s.logger.Info("media_ws: robocall detection disabled")
+		s.startMaxDurationTimer(ctx, 0)
 		return
 	}
 
 	// Load config values from global config
 	cfg := RobocallConfig{
-		SilenceThreshold:    time.Duration(s.store.GetGlobalConfigInt(ctx, "robocall_silence_threshold_ms", 30000)) * time.Millisecond,
-		BargeInThreshold:    s.store.GetGlobalConfigInt(ctx, "robocall_barge_in_threshold", 3),
-		BargeInWindow:       time.Duration(s.store.GetGlobalConfigInt(ctx, "robocall_barge_in_window_ms", 15000)) * time.Millisecond,
-		RepetitionThreshold: s.store.GetGlobalConfigInt(ctx, "robocall_repetition_threshold", 3),
+		SilenceThreshold:    time.Duration(s.globalConfigInt(ctx, "robocall_silence_threshold_ms", 30000)) * time.Millisecond,
+		BargeInThreshold:    s.globalConfigInt(ctx, "robocall_barge_in_threshold", 3),
+		BargeInWindow:       time.Duration(s.globalConfigInt(ctx, "robocall_barge_in_window_ms", 15000)) * time.Millisecond,
+		RepetitionThreshold: s.globalConfigInt(ctx, "robocall_repetition_threshold", 3),
 		HoldKeywords:        DefaultRobocallConfig().HoldKeywords, // Use defaults, can be extended later
 	}
 
 	// Try to load keywords from config (JSON array)
-	if keywordsJSON, err := s.store.GetGlobalConfig(ctx, "robocall_hold_keywords"); err == nil && keywordsJSON != "" {
+	if keywordsJSON, err := s.globalConfig(ctx, "robocall_hold_keywords"); err == nil && keywordsJSON != "" {
 		var keywords []string
 		if err := json.Unmarshal([]byte(keywordsJSON), &keywords); err == nil && len(keywords) > 0 {
 			cfg.HoldKeywords = keywords
@@ -2222,19 +2455,29 @@ func (s *callSession) initRobocallDetector() {
 	s.robocallDetector = NewRobocallDetector(cfg)
 
 	// Start max duration timer
-	maxDurationMs := s.store.GetGlobalConfigInt(ctx, "robocall_max_call_duration_ms", 300000)
+	s.startMaxDurationTimer(ctx, s.globalConfigInt(ctx, "robocall_max_call_duration_ms", 300000))
+
+	s.logger.Info("media_ws: robocall detector initialized", "silence", cfg.SilenceThreshold, "barge_in_threshold", cfg.BargeInThreshold, "barge_in_window", cfg.BargeInWindow, "repetition", cfg.RepetitionThreshold)
+}
+
+// startMaxDurationTimer ends the call after maxDurationMs (0 = no limit).
+// Calls in cost-budget degraded mode get the shorter degraded limit.
+func (s *callSession) startMaxDurationTimer(ctx context.Context, maxDurationMs int) {
+	if s.budget.Degraded() {
+		degradedMs := s.globalConfigInt(ctx, "cost_budget_degraded_max_call_duration_ms", 120000)
+		if degradedMs > 0 && (maxDurationMs <= 0 || degradedMs < maxDurationMs) {
+			maxDurationMs = degradedMs
+		}
+	}
 	if maxDurationMs > 0 {
 		s.maxDurationTimer = time.AfterFunc(time.Duration(maxDurationMs)*time.Millisecond, s.handleMaxDuration)
-		s.logger.Printf("media_ws: max call duration set to %dms", maxDurationMs)
+		s.logger.Info("media_ws: max call duration set", "max_duration_ms", maxDurationMs)
 	}
-
-	s.logger.Printf("media_ws: robocall detector initialized (silence=%v, barge-in=%d/%v, repetition=%d)",
-		cfg.SilenceThreshold, cfg.BargeInThreshold, cfg.BargeInWindow, cfg.RepetitionThreshold)
 }
 
 // handleMaxDuration is called when the max call duration timer fires.
 func (s *callSession) handleMaxDuration() {
-	s.logger.Printf("media_ws: MAX DURATION reached - terminating call")
+	s.logger.Info("media_ws: MAX DURATION reached - terminating call")
 	s.eventLog.LogAsync(s.callID, eventlog.EventMaxDurationReached, nil)
 
 	s.robocallDetected = true
@@ -2259,7 +2502,8 @@ func (s *callSession) checkRobocall() {
 
 	result := s.robocallDetector.Check()
 	if result.IsRobocall {
-		s.logger.Printf("media_ws: ROBOCALL DETECTED: %s", result.Reason)
+		s.logger.Warn("media_ws: ROBOCALL DETECTED", "reason", result.Reason)
+		metrics.RobocallDetections.WithLabelValues(metrics.RobocallReason(result.Reason)).Inc()
 		s.eventLog.LogAsync(s.callID, eventlog.EventRobocallDetected, map[string]any{
 			"reason": result.Reason,
 		})
@@ -2287,10 +2531,11 @@ func (s *callSession) checkRobocallKeywords(text string) {
 
 	result := s.robocallDetector.CheckText(text)
 	if result.IsRobocall {
-		s.logger.Printf("media_ws: ROBOCALL KEYWORD DETECTED: %s", result.Reason)
+		s.logger.Warn("media_ws: ROBOCALL KEYWORD DETECTED", "reason", result.Reason)
+		metrics.RobocallDetections.WithLabelValues(metrics.RobocallReason(result.Reason)).Inc()
 		s.eventLog.LogAsync(s.callID, eventlog.EventRobocallDetected, map[string]any{
 			"reason": result.Reason,
-			"text":   text,
+			"text":   s.redact(text),
 		})
 
 		s.robocallDetected = true
@@ -2315,7 +2560,7 @@ func (s *callSession) speakAndHangUp(message string) {
 
 	// Speak the message
 	if _, err := s.speakText(ctx, message); err != nil && err != context.Canceled {
-		s.logger.Printf("media_ws: failed to speak hangup message: %v", err)
+		s.logger.Error("media_ws: failed to speak hangup message", "error", err)
 	}
 
 	// Wait a bit for audio to play
@@ -2328,6 +2573,10 @@ func (s *callSession) speakAndHangUp(message string) {
 
 // hangUpCallSync hangs up the call synchronously (for use in goroutines).
 func (s *callSession) hangUpCallSync() {
+	if s.softphone != nil {
+		s.endSoftphoneCall()
+		return
+	}
 	if s.callSid == "" || s.cfg.TwilioAccountSID == "" || s.cfg.TwilioAuthToken == "" {
 		return
 	}
@@ -2340,7 +2589,7 @@ func (s *callSession) hangUpCallSync() {
 
 	req, err := http.NewRequest("POST", hangupURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		s.logger.Printf("media_ws: failed to create hangup request: %v", err)
+		s.logger.Error("media_ws: failed to create hangup request", "error", err)
 		return
 	}
 	req.SetBasicAuth(s.cfg.TwilioAccountSID, s.cfg.TwilioAuthToken)
@@ -2348,15 +2597,16 @@ func (s *callSession) hangUpCallSync() {
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		s.logger.Printf("media_ws: failed to hang up call: %v", err)
+		s.logger.Error("media_ws: failed to hang up call", "error", err)
 		return
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		s.logger.Printf("media_ws: hangup returned status %d", resp.StatusCode)
+		s.logger.Warn("media_ws: hangup returned status", "status_code", resp.StatusCode)
 	} else {
-		s.logger.Printf("media_ws: call hung up successfully")
+		s.logger.Info("media_ws: call hung up successfully")
+		metrics.Hangups.WithLabelValues("agent").Inc()
 	}
 
 	// Update DB
@@ -1,11 +1,12 @@
 package httpapi
 
 import (
-	"io"
-	"log"
+	"context"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/lukasbauer/karen/internal/logging"
 )
 
 func TestGetRandomFiller(t *testing.T) {
@@ -494,7 +495,7 @@ func TestAudioPendingCounterNeverNegative(t *testing.T) {
 
 func TestHandleMarkSignalsPendingDoneMark(t *testing.T) {
 	s := &callSession{
-		logger:      log.New(io.Discard, "", 0),
+		logger:      logging.Discard(),
 		goodbyeDone: make(chan struct{}, 1),
 	}
 
@@ -585,7 +586,7 @@ func TestBargeInSkippedDuringGreeting(t *testing.T) {
 func TestBargeInEnabledAfterGreeting(t *testing.T) {
 	// Test that barge-in is enabled after greeting completes
 	s := &callSession{
-		logger:      log.New(io.Discard, "", 0),
+		logger:      logging.Discard(),
 		bargeInCh:   make(chan string, 1),
 		goodbyeDone: make(chan struct{}),
 	}
@@ -816,3 +817,34 @@ func TestEmptyBufferNotSavedOnContextCancel(t *testing.T) {
 		t.Error("whitespace-only buffer should not trigger save")
 	}
 }
+
+func TestObserveTurnLatencyOnce(t *testing.T) {
+	// Without turn timing (e.g. greeting) observing is a no-op
+	observeTurnLatency(context.Background())
+
+	ctx := withTurnTiming(context.Background(), time.Now())
+	tt := ctx.Value(turnTimingKey{}).(*turnTiming)
+
+	calls := 0
+	for i := 0; i < 3; i++ {
+		observeTurnLatency(ctx)
+		tt.once.Do(func() { calls++ })
+	}
+	if calls != 0 {
+		t.Errorf("once fired %d more times after first observation, want 0", calls)
+	}
+}
+
+func TestTurnEnd(t *testing.T) {
+	// Latency counts from speech_final, not from when the response starts
+	speechFinalAt := time.Now().Add(-300 * time.Millisecond)
+	ctx := withTurnTiming(context.Background(), turnEnd(speechFinalAt))
+	if tt := ctx.Value(turnTimingKey{}).(*turnTiming); !tt.start.Equal(speechFinalAt) {
+		t.Errorf("turn timing start = %v, want speech_final at %v", tt.start, speechFinalAt)
+	}
+
+	// Without speech_final (max turn timeout) the turn ends when it is finalized
+	if end := turnEnd(time.Time{}); time.Since(end) > time.Second {
+		t.Errorf("turnEnd(zero) = %v, want now", end)
+	}
+}
@@ -0,0 +1,397 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// memberAuditEntry starts an entry for a change to a member or invitation of
+// the user's tenant.
+func memberAuditEntry(authUser *AuthUser, action, targetType, targetID string) store.AuditEntry {
+	e := tenantAuditEntry(authUser, action)
+	e.TargetType, e.TargetID = targetType, targetID
+	return e
+}
+
+// handleListMembers returns the users of the tenant with their roles.
+func (r *Router) handleListMembers(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	members, err := r.store.ListUsersByTenant(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("members: failed to list", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if members == nil {
+		members = []store.AdminUser{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"members": members})
+}
+
+// handleUpdateMember changes the role of a member of the tenant.
+func (r *Router) handleUpdateMember(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	userID := req.PathValue("userId")
+	if !store.IsUUID(userID) {
+		http.Error(w, `{"error": "member not found"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if !store.ValidRole(body.Role) {
+		http.Error(w, `{"error": "role must be owner, member or viewer"}`, http.StatusBadRequest)
+		return
+	}
+
+	prev, err := r.store.UpdateMemberRole(req.Context(), *authUser.TenantID, userID, body.Role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "member not found"}`, http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrLastOwner) {
+		http.Error(w, `{"error": "the tenant must keep an owner"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		r.logger.Error("members: failed to update role", "tenant_id", *authUser.TenantID, "user_id", userID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to update member"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("members: updated role", "tenant_id", *authUser.TenantID, "user_id", userID, "role", body.Role)
+	r.recordAudit(req, memberAuditEntry(authUser, store.AuditActionMemberRoleUpdate, store.AuditTargetUser, userID),
+		map[string]string{"role": prev}, map[string]string{"role": body.Role})
+	writeJSON(w, http.StatusOK, map[string]string{"id": userID, "role": body.Role})
+}
+
+// handleRemoveMember removes a user from the tenant. Owners can remove anyone;
+// other members can only leave themselves.
+func (r *Router) handleRemoveMember(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	userID := req.PathValue("userId")
+	if userID != authUser.ID && !store.RoleAtLeast(authUser.Role, store.RoleOwner) {
+		http.Error(w, `{"error": "owner role required"}`, http.StatusForbidden)
+		return
+	}
+	if !store.IsUUID(userID) {
+		http.Error(w, `{"error": "member not found"}`, http.StatusNotFound)
+		return
+	}
+
+	prev, err := r.store.RemoveMember(req.Context(), *authUser.TenantID, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "member not found"}`, http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrLastOwner) {
+		http.Error(w, `{"error": "the tenant must keep an owner"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		r.logger.Error("members: failed to remove", "tenant_id", *authUser.TenantID, "user_id", userID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to remove member"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("members: removed", "tenant_id", *authUser.TenantID, "user_id", userID, "by", authUser.ID)
+	r.recordAudit(req, memberAuditEntry(authUser, store.AuditActionMemberRemove, store.AuditTargetUser, userID),
+		map[string]string{"role": prev}, nil)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// handleListInvitations returns the tenant's open invitations.
+func (r *Router) handleListInvitations(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	invitations, err := r.store.ListTenantInvitations(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("members: failed to list invitations", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"invitations": invitations})
+}
+
+// handleCreateInvitation invites a phone number to the tenant. The invitee
+// signs in with the number and accepts the invitation.
+func (r *Router) handleCreateInvitation(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Phone string `json:"phone"`
+		Role  string `json:"role"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	body.Phone = strings.TrimSpace(body.Phone)
+	if !isValidE164(body.Phone) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid phone format, use E.164 (e.g., +420777123456)",
+		})
+		return
+	}
+	if body.Role == "" {
+		body.Role = store.RoleMember
+	}
+	if !store.ValidRole(body.Role) {
+		http.Error(w, `{"error": "role must be owner, member or viewer"}`, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := r.store.GetUserByPhone(req.Context(), body.Phone)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		r.logger.Error("members: failed to look up invitee", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if existing != nil && existing.TenantID != nil && *existing.TenantID == *authUser.TenantID {
+		http.Error(w, `{"error": "already a member"}`, http.StatusConflict)
+		return
+	}
+
+	inv, err := r.store.CreateInvitation(req.Context(), *authUser.TenantID, body.Phone, body.Role, &authUser.ID)
+	if err != nil {
+		r.logger.Error("members: failed to create invitation", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to create invitation"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("members: invited", "tenant_id", *authUser.TenantID, "invitation_id", inv.ID, "role", inv.Role)
+	r.recordAudit(req, memberAuditEntry(authUser, store.AuditActionMemberInvite, store.AuditTargetInvitation, inv.ID),
+		nil, map[string]string{"phone": inv.Phone, "role": inv.Role})
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+// handleRevokeInvitation revokes an open invitation of the tenant.
+func (r *Router) handleRevokeInvitation(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	id := req.PathValue("id")
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "invitation not found"}`, http.StatusNotFound)
+		return
+	}
+
+	inv, err := r.store.RevokeInvitation(req.Context(), *authUser.TenantID, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "invitation not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		r.logger.Error("members: failed to revoke invitation", "tenant_id", *authUser.TenantID, "invitation_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to revoke invitation"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("members: revoked invitation", "tenant_id", *authUser.TenantID, "invitation_id", id)
+	r.recordAudit(req, memberAuditEntry(authUser, store.AuditActionMemberInviteRevoke, store.AuditTargetInvitation, id),
+		map[string]string{"phone": inv.Phone, "role": inv.Role}, nil)
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// handleListMyInvitations returns the open invitations to the user's phone
+// number.
+func (r *Router) handleListMyInvitations(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil {
+		http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	invitations, err := r.store.ListPhoneInvitations(req.Context(), authUser.Phone)
+	if err != nil {
+		r.logger.Error("members: failed to list invitations", "user_id", authUser.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"invitations": invitations})
+}
+
+// handleAcceptInvitation adds the user to the inviting tenant. Users already
+// in a tenant have to leave it first.
+func (r *Router) handleAcceptInvitation(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil {
+		http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	id := req.PathValue("id")
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "invitation not found or expired"}`, http.StatusNotFound)
+		return
+	}
+
+	inv, err := r.store.AcceptInvitation(req.Context(), id, authUser.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "invitation not found or expired"}`, http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrAlreadyInTenant) {
+		http.Error(w, `{"error": "already a member of a tenant"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		r.logger.Error("members: failed to accept invitation", "invitation_id", id, "user_id", authUser.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to accept invitation"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("members: joined tenant", "tenant_id", inv.TenantID, "user_id", authUser.ID, "role", inv.Role)
+	r.recordAudit(req, store.AuditEntry{
+		ActorType: store.AuditActorUser, Action: store.AuditActionMemberJoin,
+		TargetType: store.AuditTargetUser, TargetID: authUser.ID, TenantID: &inv.TenantID,
+	}, nil, map[string]string{"role": inv.Role, "invitation_id": inv.ID})
+
+	user, err := r.store.GetUserByID(req.Context(), authUser.ID)
+	if err != nil {
+		http.Error(w, `{"error": "user not found"}`, http.StatusNotFound)
+		return
+	}
+	tenant, err := r.store.GetTenantByID(req.Context(), inv.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"user": user, "tenant": tenant})
+}
+
+// handleDeclineInvitation declines an open invitation to the user's phone
+// number.
+func (r *Router) handleDeclineInvitation(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil {
+		http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	id := req.PathValue("id")
+	if !store.IsUUID(id) {
+		http.Error(w, `{"error": "invitation not found"}`, http.StatusNotFound)
+		return
+	}
+
+	err := r.store.DeclineInvitation(req.Context(), id, authUser.Phone)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, `{"error": "invitation not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		r.logger.Error("members: failed to decline invitation", "invitation_id", id, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to decline invitation"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// handleGetMemberSettings returns the user's own preferences.
+func (r *Router) handleGetMemberSettings(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil {
+		http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := r.store.GetMemberSettings(req.Context(), authUser.ID)
+	if err != nil {
+		r.logger.Error("members: failed to load settings", "user_id", authUser.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// handleUpdateMemberSettings updates the given fields of the user's own
+// preferences.
+func (r *Router) handleUpdateMemberSettings(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil {
+		http.Error(w, `{"error": "not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		NotifyCalls *string `json:"notify_calls"`
+		NotifyUsage *bool   `json:"notify_usage"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if body.NotifyCalls != nil {
+		switch *body.NotifyCalls {
+		case store.NotifyCallsAll, store.NotifyCallsLegitimate, store.NotifyCallsNone:
+		default:
+			http.Error(w, `{"error": "notify_calls must be all, legitimate or none"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	settings, err := r.store.GetMemberSettings(req.Context(), authUser.ID)
+	if err != nil {
+		r.logger.Error("members: failed to load settings", "user_id", authUser.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if body.NotifyCalls != nil {
+		settings.NotifyCalls = *body.NotifyCalls
+	}
+	if body.NotifyUsage != nil {
+		settings.NotifyUsage = *body.NotifyUsage
+	}
+	if err := r.store.SetMemberSettings(req.Context(), authUser.ID, settings); err != nil {
+		r.logger.Error("members: failed to save settings", "user_id", authUser.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save settings"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func TestRequireRole(t *testing.T) {
+	tenantID := "tenant-1"
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	tests := []struct {
+		name string
+		user *AuthUser
+		min  string
+		want int
+	}{
+		{"not authenticated", nil, store.RoleViewer, http.StatusUnauthorized},
+		{"owner", &AuthUser{ID: "u", TenantID: &tenantID, Role: store.RoleOwner}, store.RoleOwner, http.StatusOK},
+		{"member for member", &AuthUser{ID: "u", TenantID: &tenantID, Role: store.RoleMember}, store.RoleMember, http.StatusOK},
+		{"member for owner", &AuthUser{ID: "u", TenantID: &tenantID, Role: store.RoleMember}, store.RoleOwner, http.StatusForbidden},
+		{"viewer for member", &AuthUser{ID: "u", TenantID: &tenantID, Role: store.RoleViewer}, store.RoleMember, http.StatusForbidden},
+		{"unknown role", &AuthUser{ID: "u", TenantID: &tenantID, Role: "admin"}, store.RoleViewer, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.user != nil {
+				ctx = context.WithValue(ctx, userContextKey, tt.user)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/tenant", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			requireRole(tt.min, ok)(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMemberHandlers_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	ownerCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID, Role: store.RoleOwner})
+	viewerCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-2", TenantID: &tenantID, Role: store.RoleViewer})
+	otherUser := "3f2b8c1e-5d4a-4e6b-9c7d-8a1b2c3d4e5f"
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		ctx     context.Context
+		userID  string
+		body    string
+		want    int
+	}{
+		{"invite without tenant", r.handleCreateInvitation, context.Background(), "", `{"phone":"+420777123456"}`, http.StatusNotFound},
+		{"invite invalid body", r.handleCreateInvitation, ownerCtx, "", `{`, http.StatusBadRequest},
+		{"invite invalid phone", r.handleCreateInvitation, ownerCtx, "", `{"phone":"777123456"}`, http.StatusBadRequest},
+		{"invite invalid role", r.handleCreateInvitation, ownerCtx, "", `{"phone":"+420777123456","role":"admin"}`, http.StatusBadRequest},
+		{"update invalid role", r.handleUpdateMember, ownerCtx, otherUser, `{"role":"boss"}`, http.StatusBadRequest},
+		{"update invalid id", r.handleUpdateMember, ownerCtx, "not-a-uuid", `{"role":"viewer"}`, http.StatusNotFound},
+		{"viewer removes someone else", r.handleRemoveMember, viewerCtx, otherUser, ``, http.StatusForbidden},
+		{"remove invalid id", r.handleRemoveMember, ownerCtx, "not-a-uuid", ``, http.StatusNotFound},
+		{"settings invalid notify_calls", r.handleUpdateMemberSettings, viewerCtx, "", `{"notify_calls":"some"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/tenant/members", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			req.SetPathValue("userId", tt.userID)
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// Playground limits (the transcript is sent by the client on every turn).
+const (
+	playgroundMaxMessages   = 100
+	playgroundMaxTextLength = 2000
+)
+
+// Playground actions detected in the agent reply, as on a real call.
+const (
+	playgroundActionForward = "forward"
+	playgroundActionGoodbye = "goodbye"
+)
+
+// playgroundMessage is one transcript line of a playground session.
+type playgroundMessage struct {
+	Speaker string `json:"speaker"` // "agent" or "caller"
+	Text    string `json:"text"`
+}
+
+// playgroundResult is the response of one playground turn.
+type playgroundResult struct {
+	Messages  []playgroundMessage      `json:"messages"`            // Full transcript, sent back with the next turn
+	Reply     string                   `json:"reply,omitempty"`     // Agent reply to this turn (forward marker stripped)
+	Action    string                   `json:"action,omitempty"`    // "forward" or "goodbye" when the reply ends the call
+	Ended     bool                     `json:"ended"`               // The call would end here; no further turns
+	Screening *llm.ScreeningResult     `json:"screening,omitempty"` // Screening of the transcript, once ended
+	Knowledge []store.KnowledgeSnippet `json:"knowledge,omitempty"` // Knowledge base snippets given to the LLM for this turn
+}
+
+// knowledgeSearchFunc searches the tenant's knowledge base for a caller turn.
+type knowledgeSearchFunc func(ctx context.Context, query string) ([]store.KnowledgeSnippet, error)
+
+// handlePlayground runs one turn of a text chat with the tenant's assistant.
+// The user types as the caller; the reply goes through the same message
+// assembly, guardrails and forward/goodbye detection as a real call, and the
+// final screening is run when the call ends. Sessions are stateless: the
+// client sends back the transcript from the previous response. Nothing is
+// stored as a call and no usage is recorded.
+//
+// Body: messages (transcript so far; empty starts a session with the greeting),
+// message (caller text), end (caller hangs up), and optional unsaved
+// system_prompt / greeting_text drafts to try instead of the saved ones.
+func (r *Router) handlePlayground(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Messages     []playgroundMessage `json:"messages"`
+		Message      string              `json:"message"`
+		End          bool                `json:"end"`
+		SystemPrompt *string             `json:"system_prompt"`
+		GreetingText *string             `json:"greeting_text"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	body.Message = strings.TrimSpace(body.Message)
+	if msg := validatePlaygroundMessages(body.Messages, body.Message); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	if len(body.Messages) > 0 && body.Message == "" && !body.End {
+		http.Error(w, `{"error": "message is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	if r.cfg.OpenAIAPIKey == "" || r.providers == nil {
+		http.Error(w, `{"error": "voice AI not configured"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	tenant, err := r.store.GetTenantByID(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+
+	systemPrompt := tenant.SystemPrompt
+	if body.SystemPrompt != nil {
+		systemPrompt = *body.SystemPrompt
+	}
+	greetingText := tenant.GreetingText
+	if body.GreetingText != nil {
+		greetingText = body.GreetingText
+	}
+
+	// Same client and prompt setup as a call session; no usage accumulator
+	// in the context, so nothing is billed to the tenant.
+	client := r.providers.newLLMClient(func(provider string, err error) {
+		r.logger.Error("playground: provider failed", "provider", provider, "error", err)
+	})
+	if systemPrompt != "" {
+		client.SetSystemPrompt(systemPrompt)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), 60*time.Second)
+	defer cancel()
+
+	search := func(ctx context.Context, query string) ([]store.KnowledgeSnippet, error) {
+		return r.store.SearchKnowledge(ctx, tenant.ID, query, knowledgeSnippetLimit)
+	}
+
+	// Screening uses the tenant's labels, as on a real call
+	taxonomy, custom, err := loadScreeningTaxonomy(ctx, r.store, tenant.ID)
+	if err != nil {
+		r.logger.Error("playground: failed to load screening taxonomy", "tenant_id", tenant.ID, "error", err)
+		sentry.CaptureException(err)
+	}
+	ctx = analysisContext(ctx, taxonomy, custom)
+
+	result, err := runPlaygroundTurn(ctx, client, search, callGreeting(greetingText, r.cfg.GreetingText), body.Messages, body.Message, body.End)
+	if err != nil {
+		r.logger.Error("playground: LLM error", "tenant_id", tenant.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to generate response"}`, http.StatusBadGateway)
+		return
+	}
+	if result.Screening != nil {
+		taxonomy.Apply(result.Screening)
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// validatePlaygroundMessages checks the client-supplied transcript and caller
+// text; it returns an error message or "".
+func validatePlaygroundMessages(messages []playgroundMessage, message string) string {
+	if len(messages) > playgroundMaxMessages {
+		return "too many messages"
+	}
+	if len(message) > playgroundMaxTextLength {
+		return "message is too long"
+	}
+	for _, m := range messages {
+		if m.Speaker != "agent" && m.Speaker != "caller" {
+			return "message speaker must be agent or caller"
+		}
+		if len(m.Text) > playgroundMaxTextLength {
+			return "message is too long"
+		}
+	}
+	return ""
+}
+
+// runPlaygroundTurn plays one turn against the LLM client. An empty transcript
+// starts with the greeting, as the call session does. The caller message is
+// answered (with knowledge base snippets from search, if any); a forward or
+// goodbye reply, or end, finishes the call and runs the screening over the
+// whole conversation.
+func runPlaygroundTurn(ctx context.Context, client llm.Client, search knowledgeSearchFunc, greeting string, transcript []playgroundMessage, message string, end bool) (*playgroundResult, error) {
+	result := &playgroundResult{Messages: append([]playgroundMessage(nil), transcript...)}
+	if len(result.Messages) == 0 {
+		result.Messages = append(result.Messages, playgroundMessage{Speaker: "agent", Text: greeting})
+	}
+
+	if message != "" {
+		result.Messages = append(result.Messages, playgroundMessage{Speaker: "caller", Text: message})
+
+		msgs := playgroundLLMMessages(result.Messages)
+		if search != nil {
+			snippets, err := search(ctx, message)
+			if err != nil {
+				return nil, err
+			}
+			result.Knowledge = snippets
+			msgs = withKnowledge(msgs, snippets)
+		}
+
+		ch, err := client.GenerateResponse(ctx, msgs)
+		if err != nil {
+			return nil, err
+		}
+		var reply strings.Builder
+		for chunk := range ch {
+			reply.WriteString(chunk)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		responseText := strings.TrimSpace(reply.String())
+		if responseText != "" {
+			result.Messages = append(result.Messages, playgroundMessage{Speaker: "agent", Text: responseText})
+			result.Reply = stripForwardMarker(responseText)
+			if isForward(responseText) {
+				result.Action = playgroundActionForward
+			} else if isGoodbye(responseText) {
+				result.Action = playgroundActionGoodbye
+			}
+		}
+	}
+
+	if !end && result.Action == "" {
+		return result, nil
+	}
+
+	screening, err := client.AnalyzeCall(ctx, playgroundLLMMessages(result.Messages))
+	if err != nil {
+		return nil, err
+	}
+	result.Ended = true
+	result.Screening = screening
+	return result, nil
+}
+
+// playgroundLLMMessages converts a transcript into conversation history.
+func playgroundLLMMessages(transcript []playgroundMessage) []llm.Message {
+	msgs := make([]llm.Message, 0, len(transcript))
+	for _, m := range transcript {
+		role := "assistant"
+		if m.Speaker == "caller" {
+			role = "user"
+		}
+		msgs = append(msgs, llm.Message{Role: role, Content: m.Text})
+	}
+	return msgs
+}
@@ -0,0 +1,187 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// playgroundLLM replies with fixed text and records requests.
+type playgroundLLM struct {
+	prompt    string
+	reply     string
+	err       error
+	generated [][]llm.Message
+	analyzed  [][]llm.Message
+}
+
+func (p *playgroundLLM) AnalyzeCall(_ context.Context, messages []llm.Message) (*llm.ScreeningResult, error) {
+	p.analyzed = append(p.analyzed, messages)
+	return &llm.ScreeningResult{LegitimacyLabel: "legitimní", IntentText: "faktura"}, nil
+}
+
+func (p *playgroundLLM) GenerateResponse(_ context.Context, messages []llm.Message) (<-chan string, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.generated = append(p.generated, messages)
+	ch := make(chan string, 1)
+	ch <- p.reply
+	close(ch)
+	return ch, nil
+}
+
+func (p *playgroundLLM) SetSystemPrompt(prompt string) { p.prompt = prompt }
+func (p *playgroundLLM) GetSystemPrompt() string       { return p.prompt }
+
+func TestRunPlaygroundTurn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("start returns greeting", func(t *testing.T) {
+		client := &playgroundLLM{}
+		res, err := runPlaygroundTurn(ctx, client, nil, "Dobrý den", nil, "", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(res.Messages) != 1 || res.Messages[0].Text != "Dobrý den" || res.Ended {
+			t.Errorf("unexpected result %+v", res)
+		}
+		if len(client.generated) != 0 {
+			t.Error("expected no LLM request")
+		}
+	})
+
+	t.Run("caller turn", func(t *testing.T) {
+		client := &playgroundLLM{reply: " Rozumím, jak se jmenujete? "}
+		transcript := []playgroundMessage{{Speaker: "agent", Text: "Dobrý den"}}
+		res, err := runPlaygroundTurn(ctx, client, nil, "ignored", transcript, "Volám kvůli faktuře", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Reply != "Rozumím, jak se jmenujete?" || res.Action != "" || res.Ended || res.Screening != nil {
+			t.Errorf("unexpected result %+v", res)
+		}
+		if len(res.Messages) != 3 || res.Messages[2].Speaker != "agent" {
+			t.Errorf("unexpected transcript %+v", res.Messages)
+		}
+		msgs := client.generated[0]
+		if len(msgs) != 2 || msgs[0].Role != "assistant" || msgs[1].Role != "user" || msgs[1].Content != "Volám kvůli faktuře" {
+			t.Errorf("unexpected LLM history %+v", msgs)
+		}
+	})
+
+	t.Run("forward ends the call", func(t *testing.T) {
+		client := &playgroundLLM{reply: "[PŘEPOJIT] Přepojuji vás."}
+		res, err := runPlaygroundTurn(ctx, client, nil, "Dobrý den", nil, "Je to urgentní", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != playgroundActionForward || res.Reply != "Přepojuji vás." || !res.Ended {
+			t.Errorf("unexpected result %+v", res)
+		}
+		if res.Screening == nil || len(client.analyzed[0]) != 3 {
+			t.Errorf("expected screening over the full transcript, got %+v", client.analyzed)
+		}
+	})
+
+	t.Run("goodbye ends the call", func(t *testing.T) {
+		client := &playgroundLLM{reply: "Děkuji, na shledanou."}
+		res, err := runPlaygroundTurn(ctx, client, nil, "Dobrý den", nil, "To je vše", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Action != playgroundActionGoodbye || !res.Ended || res.Screening == nil {
+			t.Errorf("unexpected result %+v", res)
+		}
+	})
+
+	t.Run("caller hangs up", func(t *testing.T) {
+		client := &playgroundLLM{}
+		transcript := []playgroundMessage{{Speaker: "agent", Text: "Dobrý den"}, {Speaker: "caller", Text: "Haló"}}
+		res, err := runPlaygroundTurn(ctx, client, nil, "", transcript, "", true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !res.Ended || res.Screening.IntentText != "faktura" || len(client.generated) != 0 {
+			t.Errorf("unexpected result %+v", res)
+		}
+	})
+
+	t.Run("knowledge snippets injected", func(t *testing.T) {
+		client := &playgroundLLM{reply: "Máme otevřeno od osmi."}
+		var searched string
+		search := func(_ context.Context, query string) ([]store.KnowledgeSnippet, error) {
+			searched = query
+			return []store.KnowledgeSnippet{{ChunkID: "c1", Title: "Otevírací doba", Content: "Otevírací doba\nPo–Pá 8–16"}}, nil
+		}
+		res, err := runPlaygroundTurn(ctx, client, search, "Dobrý den", nil, "Kdy máte otevřeno?", false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if searched != "Kdy máte otevřeno?" || len(res.Knowledge) != 1 {
+			t.Errorf("unexpected search %q / knowledge %+v", searched, res.Knowledge)
+		}
+		msgs := client.generated[0]
+		if len(msgs) != 3 || msgs[1].Role != "system" || !strings.Contains(msgs[1].Content, "Po–Pá 8–16") {
+			t.Errorf("expected snippets before the caller message, got %+v", msgs)
+		}
+		if len(res.Messages) != 3 {
+			t.Errorf("snippets must not be part of the transcript, got %+v", res.Messages)
+		}
+	})
+
+	t.Run("knowledge search error", func(t *testing.T) {
+		client := &playgroundLLM{reply: "x"}
+		search := func(context.Context, string) ([]store.KnowledgeSnippet, error) { return nil, errors.New("db down") }
+		if _, err := runPlaygroundTurn(ctx, client, search, "Dobrý den", nil, "Haló", false); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("LLM error", func(t *testing.T) {
+		client := &playgroundLLM{err: errors.New("boom")}
+		if _, err := runPlaygroundTurn(ctx, client, nil, "Dobrý den", nil, "Haló", false); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestHandlePlayground_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"invalid speaker", authCtx, `{"messages":[{"speaker":"system","text":"x"}],"message":"hi"}`, http.StatusBadRequest},
+		{"message too long", authCtx, `{"message":"` + strings.Repeat("a", playgroundMaxTextLength+1) + `"}`, http.StatusBadRequest},
+		{"missing message", authCtx, `{"messages":[{"speaker":"agent","text":"Dobrý den"}]}`, http.StatusBadRequest},
+		{"not configured", authCtx, `{"message":"hi"}`, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/tenant/playground", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handlePlayground(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
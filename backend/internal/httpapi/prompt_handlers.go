@@ -0,0 +1,154 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/store"
+	"github.com/lukasbauer/karen/internal/textdiff"
+)
+
+// handleListPromptVersions returns the system prompt history of the user's tenant.
+func (r *Router) handleListPromptVersions(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	versions, err := r.store.ListPromptVersions(req.Context(), *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("prompts: failed to list prompt versions", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to list prompt versions"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"versions": versions,
+	})
+}
+
+// handleDiffPromptVersions returns a line diff between two prompt versions.
+// Query params: from (required), to (optional, defaults to the current version).
+func (r *Router) handleDiffPromptVersions(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	from, err := strconv.Atoi(req.URL.Query().Get("from"))
+	if err != nil || from < 1 {
+		http.Error(w, `{"error": "from must be a version number"}`, http.StatusBadRequest)
+		return
+	}
+	to := 0
+	if v := req.URL.Query().Get("to"); v != "" {
+		to, err = strconv.Atoi(v)
+		if err != nil || to < 1 {
+			http.Error(w, `{"error": "to must be a version number"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if to == 0 {
+		tenant, err := r.store.GetTenantByID(req.Context(), *authUser.TenantID)
+		if err != nil {
+			http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+			return
+		}
+		to = tenant.PromptVersion
+	}
+
+	fromVersion, ok := r.loadPromptVersion(w, req, *authUser.TenantID, from)
+	if !ok {
+		return
+	}
+	toVersion, ok := r.loadPromptVersion(w, req, *authUser.TenantID, to)
+	if !ok {
+		return
+	}
+
+	lines := textdiff.Lines(fromVersion.SystemPrompt, toVersion.SystemPrompt)
+	added, removed := textdiff.Stats(lines)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"from":    fromVersion,
+		"to":      toVersion,
+		"added":   added,
+		"removed": removed,
+		"lines":   lines,
+		"diff":    textdiff.Format(lines),
+	})
+}
+
+// handleRollbackPromptVersion makes an earlier prompt version active again.
+// The rollback is saved as a new version so the history stays append-only.
+func (r *Router) handleRollbackPromptVersion(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	version, err := strconv.Atoi(req.PathValue("version"))
+	if err != nil || version < 1 {
+		http.Error(w, `{"error": "invalid version"}`, http.StatusBadRequest)
+		return
+	}
+
+	target, ok := r.loadPromptVersion(w, req, *authUser.TenantID, version)
+	if !ok {
+		return
+	}
+	if target.Current {
+		http.Error(w, `{"error": "version is already active"}`, http.StatusBadRequest)
+		return
+	}
+
+	before, err := r.store.GetTenantByID(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+
+	reason := fmt.Sprintf("rollback to v%d", version)
+	newVersion, err := r.store.SaveTenantPrompt(req.Context(), *authUser.TenantID, target.SystemPrompt, &authUser.ID, reason)
+	if err != nil {
+		r.logger.Error("prompts: failed to roll back system prompt", "tenant_id", *authUser.TenantID, "version", version, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to roll back prompt"}`, http.StatusInternalServerError)
+		return
+	}
+	r.logger.Info("prompts: rolled back system prompt", "tenant_id", *authUser.TenantID, "from_version", version, "new_version", newVersion)
+
+	tenant, err := r.store.GetTenantByID(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionPromptRollback), before, tenant, "updated_at")
+	writeJSON(w, http.StatusOK, tenant)
+}
+
+// loadPromptVersion fetches a prompt version of the tenant, writing a 404/500
+// response and returning false if it cannot be loaded.
+func (r *Router) loadPromptVersion(w http.ResponseWriter, req *http.Request, tenantID string, version int) (*store.PromptVersion, bool) {
+	v, err := r.store.GetPromptVersion(req.Context(), tenantID, version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, fmt.Sprintf(`{"error": "prompt version %d not found"}`, version), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		r.logger.Error("prompts: failed to get prompt version", "tenant_id", tenantID, "version", version, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to get prompt version"}`, http.StatusInternalServerError)
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,142 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/logging"
+)
+
+func TestPromptVersionHandlers_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		version string
+		handler http.HandlerFunc
+		ctx     context.Context
+		want    int
+	}{
+		{"list without tenant", http.MethodGet, "/api/tenant/prompt-versions", "", r.handleListPromptVersions, context.Background(), http.StatusNotFound},
+		{"diff without from", http.MethodGet, "/api/tenant/prompt-versions/diff", "", r.handleDiffPromptVersions, authCtx, http.StatusBadRequest},
+		{"diff with invalid to", http.MethodGet, "/api/tenant/prompt-versions/diff?from=1&to=abc", "", r.handleDiffPromptVersions, authCtx, http.StatusBadRequest},
+		{"diff with zero from", http.MethodGet, "/api/tenant/prompt-versions/diff?from=0", "", r.handleDiffPromptVersions, authCtx, http.StatusBadRequest},
+		{"rollback invalid version", http.MethodPost, "/api/tenant/prompt-versions/x/rollback", "x", r.handleRollbackPromptVersion, authCtx, http.StatusBadRequest},
+		{"rollback without tenant", http.MethodPost, "/api/tenant/prompt-versions/1/rollback", "1", r.handleRollbackPromptVersion, context.Background(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil).WithContext(tt.ctx)
+			if tt.version != "" {
+				req.SetPathValue("version", tt.version)
+			}
+			rec := httptest.NewRecorder()
+
+			tt.handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPromptVersionsIntegration(t *testing.T) {
+	r, db, cleanup := getTestRouterWithDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	testPhone := "+420333" + time.Now().Format("150405")
+	user, _, err := r.store.FindOrCreateUser(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	tenant, err := r.store.CreateTenant(ctx, "Prompt Test", "Jsi Karen.\nBuď stručná.", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+	if _, err := r.store.SaveTenantPrompt(ctx, tenant.ID, tenant.SystemPrompt, &user.ID, "onboarding"); err != nil {
+		t.Fatalf("SaveTenantPrompt failed: %v", err)
+	}
+
+	reqCtx := context.WithValue(ctx, userContextKey, &AuthUser{ID: user.ID, TenantID: &tenant.ID, Phone: testPhone})
+	do := func(handler http.HandlerFunc, method, target, body string, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(reqCtx)
+		if version != "" {
+			req.SetPathValue("version", version)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	// Editing the prompt creates version 2
+	rec := do(r.handleUpdateTenant, http.MethodPatch, "/api/tenant",
+		`{"system_prompt": "Jsi Karen.\nBuď velmi stručná.", "prompt_change_reason": "shorter answers"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(r.handleListPromptVersions, http.MethodGet, "/api/tenant/prompt-versions", "", "")
+	var list struct {
+		Versions []struct {
+			Version int    `json:"version"`
+			Reason  string `json:"reason"`
+			Current bool   `json:"current"`
+		} `json:"versions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode list: %v", err)
+	}
+	if len(list.Versions) != 2 || list.Versions[0].Version != 2 || !list.Versions[0].Current || list.Versions[0].Reason != "shorter answers" {
+		t.Fatalf("versions = %+v, want current v2 'shorter answers' and v1", list.Versions)
+	}
+
+	// Diff v1 against current
+	rec = do(r.handleDiffPromptVersions, http.MethodGet, "/api/tenant/prompt-versions/diff?from=1", "", "")
+	var diff struct {
+		Added   int    `json:"added"`
+		Removed int    `json:"removed"`
+		Diff    string `json:"diff"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&diff); err != nil {
+		t.Fatalf("failed to decode diff: %v", err)
+	}
+	if diff.Added != 1 || diff.Removed != 1 || !strings.Contains(diff.Diff, "+Buď velmi stručná.") {
+		t.Errorf("diff = %+v, want one line changed", diff)
+	}
+
+	// Roll back to v1 creates version 3 with the v1 prompt
+	rec = do(r.handleRollbackPromptVersion, http.MethodPost, "/api/tenant/prompt-versions/1/rollback", "", "1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rollback status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	updated, err := r.store.GetTenantByID(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("GetTenantByID failed: %v", err)
+	}
+	if updated.PromptVersion != 3 || updated.SystemPrompt != "Jsi Karen.\nBuď stručná." {
+		t.Errorf("tenant prompt = v%d %q, want v3 with the v1 prompt", updated.PromptVersion, updated.SystemPrompt)
+	}
+
+	// Rolling back to the active version is rejected
+	rec = do(r.handleRollbackPromptVersion, http.MethodPost, "/api/tenant/prompt-versions/3/rollback", "", "3")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("rollback to active status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,241 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/breaker"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/stt"
+	"github.com/lukasbauer/karen/internal/tracing"
+	"github.com/lukasbauer/karen/internal/tts"
+)
+
+// Provider names used in breaker snapshots and admin endpoints.
+const (
+	providerFallback   = "fallback"
+	providerDeepgram   = "deepgram"
+	providerOpenAI     = "openai"
+	providerElevenLabs = "elevenlabs"
+)
+
+type sttProvider struct {
+	apiKey  string
+	url     string
+	breaker *breaker.Breaker
+}
+
+type llmProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	breaker *breaker.Breaker
+}
+
+type ttsProvider struct {
+	apiKey  string
+	baseURL string
+	modelID string
+	breaker *breaker.Breaker
+}
+
+// providerRegistry holds the ordered STT, LLM and TTS providers. Each provider
+// is wrapped in a circuit breaker shared by all calls, so failures observed on
+// one call route subsequent turns and new calls to the secondary provider.
+type providerRegistry struct {
+	stt []sttProvider
+	llm []llmProvider
+	tts []ttsProvider
+
+	llmHTTPClient *http.Client // Shared by all LLM clients; propagates trace context
+}
+
+// newProviderRegistry builds the provider lists from config. A secondary
+// provider is only added when its API key or endpoint is configured; a
+// secondary without its own API key reuses the primary's key.
+func newProviderRegistry(cfg RouterConfig) *providerRegistry {
+	bc := breaker.Config{
+		FailureThreshold: cfg.ProviderBreakerFailures,
+		OpenDuration:     cfg.ProviderBreakerCooldown,
+	}
+	llmCfg := bc
+	llmCfg.SlowThreshold = time.Duration(cfg.LLMSlowFirstTokenMs) * time.Millisecond
+	ttsCfg := bc
+	ttsCfg.SlowThreshold = time.Duration(cfg.TTSSlowFirstChunkMs) * time.Millisecond
+
+	p := &providerRegistry{
+		llmHTTPClient: &http.Client{Transport: tracing.Transport(nil)},
+		stt: []sttProvider{{
+			apiKey:  cfg.DeepgramAPIKey,
+			breaker: breaker.New("stt", providerDeepgram, bc),
+		}},
+		llm: []llmProvider{{
+			apiKey:  cfg.OpenAIAPIKey,
+			model:   "gpt-4o-mini",
+			breaker: breaker.New("llm", providerOpenAI, llmCfg),
+		}},
+		tts: []ttsProvider{{
+			apiKey:  cfg.ElevenLabsAPIKey,
+			modelID: firstNonEmpty(cfg.TTSModelID, "eleven_flash_v2_5"),
+			breaker: breaker.New("tts", providerElevenLabs, ttsCfg),
+		}},
+	}
+
+	if cfg.STTFallbackAPIKey != "" || cfg.STTFallbackURL != "" {
+		p.stt = append(p.stt, sttProvider{
+			apiKey:  firstNonEmpty(cfg.STTFallbackAPIKey, cfg.DeepgramAPIKey),
+			url:     cfg.STTFallbackURL,
+			breaker: breaker.New("stt", providerFallback, bc),
+		})
+	}
+	if cfg.LLMFallbackAPIKey != "" || cfg.LLMFallbackBaseURL != "" {
+		p.llm = append(p.llm, llmProvider{
+			apiKey:  firstNonEmpty(cfg.LLMFallbackAPIKey, cfg.OpenAIAPIKey),
+			baseURL: cfg.LLMFallbackBaseURL,
+			model:   firstNonEmpty(cfg.LLMFallbackModel, "gpt-4o-mini"),
+			breaker: breaker.New("llm", providerFallback, llmCfg),
+		})
+	}
+	if cfg.TTSFallbackAPIKey != "" || cfg.TTSFallbackBaseURL != "" {
+		p.tts = append(p.tts, ttsProvider{
+			apiKey:  firstNonEmpty(cfg.TTSFallbackAPIKey, cfg.ElevenLabsAPIKey),
+			baseURL: cfg.TTSFallbackBaseURL,
+			modelID: firstNonEmpty(cfg.TTSFallbackModelID, "eleven_flash_v2_5"),
+			breaker: breaker.New("tts", providerFallback, ttsCfg),
+		})
+	}
+
+	return p
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// breakers returns all breakers in pipeline order (STT, LLM, TTS).
+func (p *providerRegistry) breakers() []*breaker.Breaker {
+	if p == nil {
+		return nil
+	}
+	var all []*breaker.Breaker
+	for _, sp := range p.stt {
+		all = append(all, sp.breaker)
+	}
+	for _, lp := range p.llm {
+		all = append(all, lp.breaker)
+	}
+	for _, tp := range p.tts {
+		all = append(all, tp.breaker)
+	}
+	return all
+}
+
+// snapshots returns the current state of every breaker.
+func (p *providerRegistry) snapshots() []breaker.Snapshot {
+	snaps := []breaker.Snapshot{}
+	for _, b := range p.breakers() {
+		snaps = append(snaps, b.Snapshot())
+	}
+	return snaps
+}
+
+// find returns the breaker for the given kind and provider name, or nil.
+func (p *providerRegistry) find(kind, name string) *breaker.Breaker {
+	for _, b := range p.breakers() {
+		if b.Kind() == kind && b.Name() == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// newLLMClient creates a per-call LLM client that fails over between providers.
+func (p *providerRegistry) newLLMClient(onFailover llm.FailoverFunc) llm.Client {
+	providers := make([]llm.Provider, 0, len(p.llm))
+	for _, lp := range p.llm {
+		providers = append(providers, llm.Provider{
+			Client: llm.NewOpenAIClient(llm.OpenAIConfig{
+				APIKey:     lp.apiKey,
+				Model:      lp.model,
+				BaseURL:    lp.baseURL,
+				HTTPClient: p.llmHTTPClient,
+			}),
+			Breaker: lp.breaker,
+		})
+	}
+	return llm.NewFailoverClient(providers, onFailover)
+}
+
+// newTTSClient creates a per-call TTS client that fails over between providers.
+// modelID overrides the primary provider's model (e.g. a cheaper model for
+// calls over their cost budget); empty keeps the configured model.
+func (p *providerRegistry) newTTSClient(voiceID, modelID string, stability, similarity float64, httpClient *http.Client, onFailover tts.FailoverFunc) tts.Client {
+	providers := make([]tts.Provider, 0, len(p.tts))
+	for i, tp := range p.tts {
+		model := tp.modelID
+		if i == 0 && modelID != "" {
+			model = modelID
+		}
+		providers = append(providers, tts.Provider{
+			Client: tts.NewElevenLabsClient(tts.ElevenLabsConfig{
+				APIKey:     tp.apiKey,
+				VoiceID:    voiceID,
+				ModelID:    model,
+				Stability:  stability,
+				Similarity: similarity,
+				HTTPClient: httpClient,
+				BaseURL:    tp.baseURL,
+			}),
+			Breaker: tp.breaker,
+		})
+	}
+	return tts.NewFailoverClient(providers, onFailover)
+}
+
+// dialSTT connects to the first healthy STT provider. The returned breaker
+// belongs to the connected provider so mid-call errors can be recorded on it.
+// A breaker is only asked for permission when its provider is reached, so no
+// half-open probe is left reserved once a provider connects. If every breaker
+// is open, the primary is tried anyway.
+func (p *providerRegistry) dialSTT(ctx context.Context, cfg stt.DeepgramConfig, onFailover func(provider string, err error)) (stt.Client, *breaker.Breaker, error) {
+	var lastErr error
+	allowed := false
+	for i, sp := range p.stt {
+		if !sp.breaker.Allow() {
+			if allowed || i < len(p.stt)-1 {
+				continue
+			}
+			// Nothing allowed traffic: try the primary anyway
+			sp = p.stt[0]
+		}
+		allowed = true
+
+		dialCfg := cfg
+		dialCfg.APIKey = sp.apiKey
+		dialCfg.URL = sp.url
+		dialCfg.Headers = http.Header{}
+		tracing.InjectHeaders(ctx, dialCfg.Headers)
+		client, err := stt.NewDeepgramClient(ctx, dialCfg)
+		if err == nil {
+			sp.breaker.RecordSuccess()
+			return client, sp.breaker, nil
+		}
+		if ctx.Err() != nil {
+			sp.breaker.Release()
+			return nil, nil, err
+		}
+		sp.breaker.RecordFailure(err)
+		if onFailover != nil {
+			onFailover(sp.breaker.Name(), err)
+		}
+		lastErr = err
+	}
+	return nil, nil, fmt.Errorf("all STT providers failed: %w", lastErr)
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewProviderRegistry_PrimaryOnly(t *testing.T) {
+	p := newProviderRegistry(RouterConfig{
+		DeepgramAPIKey:   "dg",
+		OpenAIAPIKey:     "oa",
+		ElevenLabsAPIKey: "el",
+	})
+
+	if len(p.stt) != 1 || len(p.llm) != 1 || len(p.tts) != 1 {
+		t.Fatalf("providers = %d/%d/%d, want 1/1/1", len(p.stt), len(p.llm), len(p.tts))
+	}
+	if got := len(p.snapshots()); got != 3 {
+		t.Errorf("snapshots = %d, want 3", got)
+	}
+}
+
+func TestNewProviderRegistry_Fallbacks(t *testing.T) {
+	p := newProviderRegistry(RouterConfig{
+		DeepgramAPIKey:     "dg",
+		OpenAIAPIKey:       "oa",
+		ElevenLabsAPIKey:   "el",
+		STTFallbackURL:     "wss://api.eu.deepgram.com/v1/listen",
+		LLMFallbackAPIKey:  "other",
+		LLMFallbackBaseURL: "https://llm.example.com/v1/chat/completions",
+	})
+
+	if len(p.stt) != 2 || len(p.llm) != 2 || len(p.tts) != 1 {
+		t.Fatalf("providers = %d/%d/%d, want 2/2/1", len(p.stt), len(p.llm), len(p.tts))
+	}
+	// STT fallback without its own key reuses the primary key
+	if p.stt[1].apiKey != "dg" {
+		t.Errorf("stt fallback apiKey = %q, want %q", p.stt[1].apiKey, "dg")
+	}
+	if p.llm[1].apiKey != "other" || p.llm[1].model != "gpt-4o-mini" {
+		t.Errorf("llm fallback = %+v, want apiKey other, model gpt-4o-mini", p.llm[1])
+	}
+}
+
+func TestProviderRegistry_Find(t *testing.T) {
+	p := newProviderRegistry(RouterConfig{})
+
+	b := p.find("llm", providerOpenAI)
+	if b == nil {
+		t.Fatal("find(llm, openai) = nil")
+	}
+	if p.find("llm", providerFallback) != nil {
+		t.Error("find(llm, fallback) != nil without fallback configured")
+	}
+
+	b.RecordFailure(errors.New("boom"))
+	b.Reset()
+	if b.Snapshot().ConsecutiveFailures != 0 {
+		t.Error("Reset() did not clear failures")
+	}
+}
+
+func TestProviderRegistry_NilSafe(t *testing.T) {
+	var p *providerRegistry
+	if got := p.snapshots(); got == nil || len(got) != 0 {
+		t.Errorf("nil snapshots() = %v, want empty slice", got)
+	}
+	if p.find("llm", providerOpenAI) != nil {
+		t.Error("nil find() != nil")
+	}
+}
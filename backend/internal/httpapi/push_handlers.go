@@ -34,12 +34,12 @@ func (r *Router) handlePushRegister(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if err := r.store.RegisterPushToken(req.Context(), user.ID, body.Token, body.Platform); err != nil {
-		r.logger.Printf("push: failed to register token: %v", err)
+		r.logger.Error("push: failed to register token", "error", err)
 		http.Error(w, `{"error": "failed to register token"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("push: registered %s token for user %s", body.Platform, user.ID)
+	r.logger.Info("push: registered token", "platform", body.Platform, "user_id", user.ID)
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
 
@@ -66,11 +66,11 @@ func (r *Router) handlePushUnregister(w http.ResponseWriter, req *http.Request)
 	}
 
 	if err := r.store.UnregisterPushToken(req.Context(), body.Token); err != nil {
-		r.logger.Printf("push: failed to unregister token: %v", err)
+		r.logger.Error("push: failed to unregister token", "error", err)
 		http.Error(w, `{"error": "failed to unregister token"}`, http.StatusInternalServerError)
 		return
 	}
 
-	r.logger.Printf("push: unregistered token for user %s", user.ID)
+	r.logger.Info("push: unregistered token", "user_id", user.ID)
 	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
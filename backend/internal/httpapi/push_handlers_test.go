@@ -3,8 +3,6 @@ package httpapi
 import (
 	"context"
 	"encoding/json"
-	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -13,13 +11,14 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lukasbauer/karen/internal/logging"
 	"github.com/lukasbauer/karen/internal/store"
 )
 
 func TestHandlePushRegister(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	t.Run("unauthorized without auth", func(t *testing.T) {
@@ -107,7 +106,7 @@ func TestHandlePushRegister(t *testing.T) {
 func TestHandlePushUnregister(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	t.Run("unauthorized without auth", func(t *testing.T) {
@@ -184,7 +183,7 @@ func TestPushTokensIntegration(t *testing.T) {
 			JWTSecret: "test-secret-key",
 			JWTExpiry: 1 * time.Hour,
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		store:  s,
 	}
 
@@ -0,0 +1,189 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/redact"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// redactionResponse is the PII kinds the tenant redacts and the kinds
+// available.
+type redactionResponse struct {
+	Kinds     []redact.Kind `json:"kinds"`
+	Custom    bool          `json:"custom"` // false = default kinds
+	Available []redact.Kind `json:"available"`
+}
+
+// redactionAuditState is a tenant's redaction settings as recorded in the
+// audit log.
+type redactionAuditState struct {
+	Kinds  []redact.Kind `json:"kinds"`
+	Custom bool          `json:"custom"`
+}
+
+// loadRedactionKinds returns the kinds the tenant redacts and whether they
+// are custom (the defaults otherwise). Unknown stored kinds are skipped.
+func loadRedactionKinds(ctx context.Context, st *store.Store, tenantID string) ([]redact.Kind, bool, error) {
+	names, err := st.GetRedactionKinds(ctx, tenantID)
+	if err != nil {
+		return redact.DefaultKinds, false, err
+	}
+	if names == nil {
+		return redact.DefaultKinds, false, nil
+	}
+	kinds := []redact.Kind{}
+	for _, name := range names {
+		if k := redact.Kind(name); redact.ValidKind(k) {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds, true, nil
+}
+
+// loadRedaction sets up the redactor for the call. Calls without a tenant,
+// and calls whose settings fail to load, use the default kinds.
+func (s *callSession) loadRedaction() {
+	kinds := redact.DefaultKinds
+	if s.tenantCfg.TenantID != "" {
+		var err error
+		if kinds, _, err = loadRedactionKinds(s.ctx, s.store, s.tenantCfg.TenantID); err != nil {
+			s.logger.Error("media_ws: failed to load redaction settings", "error", err)
+			sentry.CaptureException(err)
+		}
+	}
+	s.redactor = redact.New(kinds)
+}
+
+// redact returns text with the tenant's PII kinds replaced, for storing in
+// utterances, call events and logs. The conversation itself keeps the
+// original text so the assistant can act on it.
+func (s *callSession) redact(text string) string {
+	return s.redactor.Redact(text)
+}
+
+// partialBargeInEvent is the call event data for a barge-in detected on an
+// interim transcript.
+func (s *callSession) partialBargeInEvent(partial string) map[string]any {
+	return map[string]any{
+		"partial_text":       s.redact(partial),
+		"agent_was_speaking": true,
+	}
+}
+
+// maxTurnTimeoutEvent is the call event data for a turn finalized because
+// speech_final didn't arrive in time.
+func (s *callSession) maxTurnTimeoutEvent(pending string) map[string]any {
+	return map[string]any{
+		"pending_text": s.redact(pending),
+	}
+}
+
+// redactScreeningResult redacts the free-text fields of an analysis result
+// before it is stored and pushed: the model reads the unredacted
+// conversation and may quote it.
+func redactScreeningResult(r *redact.Redactor, result *llm.ScreeningResult) {
+	result.IntentText = r.Redact(result.IntentText)
+	result.Summary = r.Redact(result.Summary)
+	for k, v := range result.Entities {
+		result.Entities[k] = r.Redact(v)
+	}
+	for i := range result.ActionItems {
+		result.ActionItems[i].Text = r.Redact(result.ActionItems[i].Text)
+	}
+}
+
+// handleGetRedaction returns the PII kinds redacted from the tenant's calls.
+func (r *Router) handleGetRedaction(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	kinds, custom, err := loadRedactionKinds(req.Context(), r.store, *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("redaction: failed to load settings", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to load redaction settings"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, redactionResponse{Kinds: kinds, Custom: custom, Available: redact.AllKinds})
+}
+
+// handleSetRedaction sets the PII kinds redacted from the tenant's new calls
+// (an empty list turns redaction off).
+func (r *Router) handleSetRedaction(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Kinds *[]string `json:"kinds"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Kinds == nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	kinds, err := redact.ParseKinds(*body.Kinds)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	before, custom, err := loadRedactionKinds(req.Context(), r.store, *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("redaction: failed to load settings", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save redaction settings"}`, http.StatusInternalServerError)
+		return
+	}
+
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = string(k)
+	}
+	if err := r.store.SetRedactionKinds(req.Context(), *authUser.TenantID, names, &authUser.ID); err != nil {
+		r.logger.Error("redaction: failed to save settings", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save redaction settings"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("redaction: saved settings", "tenant_id", *authUser.TenantID, "kinds", names)
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionRedactionUpdate),
+		redactionAuditState{before, custom}, redactionAuditState{kinds, true})
+	writeJSON(w, http.StatusOK, redactionResponse{Kinds: kinds, Custom: true, Available: redact.AllKinds})
+}
+
+// handleResetRedaction reverts the tenant to the default PII kinds.
+func (r *Router) handleResetRedaction(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	before, custom, err := loadRedactionKinds(req.Context(), r.store, *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("redaction: failed to load settings", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to reset redaction settings"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := r.store.DeleteRedactionKinds(req.Context(), *authUser.TenantID); err != nil {
+		r.logger.Error("redaction: failed to reset settings", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to reset redaction settings"}`, http.StatusInternalServerError)
+		return
+	}
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionRedactionReset),
+		redactionAuditState{before, custom}, redactionAuditState{redact.DefaultKinds, false})
+	writeJSON(w, http.StatusOK, redactionResponse{Kinds: redact.DefaultKinds, Custom: false, Available: redact.AllKinds})
+}
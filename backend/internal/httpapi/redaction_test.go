@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+	"github.com/lukasbauer/karen/internal/redact"
+)
+
+func TestRedactScreeningResult(t *testing.T) {
+	result := &llm.ScreeningResult{
+		IntentText:  "Chce vrátit platbu z karty 4111 1111 1111 1111",
+		Summary:     "Volající (rodné číslo 880101/1230) žádá o vrácení platby.",
+		Entities:    map[string]string{"name": "Jan Novák", "account": "19-2000145399/0800"},
+		ActionItems: []llm.ActionItem{{Text: "Vrátit peníze na účet 19-2000145399/0800"}},
+	}
+	redactScreeningResult(redact.New(redact.DefaultKinds), result)
+
+	if result.IntentText != "Chce vrátit platbu z karty [CARD]" {
+		t.Errorf("IntentText = %q", result.IntentText)
+	}
+	if !strings.Contains(result.Summary, "[BIRTH_NUMBER]") {
+		t.Errorf("Summary = %q", result.Summary)
+	}
+	if result.Entities["name"] != "Jan Novák" || result.Entities["account"] != "[BANK_ACCOUNT]" {
+		t.Errorf("Entities = %v", result.Entities)
+	}
+	if result.ActionItems[0].Text != "Vrátit peníze na účet [BANK_ACCOUNT]" {
+		t.Errorf("ActionItems = %v", result.ActionItems)
+	}
+}
+
+func TestCallSessionRedact_NoRedactor(t *testing.T) {
+	s := &callSession{}
+	if got := s.redact("4111 1111 1111 1111"); got != "4111 1111 1111 1111" {
+		t.Errorf("session without a redactor should keep the text, got %q", got)
+	}
+}
+
+func TestCallSessionEvents_Redacted(t *testing.T) {
+	s := &callSession{redactor: redact.New(redact.DefaultKinds)}
+	text := "Moje karta je 4111 1111 1111 1111"
+	want := "Moje karta je [CARD]"
+
+	if got := s.partialBargeInEvent(text)["partial_text"]; got != want {
+		t.Errorf("barge-in partial_text = %q, want %q", got, want)
+	}
+	if got := s.maxTurnTimeoutEvent(text)["pending_text"]; got != want {
+		t.Errorf("max turn timeout pending_text = %q, want %q", got, want)
+	}
+}
+
+func TestHandleSetRedaction_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{"kinds":[]}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"missing kinds", authCtx, `{}`, http.StatusBadRequest},
+		{"unknown kind", authCtx, `{"kinds":["card","passport"]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/tenant/redaction", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleSetRedaction(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
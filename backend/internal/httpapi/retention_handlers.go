@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/retention"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// retentionResponse is the tenant's retention override, the global defaults
+// and the resulting policy.
+type retentionResponse struct {
+	Policy    store.RetentionPolicy `json:"policy"`
+	Defaults  retention.Policy      `json:"defaults"`
+	Effective retention.Policy      `json:"effective"`
+}
+
+// handleGetRetention returns the tenant's data retention settings.
+func (r *Router) handleGetRetention(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	p, err := r.store.GetRetentionPolicy(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defaults := retention.Defaults(req.Context(), r.store)
+	writeJSON(w, http.StatusOK, retentionResponse{Policy: p, Defaults: defaults, Effective: retention.Resolve(defaults, p)})
+}
+
+// handleSetRetention replaces the tenant's retention overrides. Each field is
+// days (0 = forever) or null to use the global default.
+func (r *Router) handleSetRetention(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var p store.RetentionPolicy
+	if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if msg := validateRetentionPolicy(p); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	before, err := r.store.GetRetentionPolicy(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := r.store.SetRetentionPolicy(req.Context(), *authUser.TenantID, p, &authUser.ID); err != nil {
+		r.logger.Error("retention: failed to save policy", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save retention settings"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("retention: saved policy", "tenant_id", *authUser.TenantID)
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionRetentionUpdate), before, p)
+	defaults := retention.Defaults(req.Context(), r.store)
+	writeJSON(w, http.StatusOK, retentionResponse{Policy: p, Defaults: defaults, Effective: retention.Resolve(defaults, p)})
+}
+
+// validateRetentionPolicy returns an error message, or "" if every set field
+// is within range.
+func validateRetentionPolicy(p store.RetentionPolicy) string {
+	for _, f := range []struct {
+		name string
+		days *int
+	}{
+		{"transcript_days", p.TranscriptDays},
+		{"event_days", p.EventDays},
+		{"call_days", p.CallDays},
+	} {
+		if f.days != nil && !retention.ValidDays(*f.days) {
+			return fmt.Sprintf("%s must be between 0 and %d", f.name, retention.MaxDays)
+		}
+	}
+	return ""
+}
+
+// handleAdminRetentionPreview reports, per tenant, the retention policy in
+// effect and how much data the next purge would remove.
+func (r *Router) handleAdminRetentionPreview(w http.ResponseWriter, req *http.Request) {
+	preview, err := retention.NewPurger(r.store, r.logger).Preview(req.Context())
+	if err != nil {
+		r.logger.Error("admin: retention preview failed", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "database error"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tenants": preview})
+}
@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+)
+
+func TestHandleSetRetention_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"negative days", authCtx, `{"transcript_days":-1}`, http.StatusBadRequest},
+		{"too many days", authCtx, `{"call_days":3651}`, http.StatusBadRequest},
+		{"not a number", authCtx, `{"event_days":"30"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/tenant/retention", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleSetRetention(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidateGlobalConfigValue_Retention(t *testing.T) {
+	tests := []struct {
+		key, value string
+		valid      bool
+	}{
+		{"retention_call_days", "365", true},
+		{"retention_call_days", "0", true},
+		{"retention_transcript_days", "-5", false},
+		{"retention_event_days", "99999", false},
+		{"retention_event_days", "abc", false},
+	}
+	for _, tt := range tests {
+		if msg := validateGlobalConfigValue(tt.key, tt.value); (msg == "") != tt.valid {
+			t.Errorf("validateGlobalConfigValue(%q, %q) = %q, want valid=%v", tt.key, tt.value, msg, tt.valid)
+		}
+	}
+}
@@ -3,13 +3,14 @@ package httpapi
 import (
 	"crypto/subtle"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/metrics"
 	"github.com/lukasbauer/karen/internal/notifications"
 	"github.com/lukasbauer/karen/internal/store"
 )
@@ -27,6 +28,23 @@ type RouterConfig struct {
 	OpenAIAPIKey     string
 	ElevenLabsAPIKey string
 
+	// Secondary voice AI providers (optional). Used when the primary's circuit
+	// breaker is open. A secondary without its own API key reuses the primary's.
+	STTFallbackAPIKey  string
+	STTFallbackURL     string // Deepgram-compatible streaming endpoint
+	LLMFallbackAPIKey  string
+	LLMFallbackBaseURL string // OpenAI-compatible chat completions URL
+	LLMFallbackModel   string
+	TTSFallbackAPIKey  string
+	TTSFallbackBaseURL string // ElevenLabs-compatible text-to-speech URL
+	TTSFallbackModelID string
+
+	// Provider circuit breakers
+	ProviderBreakerFailures int           // Consecutive failures before a breaker opens
+	ProviderBreakerCooldown time.Duration // How long a breaker stays open before probing
+	LLMSlowFirstTokenMs     int           // First-token latency counted as a failure (0 = disabled)
+	TTSSlowFirstChunkMs     int           // First-chunk latency counted as a failure (0 = disabled)
+
 	// STT settings
 	STTEndpointingMs  int // Deepgram endpointing in ms (silence threshold)
 	STTUtteranceEndMs int // Hard timeout after last speech, regardless of noise
@@ -34,6 +52,7 @@ type RouterConfig struct {
 	// Voice settings (defaults, can be overridden by tenant)
 	GreetingText  string
 	TTSVoiceID    string
+	TTSModelID    string  // ElevenLabs model (default: eleven_flash_v2_5)
 	TTSStability  float64 // ElevenLabs voice stability (0.0-1.0)
 	TTSSimilarity float64 // ElevenLabs voice similarity boost (0.0-1.0)
 
@@ -59,20 +78,25 @@ type RouterConfig struct {
 
 	// AI Debug API (for Claude CLI remote debugging)
 	AIDebugAPIKey string // API key for AI debug endpoints
+
+	// Prometheus metrics
+	MetricsToken string // Bearer token required to scrape /metrics
+	Development  bool   // Local development: /metrics is open when MetricsToken is unset
 }
 
 type Router struct {
-	cfg      RouterConfig
-	logger   *log.Logger
-	store    *store.Store
-	eventLog *eventlog.Logger
-	discord  *notifications.Discord
-	apns     *notifications.APNsClient
-	calls    *CallRegistry
-	mux      *http.ServeMux
+	cfg       RouterConfig
+	logger    *slog.Logger
+	store     *store.Store
+	eventLog  *eventlog.Logger
+	discord   *notifications.Discord
+	apns      *notifications.APNsClient
+	calls     *CallRegistry
+	providers *providerRegistry
+	mux       *http.ServeMux
 }
 
-func NewRouter(cfg RouterConfig, logger *log.Logger, s *store.Store, eventLog *eventlog.Logger, calls *CallRegistry) http.Handler {
+func NewRouter(cfg RouterConfig, logger *slog.Logger, s *store.Store, eventLog *eventlog.Logger, calls *CallRegistry) http.Handler {
 	// Initialize APNs client (may be nil if not configured)
 	apnsClient, err := notifications.NewAPNsClient(notifications.APNsConfig{
 		KeyPath:    cfg.APNsKeyPath,
@@ -82,18 +106,23 @@ func NewRouter(cfg RouterConfig, logger *log.Logger, s *store.Store, eventLog *e
 		Production: cfg.APNsProduction,
 	}, logger)
 	if err != nil {
-		logger.Printf("Warning: APNs client initialization failed: %v", err)
+		logger.Error("APNs: client initialization failed", "error", err)
 	}
 
 	r := &Router{
-		cfg:      cfg,
-		logger:   logger,
-		store:    s,
-		eventLog: eventLog,
-		discord:  notifications.NewDiscord(cfg.DiscordWebhookURL, logger),
-		apns:     apnsClient,
-		calls:    calls,
-		mux:      http.NewServeMux(),
+		cfg:       cfg,
+		logger:    logger,
+		store:     s,
+		eventLog:  eventLog,
+		discord:   notifications.NewDiscord(cfg.DiscordWebhookURL, logger),
+		apns:      apnsClient,
+		calls:     calls,
+		providers: newProviderRegistry(cfg),
+		mux:       http.NewServeMux(),
+	}
+
+	if calls != nil {
+		metrics.SetActiveCallsSource(func() int { return int(calls.ActiveCount()) })
 	}
 
 	r.routes()
@@ -105,10 +134,14 @@ func (r *Router) routes() {
 	r.mux.HandleFunc("GET /healthz", r.handleHealthz)
 	r.mux.HandleFunc("GET /readyz", r.handleReadyz)
 
+	// Prometheus metrics (protected by METRICS_TOKEN outside development)
+	r.mux.Handle("GET /metrics", r.withMetricsToken(metrics.Handler()))
+
 	// Twilio webhooks (no auth - signature verified)
 	r.mux.HandleFunc("POST /telephony/inbound", r.handleTwilioInbound)
 	r.mux.HandleFunc("POST /telephony/status", r.handleTwilioStatus)
 	r.mux.HandleFunc("GET /media", r.handleMediaWS)
+	r.mux.HandleFunc("GET /media/softphone", r.handleSoftphoneWS)
 
 	// Auth endpoints (public)
 	r.mux.HandleFunc("POST /auth/send-code", r.handleSendCode)
@@ -116,17 +149,66 @@ func (r *Router) routes() {
 	r.mux.HandleFunc("POST /auth/refresh", r.handleRefreshToken)
 	r.mux.HandleFunc("POST /auth/logout", r.withAuth(r.handleLogout))
 
-	// Protected API endpoints
+	// Protected API endpoints. withAuth alone lets every member of the tenant
+	// in, viewers included; withRole requires at least the given role.
+	// withScope also accepts API tokens with the scope; the others only JWTs.
 	r.mux.HandleFunc("GET /api/me", r.withAuth(r.handleGetMe))
-	r.mux.HandleFunc("GET /api/calls", r.withAuth(r.handleListCalls))
-	r.mux.HandleFunc("GET /api/calls/unresolved-count", r.withAuth(r.handleGetUnresolvedCount))
-	r.mux.HandleFunc("GET /api/calls/", r.withAuth(r.handleGetCall))
-	r.mux.HandleFunc("PATCH /api/calls/", r.withAuth(r.handleCallPatch))
-	r.mux.HandleFunc("DELETE /api/calls/", r.withAuth(r.handleCallDelete))
-	r.mux.HandleFunc("GET /api/tenant", r.withAuth(r.handleGetTenant))
-	r.mux.HandleFunc("PATCH /api/tenant", r.withAuth(r.handleUpdateTenant))
+	r.mux.HandleFunc("GET /api/calls", r.withScope(store.ScopeCallsRead, store.RoleViewer, r.handleListCalls))
+	r.mux.HandleFunc("GET /api/calls/unresolved-count", r.withScope(store.ScopeCallsRead, store.RoleViewer, r.handleGetUnresolvedCount))
+	r.mux.HandleFunc("GET /api/calls/search", r.withScope(store.ScopeCallsRead, store.RoleViewer, r.handleSearchCalls))
+	r.mux.HandleFunc("GET /api/calls/export", r.withScope(store.ScopeCallsRead, store.RoleMember, r.handleExportCalls))
+	r.mux.HandleFunc("GET /api/calls/", r.withScope(store.ScopeCallsRead, store.RoleViewer, r.handleGetCall))
+	r.mux.HandleFunc("PATCH /api/calls/", r.withScope(store.ScopeCallsWrite, store.RoleMember, r.handleCallPatch))
+	r.mux.HandleFunc("DELETE /api/calls/", r.withScope(store.ScopeCallsWrite, store.RoleMember, r.handleCallDelete))
+	r.mux.HandleFunc("POST /api/calls/{id}/summary", r.withScope(store.ScopeCallsWrite, store.RoleMember, r.handleRegenerateCallSummary))
+	r.mux.HandleFunc("GET /api/tenant", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleGetTenant))
+	r.mux.HandleFunc("PATCH /api/tenant", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleUpdateTenant))
+	r.mux.HandleFunc("GET /api/tenant/prompt-versions", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleListPromptVersions))
+	r.mux.HandleFunc("GET /api/tenant/prompt-versions/diff", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleDiffPromptVersions))
+	r.mux.HandleFunc("POST /api/tenant/prompt-versions/{version}/rollback", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleRollbackPromptVersion))
+	r.mux.HandleFunc("POST /api/tenant/playground", r.withRole(store.RoleMember, r.handlePlayground))
+	r.mux.HandleFunc("POST /api/softphone/ticket", r.withRole(store.RoleMember, r.handleCreateSoftphoneTicket))
+	r.mux.HandleFunc("GET /api/tenant/call-fields", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleGetCallFields))
+	r.mux.HandleFunc("PUT /api/tenant/call-fields", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleSetCallFields))
+	r.mux.HandleFunc("GET /api/tenant/screening-taxonomy", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleGetScreeningTaxonomy))
+	r.mux.HandleFunc("PUT /api/tenant/screening-taxonomy", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleSetScreeningTaxonomy))
+	r.mux.HandleFunc("DELETE /api/tenant/screening-taxonomy", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleResetScreeningTaxonomy))
+	r.mux.HandleFunc("GET /api/tenant/retention", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleGetRetention))
+	r.mux.HandleFunc("PUT /api/tenant/retention", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleSetRetention))
+	r.mux.HandleFunc("GET /api/tenant/redaction", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleGetRedaction))
+	r.mux.HandleFunc("PUT /api/tenant/redaction", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleSetRedaction))
+	r.mux.HandleFunc("DELETE /api/tenant/redaction", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleResetRedaction))
+	r.mux.HandleFunc("GET /api/tenant/encryption", r.withScope(store.ScopeSettingsRead, store.RoleViewer, r.handleGetEncryption))
+	r.mux.HandleFunc("PUT /api/tenant/encryption", r.withScope(store.ScopeSettingsWrite, store.RoleOwner, r.handleSetEncryption))
+	r.mux.HandleFunc("POST /api/privacy/export", r.withRole(store.RoleOwner, r.handleSubjectExport))
+	r.mux.HandleFunc("POST /api/privacy/erase", r.withRole(store.RoleOwner, r.handleSubjectErase))
+	r.mux.HandleFunc("GET /api/privacy/requests", r.withRole(store.RoleOwner, r.handleListSubjectRequests))
+	r.mux.HandleFunc("GET /api/tenant/audit", r.withRole(store.RoleOwner, r.handleListTenantAudit))
+	r.mux.HandleFunc("GET /api/knowledge", r.withScope(store.ScopeKnowledgeRead, store.RoleViewer, r.handleListKnowledge))
+	r.mux.HandleFunc("POST /api/knowledge", r.withScope(store.ScopeKnowledgeWrite, store.RoleMember, r.handleCreateKnowledge))
+	r.mux.HandleFunc("GET /api/knowledge/search", r.withScope(store.ScopeKnowledgeRead, store.RoleViewer, r.handleSearchKnowledge))
+	r.mux.HandleFunc("PUT /api/knowledge/{id}", r.withScope(store.ScopeKnowledgeWrite, store.RoleMember, r.handleUpdateKnowledge))
+	r.mux.HandleFunc("DELETE /api/knowledge/{id}", r.withScope(store.ScopeKnowledgeWrite, store.RoleMember, r.handleDeleteKnowledge))
 	r.mux.HandleFunc("GET /api/billing", r.withAuth(r.handleGetBilling))
 
+	// Tenant members and invitations (protected)
+	r.mux.HandleFunc("GET /api/tenant/members", r.withAuth(r.handleListMembers))
+	r.mux.HandleFunc("PATCH /api/tenant/members/{userId}", r.withRole(store.RoleOwner, r.handleUpdateMember))
+	r.mux.HandleFunc("DELETE /api/tenant/members/{userId}", r.withAuth(r.handleRemoveMember)) // Owners, or members leaving
+	r.mux.HandleFunc("GET /api/tenant/invitations", r.withRole(store.RoleOwner, r.handleListInvitations))
+	r.mux.HandleFunc("POST /api/tenant/invitations", r.withRole(store.RoleOwner, r.handleCreateInvitation))
+	r.mux.HandleFunc("DELETE /api/tenant/invitations/{id}", r.withRole(store.RoleOwner, r.handleRevokeInvitation))
+	r.mux.HandleFunc("GET /api/invitations", r.withAuth(r.handleListMyInvitations))
+	r.mux.HandleFunc("POST /api/invitations/{id}/accept", r.withAuth(r.handleAcceptInvitation))
+	r.mux.HandleFunc("POST /api/invitations/{id}/decline", r.withAuth(r.handleDeclineInvitation))
+	r.mux.HandleFunc("GET /api/me/settings", r.withAuth(r.handleGetMemberSettings))
+	r.mux.HandleFunc("PATCH /api/me/settings", r.withAuth(r.handleUpdateMemberSettings))
+
+	// Personal API tokens (protected, JWT only)
+	r.mux.HandleFunc("GET /api/tokens", r.withAuth(r.handleListAPITokens))
+	r.mux.HandleFunc("POST /api/tokens", r.withAuth(r.handleCreateAPIToken))
+	r.mux.HandleFunc("DELETE /api/tokens/{id}", r.withAuth(r.handleRevokeAPIToken))
+
 	// Onboarding (protected)
 	r.mux.HandleFunc("POST /api/onboarding/complete", r.withAuth(r.handleCompleteOnboarding))
 
@@ -135,12 +217,12 @@ func (r *Router) routes() {
 	r.mux.HandleFunc("POST /api/push/unregister", r.withAuth(r.handlePushUnregister))
 
 	// Billing endpoints (protected)
-	r.mux.HandleFunc("POST /api/billing/checkout", r.withAuth(r.handleCreateCheckout))
-	r.mux.HandleFunc("POST /api/billing/portal", r.withAuth(r.handleCreatePortal))
+	r.mux.HandleFunc("POST /api/billing/checkout", r.withRole(store.RoleOwner, r.handleCreateCheckout))
+	r.mux.HandleFunc("POST /api/billing/portal", r.withRole(store.RoleOwner, r.handleCreatePortal))
 
 	// Voice selection endpoints (protected)
 	r.mux.HandleFunc("GET /api/voices", r.withAuth(r.handleListVoices))
-	r.mux.HandleFunc("POST /api/voices/preview", r.withAuth(r.handlePreviewVoice))
+	r.mux.HandleFunc("POST /api/voices/preview", r.withRole(store.RoleMember, r.handlePreviewVoice))
 
 	// Stripe webhook (no auth - signature verified)
 	r.mux.HandleFunc("POST /webhooks/stripe", r.handleStripeWebhook)
@@ -169,6 +251,21 @@ func (r *Router) routes() {
 	// Global config (admin only)
 	r.mux.HandleFunc("GET /admin/config", r.withAdmin(r.handleAdminListGlobalConfig))
 	r.mux.HandleFunc("PATCH /admin/config/{key}", r.withAdmin(r.handleAdminUpdateGlobalConfig))
+	r.mux.HandleFunc("GET /admin/retention/preview", r.withAdmin(r.handleAdminRetentionPreview))
+	r.mux.HandleFunc("POST /admin/privacy/export", r.withAdmin(r.handleAdminSubjectExport))
+	r.mux.HandleFunc("POST /admin/privacy/erase", r.withAdmin(r.handleAdminSubjectErase))
+	r.mux.HandleFunc("GET /admin/privacy/requests", r.withAdmin(r.handleAdminListSubjectRequests))
+	r.mux.HandleFunc("GET /admin/audit", r.withAdmin(r.handleAdminListAudit))
+
+	// A/B experiments (admin only)
+	r.mux.HandleFunc("GET /admin/experiments", r.withAdmin(r.handleAdminListExperiments))
+	r.mux.HandleFunc("POST /admin/experiments", r.withAdmin(r.handleAdminCreateExperiment))
+	r.mux.HandleFunc("PATCH /admin/experiments/{id}", r.withAdmin(r.handleAdminUpdateExperiment))
+	r.mux.HandleFunc("GET /admin/experiments/{id}/results", r.withAdmin(r.handleAdminGetExperimentResults))
+
+	// Voice AI provider circuit breakers (admin only)
+	r.mux.HandleFunc("GET /admin/providers", r.withAdmin(r.handleAdminListProviders))
+	r.mux.HandleFunc("POST /admin/providers/{kind}/{name}/reset", r.withAdmin(r.handleAdminResetProvider))
 
 	// AI Debug API (for Claude CLI remote debugging)
 	r.mux.HandleFunc("GET /ai/health", r.handleAIHealth) // Unauthenticated health check
@@ -219,7 +316,7 @@ func withSentryRecovery(next http.Handler) http.Handler {
 func withCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PATCH,DELETE,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,X-API-Key")
 		if req.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
@@ -266,6 +363,27 @@ func (r *Router) withAIKey(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withMetricsToken requires "Authorization: Bearer <token>". Without a
+// configured token, /metrics is only served in development; deployments
+// scrape it with the token or on the internal METRICS_ADDR listener.
+func (r *Router) withMetricsToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if r.cfg.MetricsToken == "" {
+			if !r.cfg.Development {
+				http.NotFound(w, req)
+				return
+			}
+		} else {
+			token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(r.cfg.MetricsToken)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
 func nowUTC() time.Time { return time.Now().UTC() }
 
 // captureError sends an error to Sentry with request context
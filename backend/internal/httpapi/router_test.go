@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithMetricsToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		token       string
+		development bool
+		authHeader  string
+		wantStatus  int
+	}{
+		{"no token in development", "", true, "", http.StatusOK},
+		{"no token in production", "", false, "", http.StatusNotFound},
+		{"no token in production with header", "", false, "Bearer ", http.StatusNotFound},
+		{"valid token", "secret", false, "Bearer secret", http.StatusOK},
+		{"missing header", "secret", false, "", http.StatusUnauthorized},
+		{"wrong token", "secret", true, "Bearer nope", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Router{cfg: RouterConfig{MetricsToken: tt.token, Development: tt.development}}
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+
+			r.withMetricsToken(ok).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWithCORS_PreflightAllowsRouteMethods(t *testing.T) {
+	h := withCORS(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("preflight should not reach the handler")
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/tenant/retention", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	allowed := strings.Split(rec.Header().Get("Access-Control-Allow-Methods"), ",")
+	for _, m := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		if !slices.Contains(allowed, m) {
+			t.Errorf("Access-Control-Allow-Methods = %v, missing %s", allowed, m)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/metrics"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// softphoneTicketTTL is how long a softphone ticket can be used to open the
+// websocket.
+const softphoneTicketTTL = 30 * time.Second
+
+// Softphone media formats (values of the start message's mediaFormat.encoding).
+const (
+	softphoneEncodingMuLaw = "audio/x-mulaw" // μ-law at 8 kHz, as sent by Twilio
+	softphoneEncodingL16   = "audio/x-l16"   // PCM16 little-endian at 16 kHz
+)
+
+// softphoneCall is a browser test call. The browser speaks the Twilio media
+// stream protocol (connected/start/media/mark/stop); the call SID and tenant
+// config come from the server, not from the browser's start message.
+type softphoneCall struct {
+	callSid      string
+	tenantID     string
+	tenantConfig string // JSON, as passed in the Twilio stream parameters
+}
+
+// applyStart replaces the call identity in the browser's start message and
+// validates the requested media format.
+func (c *softphoneCall) applyStart(start *twilioStart) error {
+	switch start.MediaFormat.Encoding {
+	case "", softphoneEncodingMuLaw:
+		if start.MediaFormat.SampleRate != 0 && start.MediaFormat.SampleRate != 8000 {
+			return fmt.Errorf("unsupported μ-law sample rate %d (want 8000)", start.MediaFormat.SampleRate)
+		}
+		start.MediaFormat.Encoding = softphoneEncodingMuLaw
+	case softphoneEncodingL16:
+		if start.MediaFormat.SampleRate != 16000 {
+			return fmt.Errorf("unsupported PCM16 sample rate %d (want 16000)", start.MediaFormat.SampleRate)
+		}
+	default:
+		return fmt.Errorf("unsupported media encoding %q", start.MediaFormat.Encoding)
+	}
+
+	start.CallSid = c.callSid
+	start.AccountSid = ""
+	start.CustomParams = map[string]string{
+		"callSid":      c.callSid,
+		"tenantId":     c.tenantID,
+		"tenantConfig": c.tenantConfig,
+	}
+	return nil
+}
+
+// handleCreateSoftphoneTicket issues a single-use ticket for opening the
+// softphone websocket. Browsers can't set headers on websockets, and the JWT
+// must not end up in URLs.
+func (r *Router) handleCreateSoftphoneTicket(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	ticket, err := newSoftphoneTicket()
+	if err != nil {
+		r.logger.Error("softphone: failed to generate ticket", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to create ticket"}`, http.StatusInternalServerError)
+		return
+	}
+	expiresAt := time.Now().Add(softphoneTicketTTL)
+	if err := r.store.CreateSoftphoneTicket(req.Context(), authUser.ID, hashToken(ticket), expiresAt); err != nil {
+		r.logger.Error("softphone: failed to store ticket", "user_id", authUser.ID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to create ticket"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"ticket": ticket, "expires_at": expiresAt.UTC()})
+}
+
+// authenticateSoftphoneTicket redeems a softphone ticket. It returns the
+// ticket's user, or nil and the error message for the 401 response.
+func (r *Router) authenticateSoftphoneTicket(ctx context.Context, ticket string) (*AuthUser, string) {
+	user, err := r.store.RedeemSoftphoneTicket(ctx, hashToken(ticket))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "invalid, expired or used ticket"
+	}
+	if err != nil {
+		r.logger.Error("softphone: failed to redeem ticket", "error", err)
+		sentry.CaptureException(err)
+		return nil, "failed to check ticket"
+	}
+	return &AuthUser{
+		ID:       user.ID,
+		TenantID: user.TenantID,
+		Phone:    user.Phone,
+		Role:     user.Role,
+	}, ""
+}
+
+// handleSoftphoneWS runs a voice test call from the browser against the
+// user's tenant config. The websocket is opened with a ticket from
+// handleCreateSoftphoneTicket as the ticket query parameter. The call is
+// tagged "test" and is excluded from usage and billing.
+func (r *Router) handleSoftphoneWS(w http.ResponseWriter, req *http.Request) {
+	if r.calls.IsDraining() {
+		r.logger.Warn("softphone: rejecting new call, server is draining")
+		http.Error(w, "server is draining", http.StatusServiceUnavailable)
+		return
+	}
+
+	ticket := req.URL.Query().Get("ticket")
+	if ticket == "" {
+		http.Error(w, `{"error": "missing ticket"}`, http.StatusUnauthorized)
+		return
+	}
+	authUser, errMsg := r.authenticateSoftphoneTicket(req.Context(), ticket)
+	if authUser == nil {
+		http.Error(w, `{"error": "`+errMsg+`"}`, http.StatusUnauthorized)
+		return
+	}
+	if authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+	if !store.RoleAtLeast(authUser.Role, store.RoleMember) {
+		http.Error(w, `{"error": "member role required"}`, http.StatusForbidden)
+		return
+	}
+
+	if r.cfg.DeepgramAPIKey == "" || r.cfg.OpenAIAPIKey == "" || r.cfg.ElevenLabsAPIKey == "" {
+		r.logger.Warn("softphone: missing API keys")
+		http.Error(w, "voice AI not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	tenant, err := r.store.GetTenantByID(req.Context(), *authUser.TenantID)
+	if err != nil {
+		http.Error(w, `{"error": "tenant not found"}`, http.StatusNotFound)
+		return
+	}
+
+	callSid, err := newSoftphoneCallSid()
+	if err != nil {
+		r.logger.Error("softphone: failed to generate call SID", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to start call"}`, http.StatusInternalServerError)
+		return
+	}
+
+	var promptVersion *int
+	if tenant.PromptVersion > 0 {
+		promptVersion = &tenant.PromptVersion
+	}
+	// Stored under the twilio provider: the session speaks the Twilio media
+	// protocol and call lookups are keyed by it. The SP prefix and the test
+	// tag tell the calls apart.
+	if err := r.store.UpsertCallWithTenant(req.Context(), store.Call{
+		TenantID:       &tenant.ID,
+		Provider:       "twilio",
+		ProviderCallID: callSid,
+		FromNumber:     authUser.Phone,
+		ToNumber:       "softphone",
+		Status:         "in_progress",
+		StartedAt:      nowUTC(),
+		PromptVersion:  promptVersion,
+		Tags:           []string{store.CallTagTest},
+	}); err != nil {
+		r.logger.Error("softphone: failed to create call", "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to start call"}`, http.StatusInternalServerError)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		r.logger.Error("softphone: upgrade failed", "error", err)
+		sentry.CaptureException(err)
+		return
+	}
+
+	r.logger.Info("softphone: test call connected", "call_sid", callSid, "tenant_id", tenant.ID, "user_id", authUser.ID)
+	r.runCallSession(req, conn, &softphoneCall{
+		callSid:      callSid,
+		tenantID:     tenant.ID,
+		tenantConfig: r.tenantCallConfigJSON(req.Context(), tenant),
+	})
+}
+
+// newSoftphoneTicket returns a random softphone ticket.
+func newSoftphoneTicket() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newSoftphoneCallSid returns a random call SID for a softphone call
+// ("SP" + 32 hex digits, the shape of a Twilio call SID).
+func newSoftphoneCallSid() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "SP" + hex.EncodeToString(b), nil
+}
+
+// twilioStop is sent to the softphone when the agent ends the call.
+type twilioStop struct {
+	Event     string `json:"event"`
+	StreamSid string `json:"streamSid"`
+}
+
+// endSoftphoneCall ends a softphone call from the agent side: there is no
+// Twilio call to hang up, so the browser is sent a stop event and the
+// websocket is closed (run exits on the read error and cleans up).
+func (s *callSession) endSoftphoneCall() {
+	s.agentHungUp = true
+	metrics.Hangups.WithLabelValues("agent").Inc()
+
+	if s.callID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.store.UpdateCallStatus(ctx, s.callSid, "completed", time.Now().UTC()); err != nil {
+			s.logger.Error("media_ws: failed to update call status", "error", err)
+			sentry.CaptureException(err)
+		}
+		if err := s.store.UpdateCallEndedBy(ctx, s.callSid, "agent"); err != nil {
+			s.logger.Error("media_ws: failed to update ended_by", "error", err)
+			sentry.CaptureException(err)
+		}
+	}
+	s.eventLog.LogAsync(s.callID, eventlog.EventCallHangup, map[string]any{
+		"initiated_by": "agent",
+		"success":      true,
+	})
+
+	s.connMu.Lock()
+	_ = s.conn.WriteJSON(twilioStop{Event: "stop", StreamSid: s.streamSid})
+	s.conn.Close()
+	s.connMu.Unlock()
+	s.logger.Info("media_ws: softphone call ended (agent initiated)")
+}
+
+// linearToMuLaw encodes a 16-bit linear sample as G.711 μ-law.
+func linearToMuLaw(sample int16) byte {
+	const (
+		bias = 0x84
+		clip = 32635
+	)
+	v := int(sample)
+	sign := 0
+	if v < 0 {
+		v = -v
+		sign = 0x80
+	}
+	if v > clip {
+		v = clip
+	}
+	v += bias
+
+	exponent := 7
+	for mask := 0x4000; v&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := (v >> (exponent + 3)) & 0x0F
+	return ^byte(sign | exponent<<4 | mantissa)
+}
+
+// pcm16ToMuLaw8k converts PCM16 little-endian audio at 16 kHz to μ-law at
+// 8 kHz. Sample pairs are averaged, which also low-passes before decimation.
+func pcm16ToMuLaw8k(pcm []byte) []byte {
+	n := len(pcm) / 2
+	out := make([]byte, 0, (n+1)/2)
+	for i := 0; i < n; i += 2 {
+		a := int(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+		if i+1 < n {
+			b := int(int16(binary.LittleEndian.Uint16(pcm[2*i+2:])))
+			a = (a + b) / 2
+		}
+		out = append(out, linearToMuLaw(int16(a)))
+	}
+	return out
+}
+
+// muLaw8kToPCM16 converts μ-law audio at 8 kHz to PCM16 little-endian at
+// 16 kHz, interpolating a sample between each pair.
+func muLaw8kToPCM16(muLaw []byte) []byte {
+	out := make([]byte, 4*len(muLaw))
+	for i, b := range muLaw {
+		cur := muLawToLinear(b)
+		next := cur
+		if i+1 < len(muLaw) {
+			next = muLawToLinear(muLaw[i+1])
+		}
+		mid := int16((int(cur) + int(next)) / 2)
+		binary.LittleEndian.PutUint16(out[4*i:], uint16(cur))
+		binary.LittleEndian.PutUint16(out[4*i+2:], uint16(mid))
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
+)
+
+func TestSoftphoneCall_ApplyStart(t *testing.T) {
+	call := &softphoneCall{callSid: "SP123", tenantID: "tenant-1", tenantConfig: `{"language":"cs"}`}
+
+	tests := []struct {
+		name       string
+		encoding   string
+		sampleRate int
+		wantErr    bool
+	}{
+		{"default format", "", 0, false},
+		{"mulaw 8k", softphoneEncodingMuLaw, 8000, false},
+		{"pcm16 16k", softphoneEncodingL16, 16000, false},
+		{"mulaw 16k", softphoneEncodingMuLaw, 16000, true},
+		{"pcm16 8k", softphoneEncodingL16, 8000, true},
+		{"opus", "audio/opus", 48000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := &twilioStart{
+				CallSid:      "CAbrowser",
+				AccountSid:   "ACbrowser",
+				CustomParams: map[string]string{"tenantId": "other-tenant"},
+			}
+			start.MediaFormat.Encoding = tt.encoding
+			start.MediaFormat.SampleRate = tt.sampleRate
+
+			err := call.applyStart(start)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyStart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if start.CallSid != "SP123" || start.AccountSid != "" {
+				t.Errorf("call identity not replaced: %+v", start)
+			}
+			if start.CustomParams["tenantId"] != "tenant-1" || start.CustomParams["tenantConfig"] != `{"language":"cs"}` {
+				t.Errorf("custom params not replaced: %v", start.CustomParams)
+			}
+			if start.MediaFormat.Encoding == "" {
+				t.Error("expected encoding to be normalized")
+			}
+		})
+	}
+}
+
+func TestNewSoftphoneCallSid(t *testing.T) {
+	a, err := newSoftphoneCallSid()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := newSoftphoneCallSid()
+	if !strings.HasPrefix(a, "SP") || len(a) != 34 || a == b {
+		t.Errorf("unexpected call SIDs %q, %q", a, b)
+	}
+}
+
+func TestLinearToMuLaw_RoundTrip(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		b := byte(i)
+		if b == 0x7F {
+			continue // negative zero encodes as positive zero
+		}
+		if got := linearToMuLaw(muLawToLinear(b)); got != b {
+			t.Errorf("round trip of 0x%02X = 0x%02X", b, got)
+		}
+	}
+	if linearToMuLaw(32767) != 0x80 || linearToMuLaw(-32768) != 0x00 {
+		t.Error("expected clipping to the extreme codes")
+	}
+}
+
+func TestSoftphoneResampling(t *testing.T) {
+	// 4 samples at 16 kHz -> 2 samples at 8 kHz (pair averages)
+	pcm := make([]byte, 8)
+	for i, v := range []int16{1000, 3000, -2000, -4000} {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(v))
+	}
+	mu := pcm16ToMuLaw8k(pcm)
+	if len(mu) != 2 {
+		t.Fatalf("expected 2 μ-law samples, got %d", len(mu))
+	}
+	if mu[0] != linearToMuLaw(2000) || mu[1] != linearToMuLaw(-3000) {
+		t.Errorf("unexpected μ-law samples %v", mu)
+	}
+
+	// 2 samples at 8 kHz -> 4 samples at 16 kHz with interpolation
+	out := muLaw8kToPCM16([]byte{linearToMuLaw(1000), linearToMuLaw(3000)})
+	if len(out) != 8 {
+		t.Fatalf("expected 8 bytes, got %d", len(out))
+	}
+	samples := make([]int16, 4)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(out[2*i:]))
+	}
+	first, second := muLawToLinear(linearToMuLaw(1000)), muLawToLinear(linearToMuLaw(3000))
+	if samples[0] != first || samples[2] != second || samples[1] != (first+second)/2 || samples[3] != second {
+		t.Errorf("unexpected PCM16 samples %v", samples)
+	}
+
+	// Odd trailing sample is kept
+	if got := pcm16ToMuLaw8k(pcm[:6]); len(got) != 2 {
+		t.Errorf("expected 2 samples for odd input, got %d", len(got))
+	}
+}
+
+func TestHandleSoftphoneWS_Auth(t *testing.T) {
+	r := &Router{logger: logging.Discard(), calls: NewCallRegistry(), cfg: RouterConfig{JWTSecret: "test-secret-key"}}
+
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"missing ticket", "/media/softphone"},
+		{"JWT instead of ticket", "/media/softphone?token=not-a-ticket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.handleSoftphoneWS(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", rec.Code)
+			}
+		})
+	}
+}
+
+func TestNewSoftphoneTicket(t *testing.T) {
+	a, err := newSoftphoneTicket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := newSoftphoneTicket()
+	if len(a) != 43 || a == b {
+		t.Errorf("unexpected tickets %q, %q", a, b)
+	}
+}
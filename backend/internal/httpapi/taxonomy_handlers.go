@@ -0,0 +1,140 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// screeningTaxonomyResponse is the tenant's effective taxonomy and the
+// analysis prompt built from it.
+type screeningTaxonomyResponse struct {
+	Taxonomy       llm.Taxonomy `json:"taxonomy"`
+	Custom         bool         `json:"custom"` // false = built-in labels
+	AnalysisPrompt string       `json:"analysis_prompt"`
+}
+
+// loadScreeningTaxonomy returns the tenant's taxonomy and whether it is
+// custom (the built-in one otherwise).
+func loadScreeningTaxonomy(ctx context.Context, st *store.Store, tenantID string) (llm.Taxonomy, bool, error) {
+	t, err := st.GetScreeningTaxonomy(ctx, tenantID)
+	if err != nil {
+		return llm.DefaultTaxonomy(), false, err
+	}
+	if t == nil {
+		return llm.DefaultTaxonomy(), false, nil
+	}
+	return *t, true, nil
+}
+
+// analysisContext returns ctx carrying the analysis prompt of a custom
+// taxonomy; the built-in taxonomy uses the client's default prompt.
+func analysisContext(ctx context.Context, t llm.Taxonomy, custom bool) context.Context {
+	if !custom {
+		return ctx
+	}
+	return llm.WithAnalysisPrompt(ctx, t.AnalysisPrompt())
+}
+
+// taxonomyAuditState is a tenant's taxonomy as recorded in the audit log.
+type taxonomyAuditState struct {
+	Taxonomy llm.Taxonomy `json:"taxonomy"`
+	Custom   bool         `json:"custom"`
+}
+
+func newScreeningTaxonomyResponse(t llm.Taxonomy, custom bool) screeningTaxonomyResponse {
+	prompt := llm.AnalysisPromptCzech
+	if custom {
+		prompt = t.AnalysisPrompt()
+	}
+	return screeningTaxonomyResponse{Taxonomy: t, Custom: custom, AnalysisPrompt: prompt}
+}
+
+// handleGetScreeningTaxonomy returns the labels calls are classified into.
+func (r *Router) handleGetScreeningTaxonomy(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	t, custom, err := loadScreeningTaxonomy(req.Context(), r.store, *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("taxonomy: failed to load", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to load screening labels"}`, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newScreeningTaxonomyResponse(t, custom))
+}
+
+// handleSetScreeningTaxonomy replaces the tenant's label sets. Applies to
+// calls analyzed from now on; stored results keep their labels.
+func (r *Router) handleSetScreeningTaxonomy(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	var t llm.Taxonomy
+	if err := json.NewDecoder(req.Body).Decode(&t); err != nil {
+		http.Error(w, `{"error": "invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if err := t.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	before, custom, err := loadScreeningTaxonomy(req.Context(), r.store, *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("taxonomy: failed to load", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save screening labels"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := r.store.SetScreeningTaxonomy(req.Context(), *authUser.TenantID, t, &authUser.ID); err != nil {
+		r.logger.Error("taxonomy: failed to save", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to save screening labels"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.logger.Info("taxonomy: saved custom screening labels", "tenant_id", *authUser.TenantID)
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionScreeningTaxonomyUpdate),
+		taxonomyAuditState{before, custom}, taxonomyAuditState{t, true})
+	writeJSON(w, http.StatusOK, newScreeningTaxonomyResponse(t, true))
+}
+
+// handleResetScreeningTaxonomy reverts the tenant to the built-in labels.
+func (r *Router) handleResetScreeningTaxonomy(w http.ResponseWriter, req *http.Request) {
+	authUser := getAuthUser(req.Context())
+	if authUser == nil || authUser.TenantID == nil {
+		http.Error(w, `{"error": "no tenant assigned"}`, http.StatusNotFound)
+		return
+	}
+
+	before, custom, err := loadScreeningTaxonomy(req.Context(), r.store, *authUser.TenantID)
+	if err != nil {
+		r.logger.Error("taxonomy: failed to load", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to reset screening labels"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := r.store.DeleteScreeningTaxonomy(req.Context(), *authUser.TenantID); err != nil {
+		r.logger.Error("taxonomy: failed to reset", "tenant_id", *authUser.TenantID, "error", err)
+		sentry.CaptureException(err)
+		http.Error(w, `{"error": "failed to reset screening labels"}`, http.StatusInternalServerError)
+		return
+	}
+
+	r.recordAudit(req, tenantAuditEntry(authUser, store.AuditActionScreeningTaxonomyReset),
+		taxonomyAuditState{before, custom}, taxonomyAuditState{llm.DefaultTaxonomy(), false})
+	writeJSON(w, http.StatusOK, newScreeningTaxonomyResponse(llm.DefaultTaxonomy(), false))
+}
@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/logging"
+)
+
+func TestNewScreeningTaxonomyResponse(t *testing.T) {
+	res := newScreeningTaxonomyResponse(llm.DefaultTaxonomy(), false)
+	if res.Custom || res.AnalysisPrompt != llm.AnalysisPromptCzech {
+		t.Errorf("built-in taxonomy should report the default analysis prompt, got %+v", res)
+	}
+
+	custom := llm.Taxonomy{
+		Legitimacy: []llm.Label{{Name: "pacient"}},
+		Lead:       []llm.Label{{Name: "akutní"}},
+		Intent:     []llm.Label{{Name: "prohlídka"}},
+	}
+	res = newScreeningTaxonomyResponse(custom, true)
+	if !res.Custom || !strings.Contains(res.AnalysisPrompt, `"legitimacy_label": "pacient"`) {
+		t.Errorf("unexpected custom response %+v", res)
+	}
+}
+
+func TestHandleSetScreeningTaxonomy_Validation(t *testing.T) {
+	r := &Router{logger: logging.Discard()}
+	tenantID := "tenant-1"
+	authCtx := context.WithValue(context.Background(), userContextKey, &AuthUser{ID: "user-1", TenantID: &tenantID})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		body string
+		want int
+	}{
+		{"no tenant", context.Background(), `{}`, http.StatusNotFound},
+		{"invalid body", authCtx, `{`, http.StatusBadRequest},
+		{"empty sets", authCtx, `{}`, http.StatusBadRequest},
+		{"duplicate label", authCtx, `{"legitimacy_labels":[{"name":"a"},{"name":"A"}],"lead_labels":[{"name":"b"}],"intent_categories":[{"name":"c"}]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/tenant/screening-taxonomy", strings.NewReader(tt.body)).WithContext(tt.ctx)
+			rec := httptest.NewRecorder()
+
+			r.handleSetScreeningTaxonomy(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"net/http"
@@ -44,7 +45,7 @@ type twimlParameter struct {
 func (r *Router) handleTwilioInbound(w http.ResponseWriter, req *http.Request) {
 	// Reject new calls during graceful shutdown (draining)
 	if r.calls.IsDraining() {
-		r.logger.Printf("inbound: rejecting call, server is draining")
+		r.logger.Warn("inbound: rejecting call, server is draining")
 		resp := twimlResponse{Reject: &twimlReject{Reason: "busy"}}
 		out, _ := xml.MarshalIndent(resp, "", "  ")
 		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
@@ -81,21 +82,24 @@ func (r *Router) handleTwilioInbound(w http.ResponseWriter, req *http.Request) {
 		tenant, _ = r.store.GetTenantByForwardingSource(req.Context(), forwardedFrom)
 	}
 
-	// Determine tenant ID for the call record
+	// Determine tenant ID (and the prompt version the call is answered with) for the call record
 	var tenantID *string
+	var promptVersion *int
 	if tenant != nil {
 		tenantID = &tenant.ID
-		r.logger.Printf("inbound: call %s routed to tenant %s (%s)", callSid, tenant.ID, tenant.Name)
+		if tenant.PromptVersion > 0 {
+			promptVersion = &tenant.PromptVersion
+		}
+		r.logger.Info("inbound: call routed", "call_sid", callSid, "tenant_id", tenant.ID, "tenant_name", tenant.Name)
 	} else {
-		r.logger.Printf("inbound: call %s has no tenant (to=%s, forwardedFrom=%s)", callSid, to, forwardedFrom)
+		r.logger.Info("inbound: call has no tenant", "call_sid", callSid, "to", to, "forwarded_from", forwardedFrom)
 	}
 
 	// Check if tenant has exceeded their call limit (trial expired or limit reached)
 	if tenant != nil {
 		callStatus := store.CanTenantReceiveCalls(tenant)
 		if !callStatus.CanReceive {
-			r.logger.Printf("inbound: call %s rejected for tenant %s: %s (calls: %d/%d)",
-				callSid, tenant.ID, callStatus.Reason, callStatus.CallsUsed, callStatus.CallsLimit)
+			r.logger.Warn("inbound: call rejected", "call_sid", callSid, "tenant_id", tenant.ID, "reason", callStatus.Reason, "calls", callStatus.CallsUsed, "calls_limit", callStatus.CallsLimit)
 
 			// Store call record as rejected with specific reason
 			rejectionReason := callStatus.Reason
@@ -109,7 +113,7 @@ func (r *Router) handleTwilioInbound(w http.ResponseWriter, req *http.Request) {
 				RejectionReason: &rejectionReason,
 				StartedAt:       nowUTC(),
 			}); err != nil {
-				r.logger.Printf("inbound: failed to save rejected call record for %s: %v", callSid, err)
+				r.logger.Error("inbound: failed to save rejected call record", "call_sid", callSid, "error", err)
 			}
 
 			// Return TwiML that simply hangs up (don't answer, let it ring through to voicemail)
@@ -132,6 +136,7 @@ func (r *Router) handleTwilioInbound(w http.ResponseWriter, req *http.Request) {
 		ToNumber:       to,
 		Status:         "in_progress",
 		StartedAt:      nowUTC(),
+		PromptVersion:  promptVersion,
 	})
 
 	// Start a media stream to our websocket.
@@ -147,23 +152,9 @@ func (r *Router) handleTwilioInbound(w http.ResponseWriter, req *http.Request) {
 	if tenant != nil {
 		params = append(params, twimlParameter{Name: "tenantId", Value: tenant.ID})
 
-		// Get tenant owner's phone number for call forwarding
-		ownerPhone, _ := r.store.GetTenantOwnerPhone(req.Context(), tenant.ID)
-
 		// Pass tenant config as JSON for the call session
-		tenantConfig := map[string]any{
-			"system_prompt":       tenant.SystemPrompt,
-			"greeting_text":       tenant.GreetingText,
-			"voice_id":            tenant.VoiceID,
-			"language":            tenant.Language,
-			"vip_names":           tenant.VIPNames,
-			"marketing_email":     tenant.MarketingEmail,
-			"forward_number":      tenant.ForwardNumber,
-			"max_turn_timeout_ms": tenant.MaxTurnTimeoutMs,
-			"owner_phone":         ownerPhone, // User's verified phone for forwarding
-		}
-		configJSON, _ := json.Marshal(tenantConfig)
-		params = append(params, twimlParameter{Name: "tenantConfig", Value: string(configJSON)})
+		configJSON := r.tenantCallConfigJSON(req.Context(), tenant)
+		params = append(params, twimlParameter{Name: "tenantConfig", Value: configJSON})
 	}
 
 	resp := twimlResponse{
@@ -192,3 +183,23 @@ func (r *Router) handleTwilioStatus(w http.ResponseWriter, req *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// tenantCallConfigJSON returns the tenant config passed to the call session
+// (the tenantConfig stream parameter, parsed into TenantConfig).
+func (r *Router) tenantCallConfigJSON(ctx context.Context, tenant *store.Tenant) string {
+	// Get tenant owner's phone number for call forwarding
+	ownerPhone, _ := r.store.GetTenantOwnerPhone(ctx, tenant.ID)
+
+	configJSON, _ := json.Marshal(map[string]any{
+		"system_prompt":       tenant.SystemPrompt,
+		"greeting_text":       tenant.GreetingText,
+		"voice_id":            tenant.VoiceID,
+		"language":            tenant.Language,
+		"vip_names":           tenant.VIPNames,
+		"marketing_email":     tenant.MarketingEmail,
+		"forward_number":      tenant.ForwardNumber,
+		"max_turn_timeout_ms": tenant.MaxTurnTimeoutMs,
+		"owner_phone":         ownerPhone, // User's verified phone for forwarding
+	})
+	return string(configJSON)
+}
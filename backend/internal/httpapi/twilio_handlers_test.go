@@ -3,8 +3,6 @@ package httpapi
 import (
 	"context"
 	"encoding/xml"
-	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -14,6 +12,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/lukasbauer/karen/internal/logging"
 	"github.com/lukasbauer/karen/internal/store"
 )
 
@@ -117,7 +116,7 @@ func TestHandleTwilioInboundBasic(t *testing.T) {
 		cfg: RouterConfig{
 			PublicBaseURL: "https://example.com",
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		store:  s,
 	}
 
@@ -198,7 +197,7 @@ func TestHandleTwilioStatus(t *testing.T) {
 	s := store.New(db)
 
 	r := &Router{
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		store:  s,
 	}
 
@@ -237,7 +236,7 @@ func TestTenantRoutingIntegration(t *testing.T) {
 		cfg: RouterConfig{
 			PublicBaseURL: "https://example.com",
 		},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 		store:  s,
 	}
 
@@ -105,7 +105,7 @@ func (r *Router) handlePreviewVoice(w http.ResponseWriter, req *http.Request) {
 	// Generate preview audio from ElevenLabs
 	audio, err := r.generatePreviewAudio(req.Context(), body.VoiceID)
 	if err != nil {
-		r.logger.Printf("voice: failed to generate preview: %v", err)
+		r.logger.Error("voice: failed to generate preview", "error", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate preview"})
 		return
 	}
@@ -2,12 +2,12 @@ package httpapi
 
 import (
 	"encoding/json"
-	"io"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/lukasbauer/karen/internal/logging"
 )
 
 func TestCuratedVoices(t *testing.T) {
@@ -47,7 +47,7 @@ func TestCuratedVoices(t *testing.T) {
 func TestHandleListVoices(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/api/voices", nil)
@@ -105,7 +105,7 @@ func TestHandleListVoices(t *testing.T) {
 func TestHandlePreviewVoiceValidation(t *testing.T) {
 	r := &Router{
 		cfg:    RouterConfig{},
-		logger: log.New(io.Discard, "", 0),
+		logger: logging.Discard(),
 	}
 
 	t.Run("invalid request body", func(t *testing.T) {
@@ -0,0 +1,290 @@
+// Package knowledge implements the tenant knowledge base text processing:
+// splitting documents into chunks, normalizing Czech text into search terms
+// and ranking chunks with BM25.
+//
+// Postgres full-text search (the czech_unaccent configuration: unaccent +
+// simple dictionary) selects candidate chunks by term prefix; Postgres has no
+// Czech stemmer, so query terms are stemmed here by stripping common Czech
+// inflection suffixes and matched as prefixes.
+package knowledge
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document kinds.
+const (
+	KindFAQ      = "faq"      // Question (title) and answer (content), kept as one chunk
+	KindDocument = "document" // Free text, split into chunks
+)
+
+// DefaultChunkChars is the target chunk size in characters. Chunks are
+// injected into the LLM context, so they stay short.
+const DefaultChunkChars = 600
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// ChunkText splits text into chunks of about maxChars characters on paragraph
+// and sentence boundaries. Paragraphs are kept together when they fit.
+func ChunkText(text string, maxChars int) []string {
+	if maxChars <= 0 {
+		maxChars = DefaultChunkChars
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		current.Reset()
+	}
+	add := func(piece, sep string) {
+		if current.Len() > 0 && current.Len()+len(sep)+len(piece) > maxChars {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(piece)
+	}
+
+	for _, para := range splitParagraphs(text) {
+		if len(para) <= maxChars {
+			add(para, "\n\n")
+			continue
+		}
+		// Long paragraph: pack its sentences
+		flush()
+		for _, sentence := range splitSentences(para) {
+			for len(sentence) > maxChars {
+				cut := wordBoundary(sentence, maxChars)
+				add(sentence[:cut], " ")
+				flush()
+				sentence = strings.TrimSpace(sentence[cut:])
+			}
+			add(sentence, " ")
+		}
+		flush()
+	}
+	flush()
+	return chunks
+}
+
+func splitParagraphs(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var out []string
+	for _, p := range strings.Split(text, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func splitSentences(text string) []string {
+	var out []string
+	start := 0
+	for i, r := range text {
+		if r == '.' || r == '!' || r == '?' || r == '\n' {
+			if s := strings.TrimSpace(text[start : i+1]); s != "" {
+				out = append(out, s)
+			}
+			start = i + 1
+		}
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// wordBoundary returns the last space before max (or max on a rune boundary).
+func wordBoundary(s string, max int) int {
+	if i := strings.LastIndexByte(s[:max], ' '); i > 0 {
+		return i
+	}
+	for max > 0 && !isRuneStart(s[max]) {
+		max--
+	}
+	return max
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// czechFolding maps Czech diacritics to ASCII, matching Postgres unaccent.
+var czechFolding = strings.NewReplacer(
+	"á", "a", "č", "c", "ď", "d", "é", "e", "ě", "e", "í", "i", "ň", "n", "ó", "o",
+	"ř", "r", "š", "s", "ť", "t", "ú", "u", "ů", "u", "ý", "y", "ž", "z",
+)
+
+// czechSuffixes are inflection endings stripped by Stem, longest first.
+var czechSuffixes = []string{
+	"ovi", "ami", "ach", "ech", "ich", "ych", "emu", "ymu", "eho", "imu", "ove", "ata",
+	"em", "im", "ym", "ou", "um", "am", "ie", "mi",
+	"a", "e", "i", "o", "u", "y",
+}
+
+// minStemLength keeps stems specific enough for prefix matching.
+const minStemLength = 3
+
+// Normalize lowercases and removes diacritics.
+func Normalize(s string) string {
+	return czechFolding.Replace(strings.ToLower(s))
+}
+
+// Tokens splits normalized text into words.
+func Tokens(text string) []string {
+	return strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Stem strips one Czech inflection suffix from a normalized word, so that
+// e.g. "oteviraci" and "oteviracich" share the stem "otevirac".
+func Stem(word string) string {
+	for _, suffix := range czechSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= minStemLength {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
+
+// stopwords are frequent Czech words that carry no search meaning.
+var stopwords = map[string]bool{
+	"a": true, "aby": true, "ale": true, "ani": true, "asi": true, "bych": true, "byt": true,
+	"co": true, "do": true, "i": true, "jak": true, "jaka": true, "jake": true, "jaky": true, "je": true,
+	"jsem": true, "jsou": true, "k": true, "kde": true, "kdy": true, "ktery": true, "mam": true,
+	"me": true, "mi": true, "mit": true, "mate": true, "na": true, "ne": true, "o": true, "od": true, "ona": true, "ono": true,
+	"po": true, "pro": true, "prosim": true, "s": true, "se": true, "si": true, "taky": true,
+	"to": true, "u": true, "v": true, "vam": true, "vas": true, "ve": true, "z": true, "za": true,
+	"ze": true, "dobry": true, "den": true, "chtel": true, "chtela": true,
+}
+
+// maxTerms caps the terms of a query (callers can talk for a while).
+const maxTerms = 12
+
+// Terms returns the distinct stemmed search terms of a query, without stopwords.
+func Terms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, tok := range Tokens(query) {
+		if stopwords[tok] || len(tok) < 2 {
+			continue
+		}
+		if len(terms) == maxTerms {
+			break
+		}
+		stem := Stem(tok)
+		if !seen[stem] {
+			seen[stem] = true
+			terms = append(terms, stem)
+		}
+	}
+	return terms
+}
+
+// TSQuery builds a Postgres tsquery matching any of the terms as a prefix
+// (for the czech_unaccent configuration). Terms contain only letters and
+// digits, so no escaping is needed.
+func TSQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t + ":*"
+	}
+	return strings.Join(parts, " | ")
+}
+
+// TSQueryAll is like TSQuery but matches only text containing all terms.
+func TSQueryAll(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// Candidate is a chunk matched by full-text search, to be ranked.
+type Candidate struct {
+	ID      string
+	Content string
+}
+
+// Stats are the corpus statistics BM25 needs.
+type Stats struct {
+	Chunks       int            // Number of chunks in the tenant's knowledge base
+	AvgTokens    float64        // Average chunk length in tokens
+	DocFrequency map[string]int // Chunks containing each term
+}
+
+// Scored is a ranked candidate.
+type Scored struct {
+	Candidate
+	Score float64
+}
+
+// RankBM25 scores candidates with BM25 (terms match token prefixes, as in the
+// full-text query) and returns the best limit candidates with a positive
+// score, highest first.
+func RankBM25(candidates []Candidate, terms []string, stats Stats, limit int) []Scored {
+	avg := stats.AvgTokens
+	if avg <= 0 {
+		avg = 1
+	}
+	n := float64(stats.Chunks)
+
+	scored := make([]Scored, 0, len(candidates))
+	for _, c := range candidates {
+		tokens := Tokens(c.Content)
+		dl := float64(len(tokens))
+		score := 0.0
+		for _, term := range terms {
+			tf := 0
+			for _, tok := range tokens {
+				if strings.HasPrefix(tok, term) {
+					tf++
+				}
+			}
+			if tf == 0 {
+				continue
+			}
+			df := float64(stats.DocFrequency[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			f := float64(tf)
+			score += idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*dl/avg))
+		}
+		if score > 0 {
+			scored = append(scored, Scored{Candidate: c, Score: score})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored
+}
+
+// Chunks returns the indexed chunks of a knowledge base entry. Each chunk
+// starts with the title (the question of an FAQ entry), so it is found by
+// title terms and reads on its own when injected into the LLM context.
+func Chunks(kind, title, content string) []string {
+	title = strings.TrimSpace(title)
+	if kind == KindFAQ {
+		return []string{title + "\n" + strings.TrimSpace(content)}
+	}
+	pieces := ChunkText(content, DefaultChunkChars)
+	chunks := make([]string, len(pieces))
+	for i, p := range pieces {
+		chunks[i] = title + "\n" + p
+	}
+	return chunks
+}
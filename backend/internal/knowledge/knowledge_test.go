@@ -0,0 +1,154 @@
+package knowledge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChunkText(t *testing.T) {
+	t.Run("short paragraphs are packed together", func(t *testing.T) {
+		chunks := ChunkText("První odstavec.\n\nDruhý odstavec.", 100)
+		if len(chunks) != 1 || chunks[0] != "První odstavec.\n\nDruhý odstavec." {
+			t.Errorf("unexpected chunks %q", chunks)
+		}
+	})
+
+	t.Run("paragraphs split at the limit", func(t *testing.T) {
+		chunks := ChunkText("Aaaa aaaa.\n\nBbbb bbbb.\r\n\r\nCccc cccc.", 22)
+		want := []string{"Aaaa aaaa.\n\nBbbb bbbb.", "Cccc cccc."}
+		if !reflect.DeepEqual(chunks, want) {
+			t.Errorf("got %q, want %q", chunks, want)
+		}
+	})
+
+	t.Run("long paragraph split on sentences", func(t *testing.T) {
+		chunks := ChunkText("Jedna věta. Druhá věta! Třetí věta?", 30)
+		want := []string{"Jedna věta. Druhá věta!", "Třetí věta?"}
+		if !reflect.DeepEqual(chunks, want) {
+			t.Errorf("got %q, want %q", chunks, want)
+		}
+	})
+
+	t.Run("long sentence split on words and runes", func(t *testing.T) {
+		text := strings.Repeat("žluťoučký ", 20) + strings.Repeat("ř", 40)
+		for _, c := range ChunkText(text, 30) {
+			if len(c) > 30 {
+				t.Errorf("chunk too long: %q", c)
+			}
+			if !utf8.ValidString(c) {
+				t.Errorf("chunk split inside a rune: %q", c)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if chunks := ChunkText(" \n\n ", 0); len(chunks) != 0 {
+			t.Errorf("expected no chunks, got %q", chunks)
+		}
+	})
+}
+
+func TestNormalizeAndStem(t *testing.T) {
+	if got := Normalize("Otevírací DOBA Žďár"); got != "oteviraci doba zdar" {
+		t.Errorf("Normalize = %q", got)
+	}
+	if got := Tokens("Kolik stojí, prosím, 2 hodiny?"); !reflect.DeepEqual(got, []string{"kolik", "stoji", "prosim", "2", "hodiny"}) {
+		t.Errorf("Tokens = %q", got)
+	}
+
+	tests := map[string]string{
+		"oteviraci":   "otevirac",
+		"oteviracich": "otevirac",
+		"cenami":      "cen",
+		"cena":        "cen",
+		"pes":         "pes",
+		"kde":         "kde",
+	}
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := Terms("Dobrý den, jaká je otevírací doba? Otevírací doba v sobotu?")
+	want := []string{"otevirac", "dob", "sobot"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms = %q, want %q", got, want)
+	}
+
+	if got := Terms("a to je ono"); len(got) != 0 {
+		t.Errorf("expected no terms, got %q", got)
+	}
+
+	var long strings.Builder
+	for i := 0; i < 20; i++ {
+		long.WriteString(strings.Repeat(string(rune('b'+i)), 4) + "x ")
+	}
+	if got := Terms(long.String()); len(got) != maxTerms {
+		t.Errorf("expected %d terms, got %d", maxTerms, len(got))
+	}
+}
+
+func TestTSQuery(t *testing.T) {
+	if got := TSQuery([]string{"otevirac", "dob"}); got != "otevirac:* | dob:*" {
+		t.Errorf("TSQuery = %q", got)
+	}
+	if got := TSQuery(nil); got != "" {
+		t.Errorf("TSQuery(nil) = %q", got)
+	}
+}
+
+func TestTSQueryAll(t *testing.T) {
+	if got := TSQueryAll([]string{"strech", "novak"}); got != "strech:* & novak:*" {
+		t.Errorf("TSQueryAll = %q", got)
+	}
+}
+
+func TestRankBM25(t *testing.T) {
+	candidates := []Candidate{
+		{ID: "hours", Content: "Otevírací doba\nOtevírací doba je pondělí až pátek od 8 do 16."},
+		{ID: "prices", Content: "Ceník\nCena konzultace je 1500 Kč za hodinu."},
+		{ID: "parking", Content: "Parkování\nParkovat můžete před budovou, doba parkování není omezena."},
+	}
+	stats := Stats{
+		Chunks:       10,
+		AvgTokens:    10,
+		DocFrequency: map[string]int{"otevirac": 1, "dob": 2, "cen": 1},
+	}
+
+	got := RankBM25(candidates, Terms("Jaká je otevírací doba?"), stats, 5)
+	if len(got) != 2 || got[0].ID != "hours" || got[1].ID != "parking" {
+		t.Fatalf("unexpected ranking %+v", got)
+	}
+	if got[0].Score <= got[1].Score {
+		t.Errorf("expected hours to score higher: %+v", got)
+	}
+
+	if got := RankBM25(candidates, Terms("otevírací doba ceník"), stats, 1); len(got) != 1 {
+		t.Errorf("expected limit 1, got %d", len(got))
+	}
+	if got := RankBM25(candidates, Terms("pojištění"), stats, 5); len(got) != 0 {
+		t.Errorf("expected no matches, got %+v", got)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	faq := Chunks(KindFAQ, " Kde parkovat? ", strings.Repeat("Před budovou. ", 100))
+	if len(faq) != 1 || !strings.HasPrefix(faq[0], "Kde parkovat?\n") {
+		t.Errorf("FAQ should be one chunk starting with the question, got %d", len(faq))
+	}
+
+	doc := Chunks(KindDocument, "Ceník", strings.Repeat("Konzultace stojí 1500 Kč. ", 60))
+	if len(doc) < 2 {
+		t.Fatalf("expected the document to be split, got %d chunks", len(doc))
+	}
+	for _, c := range doc {
+		if !strings.HasPrefix(c, "Ceník\n") {
+			t.Errorf("chunk doesn't start with the title: %q", c)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/breaker"
+)
+
+// errEmptyStream is recorded when a provider accepts a request but closes
+// the stream without producing any tokens.
+var errEmptyStream = errors.New("stream closed without tokens")
+
+// Provider is a single LLM backend guarded by a circuit breaker.
+type Provider struct {
+	Client  Client
+	Breaker *breaker.Breaker
+}
+
+// FailoverFunc is called whenever a provider fails a request, before the
+// next provider is tried.
+type FailoverFunc func(provider string, err error)
+
+// FailoverClient implements Client over an ordered list of providers.
+// Requests go to the first provider whose breaker allows traffic; errors
+// and slow first tokens trip the breaker so subsequent turns route to the
+// next provider. If every breaker is open, the primary is tried anyway so
+// a call never fails just because all breakers are cooling down.
+type FailoverClient struct {
+	providers  []Provider
+	onFailover FailoverFunc
+}
+
+// NewFailoverClient creates a failover client. providers must be non-empty
+// and ordered by preference.
+func NewFailoverClient(providers []Provider, onFailover FailoverFunc) *FailoverClient {
+	return &FailoverClient{
+		providers:  providers,
+		onFailover: onFailover,
+	}
+}
+
+// candidates yields the providers to try in order. A breaker is asked for
+// permission only when its provider is reached, so a half-open probe is never
+// reserved on a provider that an earlier one made unnecessary. If no breaker
+// allows traffic, the primary is tried anyway.
+func (c *FailoverClient) candidates() iter.Seq[Provider] {
+	return func(yield func(Provider) bool) {
+		allowed := false
+		for _, p := range c.providers {
+			if p.Breaker != nil && !p.Breaker.Allow() {
+				continue
+			}
+			allowed = true
+			if !yield(p) {
+				return
+			}
+		}
+		if !allowed && len(c.providers) > 0 {
+			yield(c.providers[0])
+		}
+	}
+}
+
+func (c *FailoverClient) recordFailure(p Provider, err error) {
+	if p.Breaker == nil {
+		return
+	}
+	p.Breaker.RecordFailure(err)
+	if c.onFailover != nil {
+		c.onFailover(p.Breaker.Name(), err)
+	}
+}
+
+// AnalyzeCall analyzes the conversation using the first healthy provider.
+func (c *FailoverClient) AnalyzeCall(ctx context.Context, messages []Message) (*ScreeningResult, error) {
+	var lastErr error
+	for p := range c.candidates() {
+		result, err := p.Client.AnalyzeCall(ctx, messages)
+		if err == nil {
+			if p.Breaker != nil {
+				p.Breaker.RecordSuccess()
+			}
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			if p.Breaker != nil {
+				p.Breaker.Release()
+			}
+			return nil, err
+		}
+		c.recordFailure(p, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// GenerateResponse streams a response from the first healthy provider.
+// The first-token latency of the chosen provider is reported to its breaker.
+func (c *FailoverClient) GenerateResponse(ctx context.Context, messages []Message) (<-chan string, error) {
+	var lastErr error
+	for p := range c.candidates() {
+		start := time.Now()
+		ch, err := p.Client.GenerateResponse(ctx, messages)
+		if err == nil {
+			if p.Breaker == nil {
+				return ch, nil
+			}
+			return c.watchStream(ctx, p, start, ch), nil
+		}
+		if ctx.Err() != nil {
+			if p.Breaker != nil {
+				p.Breaker.Release()
+			}
+			return nil, err
+		}
+		c.recordFailure(p, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// watchStream forwards tokens and reports first-token latency (or an empty
+// stream) to the provider's breaker.
+func (c *FailoverClient) watchStream(ctx context.Context, p Provider, start time.Time, in <-chan string) <-chan string {
+	out := make(chan string, 100)
+	go func() {
+		defer close(out)
+		first := true
+		for token := range in {
+			if first {
+				first = false
+				p.Breaker.RecordLatency(time.Since(start))
+			}
+			select {
+			case <-ctx.Done():
+				// Drain so the provider goroutine can exit
+				for range in {
+				}
+				return
+			case out <- token:
+			}
+		}
+		if first {
+			if ctx.Err() != nil {
+				p.Breaker.Release()
+				return
+			}
+			c.recordFailure(p, errEmptyStream)
+		}
+	}()
+	return out
+}
+
+// SetSystemPrompt sets the system prompt on every provider.
+func (c *FailoverClient) SetSystemPrompt(prompt string) {
+	for _, p := range c.providers {
+		p.Client.SetSystemPrompt(prompt)
+	}
+}
+
+// GetSystemPrompt returns the system prompt of the primary provider.
+func (c *FailoverClient) GetSystemPrompt() string {
+	if len(c.providers) == 0 {
+		return ""
+	}
+	return c.providers[0].Client.GetSystemPrompt()
+}
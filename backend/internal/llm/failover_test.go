@@ -0,0 +1,189 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/breaker"
+)
+
+// fakeClient is a scripted Client for failover tests.
+type fakeClient struct {
+	err    error
+	tokens []string
+	delay  time.Duration
+	prompt string
+	calls  int
+}
+
+func (f *fakeClient) AnalyzeCall(ctx context.Context, messages []Message) (*ScreeningResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ScreeningResult{LegitimacyLabel: "legitimní"}, nil
+}
+
+func (f *fakeClient) GenerateResponse(ctx context.Context, messages []Message) (<-chan string, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan string, len(f.tokens))
+	go func() {
+		defer close(ch)
+		time.Sleep(f.delay)
+		for _, t := range f.tokens {
+			ch <- t
+		}
+	}()
+	return ch, nil
+}
+
+func (f *fakeClient) SetSystemPrompt(prompt string) { f.prompt = prompt }
+func (f *fakeClient) GetSystemPrompt() string       { return f.prompt }
+
+func collect(ch <-chan string) string {
+	var out string
+	for t := range ch {
+		out += t
+	}
+	return out
+}
+
+func TestFailoverClient_FallsBackOnError(t *testing.T) {
+	primary := &fakeClient{err: errors.New("503 Service Unavailable")}
+	secondary := &fakeClient{tokens: []string{"Dobrý ", "den"}}
+	pb := breaker.New("llm", "openai", breaker.Config{FailureThreshold: 2})
+	sb := breaker.New("llm", "fallback", breaker.Config{FailureThreshold: 2})
+
+	var failed []string
+	client := NewFailoverClient([]Provider{
+		{Client: primary, Breaker: pb},
+		{Client: secondary, Breaker: sb},
+	}, func(provider string, err error) { failed = append(failed, provider) })
+
+	ch, err := client.GenerateResponse(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GenerateResponse() error = %v", err)
+	}
+	if got := collect(ch); got != "Dobrý den" {
+		t.Errorf("response = %q, want %q", got, "Dobrý den")
+	}
+	if len(failed) != 1 || failed[0] != "openai" {
+		t.Errorf("failover callbacks = %v, want [openai]", failed)
+	}
+
+	// Second failure opens the primary breaker; the third turn skips it entirely
+	_, _ = client.GenerateResponse(context.Background(), nil)
+	_, _ = client.GenerateResponse(context.Background(), nil)
+	if primary.calls != 2 {
+		t.Errorf("primary calls = %d, want 2 (skipped once breaker opened)", primary.calls)
+	}
+	if got := pb.Snapshot().State; got != breaker.StateOpen {
+		t.Errorf("primary state = %q, want %q", got, breaker.StateOpen)
+	}
+}
+
+func TestFailoverClient_SlowFirstTokenTripsBreaker(t *testing.T) {
+	primary := &fakeClient{tokens: []string{"pomalu"}, delay: 20 * time.Millisecond}
+	pb := breaker.New("llm", "openai", breaker.Config{FailureThreshold: 1, SlowThreshold: 5 * time.Millisecond})
+
+	client := NewFailoverClient([]Provider{{Client: primary, Breaker: pb}}, nil)
+
+	ch, err := client.GenerateResponse(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GenerateResponse() error = %v", err)
+	}
+	// The slow response is still delivered; only later turns are rerouted
+	if got := collect(ch); got != "pomalu" {
+		t.Errorf("response = %q, want %q", got, "pomalu")
+	}
+	if got := pb.Snapshot().State; got != breaker.StateOpen {
+		t.Errorf("state = %q, want %q", got, breaker.StateOpen)
+	}
+}
+
+func TestFailoverClient_AllOpenTriesPrimary(t *testing.T) {
+	primary := &fakeClient{}
+	pb := breaker.New("llm", "openai", breaker.Config{FailureThreshold: 1, OpenDuration: time.Hour})
+	pb.RecordFailure(errors.New("boom"))
+
+	client := NewFailoverClient([]Provider{{Client: primary, Breaker: pb}}, nil)
+
+	if _, err := client.AnalyzeCall(context.Background(), nil); err != nil {
+		t.Fatalf("AnalyzeCall() error = %v", err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want 1", primary.calls)
+	}
+}
+
+func TestFailoverClient_SecondaryRecoversAfterPrimaryRecovers(t *testing.T) {
+	primary := &fakeClient{err: errors.New("503 Service Unavailable")}
+	secondary := &fakeClient{err: errors.New("502 Bad Gateway")}
+	cfg := breaker.Config{FailureThreshold: 1, OpenDuration: 10 * time.Millisecond}
+	pb := breaker.New("llm", "openai", cfg)
+	sb := breaker.New("llm", "fallback", cfg)
+
+	client := NewFailoverClient([]Provider{
+		{Client: primary, Breaker: pb},
+		{Client: secondary, Breaker: sb},
+	}, nil)
+
+	// Both providers fail and trip
+	if _, err := client.AnalyzeCall(context.Background(), nil); err == nil {
+		t.Fatal("AnalyzeCall() error = nil, want error")
+	}
+
+	// After the cooldown the primary answers the probe; the secondary must
+	// not be left holding a probe it never sent
+	time.Sleep(20 * time.Millisecond)
+	primary.err = nil
+	if _, err := client.AnalyzeCall(context.Background(), nil); err != nil {
+		t.Fatalf("AnalyzeCall() error = %v", err)
+	}
+	if got := sb.Snapshot().State; got != breaker.StateOpen {
+		t.Errorf("secondary state = %q, want %q", got, breaker.StateOpen)
+	}
+
+	// When the primary fails again, the recovered secondary takes over
+	primary.err = errors.New("503 Service Unavailable")
+	secondary.err = nil
+	if _, err := client.AnalyzeCall(context.Background(), nil); err != nil {
+		t.Fatalf("AnalyzeCall() error = %v", err)
+	}
+	if secondary.calls != 2 {
+		t.Errorf("secondary calls = %d, want 2", secondary.calls)
+	}
+	if got := sb.Snapshot().State; got != breaker.StateClosed {
+		t.Errorf("secondary state = %q, want %q", got, breaker.StateClosed)
+	}
+}
+
+func TestFailoverClient_AllFail(t *testing.T) {
+	client := NewFailoverClient([]Provider{
+		{Client: &fakeClient{err: errors.New("a")}, Breaker: breaker.New("llm", "openai", breaker.DefaultConfig())},
+		{Client: &fakeClient{err: errors.New("b")}, Breaker: breaker.New("llm", "fallback", breaker.DefaultConfig())},
+	}, nil)
+
+	if _, err := client.AnalyzeCall(context.Background(), nil); err == nil {
+		t.Error("AnalyzeCall() error = nil, want error")
+	}
+}
+
+func TestFailoverClient_SetSystemPromptAllProviders(t *testing.T) {
+	a, b := &fakeClient{}, &fakeClient{}
+	client := NewFailoverClient([]Provider{{Client: a}, {Client: b}}, nil)
+
+	client.SetSystemPrompt("Jsi Karen.")
+
+	if a.prompt != "Jsi Karen." || b.prompt != "Jsi Karen." {
+		t.Errorf("prompts = %q, %q; want both set", a.prompt, b.prompt)
+	}
+	if got := client.GetSystemPrompt(); got != "Jsi Karen." {
+		t.Errorf("GetSystemPrompt() = %q, want %q", got, "Jsi Karen.")
+	}
+}
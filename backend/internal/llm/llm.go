@@ -1,6 +1,9 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 // ScreeningResult contains the LLM's analysis of a call.
 type ScreeningResult struct {
@@ -12,6 +15,38 @@ type ScreeningResult struct {
 	Entities             map[string]string `json:"entities"`              // Extracted entities (name, company, etc.)
 	SuggestedResponse    string            `json:"suggested_response"`    // What the agent should say
 	ShouldEndCall        bool              `json:"should_end_call"`       // Whether to end the call
+
+	// Post-call summary for the owner
+	Summary     string       `json:"summary"`      // 2–4 sentence summary in Czech
+	ActionItems []ActionItem `json:"action_items"` // What should happen next
+	Urgency     string       `json:"urgency"`      // Urgency stated by the caller (Urgency* constants)
+}
+
+// ActionItem is a follow-up task from a call.
+type ActionItem struct {
+	Text  string `json:"text"`            // What should be done
+	Owner string `json:"owner,omitempty"` // Who should do it: "majitel", "volající" or a name
+	Due   string `json:"due,omitempty"`   // When, as stated by the caller (e.g. "do pátku")
+}
+
+// Caller-stated urgency values.
+const (
+	UrgencyLow      = "nizka"
+	UrgencyNormal   = "bezna"
+	UrgencyHigh     = "vysoka"
+	UrgencyCritical = "kriticka"
+	UrgencyUnknown  = "nezjisteno"
+)
+
+// NormalizeUrgency returns the urgency value, or UrgencyUnknown if the model
+// returned something else.
+func NormalizeUrgency(urgency string) string {
+	switch u := strings.ToLower(strings.TrimSpace(urgency)); u {
+	case UrgencyLow, UrgencyNormal, UrgencyHigh, UrgencyCritical:
+		return u
+	default:
+		return UrgencyUnknown
+	}
 }
 
 // Message represents a conversation message.
@@ -9,23 +9,35 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/lukasbauer/karen/internal/costs"
 )
 
 const openaiAPIURL = "https://api.openai.com/v1/chat/completions"
 
 // OpenAIClient implements the Client interface using OpenAI's API.
 type OpenAIClient struct {
-	apiKey       string
-	model        string
-	systemPrompt string
-	httpClient   *http.Client
+	apiURL         string
+	apiKey         string
+	model          string
+	systemPrompt   string
+	guardrails     string
+	analysisPrompt string
+	httpClient     *http.Client
 }
 
 // OpenAIConfig holds configuration for the OpenAI client.
 type OpenAIConfig struct {
 	APIKey       string
-	Model        string // e.g., "gpt-4o-mini"
-	SystemPrompt string // Optional custom system prompt
+	Model        string       // e.g., "gpt-4o-mini"
+	SystemPrompt string       // Optional custom system prompt
+	BaseURL      string       // Optional chat completions URL for OpenAI-compatible providers (default: OpenAI)
+	HTTPClient   *http.Client // Optional: shared HTTP client (e.g. with tracing transport)
+
+	// Optional prompt overrides (default: VoiceGuardrailsCzech, AnalysisPromptCzech).
+	// Used by the replay harness to evaluate candidate prompts.
+	Guardrails     string
+	AnalysisPrompt string
 }
 
 // NewOpenAIClient creates a new OpenAI client.
@@ -38,11 +50,30 @@ func NewOpenAIClient(cfg OpenAIConfig) *OpenAIClient {
 	if systemPrompt == "" {
 		systemPrompt = SystemPromptCzech
 	}
+	apiURL := cfg.BaseURL
+	if apiURL == "" {
+		apiURL = openaiAPIURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	guardrails := cfg.Guardrails
+	if guardrails == "" {
+		guardrails = VoiceGuardrailsCzech
+	}
+	analysisPrompt := cfg.AnalysisPrompt
+	if analysisPrompt == "" {
+		analysisPrompt = AnalysisPromptCzech
+	}
 	return &OpenAIClient{
-		apiKey:       cfg.APIKey,
-		model:        model,
-		systemPrompt: systemPrompt,
-		httpClient:   &http.Client{},
+		apiURL:         apiURL,
+		apiKey:         cfg.APIKey,
+		model:          model,
+		systemPrompt:   systemPrompt,
+		guardrails:     guardrails,
+		analysisPrompt: analysisPrompt,
+		httpClient:     httpClient,
 	}
 }
 
@@ -60,7 +91,7 @@ func (c *OpenAIClient) GetSystemPrompt() string {
 
 func (c *OpenAIClient) systemPromptWithGuardrails() string {
 	// Always include guardrails to keep turn-taking smooth.
-	return VoiceGuardrailsCzech + "\n\n" + c.systemPrompt
+	return c.guardrails + "\n\n" + c.systemPrompt
 }
 
 // chatRequest represents an OpenAI chat completion request.
@@ -70,6 +101,13 @@ type chatRequest struct {
 	Stream      bool          `json:"stream,omitempty"`
 	Temperature float64       `json:"temperature,omitempty"`
 	MaxTokens   int           `json:"max_tokens,omitempty"`
+
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// streamOptions asks OpenAI to append a final chunk with token usage to the stream.
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type chatMessage struct {
@@ -87,6 +125,32 @@ type chatResponse struct {
 			Content string `json:"content"`
 		} `json:"delta"`
 	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+}
+
+// chatUsage is the billed token usage of a completion.
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// recordUsage reports a request's token usage to the call's usage accumulator.
+// Providers that don't return usage (some OpenAI-compatible fallbacks, or a
+// stream cancelled before its final chunk) are estimated from text length.
+func recordUsage(ctx context.Context, usage *chatUsage, msgs []chatMessage, outputChars int) {
+	acc := costs.UsageFromContext(ctx)
+	if acc == nil {
+		return
+	}
+	if usage != nil {
+		acc.AddLLMTokens(usage.PromptTokens, usage.CompletionTokens)
+		return
+	}
+	inputChars := 0
+	for _, m := range msgs {
+		inputChars += len(m.Content)
+	}
+	acc.AddLLMTokens(costs.EstimateTokens(inputChars), costs.EstimateTokens(outputChars))
 }
 
 // AnalyzeCall analyzes the conversation and returns a screening result.
@@ -100,17 +164,21 @@ func (c *OpenAIClient) AnalyzeCall(ctx context.Context, messages []Message) (*Sc
 		chatMsgs = append(chatMsgs, chatMessage(m))
 	}
 
-	// Add analysis request
+	// Add analysis request (a tenant taxonomy prompt in the context takes precedence)
+	analysisPrompt := c.analysisPrompt
+	if p := analysisPromptFromContext(ctx); p != "" {
+		analysisPrompt = p
+	}
 	chatMsgs = append(chatMsgs, chatMessage{
 		Role:    "user",
-		Content: AnalysisPromptCzech,
+		Content: analysisPrompt,
 	})
 
 	req := chatRequest{
 		Model:       c.model,
 		Messages:    chatMsgs,
 		Temperature: 0.3,
-		MaxTokens:   500,
+		MaxTokens:   800, // Screening plus summary and action items
 	}
 
 	body, err := json.Marshal(req)
@@ -118,7 +186,7 @@ func (c *OpenAIClient) AnalyzeCall(ctx context.Context, messages []Message) (*Sc
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -147,6 +215,7 @@ func (c *OpenAIClient) AnalyzeCall(ctx context.Context, messages []Message) (*Sc
 	}
 
 	content := chatResp.Choices[0].Message.Content
+	recordUsage(ctx, chatResp.Usage, chatMsgs, len(content))
 
 	// Parse JSON from response (handle potential markdown code blocks)
 	content = strings.TrimSpace(content)
@@ -180,6 +249,8 @@ func (c *OpenAIClient) GenerateResponse(ctx context.Context, messages []Message)
 		Stream:      true,
 		Temperature: 0.7,
 		MaxTokens:   150,
+
+		StreamOptions: &streamOptions{IncludeUsage: true},
 	}
 
 	body, err := json.Marshal(req)
@@ -187,7 +258,7 @@ func (c *OpenAIClient) GenerateResponse(ctx context.Context, messages []Message)
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", openaiAPIURL, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -212,6 +283,10 @@ func (c *OpenAIClient) GenerateResponse(ctx context.Context, messages []Message)
 		defer close(ch)
 		defer resp.Body.Close()
 
+		var usage *chatUsage
+		outputChars := 0
+		defer func() { recordUsage(ctx, usage, chatMsgs, outputChars) }()
+
 		scanner := bufio.NewScanner(resp.Body)
 		for scanner.Scan() {
 			line := scanner.Text()
@@ -231,9 +306,15 @@ func (c *OpenAIClient) GenerateResponse(ctx context.Context, messages []Message)
 				continue
 			}
 
+			// The usage chunk (include_usage) has no choices
+			if streamResp.Usage != nil {
+				usage = streamResp.Usage
+			}
+
 			if len(streamResp.Choices) > 0 {
 				content := streamResp.Choices[0].Delta.Content
 				if content != "" {
+					outputChars += len(content)
 					select {
 					case <-ctx.Done():
 						return
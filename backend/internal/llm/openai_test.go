@@ -1,8 +1,15 @@
 package llm
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/lukasbauer/karen/internal/costs"
 )
 
 func TestNewOpenAIClient(t *testing.T) {
@@ -134,6 +141,9 @@ func TestAnalysisPromptCzech(t *testing.T) {
 		"intent_category",
 		"intent_text",
 		"entities",
+		"summary",
+		"action_items",
+		"urgency",
 	}
 
 	for _, field := range expectedFields {
@@ -143,6 +153,20 @@ func TestAnalysisPromptCzech(t *testing.T) {
 	}
 }
 
+func TestSummaryPromptCzech(t *testing.T) {
+	for _, field := range []string{"summary", "action_items", "urgency", "Pravidla pro shrnutí"} {
+		if !strings.Contains(SummaryPromptCzech, field) {
+			t.Errorf("SummaryPromptCzech should contain %q", field)
+		}
+	}
+	// The call's classification isn't asked for again
+	for _, field := range []string{"legitimacy_label", "lead_label", "intent_category"} {
+		if strings.Contains(SummaryPromptCzech, field) {
+			t.Errorf("SummaryPromptCzech should not contain %q", field)
+		}
+	}
+}
+
 func TestClientInterface(t *testing.T) {
 	// Verify OpenAIClient implements Client interface
 	var _ Client = (*OpenAIClient)(nil)
@@ -189,3 +213,143 @@ func TestScreeningResult(t *testing.T) {
 		t.Error("ShouldEndCall should be true")
 	}
 }
+
+func TestGenerateResponse_RecordsStreamUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if opts, _ := req["stream_options"].(map[string]any); opts["include_usage"] != true {
+			t.Errorf("stream_options = %v, want include_usage true", req["stream_options"])
+		}
+		fmt.Fprintln(w, `data: {"choices":[{"delta":{"content":"Dobrý den"}}]}`)
+		fmt.Fprintln(w, `data: {"choices":[],"usage":{"prompt_tokens":412,"completion_tokens":9}}`)
+		fmt.Fprintln(w, `data: [DONE]`)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{APIKey: "test-key", BaseURL: srv.URL})
+	usage := costs.NewUsage()
+	ch, err := client.GenerateResponse(costs.WithUsage(context.Background(), usage), []Message{{Role: "user", Content: "Haló"}})
+	if err != nil {
+		t.Fatalf("GenerateResponse() error = %v", err)
+	}
+	for range ch {
+	}
+
+	m := usage.Metrics(0)
+	if m.LLMInputTokens != 412 || m.LLMOutputTokens != 9 {
+		t.Errorf("tokens = %d/%d, want 412/9", m.LLMInputTokens, m.LLMOutputTokens)
+	}
+}
+
+func TestGenerateResponse_EstimatesUsageWhenMissing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `data: {"choices":[{"delta":{"content":"12345678"}}]}`)
+		fmt.Fprintln(w, `data: [DONE]`)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{APIKey: "test-key", BaseURL: srv.URL})
+	usage := costs.NewUsage()
+	ch, err := client.GenerateResponse(costs.WithUsage(context.Background(), usage), nil)
+	if err != nil {
+		t.Fatalf("GenerateResponse() error = %v", err)
+	}
+	for range ch {
+	}
+
+	m := usage.Metrics(0)
+	if m.LLMOutputTokens != 2 {
+		t.Errorf("output tokens = %d, want 2 (8 chars estimated)", m.LLMOutputTokens)
+	}
+	if m.LLMInputTokens == 0 {
+		t.Error("input tokens = 0, want estimate from system prompt")
+	}
+}
+
+func TestAnalyzeCall_RecordsUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"{\"legitimacy_label\":\"spam\"}"}}],"usage":{"prompt_tokens":900,"completion_tokens":64}}`)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{APIKey: "test-key", BaseURL: srv.URL})
+	usage := costs.NewUsage()
+	result, err := client.AnalyzeCall(costs.WithUsage(context.Background(), usage), nil)
+	if err != nil {
+		t.Fatalf("AnalyzeCall() error = %v", err)
+	}
+	if result.LegitimacyLabel != "spam" {
+		t.Errorf("LegitimacyLabel = %q, want %q", result.LegitimacyLabel, "spam")
+	}
+
+	m := usage.Metrics(0)
+	if m.LLMInputTokens != 900 || m.LLMOutputTokens != 64 {
+		t.Errorf("tokens = %d/%d, want 900/64", m.LLMInputTokens, m.LLMOutputTokens)
+	}
+}
+
+func TestAnalyzeCall_PromptOverrides(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"{\"legitimacy_label\":\"spam\"}"}}]}`)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{
+		APIKey:         "test-key",
+		BaseURL:        srv.URL,
+		SystemPrompt:   "Jsi Karen.",
+		Guardrails:     "Mluv krátce.",
+		AnalysisPrompt: "Vrať JSON.",
+	})
+	if _, err := client.AnalyzeCall(context.Background(), nil); err != nil {
+		t.Fatalf("AnalyzeCall() error = %v", err)
+	}
+
+	if len(got.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(got.Messages))
+	}
+	if got.Messages[0].Content != "Mluv krátce.\n\nJsi Karen." {
+		t.Errorf("system message = %q, want candidate guardrails + prompt", got.Messages[0].Content)
+	}
+	if got.Messages[1].Content != "Vrať JSON." {
+		t.Errorf("analysis message = %q, want candidate analysis prompt", got.Messages[1].Content)
+	}
+}
+
+func TestAnalyzeCall_ContextAnalysisPrompt(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"{\"legitimacy_label\":\"pacient\"}"}}]}`)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(OpenAIConfig{APIKey: "test-key", BaseURL: srv.URL})
+	ctx := WithAnalysisPrompt(context.Background(), "Tenant taxonomy prompt")
+	if _, err := client.AnalyzeCall(ctx, nil); err != nil {
+		t.Fatalf("AnalyzeCall() error = %v", err)
+	}
+
+	if last := got.Messages[len(got.Messages)-1].Content; last != "Tenant taxonomy prompt" {
+		t.Errorf("analysis message = %q, want the prompt from the context", last)
+	}
+}
+
+func TestNormalizeUrgency(t *testing.T) {
+	tests := map[string]string{
+		"vysoka":     UrgencyHigh,
+		" Kriticka ": UrgencyCritical,
+		"bezna":      UrgencyNormal,
+		"nizka":      UrgencyLow,
+		"":           UrgencyUnknown,
+		"hned":       UrgencyUnknown,
+	}
+	for in, want := range tests {
+		if got := NormalizeUrgency(in); got != want {
+			t.Errorf("NormalizeUrgency(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
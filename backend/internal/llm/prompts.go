@@ -65,7 +65,8 @@ const AnalysisPromptCzech = `Na základě konverzace vyplň následující JSON
     "purpose": "účel nebo null"
   },
   "suggested_response": "co by měl agent říct",
-  "should_end_call": false
+  "should_end_call": false,
+` + analysisSummaryFields + `
 }
 
 Pravidla pro lead_label:
@@ -77,7 +78,32 @@ Pravidla pro lead_label:
 
 Pravidla pro intent_category:
 - zakázka: Volající řeší existující zakázku/objednávku (stav, změna, dotaz)
-- reklamace: Volající řeší reklamaci nebo problém s produktem/službou`
+- reklamace: Volající řeší reklamaci nebo problém s produktem/službou
+
+` + analysisSummaryRules
+
+// SummaryPromptCzech asks for the post-call summary only, for regenerating a
+// summary without classifying the call again.
+const SummaryPromptCzech = `Na základě konverzace vyplň následující JSON strukturu. Odpověz POUZE validním JSON:
+
+{
+` + analysisSummaryFields + `
+}
+
+` + analysisSummaryRules
+
+// analysisSummaryFields are the post-call summary fields of the analysis JSON.
+const analysisSummaryFields = `  "summary": "shrnutí hovoru pro majitele telefonu ve 2–4 větách česky",
+  "action_items": [
+    {"text": "co je potřeba udělat", "owner": "majitel|volající|jméno nebo null", "due": "kdy (jak řekl volající) nebo null"}
+  ],
+  "urgency": "nizka|bezna|vysoka|kriticka|nezjisteno"`
+
+// analysisSummaryRules explain the post-call summary fields.
+const analysisSummaryRules = `Pravidla pro shrnutí:
+- summary: Kdo volal, proč a co bylo domluveno. Jen fakta z hovoru, nic si nedomýšlej.
+- action_items: Konkrétní další kroky (zavolat zpět, poslat nabídku, ...). Prázdný seznam, pokud žádné nejsou.
+- urgency: Naléhavost, jak ji popsal volající; nezjisteno, pokud ji nezmínil.`
 
 // GenerateDefaultSystemPrompt creates a default prompt for a new tenant.
 func GenerateDefaultSystemPrompt(name string) string {
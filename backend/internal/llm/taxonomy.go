@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lukasbauer/karen/internal/knowledge"
+)
+
+// Label is one value of a screening label set.
+type Label struct {
+	Name        string `json:"name"`                  // Value stored with the call, e.g. "urgentni"
+	Description string `json:"description,omitempty"` // When the model should use it
+	Default     bool   `json:"default,omitempty"`     // Used when the model returns an undefined label
+	Spam        bool   `json:"spam,omitempty"`        // Legitimacy labels only: call counts as spam in usage
+}
+
+// Taxonomy is the set of labels the call analysis classifies into. Tenants
+// can define their own; DefaultTaxonomy matches AnalysisPromptCzech.
+type Taxonomy struct {
+	Legitimacy []Label `json:"legitimacy_labels"`
+	Lead       []Label `json:"lead_labels"`
+	Intent     []Label `json:"intent_categories"`
+}
+
+// Taxonomy limits.
+const (
+	maxTaxonomyLabels      = 20
+	maxLabelNameLength     = 40
+	maxLabelDescriptionLen = 300
+)
+
+// DefaultTaxonomy returns the built-in label sets.
+func DefaultTaxonomy() Taxonomy {
+	return Taxonomy{
+		Legitimacy: []Label{
+			{Name: "legitimní", Description: "Skutečný zákazník, známý nebo jiný oprávněný hovor", Default: true},
+			{Name: "marketing", Description: "Nabídka služeb nebo produktů", Spam: true},
+			{Name: "spam", Description: "Nevyžádaný nebo automatický hovor", Spam: true},
+			{Name: "podvod", Description: "Pokus o podvod", Spam: true},
+		},
+		Lead: []Label{
+			{Name: "hot_lead", Description: "Jasný záměr koupit, objednat nebo uzavřít obchod"},
+			{Name: "urgentni", Description: "Naléhavá záležitost, termín, stížnost vyžadující okamžitou akci"},
+			{Name: "follow_up", Description: "Projevený zájem, vyžaduje zpětné zavolání"},
+			{Name: "informacni", Description: "Pouze dotaz na informace, žádná akce potřeba"},
+			{Name: "nezjisteno", Description: "Nelze určit", Default: true},
+		},
+		Intent: []Label{
+			{Name: "obchodní"},
+			{Name: "osobní"},
+			{Name: "servis"},
+			{Name: "zakázka", Description: "Volající řeší existující zakázku/objednávku (stav, změna, dotaz)"},
+			{Name: "reklamace", Description: "Volající řeší reklamaci nebo problém s produktem/službou"},
+			{Name: "informace"},
+			{Name: "stížnost"},
+			{Name: "jiné", Default: true},
+		},
+	}
+}
+
+// Validate checks a tenant-defined taxonomy.
+func (t Taxonomy) Validate() error {
+	sets := []struct {
+		field  string
+		labels []Label
+	}{
+		{"legitimacy_labels", t.Legitimacy},
+		{"lead_labels", t.Lead},
+		{"intent_categories", t.Intent},
+	}
+	for _, set := range sets {
+		if len(set.labels) == 0 {
+			return fmt.Errorf("%s must not be empty", set.field)
+		}
+		if len(set.labels) > maxTaxonomyLabels {
+			return fmt.Errorf("%s has more than %d labels", set.field, maxTaxonomyLabels)
+		}
+		seen := map[string]bool{}
+		defaults := 0
+		for _, l := range set.labels {
+			if labelKey(l.Name) == "" || l.Name != strings.TrimSpace(l.Name) {
+				return fmt.Errorf("%s: label names must be non-empty without surrounding spaces", set.field)
+			}
+			if utf8.RuneCountInString(l.Name) > maxLabelNameLength {
+				return fmt.Errorf("%s: label %q is too long", set.field, l.Name)
+			}
+			if strings.ContainsAny(l.Name, "|\"\n") {
+				return fmt.Errorf("%s: label %q must not contain |, \" or newlines", set.field, l.Name)
+			}
+			if utf8.RuneCountInString(l.Description) > maxLabelDescriptionLen {
+				return fmt.Errorf("%s: description of %q is too long", set.field, l.Name)
+			}
+			key := labelKey(l.Name)
+			if seen[key] {
+				return fmt.Errorf("%s: duplicate label %q", set.field, l.Name)
+			}
+			seen[key] = true
+			if l.Default {
+				defaults++
+			}
+		}
+		if defaults > 1 {
+			return fmt.Errorf("%s: at most one label can be the default", set.field)
+		}
+	}
+	return nil
+}
+
+// AnalysisPrompt builds the analysis prompt (the JSON structure to fill and
+// the rules for each label set) for the taxonomy.
+func (t Taxonomy) AnalysisPrompt() string {
+	var sb strings.Builder
+	sb.WriteString("Na základě konverzace vyplň následující JSON strukturu. Odpověz POUZE validním JSON:\n\n")
+	sb.WriteString("{\n")
+	fmt.Fprintf(&sb, "  \"legitimacy_label\": \"%s\",\n", labelNames(t.Legitimacy))
+	sb.WriteString("  \"legitimacy_confidence\": 0.0-1.0,\n")
+	fmt.Fprintf(&sb, "  \"lead_label\": \"%s\",\n", labelNames(t.Lead))
+	fmt.Fprintf(&sb, "  \"intent_category\": \"%s\",\n", labelNames(t.Intent))
+	sb.WriteString(`  "intent_text": "krátký popis účelu hovoru česky",
+  "entities": {
+    "name": "jméno volajícího nebo null",
+    "company": "firma nebo null",
+    "phone": "telefon nebo null",
+    "purpose": "účel nebo null"
+  },
+  "suggested_response": "co by měl agent říct",
+  "should_end_call": false,
+` + analysisSummaryFields + `
+}
+
+Hodnoty legitimacy_label, lead_label a intent_category použij přesně tak, jak jsou uvedeny výše.`)
+
+	writeLabelRules(&sb, "legitimacy_label", t.Legitimacy)
+	writeLabelRules(&sb, "lead_label", t.Lead)
+	writeLabelRules(&sb, "intent_category", t.Intent)
+	sb.WriteString("\n\n" + analysisSummaryRules)
+	return sb.String()
+}
+
+func labelNames(labels []Label) string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	return strings.Join(names, "|")
+}
+
+// writeLabelRules lists the labels that have a description.
+func writeLabelRules(sb *strings.Builder, field string, labels []Label) {
+	header := false
+	for _, l := range labels {
+		if l.Description == "" {
+			continue
+		}
+		if !header {
+			fmt.Fprintf(sb, "\n\nPravidla pro %s:", field)
+			header = true
+		}
+		fmt.Fprintf(sb, "\n- %s: %s", l.Name, l.Description)
+	}
+}
+
+// Apply replaces the labels in result with the taxonomy's canonical names
+// (matched ignoring case, diacritics and punctuation). A label the taxonomy doesn't define is
+// replaced with the set's default label, or cleared if it has none. The
+// rejected values are returned as "field=value".
+func (t Taxonomy) Apply(result *ScreeningResult) []string {
+	var rejected []string
+	fields := []struct {
+		name   string
+		value  *string
+		labels []Label
+	}{
+		{"legitimacy_label", &result.LegitimacyLabel, t.Legitimacy},
+		{"lead_label", &result.LeadLabel, t.Lead},
+		{"intent_category", &result.IntentCategory, t.Intent},
+	}
+	for _, f := range fields {
+		if l, ok := findLabel(f.labels, *f.value); ok {
+			*f.value = l.Name
+			continue
+		}
+		rejected = append(rejected, f.name+"="+*f.value)
+		*f.value = ""
+		for _, l := range f.labels {
+			if l.Default {
+				*f.value = l.Name
+			}
+		}
+	}
+	return rejected
+}
+
+// IsSpam reports whether the legitimacy label counts as spam.
+func (t Taxonomy) IsSpam(legitimacyLabel string) bool {
+	l, ok := findLabel(t.Legitimacy, legitimacyLabel)
+	return ok && l.Spam
+}
+
+func findLabel(labels []Label, name string) (Label, bool) {
+	key := labelKey(name)
+	if key == "" {
+		return Label{}, false
+	}
+	for _, l := range labels {
+		if labelKey(l.Name) == key {
+			return l, true
+		}
+	}
+	return Label{}, false
+}
+
+// labelKey folds a label name for matching, so that e.g. "Legitimni" and
+// "Spam." match "legitimní" and "spam".
+func labelKey(name string) string {
+	return strings.Join(knowledge.Tokens(name), " ")
+}
+
+type analysisPromptKey struct{}
+
+// WithAnalysisPrompt returns a context that carries a per-call analysis
+// prompt (e.g. built from a tenant taxonomy). AnalyzeCall uses it instead of
+// the client's configured prompt.
+func WithAnalysisPrompt(ctx context.Context, prompt string) context.Context {
+	return context.WithValue(ctx, analysisPromptKey{}, prompt)
+}
+
+// analysisPromptFromContext returns the analysis prompt in ctx, or "".
+func analysisPromptFromContext(ctx context.Context) string {
+	p, _ := ctx.Value(analysisPromptKey{}).(string)
+	return p
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func dentistTaxonomy() Taxonomy {
+	return Taxonomy{
+		Legitimacy: []Label{
+			{Name: "pacient", Description: "Stávající nebo nový pacient"},
+			{Name: "obchodník", Description: "Nabídka zboží nebo služeb", Spam: true},
+		},
+		Lead: []Label{
+			{Name: "akutní bolest", Description: "Bolest nebo úraz, potřebuje termín ještě dnes"},
+			{Name: "objednání"},
+			{Name: "ostatní", Default: true},
+		},
+		Intent: []Label{
+			{Name: "prohlídka"},
+			{Name: "ošetření"},
+		},
+	}
+}
+
+func TestDefaultTaxonomy(t *testing.T) {
+	d := DefaultTaxonomy()
+	if err := d.Validate(); err != nil {
+		t.Fatalf("default taxonomy is invalid: %v", err)
+	}
+
+	// The built-in labels are the ones AnalysisPromptCzech asks for
+	for _, set := range [][]Label{d.Legitimacy, d.Lead, d.Intent} {
+		if !strings.Contains(AnalysisPromptCzech, `"`+labelNames(set)+`"`) {
+			t.Errorf("AnalysisPromptCzech doesn't list %q", labelNames(set))
+		}
+	}
+}
+
+func TestTaxonomyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Taxonomy)
+		want   string
+	}{
+		{"valid", func(*Taxonomy) {}, ""},
+		{"empty set", func(t *Taxonomy) { t.Intent = nil }, "intent_categories must not be empty"},
+		{"too many labels", func(t *Taxonomy) {
+			t.Lead = nil
+			for i := 0; i <= maxTaxonomyLabels; i++ {
+				t.Lead = append(t.Lead, Label{Name: strings.Repeat("x", i+1)})
+			}
+		}, "more than"},
+		{"blank name", func(t *Taxonomy) { t.Lead[0].Name = " " }, "non-empty"},
+		{"padded name", func(t *Taxonomy) { t.Lead[0].Name = "a " }, "non-empty"},
+		{"name too long", func(t *Taxonomy) { t.Lead[0].Name = strings.Repeat("á", maxLabelNameLength+1) }, "too long"},
+		{"separator in name", func(t *Taxonomy) { t.Lead[0].Name = "a|b" }, "must not contain"},
+		{"description too long", func(t *Taxonomy) { t.Lead[0].Description = strings.Repeat("a", maxLabelDescriptionLen+1) }, "too long"},
+		{"duplicate", func(t *Taxonomy) { t.Intent[1].Name = "PROHLÍDKA" }, "duplicate"},
+		{"duplicate without diacritics", func(t *Taxonomy) { t.Intent[1].Name = "prohlidka" }, "duplicate"},
+		{"punctuation only", func(t *Taxonomy) { t.Lead[0].Name = "?!" }, "non-empty"},
+		{"two defaults", func(t *Taxonomy) { t.Lead[0].Default = true }, "at most one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tax := dentistTaxonomy()
+			tt.modify(&tax)
+			err := tax.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxonomyAnalysisPrompt(t *testing.T) {
+	prompt := dentistTaxonomy().AnalysisPrompt()
+
+	for _, want := range []string{
+		`"legitimacy_label": "pacient|obchodník"`,
+		`"lead_label": "akutní bolest|objednání|ostatní"`,
+		`"intent_category": "prohlídka|ošetření"`,
+		"Pravidla pro legitimacy_label:\n- pacient: Stávající nebo nový pacient",
+		"- akutní bolest: Bolest nebo úraz",
+		`"intent_text"`,
+		`"summary"`,
+		`"action_items"`,
+		"Pravidla pro shrnutí:",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt doesn't contain %q:\n%s", want, prompt)
+		}
+	}
+	// No descriptions, no rules section
+	if strings.Contains(prompt, "Pravidla pro intent_category") {
+		t.Error("expected no rules for labels without descriptions")
+	}
+}
+
+func TestTaxonomyApply(t *testing.T) {
+	tax := dentistTaxonomy()
+
+	result := &ScreeningResult{LegitimacyLabel: " Pacient ", LeadLabel: "AKUTNÍ BOLEST", IntentCategory: "ošetření"}
+	if rejected := tax.Apply(result); len(rejected) != 0 {
+		t.Errorf("unexpected rejected labels %v", rejected)
+	}
+	if result.LegitimacyLabel != "pacient" || result.LeadLabel != "akutní bolest" || result.IntentCategory != "ošetření" {
+		t.Errorf("labels not canonicalized: %+v", result)
+	}
+
+	result = &ScreeningResult{LegitimacyLabel: "Pacient.", LeadLabel: "akutni bolest", IntentCategory: "Osetreni!"}
+	if rejected := tax.Apply(result); len(rejected) != 0 {
+		t.Errorf("unexpected rejected labels %v", rejected)
+	}
+	if result.LegitimacyLabel != "pacient" || result.LeadLabel != "akutní bolest" || result.IntentCategory != "ošetření" {
+		t.Errorf("labels without diacritics not canonicalized: %+v", result)
+	}
+
+	result = &ScreeningResult{LegitimacyLabel: "spam", LeadLabel: "hot_lead", IntentCategory: "servis"}
+	rejected := tax.Apply(result)
+	want := []string{"legitimacy_label=spam", "lead_label=hot_lead", "intent_category=servis"}
+	if !reflect.DeepEqual(rejected, want) {
+		t.Errorf("rejected = %v, want %v", rejected, want)
+	}
+	if result.LegitimacyLabel != "" || result.LeadLabel != "ostatní" || result.IntentCategory != "" {
+		t.Errorf("undefined labels should fall back to the default or be cleared: %+v", result)
+	}
+}
+
+func TestTaxonomyIsSpam(t *testing.T) {
+	d := DefaultTaxonomy()
+	for label, want := range map[string]bool{"spam": true, "Spam.": true, "Marketing": true, "podvod": true, "legitimní": false, "legitimni": false, "": false} {
+		if got := d.IsSpam(label); got != want {
+			t.Errorf("IsSpam(%q) = %v, want %v", label, got, want)
+		}
+	}
+	if !dentistTaxonomy().IsSpam("obchodník") || dentistTaxonomy().IsSpam("spam") {
+		t.Error("custom taxonomy spam flags not applied")
+	}
+}
+
+func TestDefaultTaxonomyApply(t *testing.T) {
+	d := DefaultTaxonomy()
+	for _, label := range []string{"legitimni", "Legitimní.", "neznámý"} {
+		result := &ScreeningResult{LegitimacyLabel: label}
+		d.Apply(result)
+		if result.LegitimacyLabel != "legitimní" {
+			t.Errorf("Apply(%q) legitimacy = %q, want legitimní", label, result.LegitimacyLabel)
+		}
+	}
+	result := &ScreeningResult{LegitimacyLabel: "Spam."}
+	d.Apply(result)
+	if result.LegitimacyLabel != "spam" || !d.IsSpam(result.LegitimacyLabel) {
+		t.Errorf("Apply(%q) legitimacy = %q, want spam", "Spam.", result.LegitimacyLabel)
+	}
+}
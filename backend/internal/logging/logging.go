@@ -0,0 +1,136 @@
+// Package logging provides the structured JSON logger used across the backend.
+//
+// Loggers are plain *slog.Logger values. The handler adds two things on top
+// of slog's JSON handler:
+//   - attributes stored in a context via WithAttrs (turn_id, response_id, ...)
+//     and the active trace ID are added to every record logged with that context;
+//   - WithDebug lowers the level of a single logger (e.g. one call) to debug,
+//     so individual tenants can be debugged without changing LOG_LEVEL.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ParseLevel converts a LOG_LEVEL value to a slog level (default: info).
+func ParseLevel(s string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// New creates a JSON logger writing to w at the given minimum level.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	inner := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(&handler{inner: inner, level: level})
+}
+
+// WithDebug returns a logger that emits debug records regardless of the
+// global level. Used for per-tenant debug logging; loggers not created by
+// New are returned unchanged.
+func WithDebug(l *slog.Logger) *slog.Logger {
+	h, ok := l.Handler().(*handler)
+	if !ok {
+		return l
+	}
+	clone := *h
+	clone.level = slog.LevelDebug
+	return slog.New(&clone)
+}
+
+// ListContains reports whether id appears in a comma-separated list
+// (the global config format for tenant lists).
+func ListContains(list, id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Discard returns a logger that drops everything (for tests).
+func Discard() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+type handler struct {
+	inner slog.Handler
+	level slog.Level
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(ctxAttrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.inner = h.inner.WithAttrs(attrs)
+	return &clone
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.inner = h.inner.WithGroup(name)
+	return &clone
+}
+
+type ctxAttrsKey struct{}
+
+// WithAttrs returns a context carrying attributes that are added to every
+// record logged with it (via the *Context logger methods).
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	existing, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	attrs := append([]slog.Attr(nil), existing...)
+	attrs = append(attrs, argsToAttrs(args)...)
+	return context.WithValue(ctx, ctxAttrsKey{}, attrs)
+}
+
+// argsToAttrs converts alternating key/value args (as accepted by slog) to attrs.
+func argsToAttrs(args []any) []slog.Attr {
+	var attrs []slog.Attr
+	for len(args) > 0 {
+		switch k := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, k)
+			args = args[1:]
+		case string:
+			if len(args) < 2 {
+				attrs = append(attrs, slog.Any("!BADKEY", k))
+				args = nil
+				continue
+			}
+			attrs = append(attrs, slog.Any(k, args[1]))
+			args = args[2:]
+		default:
+			attrs = append(attrs, slog.Any("!BADKEY", k))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warning", slog.LevelWarn},
+		{" error ", slog.LevelError},
+		{"", slog.LevelInfo},
+		{"verbose", slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		if got := ParseLevel(tt.in); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNew_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	logger.Debug("hidden")
+	logger.Info("shown", "call_sid", "CA123")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if records[0]["msg"] != "shown" || records[0]["call_sid"] != "CA123" {
+		t.Errorf("record = %v, want msg shown with call_sid CA123", records[0])
+	}
+}
+
+func TestWithDebug(t *testing.T) {
+	var buf bytes.Buffer
+	base := New(&buf, slog.LevelWarn)
+	debug := WithDebug(base.With("tenant_id", "t1"))
+
+	base.Debug("base debug")
+	debug.Debug("tenant debug")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if records[0]["msg"] != "tenant debug" || records[0]["tenant_id"] != "t1" {
+		t.Errorf("record = %v, want tenant debug for t1", records[0])
+	}
+
+	// Loggers from other handlers are returned unchanged
+	if l := Discard(); WithDebug(l) != l {
+		t.Error("WithDebug() on foreign logger returned a new logger")
+	}
+}
+
+func TestWithAttrs_AddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	ctx := WithAttrs(context.Background(), "turn_id", 3)
+	ctx = WithAttrs(ctx, "response_id", 7)
+	logger.InfoContext(ctx, "starting response")
+	logger.Info("no context")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0]["turn_id"] != float64(3) || records[0]["response_id"] != float64(7) {
+		t.Errorf("record = %v, want turn_id 3 and response_id 7", records[0])
+	}
+	if _, ok := records[1]["turn_id"]; ok {
+		t.Errorf("record without context has turn_id: %v", records[1])
+	}
+}
+
+func TestListContains(t *testing.T) {
+	if !ListContains("t1, t2,t3", "t2") {
+		t.Error("ListContains(t2) = false, want true")
+	}
+	if ListContains("t1,t2", "t") {
+		t.Error("ListContains(t) = true, want false")
+	}
+	if ListContains("", "") {
+		t.Error("ListContains(empty) = true, want false")
+	}
+}
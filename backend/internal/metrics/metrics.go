@@ -0,0 +1,126 @@
+// Package metrics exposes Prometheus metrics for the voice pipeline.
+// Instrumentation lives next to the corresponding eventlog calls: call_events
+// keep the per-call detail, these metrics give the fleet-wide view.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "karen"
+
+// latencyBuckets covers 50ms to ~13s, the useful range for voice turn latency.
+var latencyBuckets = prometheus.ExponentialBuckets(0.05, 1.5, 15)
+
+var (
+	// TurnLatency measures caller end-of-speech (speech_final) to first agent audio sent.
+	TurnLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "turn_latency_seconds",
+		Help:      "Time from caller end-of-speech to the first audio chunk sent back.",
+		Buckets:   latencyBuckets,
+	})
+
+	// LLMFirstToken measures LLM request start to first streamed token.
+	LLMFirstToken = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_first_token_seconds",
+		Help:      "Time from LLM request to the first streamed token.",
+		Buckets:   latencyBuckets,
+	})
+
+	// TTSFirstChunk measures TTS request start to first audio chunk.
+	TTSFirstChunk = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tts_first_chunk_seconds",
+		Help:      "Time from TTS request to the first audio chunk.",
+		Buckets:   latencyBuckets,
+	})
+
+	// CallsStarted counts media streams that started a call session.
+	CallsStarted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "calls_started_total",
+		Help:      "Number of call sessions started.",
+	})
+
+	// BargeIns counts caller interruptions of agent speech.
+	BargeIns = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "barge_ins_total",
+		Help:      "Number of times the caller interrupted the agent.",
+	})
+
+	// RobocallDetections counts detected robocalls by detection reason.
+	RobocallDetections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "robocall_detections_total",
+		Help:      "Number of robocalls detected, by reason.",
+	}, []string{"reason"})
+
+	// Forwards counts call forwarding attempts by result ("success" or "failed").
+	Forwards = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "call_forwards_total",
+		Help:      "Number of call forwarding attempts, by result.",
+	}, []string{"result"})
+
+	// Hangups counts ended calls by who hung up ("caller" or "agent").
+	Hangups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "call_hangups_total",
+		Help:      "Number of calls ended, by initiator.",
+	}, []string{"initiator"})
+
+	// ProviderErrors counts voice AI provider failures by pipeline stage and provider.
+	ProviderErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_errors_total",
+		Help:      "Number of voice AI provider failures, by kind (stt, llm, tts) and provider.",
+	}, []string{"kind", "provider"})
+
+	activeCallsFn atomic.Pointer[func() int]
+
+	// ActiveCalls reports the number of in-progress calls from the call registry.
+	ActiveCalls = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_calls",
+		Help:      "Number of calls currently in progress.",
+	}, func() float64 {
+		if fn := activeCallsFn.Load(); fn != nil {
+			return float64((*fn)())
+		}
+		return 0
+	})
+)
+
+// SetActiveCallsSource sets the function used to report active calls.
+func SetActiveCallsSource(fn func() int) {
+	activeCallsFn.Store(&fn)
+}
+
+// ObserveDuration records d in seconds on the given histogram.
+func ObserveDuration(h prometheus.Observer, d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// RobocallReason reduces a detector reason to a bounded label value.
+// Reasons like "hold_keyword:<keyword>" carry free text after the colon.
+func RobocallReason(reason string) string {
+	if i := strings.Index(reason, ":"); i >= 0 {
+		return reason[:i]
+	}
+	return reason
+}
+
+// Handler returns the HTTP handler serving metrics in Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRobocallReason(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   string
+	}{
+		{"prolonged_silence", "prolonged_silence"},
+		{"rapid_barge_ins", "rapid_barge_ins"},
+		{"phrase_repetition:dobrý den", "phrase_repetition"},
+		{"hold_keyword:čekejte prosím", "hold_keyword"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			if got := RobocallReason(tt.reason); got != tt.want {
+				t.Errorf("RobocallReason(%q) = %q, want %q", tt.reason, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveCalls(t *testing.T) {
+	SetActiveCallsSource(func() int { return 7 })
+	if got := testutil.ToFloat64(ActiveCalls); got != 7 {
+		t.Errorf("ActiveCalls = %v, want 7", got)
+	}
+}
+
+func TestProviderErrorsLabels(t *testing.T) {
+	ProviderErrors.WithLabelValues("llm", "openai").Inc()
+	if got := testutil.ToFloat64(ProviderErrors.WithLabelValues("llm", "openai")); got < 1 {
+		t.Errorf("provider_errors_total{llm,openai} = %v, want >= 1", got)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"sync"
 	"time"
@@ -28,14 +28,14 @@ type APNsConfig struct {
 type APNsClient struct {
 	client   *apns2.Client
 	bundleID string
-	logger   *log.Logger
+	logger   *slog.Logger
 	mu       sync.Mutex
 }
 
 // NewAPNsClient creates a new APNs client
-func NewAPNsClient(cfg APNsConfig, logger *log.Logger) (*APNsClient, error) {
+func NewAPNsClient(cfg APNsConfig, logger *slog.Logger) (*APNsClient, error) {
 	if cfg.KeyPath == "" || cfg.KeyID == "" || cfg.TeamID == "" || cfg.BundleID == "" {
-		logger.Println("APNs: missing configuration, push notifications disabled")
+		logger.Warn("APNs: missing configuration, push notifications disabled")
 		return nil, nil
 	}
 
@@ -76,7 +76,7 @@ func NewAPNsClient(cfg APNsConfig, logger *log.Logger) (*APNsClient, error) {
 		client = apns2.NewTokenClient(authToken).Development()
 	}
 
-	logger.Printf("APNs: client initialized (production=%v, bundle=%s)", cfg.Production, cfg.BundleID)
+	logger.Info("APNs: client initialized", "production", cfg.Production, "bundle", cfg.BundleID)
 
 	return &APNsClient{
 		client:   client,
@@ -119,16 +119,16 @@ func (c *APNsClient) SendCallNotification(deviceToken string, notif CallNotifica
 
 	res, err := c.client.Push(notification)
 	if err != nil {
-		c.logger.Printf("APNs: failed to send notification: %v", err)
+		c.logger.Error("APNs: failed to send notification", "error", err)
 		return err
 	}
 
 	if res.StatusCode != 200 {
-		c.logger.Printf("APNs: notification rejected (status=%d, reason=%s)", res.StatusCode, res.Reason)
+		c.logger.Warn("APNs: notification rejected", "status", res.StatusCode, "reason", res.Reason)
 		return fmt.Errorf("APNs rejected notification: %s", res.Reason)
 	}
 
-	c.logger.Printf("APNs: notification sent successfully to %s...", deviceToken[:16])
+	c.logger.Info("APNs: notification sent", "token_prefix", deviceToken[:16])
 	return nil
 }
 
@@ -209,15 +209,15 @@ func (c *APNsClient) SendUsageWarning(deviceToken string, warningType UsageWarni
 
 	res, err := c.client.Push(notification)
 	if err != nil {
-		c.logger.Printf("APNs: failed to send usage warning: %v", err)
+		c.logger.Error("APNs: failed to send usage warning", "error", err)
 		return err
 	}
 
 	if res.StatusCode != 200 {
-		c.logger.Printf("APNs: usage warning rejected (status=%d, reason=%s)", res.StatusCode, res.Reason)
+		c.logger.Warn("APNs: usage warning rejected", "status", res.StatusCode, "reason", res.Reason)
 		return fmt.Errorf("APNs rejected notification: %s", res.Reason)
 	}
 
-	c.logger.Printf("APNs: usage warning sent successfully to %s...", deviceToken[:16])
+	c.logger.Info("APNs: usage warning sent", "token_prefix", deviceToken[:16])
 	return nil
 }
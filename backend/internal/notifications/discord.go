@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -13,13 +13,13 @@ import (
 // Discord is a simple Discord webhook notifier.
 type Discord struct {
 	webhookURL string
-	logger     *log.Logger
+	logger     *slog.Logger
 	client     *http.Client
 }
 
 // NewDiscord creates a new Discord notifier. If webhookURL is empty,
 // notifications are silently skipped.
-func NewDiscord(webhookURL string, logger *log.Logger) *Discord {
+func NewDiscord(webhookURL string, logger *slog.Logger) *Discord {
 	return &Discord{
 		webhookURL: webhookURL,
 		logger:     logger,
@@ -63,27 +63,27 @@ func (d *Discord) send(_ context.Context, msg discordMessage) {
 	go func() {
 		body, err := json.Marshal(msg)
 		if err != nil {
-			d.logger.Printf("discord: failed to marshal message: %v", err)
+			d.logger.Error("discord: failed to marshal message", "error", err)
 			return
 		}
 
 		// Use background context since the HTTP handler may return before this runs
 		req, err := http.NewRequestWithContext(context.Background(), "POST", d.webhookURL, bytes.NewReader(body))
 		if err != nil {
-			d.logger.Printf("discord: failed to create request: %v", err)
+			d.logger.Error("discord: failed to create request", "error", err)
 			return
 		}
 		req.Header.Set("Content-Type", "application/json")
 
 		resp, err := d.client.Do(req)
 		if err != nil {
-			d.logger.Printf("discord: failed to send webhook: %v", err)
+			d.logger.Error("discord: failed to send webhook", "error", err)
 			return
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode >= 400 {
-			d.logger.Printf("discord: webhook returned status %d", resp.StatusCode)
+			d.logger.Warn("discord: webhook returned status", "status_code", resp.StatusCode)
 		}
 	}()
 }
@@ -118,3 +118,22 @@ func (d *Discord) NotifyPhoneNumbersExhausted(ctx context.Context, tenantID, ten
 	}
 	d.send(ctx, msg)
 }
+
+// NotifyCostBudgetExceeded sends a notification when a tenant exceeds its
+// monthly provider-cost budget.
+func (d *Discord) NotifyCostBudgetExceeded(ctx context.Context, tenantID, period string, spentCents, limitCents int) {
+	msg := discordMessage{
+		Embeds: []discordEmbed{{
+			Title:       "Překročen rozpočet na náklady",
+			Description: "Tenant překročil měsíční rozpočet na API náklady. Hovory běží v úsporném režimu.",
+			Color:       0xFFA500, // Orange
+			Fields: []embedField{
+				{Name: "Tenant ID", Value: fmt.Sprintf("`%s`", tenantID), Inline: true},
+				{Name: "Období", Value: period, Inline: true},
+				{Name: "Útrata", Value: fmt.Sprintf("$%.2f / $%.2f", float64(spentCents)/100, float64(limitCents)/100), Inline: true},
+			},
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}},
+	}
+	d.send(ctx, msg)
+}
@@ -0,0 +1,283 @@
+// Package redact replaces personal data in call transcripts with typed
+// placeholders before they are stored: Czech birth numbers (rodné číslo),
+// bank accounts (IBAN and Czech domestic format), payment cards, emails and
+// phone numbers. Numbers are validated (checksums, dates) where the format
+// allows it, so ordinary amounts and dates stay readable.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind is a category of personal data.
+type Kind string
+
+const (
+	KindBirthNumber Kind = "birth_number"
+	KindBankAccount Kind = "bank_account"
+	KindCard        Kind = "card"
+	KindEmail       Kind = "email"
+	KindPhone       Kind = "phone"
+)
+
+// AllKinds lists every kind in the order detectors run.
+var AllKinds = []Kind{KindEmail, KindBankAccount, KindCard, KindBirthNumber, KindPhone}
+
+// DefaultKinds are redacted unless the tenant chose otherwise. Emails and
+// phone numbers are kept by default: callers leave them to be called back.
+var DefaultKinds = []Kind{KindBirthNumber, KindBankAccount, KindCard}
+
+// Placeholder returns the text that replaces data of kind k, e.g. "[CARD]".
+func Placeholder(k Kind) string {
+	return "[" + strings.ToUpper(string(k)) + "]"
+}
+
+// ValidKind reports whether k is a known kind.
+func ValidKind(k Kind) bool {
+	return slices.Contains(AllKinds, k)
+}
+
+// ParseKinds validates kind names, dropping duplicates.
+func ParseKinds(names []string) ([]Kind, error) {
+	kinds := []Kind{}
+	for _, name := range names {
+		k := Kind(name)
+		if !ValidKind(k) {
+			return nil, fmt.Errorf("unknown redaction kind %q", name)
+		}
+		if !slices.Contains(kinds, k) {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds, nil
+}
+
+// Redactor redacts a fixed set of kinds. The nil Redactor redacts nothing.
+type Redactor struct {
+	kinds []Kind
+}
+
+// New returns a redactor for kinds (in any order).
+func New(kinds []Kind) *Redactor {
+	r := &Redactor{}
+	for _, k := range AllKinds {
+		if slices.Contains(kinds, k) {
+			r.kinds = append(r.kinds, k)
+		}
+	}
+	return r
+}
+
+// Kinds returns the kinds the redactor replaces.
+func (r *Redactor) Kinds() []Kind {
+	if r == nil {
+		return nil
+	}
+	return r.kinds
+}
+
+// Redact returns text with the configured kinds replaced by placeholders.
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, k := range r.kinds {
+		text = detectors[k](text)
+	}
+	return text
+}
+
+var detectors = map[Kind]func(string) string{
+	KindEmail:       redactEmails,
+	KindBankAccount: redactBankAccounts,
+	KindCard:        redactCards,
+	KindBirthNumber: redactBirthNumbers,
+	KindPhone:       redactPhones,
+}
+
+// replaceValid replaces the matches of re for which valid returns true.
+func replaceValid(text string, re *regexp.Regexp, k Kind, valid func(string) bool) string {
+	return re.ReplaceAllStringFunc(text, func(m string) string {
+		if valid(m) {
+			return Placeholder(k)
+		}
+		return m
+	})
+}
+
+// digits returns the decimal digits of s.
+func digits(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// Emails
+
+var emailPattern = regexp.MustCompile(`[\p{L}0-9._%+-]+@[\p{L}0-9-]+(?:\.[\p{L}0-9-]+)*\.\p{L}{2,}`)
+
+func redactEmails(text string) string {
+	return emailPattern.ReplaceAllString(text, Placeholder(KindEmail))
+}
+
+// Bank accounts
+
+var (
+	// IBAN: country code, check digits and up to 30 characters, written in
+	// groups of four or as one block.
+	ibanPattern = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`)
+	// Czech domestic account: [prefix-]number/bank code.
+	czechAccountPattern = regexp.MustCompile(`\b(?:\d{1,6} ?- ?)?\d{2,10} ?/ ?\d{4}\b`)
+)
+
+func redactBankAccounts(text string) string {
+	text = replaceValid(text, ibanPattern, KindBankAccount, validIBAN)
+	return replaceValid(text, czechAccountPattern, KindBankAccount, validCzechAccount)
+}
+
+// validIBAN checks the ISO 13616 mod-97 checksum.
+func validIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	rearranged := s[4:] + s[:4]
+	rem := 0
+	for _, c := range rearranged {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			rem = (rem*100 + int(c-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
+
+// validCzechAccount checks the weighted mod-11 checksums of the prefix and
+// account number. Numbers that also pass as a birth number are left to the
+// birth number detector.
+func validCzechAccount(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	number, _, _ := strings.Cut(s, "/")
+	prefix, number, hasPrefix := strings.Cut(number, "-")
+	if !hasPrefix {
+		number, prefix = prefix, ""
+	}
+	if prefix == "" && len(number) == 6 && validBirthNumber(s) {
+		return false
+	}
+	return strings.Trim(number, "0") != "" && czechMod11(number) && (prefix == "" || czechMod11(prefix))
+}
+
+var czechAccountWeights = []int{6, 3, 7, 9, 10, 5, 8, 4, 2, 1}
+
+func czechMod11(n string) bool {
+	if len(n) > len(czechAccountWeights) {
+		return false
+	}
+	weights := czechAccountWeights[len(czechAccountWeights)-len(n):]
+	sum := 0
+	for i, c := range n {
+		sum += int(c-'0') * weights[i]
+	}
+	return sum%11 == 0
+}
+
+// Payment cards
+
+// cardPattern matches 13–19 digits, optionally grouped by spaces or dashes.
+// Card numbers don't start with 0 (unlike 00-prefixed phone numbers).
+var cardPattern = regexp.MustCompile(`\b[1-9](?:[ -]?\d){12,18}\b`)
+
+func redactCards(text string) string {
+	return replaceValid(text, cardPattern, KindCard, func(m string) bool { return luhn(digits(m)) })
+}
+
+// luhn checks the Luhn checksum used by payment card numbers.
+func luhn(n string) bool {
+	if len(n) < 13 || strings.Trim(n, "0") == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(n) - 1; i >= 0; i-- {
+		d := int(n[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Birth numbers
+
+// birthNumberPattern matches YYMMDD/XXXX (or XXX before 1954), with or
+// without the slash.
+var birthNumberPattern = regexp.MustCompile(`\b\d{6}(?: ?/ ?)?\d{3,4}\b`)
+
+func redactBirthNumbers(text string) string {
+	return replaceValid(text, birthNumberPattern, KindBirthNumber, validBirthNumber)
+}
+
+// validBirthNumber checks the date part and, for ten digits, the mod-11
+// check. Nine-digit numbers (born before 1954, no check digit) need the
+// slash, since nine digits alone are usually a phone number.
+func validBirthNumber(s string) bool {
+	n := digits(s)
+	if len(n) == 9 && !strings.Contains(s, "/") {
+		return false
+	}
+	month, _ := strconv.Atoi(n[2:4])
+	day, _ := strconv.Atoi(n[4:6])
+	switch {
+	case month >= 51 && month <= 62, month >= 71 && month <= 82: // women; +20 from 2004
+		month -= 50
+		if month > 12 {
+			month -= 20
+		}
+	case month >= 21 && month <= 32: // men from 2004
+		month -= 20
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return false
+	}
+	if len(n) == 9 {
+		return true
+	}
+	v, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		return false
+	}
+	if v%11 == 0 {
+		return true
+	}
+	// Before 1986 a remainder of 10 was written as check digit 0.
+	head, _ := strconv.ParseInt(n[:9], 10, 64)
+	return head%11 == 10 && n[9] == '0'
+}
+
+// Phone numbers
+
+// phonePattern matches international numbers (+ or 00) and Czech nine-digit
+// numbers (first digit 2–7 or 9), optionally grouped by spaces.
+var phonePattern = regexp.MustCompile(`(?:\+|\b00)[1-9]\d{0,2}(?: ?\d){6,12}\b|\b[2-79]\d{2} ?\d{3} ?\d{3}\b`)
+
+func redactPhones(text string) string {
+	return phonePattern.ReplaceAllString(text, Placeholder(KindPhone))
+}
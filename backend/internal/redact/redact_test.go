@@ -0,0 +1,104 @@
+package redact
+
+import "testing"
+
+// corpus pairs caller text with the expected result when every kind is
+// redacted. Texts are shaped like STT output: digits in groups, Czech words.
+var corpus = []struct {
+	name string
+	in   string
+	want string
+}{
+	// Birth numbers
+	{"birth number with slash", "Moje rodné číslo je 880101/1230.", "Moje rodné číslo je [BIRTH_NUMBER]."},
+	{"birth number without slash", "rodné číslo 8801011230", "rodné číslo [BIRTH_NUMBER]"},
+	{"birth number woman 2001", "015315/1240", "[BIRTH_NUMBER]"},
+	{"birth number spaced slash", "855212 / 1237", "[BIRTH_NUMBER]"},
+	{"birth number before 1954", "rodné číslo 450101/123", "rodné číslo [BIRTH_NUMBER]"},
+	{"birth number bad checksum", "880101/1234", "880101/1234"},
+	{"birth number bad month", "881301/1237", "881301/1237"},
+
+	// Bank accounts
+	{"iban grouped", "IBAN CZ65 0800 0000 1920 0014 5399 prosím", "IBAN [BANK_ACCOUNT] prosím"},
+	{"iban block", "CZ6508000000192000145399", "[BANK_ACCOUNT]"},
+	{"iban bad checksum", "CZ66 0800 0000 1920 0014 5399", "CZ66 0800 0000 1920 0014 5399"},
+	{"czech account with prefix", "číslo účtu 19-2000145399/0800", "číslo účtu [BANK_ACCOUNT]"},
+	{"czech account spaced", "2000145399 / 0800", "[BANK_ACCOUNT]"},
+	{"czech account bad checksum", "2000145398/0800", "2000145398/0800"},
+
+	// Payment cards
+	{"card grouped", "karta 4111 1111 1111 1111, platnost 12/27", "karta [CARD], platnost 12/27"},
+	{"card dashes", "5500-0000-0000-0004", "[CARD]"},
+	{"card block", "4111111111111111", "[CARD]"},
+	{"card bad luhn", "4111 1111 1111 1112", "4111 1111 1111 1112"},
+
+	// Emails
+	{"email", "pište na jan.novak@seznam.cz díky", "pište na [EMAIL] díky"},
+	{"email with diacritics", "petr@žluťoučký.cz", "[EMAIL]"},
+
+	// Phone numbers
+	{"phone international", "volejte +420 777 123 456", "volejte [PHONE]"},
+	{"phone 00 prefix", "00420777123456", "[PHONE]"},
+	{"phone national grouped", "číslo 777 123 456", "číslo [PHONE]"},
+	{"phone national block", "602123456", "[PHONE]"},
+
+	// Left alone
+	{"amount", "stojí to 100 000 000 korun", "stojí to 100 000 000 korun"},
+	{"date", "přijdu 12. 3. 2025 v 10:30", "přijdu 12. 3. 2025 v 10:30"},
+	{"order number", "objednávka 12345", "objednávka 12345"},
+	{"empty", "", ""},
+}
+
+func TestRedactCorpus(t *testing.T) {
+	r := New(AllKinds)
+	for _, tt := range corpus {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactOnlyConfiguredKinds(t *testing.T) {
+	text := "karta 4111 1111 1111 1111, volejte 777 123 456, mail jan@firma.cz"
+
+	if got, want := New(DefaultKinds).Redact(text), "karta [CARD], volejte 777 123 456, mail jan@firma.cz"; got != want {
+		t.Errorf("default kinds: got %q, want %q", got, want)
+	}
+	if got, want := New([]Kind{KindPhone, KindEmail}).Redact(text), "karta 4111 1111 1111 1111, volejte [PHONE], mail [EMAIL]"; got != want {
+		t.Errorf("phone and email: got %q, want %q", got, want)
+	}
+	if got := New(nil).Redact(text); got != text {
+		t.Errorf("no kinds should leave the text, got %q", got)
+	}
+	var nilRedactor *Redactor
+	if got := nilRedactor.Redact(text); got != text {
+		t.Errorf("nil redactor should leave the text, got %q", got)
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := ParseKinds([]string{"card", "email", "card"})
+	if err != nil || len(kinds) != 2 {
+		t.Errorf("ParseKinds = %v, %v", kinds, err)
+	}
+	if _, err := ParseKinds([]string{"passport"}); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	for n, want := range map[string]bool{
+		"4111111111111111": true,
+		"5500000000000004": true,
+		"378282246310005":  true,
+		"4111111111111112": false,
+		"0000000000000000": false,
+		"411111111111":     false,
+	} {
+		if got := luhn(n); got != want {
+			t.Errorf("luhn(%s) = %v, want %v", n, got, want)
+		}
+	}
+}
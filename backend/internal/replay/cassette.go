@@ -0,0 +1,189 @@
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+// ErrNotRecorded is returned in replay mode when a request has no recorded response.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// entry is a recorded LLM response.
+type entry struct {
+	Response  string               `json:"response,omitempty"`
+	Screening *llm.ScreeningResult `json:"screening,omitempty"`
+}
+
+// Cassette is an llm.Client that records responses of a live client to a
+// file, or serves previously recorded responses for offline runs.
+//
+// Requests are keyed by the system prompt, the conversation and a salt
+// identifying everything else that shapes the response (model, guardrails,
+// analysis prompt), so a recording made with different prompts misses
+// instead of silently returning stale responses.
+type Cassette struct {
+	inner        llm.Client // nil in replay mode
+	salt         string
+	systemPrompt string
+
+	mu      sync.Mutex
+	entries map[string]entry
+	dirty   bool
+}
+
+// NewRecordingCassette wraps a live client; responses are recorded and
+// written by Save.
+func NewRecordingCassette(inner llm.Client, salt string) *Cassette {
+	return &Cassette{
+		inner:        inner,
+		salt:         salt,
+		systemPrompt: inner.GetSystemPrompt(),
+		entries:      map[string]entry{},
+	}
+}
+
+// LoadCassette opens a recording for offline replay.
+func LoadCassette(path, salt string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{salt: salt, systemPrompt: llm.SystemPromptCzech, entries: map[string]entry{}}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes recorded responses to path, merged with any recording already there.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+
+	merged := map[string]entry{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &merged)
+	}
+	for k, v := range c.entries {
+		merged[k] = v
+	}
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (c *Cassette) key(kind string, messages []llm.Message) string {
+	h := sha256.New()
+	payload, _ := json.Marshal(struct {
+		Kind     string
+		Salt     string
+		Prompt   string
+		Messages []llm.Message
+	}{kind, c.salt, c.systemPrompt, messages})
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cassette) lookup(key string) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+func (c *Cassette) record(key string, e entry) {
+	c.mu.Lock()
+	c.entries[key] = e
+	c.dirty = true
+	c.mu.Unlock()
+}
+
+// AnalyzeCall returns the recorded screening result, or records the live one.
+func (c *Cassette) AnalyzeCall(ctx context.Context, messages []llm.Message) (*llm.ScreeningResult, error) {
+	key := c.key("analyze", messages)
+	if c.inner == nil {
+		e, ok := c.lookup(key)
+		if !ok || e.Screening == nil {
+			return nil, ErrNotRecorded
+		}
+		return e.Screening, nil
+	}
+
+	result, err := c.inner.AnalyzeCall(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	c.record(key, entry{Screening: result})
+	return result, nil
+}
+
+// GenerateResponse returns the recorded reply as a single chunk, or records
+// the live reply once its stream completes.
+func (c *Cassette) GenerateResponse(ctx context.Context, messages []llm.Message) (<-chan string, error) {
+	key := c.key("generate", messages)
+	if c.inner == nil {
+		e, ok := c.lookup(key)
+		if !ok {
+			return nil, ErrNotRecorded
+		}
+		ch := make(chan string, 1)
+		ch <- e.Response
+		close(ch)
+		return ch, nil
+	}
+
+	in, err := c.inner.GenerateResponse(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan string, 100)
+	go func() {
+		defer close(out)
+		var reply strings.Builder
+		for chunk := range in {
+			reply.WriteString(chunk)
+			select {
+			case <-ctx.Done():
+				// Drain so the inner client's goroutine can exit
+				for range in {
+				}
+				return
+			case out <- chunk:
+			}
+		}
+		if ctx.Err() == nil {
+			c.record(key, entry{Response: reply.String()})
+		}
+	}()
+	return out, nil
+}
+
+// SetSystemPrompt sets the system prompt used for subsequent requests.
+func (c *Cassette) SetSystemPrompt(prompt string) {
+	if prompt == "" {
+		return
+	}
+	c.systemPrompt = prompt
+	if c.inner != nil {
+		c.inner.SetSystemPrompt(prompt)
+	}
+}
+
+// GetSystemPrompt returns the current system prompt.
+func (c *Cassette) GetSystemPrompt() string {
+	return c.systemPrompt
+}
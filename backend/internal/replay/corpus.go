@@ -0,0 +1,95 @@
+// Package replay re-runs stored call transcripts through the LLM with
+// candidate prompts and compares the replies and screening labels against
+// what the calls originally produced. Used by cmd/replay for prompt
+// regression testing.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// Call is one transcript in a replay corpus.
+type Call struct {
+	CallSid       string      `json:"call_sid"`
+	TenantID      string      `json:"tenant_id,omitempty"`
+	SystemPrompt  string      `json:"system_prompt,omitempty"` // Prompt the call was answered with ("" = default prompt)
+	PromptVersion *int        `json:"prompt_version,omitempty"`
+	Utterances    []Utterance `json:"utterances"`
+	Screening     *Screening  `json:"screening,omitempty"` // Original screening result
+}
+
+// Utterance is one transcript line ("caller" or "agent").
+type Utterance struct {
+	Speaker string `json:"speaker"`
+	Text    string `json:"text"`
+}
+
+// Screening holds the screening labels compared by the replay.
+type Screening struct {
+	LegitimacyLabel      string  `json:"legitimacy_label"`
+	LegitimacyConfidence float64 `json:"legitimacy_confidence"`
+	LeadLabel            string  `json:"lead_label"`
+	IntentCategory       string  `json:"intent_category"`
+}
+
+// FromCallDetail converts a stored call into a corpus entry. systemPrompt is
+// the prompt the call was answered with.
+func FromCallDetail(d store.CallDetail, systemPrompt string) Call {
+	c := Call{
+		CallSid:       d.ProviderCallID,
+		SystemPrompt:  systemPrompt,
+		PromptVersion: d.PromptVersion,
+	}
+	if d.TenantID != nil {
+		c.TenantID = *d.TenantID
+	}
+	for _, u := range d.Utterances {
+		c.Utterances = append(c.Utterances, Utterance{Speaker: u.Speaker, Text: u.Text})
+	}
+	if d.Screening != nil {
+		c.Screening = &Screening{
+			LegitimacyLabel:      d.Screening.LegitimacyLabel,
+			LegitimacyConfidence: d.Screening.LegitimacyConfidence,
+			LeadLabel:            d.Screening.LeadLabel,
+			IntentCategory:       d.Screening.IntentCategory,
+		}
+	}
+	return c
+}
+
+// ReadCorpus reads a corpus in JSON Lines format (one Call per line).
+func ReadCorpus(r io.Reader) ([]Call, error) {
+	var calls []Call
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var c Call
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("corpus line %d: %w", line, err)
+		}
+		calls = append(calls, c)
+	}
+	return calls, scanner.Err()
+}
+
+// WriteCorpus writes calls in JSON Lines format.
+func WriteCorpus(w io.Writer, calls []Call) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, c := range calls {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+// Runner replays corpus calls through an LLM client.
+//
+// Each caller turn is replayed with the original conversation up to that
+// point (original agent replies, not candidate ones), so every candidate
+// reply is directly comparable to the reply the caller actually heard.
+// Screening is re-run over the original transcript.
+type Runner struct {
+	Client llm.Client
+	// SystemPrompt is the candidate prompt used for every call.
+	// Empty: each call is replayed with the prompt it was answered with.
+	SystemPrompt string
+	// Timeout per LLM request (default 30s)
+	Timeout time.Duration
+}
+
+// Report is the result of a replay run.
+type Report struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Summary     Summary      `json:"summary"`
+	Calls       []CallReport `json:"calls"`
+}
+
+// Summary aggregates a replay run.
+type Summary struct {
+	Calls              int     `json:"calls"`
+	Turns              int     `json:"turns"`
+	Errors             int     `json:"errors"`
+	AvgReplySimilarity float64 `json:"avg_reply_similarity"`
+	ScreenedCalls      int     `json:"screened_calls"` // Calls with an original screening to compare against
+	LegitimacyChanged  int     `json:"legitimacy_changed"`
+	LeadChanged        int     `json:"lead_changed"`
+}
+
+// CallReport compares one replayed call against the original.
+type CallReport struct {
+	CallSid           string       `json:"call_sid"`
+	Turns             []TurnResult `json:"turns"`
+	Original          *Screening   `json:"original_screening,omitempty"`
+	Candidate         *Screening   `json:"candidate_screening,omitempty"`
+	LegitimacyChanged bool         `json:"legitimacy_changed"`
+	LeadChanged       bool         `json:"lead_changed"`
+	Error             string       `json:"error,omitempty"`
+}
+
+// TurnResult compares the original and candidate reply to one caller turn.
+type TurnResult struct {
+	Caller     string  `json:"caller"`
+	Original   string  `json:"original"`
+	Candidate  string  `json:"candidate"`
+	Similarity float64 `json:"similarity"` // Word overlap (Jaccard), 0-1
+	Error      string  `json:"error,omitempty"`
+}
+
+// Run replays all calls sequentially (the client's system prompt is
+// switched per call) and builds the report.
+func (r *Runner) Run(ctx context.Context, calls []Call) *Report {
+	report := &Report{GeneratedAt: time.Now().UTC(), Calls: []CallReport{}}
+	similaritySum := 0.0
+
+	for _, call := range calls {
+		if ctx.Err() != nil {
+			break
+		}
+		cr := r.runCall(ctx, call)
+		report.Calls = append(report.Calls, cr)
+
+		report.Summary.Calls++
+		if cr.Error != "" {
+			report.Summary.Errors++
+		}
+		for _, t := range cr.Turns {
+			if t.Error != "" {
+				report.Summary.Errors++
+				continue
+			}
+			report.Summary.Turns++
+			similaritySum += t.Similarity
+		}
+		if cr.Original != nil && cr.Candidate != nil {
+			report.Summary.ScreenedCalls++
+			if cr.LegitimacyChanged {
+				report.Summary.LegitimacyChanged++
+			}
+			if cr.LeadChanged {
+				report.Summary.LeadChanged++
+			}
+		}
+	}
+	if report.Summary.Turns > 0 {
+		report.Summary.AvgReplySimilarity = similaritySum / float64(report.Summary.Turns)
+	}
+	return report
+}
+
+func (r *Runner) runCall(ctx context.Context, call Call) CallReport {
+	cr := CallReport{CallSid: call.CallSid, Original: call.Screening, Turns: []TurnResult{}}
+
+	prompt := r.SystemPrompt
+	if prompt == "" {
+		prompt = call.SystemPrompt
+	}
+	if prompt == "" {
+		prompt = llm.SystemPromptCzech
+	}
+	r.Client.SetSystemPrompt(prompt)
+
+	var history []llm.Message
+	var callerText []string
+	for i, u := range call.Utterances {
+		if u.Speaker != "caller" {
+			history = append(history, llm.Message{Role: "assistant", Content: u.Text})
+			continue
+		}
+		history = append(history, llm.Message{Role: "user", Content: u.Text})
+		callerText = append(callerText, u.Text)
+
+		// Reply once per caller turn: after the last of consecutive caller utterances
+		if i+1 < len(call.Utterances) && call.Utterances[i+1].Speaker == "caller" {
+			continue
+		}
+		turn := TurnResult{
+			Caller:   strings.Join(callerText, " "),
+			Original: originalReply(call.Utterances[i+1:]),
+		}
+		callerText = nil
+		if turn.Original == "" {
+			// Caller hung up before the agent replied: nothing to compare
+			continue
+		}
+
+		reply, err := r.generate(ctx, history)
+		if err != nil {
+			turn.Error = err.Error()
+		} else {
+			turn.Candidate = reply
+			turn.Similarity = Similarity(turn.Original, reply)
+		}
+		cr.Turns = append(cr.Turns, turn)
+	}
+
+	screening, err := r.analyze(ctx, history)
+	if err != nil {
+		cr.Error = fmt.Sprintf("analyze: %v", err)
+		return cr
+	}
+	cr.Candidate = screening
+	if cr.Original != nil {
+		cr.LegitimacyChanged = cr.Original.LegitimacyLabel != screening.LegitimacyLabel
+		cr.LeadChanged = cr.Original.LeadLabel != screening.LeadLabel
+	}
+	return cr
+}
+
+// originalReply joins the agent utterances that answered a caller turn.
+func originalReply(rest []Utterance) string {
+	var parts []string
+	for _, u := range rest {
+		if u.Speaker == "caller" {
+			break
+		}
+		parts = append(parts, u.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r *Runner) timeout() time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return 30 * time.Second
+}
+
+func (r *Runner) generate(ctx context.Context, history []llm.Message) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout())
+	defer cancel()
+
+	ch, err := r.Client.GenerateResponse(ctx, history)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for chunk := range ch {
+		sb.WriteString(chunk)
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func (r *Runner) analyze(ctx context.Context, history []llm.Message) (*Screening, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout())
+	defer cancel()
+
+	result, err := r.Client.AnalyzeCall(ctx, history)
+	if err != nil {
+		return nil, err
+	}
+	return &Screening{
+		LegitimacyLabel:      result.LegitimacyLabel,
+		LegitimacyConfidence: result.LegitimacyConfidence,
+		LeadLabel:            result.LeadLabel,
+		IntentCategory:       result.IntentCategory,
+	}, nil
+}
+
+// Similarity returns the word-set Jaccard similarity of two texts (0-1),
+// case-insensitive and ignoring punctuation. Two empty texts are identical.
+func Similarity(a, b string) float64 {
+	wa, wb := words(a), words(b)
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	inter := 0
+	for w := range wa {
+		if wb[w] {
+			inter++
+		}
+	}
+	union := len(wa) + len(wb) - inter
+	return float64(inter) / float64(union)
+}
+
+func words(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[w] = true
+	}
+	return set
+}
+
+// WriteText writes a human-readable report: the summary, every changed
+// screening label and every turn whose reply similarity is below threshold.
+func (rep *Report) WriteText(w io.Writer, threshold float64) {
+	s := rep.Summary
+	fmt.Fprintf(w, "Replayed %d calls, %d turns (%d errors)\n", s.Calls, s.Turns, s.Errors)
+	fmt.Fprintf(w, "Average reply similarity: %.2f\n", s.AvgReplySimilarity)
+	fmt.Fprintf(w, "Screening changes: legitimacy %d/%d, lead %d/%d\n",
+		s.LegitimacyChanged, s.ScreenedCalls, s.LeadChanged, s.ScreenedCalls)
+
+	for _, c := range rep.Calls {
+		var lines []string
+		if c.Error != "" {
+			lines = append(lines, "  error: "+c.Error)
+		}
+		if c.LegitimacyChanged {
+			lines = append(lines, fmt.Sprintf("  legitimacy: %s -> %s", c.Original.LegitimacyLabel, c.Candidate.LegitimacyLabel))
+		}
+		if c.LeadChanged {
+			lines = append(lines, fmt.Sprintf("  lead: %s -> %s", c.Original.LeadLabel, c.Candidate.LeadLabel))
+		}
+		for i, t := range c.Turns {
+			switch {
+			case t.Error != "":
+				lines = append(lines, fmt.Sprintf("  turn %d error: %s", i+1, t.Error))
+			case t.Similarity < threshold:
+				lines = append(lines,
+					fmt.Sprintf("  turn %d (similarity %.2f)", i+1, t.Similarity),
+					"    caller:    "+t.Caller,
+					"    original:  "+t.Original,
+					"    candidate: "+t.Candidate)
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(w, "\n%s\n%s\n", c.CallSid, strings.Join(lines, "\n"))
+		}
+	}
+}
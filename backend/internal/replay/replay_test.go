@@ -0,0 +1,229 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+// fakeClient replies with a fixed text per last user message and records requests.
+type fakeClient struct {
+	prompt    string
+	replies   map[string]string
+	screening *llm.ScreeningResult
+	generated [][]llm.Message
+	prompts   []string
+}
+
+func (f *fakeClient) AnalyzeCall(_ context.Context, _ []llm.Message) (*llm.ScreeningResult, error) {
+	if f.screening == nil {
+		return nil, errors.New("no screening")
+	}
+	return f.screening, nil
+}
+
+func (f *fakeClient) GenerateResponse(_ context.Context, messages []llm.Message) (<-chan string, error) {
+	f.generated = append(f.generated, messages)
+	f.prompts = append(f.prompts, f.prompt)
+	reply, ok := f.replies[messages[len(messages)-1].Content]
+	if !ok {
+		return nil, errors.New("unexpected request")
+	}
+	ch := make(chan string, 2)
+	half := len(reply) / 2
+	ch <- reply[:half]
+	ch <- reply[half:]
+	close(ch)
+	return ch, nil
+}
+
+func (f *fakeClient) SetSystemPrompt(prompt string) { f.prompt = prompt }
+func (f *fakeClient) GetSystemPrompt() string       { return f.prompt }
+
+func testCall() Call {
+	return Call{
+		CallSid:      "CA1",
+		SystemPrompt: "original prompt",
+		Utterances: []Utterance{
+			{Speaker: "agent", Text: "Dobrý den, co potřebujete?"},
+			{Speaker: "caller", Text: "Dobrý den,"},
+			{Speaker: "caller", Text: "volám ohledně faktury."},
+			{Speaker: "agent", Text: "Rozumím, jak se jmenujete?"},
+			{Speaker: "caller", Text: "Jan Novák."},
+			{Speaker: "agent", Text: "Děkuji, předám to."},
+			{Speaker: "caller", Text: "Na shledanou."},
+		},
+		Screening: &Screening{LegitimacyLabel: "legitimní", LeadLabel: "nezájem"},
+	}
+}
+
+func TestRunnerRun(t *testing.T) {
+	client := &fakeClient{
+		replies: map[string]string{
+			"volám ohledně faktury.": "Rozumím, jak se jmenujete?",
+			"Jan Novák.":             "Děkuji, pane Nováku.",
+		},
+		screening: &llm.ScreeningResult{LegitimacyLabel: "legitimní", LeadLabel: "zájem"},
+	}
+	runner := &Runner{Client: client}
+	report := runner.Run(context.Background(), []Call{testCall()})
+
+	// Consecutive caller utterances form one turn; the final goodbye has no reply
+	if len(client.generated) != 2 {
+		t.Fatalf("expected 2 generate requests, got %d", len(client.generated))
+	}
+	first := client.generated[0]
+	if len(first) != 3 || first[0].Role != "assistant" || first[1].Role != "user" {
+		t.Errorf("unexpected history for first turn: %+v", first)
+	}
+	// Second turn is replayed with the original agent reply in history
+	if got := client.generated[1][3].Content; got != "Rozumím, jak se jmenujete?" {
+		t.Errorf("expected original reply in history, got %q", got)
+	}
+	if client.prompts[0] != "original prompt" {
+		t.Errorf("expected call's original prompt, got %q", client.prompts[0])
+	}
+
+	cr := report.Calls[0]
+	if len(cr.Turns) != 2 {
+		t.Fatalf("expected 2 turns, got %d", len(cr.Turns))
+	}
+	if cr.Turns[0].Caller != "Dobrý den, volám ohledně faktury." {
+		t.Errorf("unexpected caller text %q", cr.Turns[0].Caller)
+	}
+	if cr.Turns[0].Similarity != 1 {
+		t.Errorf("expected identical reply similarity 1, got %f", cr.Turns[0].Similarity)
+	}
+	if cr.Turns[1].Candidate != "Děkuji, pane Nováku." {
+		t.Errorf("unexpected candidate %q", cr.Turns[1].Candidate)
+	}
+	if cr.LegitimacyChanged || !cr.LeadChanged {
+		t.Errorf("expected only lead change, got legitimacy=%v lead=%v", cr.LegitimacyChanged, cr.LeadChanged)
+	}
+
+	s := report.Summary
+	if s.Calls != 1 || s.Turns != 2 || s.Errors != 0 || s.ScreenedCalls != 1 || s.LeadChanged != 1 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	var out strings.Builder
+	report.WriteText(&out, 0.5)
+	if !strings.Contains(out.String(), "lead: nezájem -> zájem") || !strings.Contains(out.String(), "candidate: Děkuji, pane Nováku.") {
+		t.Errorf("unexpected text report:\n%s", out.String())
+	}
+}
+
+func TestRunnerRun_CandidatePromptAndErrors(t *testing.T) {
+	client := &fakeClient{replies: map[string]string{"volám ohledně faktury.": "Ano?"}}
+	runner := &Runner{Client: client, SystemPrompt: "candidate prompt"}
+	report := runner.Run(context.Background(), []Call{testCall()})
+
+	if client.prompts[0] != "candidate prompt" {
+		t.Errorf("expected candidate prompt, got %q", client.prompts[0])
+	}
+	cr := report.Calls[0]
+	if cr.Turns[1].Error == "" {
+		t.Error("expected turn error for unexpected request")
+	}
+	if !strings.HasPrefix(cr.Error, "analyze:") {
+		t.Errorf("expected analyze error, got %q", cr.Error)
+	}
+	if report.Summary.Errors != 2 || report.Summary.Turns != 1 || report.Summary.ScreenedCalls != 0 {
+		t.Errorf("unexpected summary %+v", report.Summary)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"Dobrý den!", "dobrý DEN", 1},
+		{"a b c d", "a b", 0.5},
+		{"ahoj", "", 0},
+		{"jedna dva", "tři čtyři", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCorpusRoundTrip(t *testing.T) {
+	v := 3
+	calls := []Call{testCall(), {CallSid: "CA2", PromptVersion: &v, Utterances: []Utterance{{Speaker: "caller", Text: "<haló>"}}}}
+
+	var buf strings.Builder
+	if err := WriteCorpus(&buf, calls); err != nil {
+		t.Fatalf("WriteCorpus: %v", err)
+	}
+	if strings.Count(buf.String(), "\n") != 2 || strings.Contains(buf.String(), `\u003c`) {
+		t.Errorf("unexpected corpus output:\n%s", buf.String())
+	}
+
+	got, err := ReadCorpus(strings.NewReader(buf.String() + "\n"))
+	if err != nil {
+		t.Fatalf("ReadCorpus: %v", err)
+	}
+	if len(got) != 2 || got[0].Screening.LeadLabel != "nezájem" || *got[1].PromptVersion != 3 || got[1].Utterances[0].Text != "<haló>" {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	if _, err := ReadCorpus(strings.NewReader("{}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line 2 error, got %v", err)
+	}
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	live := &fakeClient{
+		replies:   map[string]string{"volám ohledně faktury.": "Rozumím.", "Jan Novák.": "Děkuji."},
+		screening: &llm.ScreeningResult{LegitimacyLabel: "legitimní", LeadLabel: "zájem"},
+	}
+
+	recording := NewRecordingCassette(live, "salt")
+	recorded := (&Runner{Client: recording}).Run(context.Background(), []Call{testCall()})
+	if recorded.Summary.Errors != 0 {
+		t.Fatalf("unexpected errors while recording: %+v", recorded.Calls)
+	}
+	if err := recording.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	offline, err := LoadCassette(path, "salt")
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	replayed := (&Runner{Client: offline}).Run(context.Background(), []Call{testCall()})
+	if replayed.Summary.Errors != 0 {
+		t.Fatalf("unexpected errors in replay: %+v", replayed.Calls)
+	}
+	for i, turn := range replayed.Calls[0].Turns {
+		if turn.Candidate != recorded.Calls[0].Turns[i].Candidate {
+			t.Errorf("turn %d: replayed %q, recorded %q", i, turn.Candidate, recorded.Calls[0].Turns[i].Candidate)
+		}
+	}
+	if replayed.Calls[0].Candidate.LeadLabel != "zájem" {
+		t.Errorf("expected recorded screening, got %+v", replayed.Calls[0].Candidate)
+	}
+
+	// A different prompt or salt misses the recording
+	changed := (&Runner{Client: offline, SystemPrompt: "new prompt"}).Run(context.Background(), []Call{testCall()})
+	if changed.Calls[0].Turns[0].Error != ErrNotRecorded.Error() {
+		t.Errorf("expected ErrNotRecorded for changed prompt, got %+v", changed.Calls[0].Turns[0])
+	}
+	otherSalt, err := LoadCassette(path, "other")
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if _, err := otherSalt.AnalyzeCall(context.Background(), nil); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded for other salt, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Package retention purges call data that is past the tenant's retention
+// policy. Defaults come from global_config and tenants can override each
+// category (transcripts, event logs, calls); 0 days keeps data forever.
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+// Global config keys holding the default retention in days.
+const (
+	ConfigTranscriptDays = "retention_transcript_days"
+	ConfigEventDays      = "retention_event_days"
+	ConfigCallDays       = "retention_call_days"
+)
+
+// MaxDays is the longest retention that can be configured (10 years).
+const MaxDays = 3650
+
+// batchSize is how many calls (events: rows) one purge statement removes.
+const batchSize = 500
+
+// Policy is an effective retention policy in days (0 = forever).
+type Policy struct {
+	TranscriptDays int `json:"transcript_days"`
+	EventDays      int `json:"event_days"`
+	CallDays       int `json:"call_days"`
+}
+
+// Defaults reads the default policy from global config.
+func Defaults(ctx context.Context, st *store.Store) Policy {
+	return Policy{
+		TranscriptDays: clampDays(st.GetGlobalConfigInt(ctx, ConfigTranscriptDays, 0)),
+		EventDays:      clampDays(st.GetGlobalConfigInt(ctx, ConfigEventDays, 0)),
+		CallDays:       clampDays(st.GetGlobalConfigInt(ctx, ConfigCallDays, 0)),
+	}
+}
+
+// Resolve applies a tenant's overrides to the defaults.
+func Resolve(defaults Policy, override store.RetentionPolicy) Policy {
+	p := defaults
+	if override.TranscriptDays != nil {
+		p.TranscriptDays = clampDays(*override.TranscriptDays)
+	}
+	if override.EventDays != nil {
+		p.EventDays = clampDays(*override.EventDays)
+	}
+	if override.CallDays != nil {
+		p.CallDays = clampDays(*override.CallDays)
+	}
+	return p
+}
+
+// Cutoffs are the times before which data is purged (nil = kept forever).
+type Cutoffs struct {
+	Transcripts *time.Time `json:"transcripts"`
+	Events      *time.Time `json:"events"`
+	Calls       *time.Time `json:"calls"`
+}
+
+// Cutoffs returns the policy's cutoffs at now.
+func (p Policy) Cutoffs(now time.Time) Cutoffs {
+	return Cutoffs{
+		Transcripts: cutoff(now, p.TranscriptDays),
+		Events:      cutoff(now, p.EventDays),
+		Calls:       cutoff(now, p.CallDays),
+	}
+}
+
+func cutoff(now time.Time, days int) *time.Time {
+	if days <= 0 {
+		return nil
+	}
+	t := now.AddDate(0, 0, -days)
+	return &t
+}
+
+// ValidDays reports whether days is an acceptable retention setting.
+func ValidDays(days int) bool {
+	return days >= 0 && days <= MaxDays
+}
+
+func clampDays(days int) int {
+	if days < 0 {
+		return 0
+	}
+	if days > MaxDays {
+		return MaxDays
+	}
+	return days
+}
+
+// TenantPreview is what the next purge would remove for one tenant. Calls
+// without a tenant have an empty TenantID and use the defaults.
+type TenantPreview struct {
+	TenantID   string                `json:"tenant_id"`
+	TenantName string                `json:"tenant_name"`
+	Policy     Policy                `json:"policy"`
+	Cutoffs    Cutoffs               `json:"cutoffs"`
+	Pending    store.RetentionCounts `json:"pending"`
+}
+
+// Purger deletes expired call data.
+type Purger struct {
+	store  *store.Store
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewPurger creates a purger.
+func NewPurger(st *store.Store, logger *slog.Logger) *Purger {
+	return &Purger{store: st, logger: logger, now: time.Now}
+}
+
+// Run purges once per interval until ctx is cancelled, starting right away.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.PurgeOnce(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("retention: purge failed", "error", err)
+			sentry.CaptureException(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// target is a tenant (nil = calls without a tenant) and its policy.
+type target struct {
+	tenantID   *string
+	tenantName string
+	policy     Policy
+}
+
+func (p *Purger) targets(ctx context.Context) ([]target, error) {
+	defaults := Defaults(ctx, p.store)
+	tenants, err := p.store.ListRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]target, 0, len(tenants)+1)
+	for _, t := range tenants {
+		id := t.TenantID
+		targets = append(targets, target{tenantID: &id, tenantName: t.TenantName, policy: Resolve(defaults, t.Policy)})
+	}
+	return append(targets, target{policy: defaults}), nil
+}
+
+// PurgeOnce removes everything currently past retention, in batches, and
+// returns the totals. Calls go first so their transcripts and events aren't
+// purged separately.
+func (p *Purger) PurgeOnce(ctx context.Context) (store.RetentionCounts, error) {
+	var total store.RetentionCounts
+	targets, err := p.targets(ctx)
+	if err != nil {
+		return total, err
+	}
+	now := p.now()
+	for _, t := range targets {
+		c := t.policy.Cutoffs(now)
+		var n store.RetentionCounts
+		if c.Calls != nil {
+			if n.Calls, err = drain(ctx, func() (int64, error) {
+				return p.store.PurgeCalls(ctx, t.tenantID, *c.Calls, batchSize)
+			}); err != nil {
+				return total, err
+			}
+		}
+		if c.Transcripts != nil {
+			if n.Transcripts, err = drain(ctx, func() (int64, error) {
+				return p.store.PurgeTranscripts(ctx, t.tenantID, *c.Transcripts, batchSize)
+			}); err != nil {
+				return total, err
+			}
+		}
+		if c.Events != nil {
+			if n.Events, err = drain(ctx, func() (int64, error) {
+				return p.store.PurgeCallEvents(ctx, t.tenantID, *c.Events, batchSize)
+			}); err != nil {
+				return total, err
+			}
+		}
+		if n != (store.RetentionCounts{}) {
+			p.logger.Info("retention: purged expired data", "tenant_id", derefString(t.tenantID),
+				"calls", n.Calls, "transcripts", n.Transcripts, "events", n.Events)
+		}
+		total.Calls += n.Calls
+		total.Transcripts += n.Transcripts
+		total.Events += n.Events
+	}
+	return total, nil
+}
+
+// drain repeats a batch purge until a batch comes back short.
+func drain(ctx context.Context, batch func() (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		n, err := batch()
+		total += n
+		if err != nil || n < batchSize {
+			return total, err
+		}
+	}
+}
+
+// Preview returns, per tenant, the policy in effect and what the next purge
+// would remove. Tenants with nothing pending are included.
+func (p *Purger) Preview(ctx context.Context) ([]TenantPreview, error) {
+	targets, err := p.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := p.now()
+	out := make([]TenantPreview, 0, len(targets))
+	for _, t := range targets {
+		c := t.policy.Cutoffs(now)
+		pending, err := p.store.CountRetentionBacklog(ctx, t.tenantID, c.Transcripts, c.Events, c.Calls)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, TenantPreview{
+			TenantID:   derefString(t.tenantID),
+			TenantName: t.tenantName,
+			Policy:     t.policy,
+			Cutoffs:    c,
+			Pending:    pending,
+		})
+	}
+	return out, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,73 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/store"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestResolve(t *testing.T) {
+	defaults := Policy{TranscriptDays: 90, EventDays: 30, CallDays: 0}
+
+	if got := Resolve(defaults, store.RetentionPolicy{}); got != defaults {
+		t.Errorf("no override: got %+v, want defaults", got)
+	}
+
+	got := Resolve(defaults, store.RetentionPolicy{TranscriptDays: intPtr(0), CallDays: intPtr(5000)})
+	want := Policy{TranscriptDays: 0, EventDays: 30, CallDays: MaxDays}
+	if got != want {
+		t.Errorf("override: got %+v, want %+v", got, want)
+	}
+}
+
+func TestPolicyCutoffs(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	c := Policy{TranscriptDays: 30, EventDays: 0, CallDays: 365}.Cutoffs(now)
+
+	if c.Transcripts == nil || !c.Transcripts.Equal(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("transcripts cutoff = %v", c.Transcripts)
+	}
+	if c.Events != nil {
+		t.Errorf("0 days should keep events forever, got cutoff %v", c.Events)
+	}
+	if c.Calls == nil || !c.Calls.Equal(time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("calls cutoff = %v", c.Calls)
+	}
+}
+
+func TestValidDays(t *testing.T) {
+	for days, want := range map[int]bool{-1: false, 0: true, 30: true, MaxDays: true, MaxDays + 1: false} {
+		if got := ValidDays(days); got != want {
+			t.Errorf("ValidDays(%d) = %v, want %v", days, got, want)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	batches := []int64{batchSize, batchSize, 7}
+	calls := 0
+	total, err := drain(context.Background(), func() (int64, error) {
+		n := batches[calls]
+		calls++
+		return n, nil
+	})
+	if err != nil || total != 2*batchSize+7 || calls != 3 {
+		t.Errorf("drain = %d, %v after %d batches", total, err, calls)
+	}
+
+	boom := errors.New("boom")
+	if _, err := drain(context.Background(), func() (int64, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Errorf("expected batch error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := drain(ctx, func() (int64, error) { return batchSize, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context error, got %v", err)
+	}
+}
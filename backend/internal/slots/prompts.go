@@ -0,0 +1,94 @@
+package slots
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+// Guidance returns the system message that steers the conversation towards
+// the missing fields, or "" if nothing is missing. Required fields must be
+// collected before saying goodbye; optional ones only when it fits.
+func Guidance(missing []Field) string {
+	var required, optional []Field
+	for _, f := range missing {
+		if f.Required {
+			required = append(required, f)
+		} else {
+			optional = append(optional, f)
+		}
+	}
+	if len(required) == 0 && len(optional) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Údaje, které od volajícího ještě potřebuješ (ptej se vždy jen na jeden, až zjistíš účel hovoru):")
+	if len(required) > 0 {
+		sb.WriteString("\nPovinné – nerozlučuj se, dokud je nezjistíš (pokud je volající odmítne sdělit, nenaléhej):")
+		writeFieldLines(&sb, required)
+	}
+	if len(optional) > 0 {
+		sb.WriteString("\nNepovinné – zeptej se, jen pokud to přirozeně zapadá do rozhovoru:")
+		writeFieldLines(&sb, optional)
+	}
+	return sb.String()
+}
+
+func writeFieldLines(sb *strings.Builder, fields []Field) {
+	for _, f := range fields {
+		fmt.Fprintf(sb, "\n- %s", f.Label)
+		if f.Prompt != "" {
+			fmt.Fprintf(sb, " (%s)", f.Prompt)
+		}
+		if f.Type == TypeChoice {
+			fmt.Fprintf(sb, " – možnosti: %s", strings.Join(f.Options, ", "))
+		}
+	}
+}
+
+// typeFormats describes the expected value format per field type.
+var typeFormats = map[string]string{
+	TypeText:   "text",
+	TypeNumber: "číslo",
+	TypeDate:   "datum ve formátu RRRR-MM-DD",
+	TypeTime:   "čas ve formátu HH:MM",
+	TypePhone:  "telefonní číslo",
+	TypeEmail:  "e-mailová adresa",
+	TypeChoice: "jedna z možností",
+}
+
+// ExtractionPrompt builds the prompt that extracts the field values from the
+// conversation. The model answers with the values in the "entities" object
+// of the screening JSON, so extraction can run through llm.Client.AnalyzeCall.
+func ExtractionPrompt(fields []Field) string {
+	var sb strings.Builder
+	sb.WriteString("Na základě konverzace vyplň údaje, které volající sdělil. Odpověz POUZE validním JSON:\n\n")
+	sb.WriteString("{\n  \"entities\": {\n")
+	for i, f := range fields {
+		format := typeFormats[f.Type]
+		if f.Type == TypeChoice {
+			format += " " + strings.Join(f.Options, "|")
+		}
+		fmt.Fprintf(&sb, "    %q: \"%s: %s, nebo null\"", f.Key, f.Label, format)
+		if i < len(fields)-1 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  }\n}\n\n")
+	sb.WriteString("Všechny hodnoty uveď jako řetězce. Údaj, který volající neřekl, nastav na null – nic si nedomýšlej.")
+	return sb.String()
+}
+
+// Extract asks the LLM for the field values in the conversation and returns
+// them by field key (unparsed; see State.Merge).
+func Extract(ctx context.Context, client llm.Client, fields []Field, msgs []llm.Message) (map[string]string, error) {
+	result, err := client.AnalyzeCall(llm.WithAnalysisPrompt(ctx, ExtractionPrompt(fields)), msgs)
+	if err != nil {
+		return nil, err
+	}
+	return result.Entities, nil
+}
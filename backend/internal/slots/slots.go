@@ -0,0 +1,285 @@
+// Package slots implements tenant-defined call fields ("slots"): structured
+// values such as an address or an order number that the assistant collects
+// during a call. It validates field definitions, parses collected values into
+// typed form, tracks which slots are filled during a call and builds the
+// prompts that steer the conversation and extract the values.
+package slots
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Field types.
+const (
+	TypeText   = "text"
+	TypeNumber = "number"
+	TypeDate   = "date"   // Calendar date, stored as YYYY-MM-DD
+	TypeTime   = "time"   // Time of day, stored as HH:MM
+	TypePhone  = "phone"  // Phone number, stored as digits with an optional leading +
+	TypeEmail  = "email"  // E-mail address
+	TypeChoice = "choice" // One of Options
+)
+
+var validTypes = map[string]bool{
+	TypeText: true, TypeNumber: true, TypeDate: true, TypeTime: true,
+	TypePhone: true, TypeEmail: true, TypeChoice: true,
+}
+
+// Field definition limits.
+const (
+	MaxFields          = 20
+	maxLabelLength     = 60
+	maxPromptLength    = 300
+	maxOptions         = 30
+	maxOptionLength    = 60
+	maxTextValueLength = 500
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// Field is a value the assistant should collect on a call.
+type Field struct {
+	Key      string   `json:"key"`                // Identifier, e.g. "order_number"
+	Label    string   `json:"label"`              // Display name, e.g. "Číslo objednávky"
+	Type     string   `json:"type"`               // One of the Type constants
+	Prompt   string   `json:"prompt,omitempty"`   // How the assistant should ask for it
+	Required bool     `json:"required,omitempty"` // The assistant keeps asking until collected
+	Options  []string `json:"options,omitempty"`  // Allowed values of a choice field
+}
+
+// ValidateFields checks a tenant's field definitions.
+func ValidateFields(fields []Field) error {
+	if len(fields) > MaxFields {
+		return fmt.Errorf("at most %d fields are allowed", MaxFields)
+	}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if !keyPattern.MatchString(f.Key) {
+			return fmt.Errorf("field key %q must be lowercase letters, digits and underscores (max 40)", f.Key)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("duplicate field key %q", f.Key)
+		}
+		seen[f.Key] = true
+		if strings.TrimSpace(f.Label) == "" || utf8.RuneCountInString(f.Label) > maxLabelLength {
+			return fmt.Errorf("field %q: label is required (max %d characters)", f.Key, maxLabelLength)
+		}
+		if !validTypes[f.Type] {
+			return fmt.Errorf("field %q: unknown type %q", f.Key, f.Type)
+		}
+		if utf8.RuneCountInString(f.Prompt) > maxPromptLength {
+			return fmt.Errorf("field %q: prompt is too long", f.Key)
+		}
+		if f.Type == TypeChoice {
+			if len(f.Options) == 0 || len(f.Options) > maxOptions {
+				return fmt.Errorf("field %q: a choice needs 1 to %d options", f.Key, maxOptions)
+			}
+			for _, o := range f.Options {
+				if strings.TrimSpace(o) == "" || utf8.RuneCountInString(o) > maxOptionLength {
+					return fmt.Errorf("field %q: invalid option %q", f.Key, o)
+				}
+			}
+		} else if len(f.Options) > 0 {
+			return fmt.Errorf("field %q: options are only allowed for choice fields", f.Key)
+		}
+	}
+	return nil
+}
+
+// Value is a collected field value. Text is the canonical text form; number
+// and date fields also carry the typed value.
+type Value struct {
+	Key    string     `json:"key"`
+	Type   string     `json:"type"`
+	Text   string     `json:"text"`
+	Number *float64   `json:"number,omitempty"`
+	Date   *time.Time `json:"date,omitempty"`
+}
+
+// ErrEmpty is returned by Parse for an empty or null value.
+var ErrEmpty = errors.New("empty value")
+
+// Parse converts a raw value (as extracted by the LLM or given in a filter)
+// into the field's typed form.
+func (f Field) Parse(raw string) (Value, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.EqualFold(raw, "null") {
+		return Value{}, ErrEmpty
+	}
+	v := Value{Key: f.Key, Type: f.Type}
+
+	switch f.Type {
+	case TypeNumber:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(strings.ReplaceAll(raw, " ", ""), ",", "."), 64)
+		if err != nil {
+			return Value{}, fmt.Errorf("not a number: %q", raw)
+		}
+		v.Number = &n
+		v.Text = strconv.FormatFloat(n, 'f', -1, 64)
+	case TypeDate:
+		d, err := parseDate(raw)
+		if err != nil {
+			return Value{}, err
+		}
+		v.Date = &d
+		v.Text = d.Format(time.DateOnly)
+	case TypeTime:
+		t, err := parseTime(raw)
+		if err != nil {
+			return Value{}, err
+		}
+		v.Text = t
+	case TypePhone:
+		p := normalizePhone(raw)
+		if digits := strings.TrimPrefix(p, "+"); len(digits) < 6 || len(digits) > 15 {
+			return Value{}, fmt.Errorf("not a phone number: %q", raw)
+		}
+		v.Text = p
+	case TypeEmail:
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return Value{}, fmt.Errorf("not an e-mail address: %q", raw)
+		}
+		v.Text = strings.ToLower(addr.Address)
+	case TypeChoice:
+		for _, o := range f.Options {
+			if strings.EqualFold(strings.TrimSpace(o), raw) {
+				v.Text = o
+				return v, nil
+			}
+		}
+		return Value{}, fmt.Errorf("%q is not one of the options", raw)
+	default:
+		if utf8.RuneCountInString(raw) > maxTextValueLength {
+			raw = string([]rune(raw)[:maxTextValueLength])
+		}
+		v.Text = raw
+	}
+	return v, nil
+}
+
+// parseDate accepts ISO (2025-03-14) and Czech (14.3.2025, 14. 3. 2025) dates.
+func parseDate(raw string) (time.Time, error) {
+	if d, err := time.Parse(time.DateOnly, raw); err == nil {
+		return d, nil
+	}
+	if d, err := time.Parse("2.1.2006", strings.ReplaceAll(raw, " ", "")); err == nil {
+		return d, nil
+	}
+	return time.Time{}, fmt.Errorf("not a date: %q", raw)
+}
+
+// parseTime accepts 14:30, 14.30 and 14 (whole hour) and returns HH:MM.
+func parseTime(raw string) (string, error) {
+	s := strings.ReplaceAll(raw, ".", ":")
+	if !strings.Contains(s, ":") {
+		s += ":00"
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return "", fmt.Errorf("not a time: %q", raw)
+	}
+	return t.Format("15:04"), nil
+}
+
+// normalizePhone keeps digits and a leading +.
+func normalizePhone(raw string) string {
+	var sb strings.Builder
+	for i, r := range raw {
+		if r >= '0' && r <= '9' || r == '+' && i == 0 {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// State tracks the slots collected during a call. It is safe for concurrent use.
+type State struct {
+	fields []Field
+
+	mu     sync.Mutex
+	values map[string]Value
+}
+
+// NewState returns an empty state for the fields.
+func NewState(fields []Field) *State {
+	return &State{fields: fields, values: map[string]Value{}}
+}
+
+// Fields returns the field definitions.
+func (s *State) Fields() []Field {
+	return s.fields
+}
+
+// Merge parses extracted raw values (keyed by field key) and records the
+// valid ones; a later value replaces an earlier one. It returns the keys
+// whose value changed. Unknown keys and unparsable values are ignored.
+func (s *State) Merge(raw map[string]string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed []string
+	for _, f := range s.fields {
+		r, ok := raw[f.Key]
+		if !ok {
+			continue
+		}
+		v, err := f.Parse(r)
+		if err != nil {
+			continue
+		}
+		if old, ok := s.values[f.Key]; ok && old.Text == v.Text {
+			continue
+		}
+		s.values[f.Key] = v
+		changed = append(changed, f.Key)
+	}
+	return changed
+}
+
+// Missing returns the fields not collected yet, in definition order.
+func (s *State) Missing() []Field {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var missing []Field
+	for _, f := range s.fields {
+		if _, ok := s.values[f.Key]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// Values returns the collected values, in definition order.
+func (s *State) Values() []Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Value
+	for _, f := range s.fields {
+		if v, ok := s.values[f.Key]; ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// RequiredKeys returns the keys of the required fields in fields.
+func RequiredKeys(fields []Field) []string {
+	var keys []string
+	for _, f := range fields {
+		if f.Required {
+			keys = append(keys, f.Key)
+		}
+	}
+	return keys
+}
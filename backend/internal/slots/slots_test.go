@@ -0,0 +1,214 @@
+package slots
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+func testFields() []Field {
+	return []Field{
+		{Key: "address", Label: "Adresa", Type: TypeText, Prompt: "ulice, číslo a město", Required: true},
+		{Key: "order_number", Label: "Číslo objednávky", Type: TypeNumber},
+		{Key: "callback_time", Label: "Čas zpětného volání", Type: TypeTime, Required: true},
+		{Key: "service", Label: "Služba", Type: TypeChoice, Options: []string{"Oprava", "Montáž"}},
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func([]Field) []Field
+		want   string
+	}{
+		{"valid", func(f []Field) []Field { return f }, ""},
+		{"none", func([]Field) []Field { return nil }, ""},
+		{"bad key", func(f []Field) []Field { f[0].Key = "Adresa"; return f }, "lowercase"},
+		{"duplicate key", func(f []Field) []Field { f[1].Key = "address"; return f }, "duplicate"},
+		{"missing label", func(f []Field) []Field { f[0].Label = " "; return f }, "label is required"},
+		{"unknown type", func(f []Field) []Field { f[0].Type = "address"; return f }, "unknown type"},
+		{"prompt too long", func(f []Field) []Field { f[0].Prompt = strings.Repeat("a", maxPromptLength+1); return f }, "prompt is too long"},
+		{"choice without options", func(f []Field) []Field { f[3].Options = nil; return f }, "needs 1 to"},
+		{"blank option", func(f []Field) []Field { f[3].Options = []string{" "}; return f }, "invalid option"},
+		{"options on text", func(f []Field) []Field { f[0].Options = []string{"a"}; return f }, "only allowed for choice"},
+		{"too many", func(f []Field) []Field {
+			for i := 0; i < MaxFields; i++ {
+				f = append(f, Field{Key: "f" + strings.Repeat("x", i), Label: "F", Type: TypeText})
+			}
+			return f
+		}, "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFields(tt.modify(testFields()))
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldParse(t *testing.T) {
+	tests := []struct {
+		field    Field
+		raw      string
+		wantText string
+		wantErr  bool
+	}{
+		{Field{Type: TypeText}, "  Dlouhá 5, Praha ", "Dlouhá 5, Praha", false},
+		{Field{Type: TypeText}, "null", "", true},
+		{Field{Type: TypeText}, "", "", true},
+		{Field{Type: TypeNumber}, "12 345,5", "12345.5", false},
+		{Field{Type: TypeNumber}, "dvanáct", "", true},
+		{Field{Type: TypeDate}, "2025-03-14", "2025-03-14", false},
+		{Field{Type: TypeDate}, "14. 3. 2025", "2025-03-14", false},
+		{Field{Type: TypeDate}, "zítra", "", true},
+		{Field{Type: TypeTime}, "14.30", "14:30", false},
+		{Field{Type: TypeTime}, "9", "09:00", false},
+		{Field{Type: TypeTime}, "25:00", "", true},
+		{Field{Type: TypePhone}, "+420 777 123 456", "+420777123456", false},
+		{Field{Type: TypePhone}, "123", "", true},
+		{Field{Type: TypeEmail}, "Jan.Novak@Example.cz", "jan.novak@example.cz", false},
+		{Field{Type: TypeEmail}, "jan novak", "", true},
+		{Field{Type: TypeChoice, Options: []string{"Oprava", "Montáž"}}, "montáž", "Montáž", false},
+		{Field{Type: TypeChoice, Options: []string{"Oprava"}}, "Revize", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Type+"/"+tt.raw, func(t *testing.T) {
+			v, err := tt.field.Parse(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if v.Text != tt.wantText {
+				t.Errorf("Parse(%q) = %q, want %q", tt.raw, v.Text, tt.wantText)
+			}
+		})
+	}
+
+	v, _ := Field{Key: "n", Type: TypeNumber}.Parse("42")
+	if v.Number == nil || *v.Number != 42 || v.Key != "n" {
+		t.Errorf("number value not typed: %+v", v)
+	}
+	d, _ := Field{Type: TypeDate}.Parse("1.2.2025")
+	if d.Date == nil || !d.Date.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date value not typed: %+v", d)
+	}
+}
+
+func TestState(t *testing.T) {
+	s := NewState(testFields())
+	if got := len(s.Missing()); got != 4 {
+		t.Fatalf("expected 4 missing fields, got %d", got)
+	}
+
+	changed := s.Merge(map[string]string{
+		"address":       "Dlouhá 5, Praha",
+		"order_number":  "není číslo",
+		"callback_time": "",
+		"unknown":       "x",
+	})
+	if !reflect.DeepEqual(changed, []string{"address"}) {
+		t.Errorf("changed = %v, want [address]", changed)
+	}
+
+	// Same value again is not a change; a new value replaces it
+	if changed := s.Merge(map[string]string{"address": "Dlouhá 5, Praha"}); len(changed) != 0 {
+		t.Errorf("unexpected change %v", changed)
+	}
+	if changed := s.Merge(map[string]string{"address": "Krátká 1, Brno", "callback_time": "16:00"}); len(changed) != 2 {
+		t.Errorf("expected 2 changes, got %v", changed)
+	}
+
+	var missing []string
+	for _, f := range s.Missing() {
+		missing = append(missing, f.Key)
+	}
+	if !reflect.DeepEqual(missing, []string{"order_number", "service"}) {
+		t.Errorf("missing = %v", missing)
+	}
+	values := s.Values()
+	if len(values) != 2 || values[0].Text != "Krátká 1, Brno" || values[1].Text != "16:00" {
+		t.Errorf("unexpected values %+v", values)
+	}
+	if keys := RequiredKeys(testFields()); !reflect.DeepEqual(keys, []string{"address", "callback_time"}) {
+		t.Errorf("RequiredKeys = %v", keys)
+	}
+}
+
+func TestGuidance(t *testing.T) {
+	if got := Guidance(nil); got != "" {
+		t.Errorf("expected no guidance, got %q", got)
+	}
+
+	got := Guidance(testFields())
+	for _, want := range []string{
+		"Povinné",
+		"- Adresa (ulice, číslo a město)",
+		"- Čas zpětného volání",
+		"Nepovinné",
+		"- Služba – možnosti: Oprava, Montáž",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("guidance doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "Nepovinné") < strings.Index(got, "- Čas zpětného volání") {
+		t.Error("required fields should be listed first")
+	}
+
+	if got := Guidance(testFields()[1:2]); strings.Contains(got, "Povinné") {
+		t.Errorf("expected only optional fields, got %q", got)
+	}
+}
+
+func TestExtractionPrompt(t *testing.T) {
+	got := ExtractionPrompt(testFields())
+	for _, want := range []string{
+		`"entities"`,
+		`"address": "Adresa: text, nebo null",`,
+		`"callback_time": "Čas zpětného volání: čas ve formátu HH:MM, nebo null",`,
+		`"service": "Služba: jedna z možností Oprava|Montáž, nebo null"` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+// extractClient returns fixed entities.
+type extractClient struct {
+	entities map[string]string
+}
+
+func (c *extractClient) AnalyzeCall(context.Context, []llm.Message) (*llm.ScreeningResult, error) {
+	return &llm.ScreeningResult{Entities: c.entities}, nil
+}
+
+func (c *extractClient) GenerateResponse(context.Context, []llm.Message) (<-chan string, error) {
+	return nil, nil
+}
+func (c *extractClient) SetSystemPrompt(string)  {}
+func (c *extractClient) GetSystemPrompt() string { return "" }
+
+func TestExtract(t *testing.T) {
+	client := &extractClient{entities: map[string]string{"address": "Dlouhá 5"}}
+	got, err := Extract(context.Background(), client, testFields(), []llm.Message{{Role: "user", Content: "Bydlím v Dlouhé 5"}})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got["address"] != "Dlouhá 5" {
+		t.Errorf("Extract() = %v", got)
+	}
+}
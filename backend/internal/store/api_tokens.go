@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// API token scopes.
+const (
+	ScopeCallsRead      = "calls:read"
+	ScopeCallsWrite     = "calls:write"
+	ScopeSettingsRead   = "settings:read"
+	ScopeSettingsWrite  = "settings:write"
+	ScopeKnowledgeRead  = "knowledge:read"
+	ScopeKnowledgeWrite = "knowledge:write"
+)
+
+// ScopeRoles is the minimum role that can create a token with each scope.
+var ScopeRoles = map[string]string{
+	ScopeCallsRead:      RoleViewer,
+	ScopeCallsWrite:     RoleMember,
+	ScopeSettingsRead:   RoleViewer,
+	ScopeSettingsWrite:  RoleOwner,
+	ScopeKnowledgeRead:  RoleViewer,
+	ScopeKnowledgeWrite: RoleMember,
+}
+
+// APIToken is a personal API token of a tenant user. The token itself is
+// only shown when it is created.
+type APIToken struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	UserID     string     `json:"user_id"` // User the token acts for
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // First characters of the token
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+const apiTokenColumns = `id, tenant_id, user_id, name, token_prefix, scopes, last_used_at, expires_at, created_at`
+
+func scanAPIToken(row pgx.Row) (APIToken, error) {
+	var t APIToken
+	err := row.Scan(&t.ID, &t.TenantID, &t.UserID, &t.Name, &t.Prefix, &t.Scopes, &t.LastUsedAt, &t.ExpiresAt, &t.CreatedAt)
+	return t, err
+}
+
+// CreateAPIToken stores a new token with the hash of its secret.
+func (s *Store) CreateAPIToken(ctx context.Context, t APIToken, tokenHash string) (APIToken, error) {
+	return scanAPIToken(s.db.QueryRow(ctx, `
+		INSERT INTO api_tokens (tenant_id, user_id, name, token_hash, token_prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+apiTokenColumns,
+		t.TenantID, t.UserID, t.Name, tokenHash, t.Prefix, t.Scopes, t.ExpiresAt))
+}
+
+// ListAPITokens returns the tenant's tokens that are not revoked (expired ones
+// included), newest first; only the user's if userID is set.
+func (s *Store) ListAPITokens(ctx context.Context, tenantID string, userID *string) ([]APIToken, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+apiTokenColumns+`
+		FROM api_tokens
+		WHERE tenant_id = $1 AND revoked_at IS NULL AND ($2::uuid IS NULL OR user_id = $2)
+		ORDER BY created_at DESC
+	`, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// RevokeAPIToken revokes a token of the tenant; only the user's if userID is
+// set. Returns pgx.ErrNoRows if there is no such token.
+func (s *Store) RevokeAPIToken(ctx context.Context, tenantID, id string, userID *string) (APIToken, error) {
+	return scanAPIToken(s.db.QueryRow(ctx, `
+		UPDATE api_tokens SET revoked_at = NOW()
+		WHERE id = $2 AND tenant_id = $1 AND revoked_at IS NULL AND ($3::uuid IS NULL OR user_id = $3)
+		RETURNING `+apiTokenColumns,
+		tenantID, id, userID))
+}
+
+// AuthenticateAPIToken returns the valid (not revoked, not expired) token with
+// the hash and the current role of its user, or pgx.ErrNoRows. Tokens of users
+// who left the tenant are not valid. Records the use.
+func (s *Store) AuthenticateAPIToken(ctx context.Context, tokenHash string) (APIToken, string, error) {
+	var t APIToken
+	var role string
+	err := s.db.QueryRow(ctx, `
+		SELECT t.id, t.tenant_id, t.user_id, t.name, t.token_prefix, t.scopes, t.last_used_at, t.expires_at, t.created_at, u.role
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id AND u.tenant_id = t.tenant_id
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND (t.expires_at IS NULL OR t.expires_at > NOW())
+	`, tokenHash).Scan(&t.ID, &t.TenantID, &t.UserID, &t.Name, &t.Prefix, &t.Scopes, &t.LastUsedAt, &t.ExpiresAt, &t.CreatedAt, &role)
+	if err != nil {
+		return t, "", err
+	}
+
+	// Keep writes down for busy integrations
+	if t.LastUsedAt == nil || time.Since(*t.LastUsedAt) > time.Minute {
+		if _, err := s.db.Exec(ctx, `UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1`, t.ID); err != nil {
+			return t, "", err
+		}
+	}
+	return t, role, nil
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestAPITokens(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "API Token Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	suffix := time.Now().Format("150405")
+	owner, _, err := s.FindOrCreateUser(ctx, "+420774"+suffix)
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	member, _, err := s.FindOrCreateUser(ctx, "+420775"+suffix)
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM users WHERE id IN ($1, $2)", owner.ID, member.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+	for _, u := range []*User{owner, member} {
+		if err := s.AssignUserToTenant(ctx, u.ID, tenant.ID); err != nil {
+			t.Fatalf("AssignUserToTenant failed: %v", err)
+		}
+	}
+	if _, err := s.UpdateMemberRole(ctx, tenant.ID, member.ID, RoleMember); err != nil {
+		t.Fatalf("UpdateMemberRole failed: %v", err)
+	}
+
+	created, err := s.CreateAPIToken(ctx, APIToken{
+		TenantID: tenant.ID, UserID: member.ID, Name: "CRM", Prefix: "krn_abcdefgh", Scopes: []string{ScopeCallsRead},
+	}, "hash-member-"+suffix)
+	if err != nil {
+		t.Fatalf("CreateAPIToken failed: %v", err)
+	}
+	if _, err := s.CreateAPIToken(ctx, APIToken{
+		TenantID: tenant.ID, UserID: owner.ID, Name: "Expired", Prefix: "krn_12345678", Scopes: []string{ScopeCallsRead},
+		ExpiresAt: &[]time.Time{time.Now().Add(-time.Hour)}[0],
+	}, "hash-owner-"+suffix); err != nil {
+		t.Fatalf("CreateAPIToken failed: %v", err)
+	}
+
+	tok, role, err := s.AuthenticateAPIToken(ctx, "hash-member-"+suffix)
+	if err != nil || tok.ID != created.ID || role != RoleMember || len(tok.Scopes) != 1 {
+		t.Fatalf("AuthenticateAPIToken = %+v, %q, %v", tok, role, err)
+	}
+	if _, _, err := s.AuthenticateAPIToken(ctx, "hash-owner-"+suffix); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expired token: err = %v, want ErrNoRows", err)
+	}
+
+	all, err := s.ListAPITokens(ctx, tenant.ID, nil)
+	if err != nil || len(all) != 2 {
+		t.Errorf("ListAPITokens(all) = %d, %v", len(all), err)
+	}
+	own, err := s.ListAPITokens(ctx, tenant.ID, &member.ID)
+	if err != nil || len(own) != 1 || own[0].LastUsedAt == nil {
+		t.Errorf("ListAPITokens(member) = %+v, %v", own, err)
+	}
+
+	// Tokens stop working when their user leaves the tenant
+	if _, err := s.RemoveMember(ctx, tenant.ID, member.ID); err != nil {
+		t.Fatalf("RemoveMember failed: %v", err)
+	}
+	if _, _, err := s.AuthenticateAPIToken(ctx, "hash-member-"+suffix); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("token of a removed member: err = %v, want ErrNoRows", err)
+	}
+
+	if _, err := s.RevokeAPIToken(ctx, tenant.ID, created.ID, &owner.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("revoking another user's token: err = %v, want ErrNoRows", err)
+	}
+	if _, err := s.RevokeAPIToken(ctx, tenant.ID, created.ID, nil); err != nil {
+		t.Fatalf("RevokeAPIToken failed: %v", err)
+	}
+	if all, _ := s.ListAPITokens(ctx, tenant.ID, nil); len(all) != 1 {
+		t.Errorf("revoked token still listed: %+v", all)
+	}
+}
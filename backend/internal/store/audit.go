@@ -0,0 +1,181 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Audit log actors.
+const (
+	AuditActorUser     = "user"
+	AuditActorAdmin    = "admin"
+	AuditActorAIKey    = "ai_key"
+	AuditActorAPIToken = "api_token" // A user's API token; the actor ID is the user
+)
+
+// Audit log actions.
+const (
+	AuditActionTenantUpdate            = "tenant.update"
+	AuditActionTenantDelete            = "tenant.delete"
+	AuditActionConfigUpdate            = "config.update"
+	AuditActionPromptRollback          = "prompt.rollback"
+	AuditActionCallFieldsUpdate        = "call_fields.update"
+	AuditActionScreeningTaxonomyUpdate = "screening_taxonomy.update"
+	AuditActionScreeningTaxonomyReset  = "screening_taxonomy.reset"
+	AuditActionRetentionUpdate         = "retention.update"
+	AuditActionRedactionUpdate         = "redaction.update"
+	AuditActionRedactionReset          = "redaction.reset"
+	AuditActionEncryptionUpdate        = "encryption.update"
+	AuditActionMemberInvite            = "member.invite"
+	AuditActionMemberInviteRevoke      = "member.invite_revoke"
+	AuditActionMemberJoin              = "member.join"
+	AuditActionMemberRoleUpdate        = "member.role_update"
+	AuditActionMemberRemove            = "member.remove"
+	AuditActionAPITokenCreate          = "api_token.create"
+	AuditActionAPITokenRevoke          = "api_token.revoke"
+	AuditActionExperimentCreate        = "experiment.create"
+	AuditActionExperimentUpdate        = "experiment.update"
+	AuditActionPhoneNumberUpdate       = "phone_number.update"
+	AuditActionPhoneNumberDelete       = "phone_number.delete"
+)
+
+// Audit log target types.
+const (
+	AuditTargetTenant       = "tenant"
+	AuditTargetGlobalConfig = "global_config"
+	AuditTargetUser         = "user"
+	AuditTargetInvitation   = "invitation"
+	AuditTargetAPIToken     = "api_token"
+	AuditTargetExperiment   = "experiment"
+	AuditTargetPhoneNumber  = "phone_number"
+)
+
+// AuditEntry is a recorded administrative or tenant settings change.
+type AuditEntry struct {
+	ID         string          `json:"id"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    *string         `json:"actor_id,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	TenantID   *string         `json:"tenant_id,omitempty"`
+	Before     json.RawMessage `json:"before"` // Changed fields before (null = created)
+	After      json.RawMessage `json:"after"`  // Changed fields after (null = deleted)
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything. Until
+// pages back through the log (entries strictly older).
+type AuditFilter struct {
+	TenantID   *string
+	ActorType  string
+	Action     string
+	TargetType string
+	TargetID   string
+	Until      *time.Time
+}
+
+// AuditDiff returns the JSON of before and after reduced to the top-level
+// fields that differ, ignoring the fields in ignore. Values that are not JSON
+// objects (lists, nil for created or deleted targets) are kept whole if they
+// differ. Both are nil if nothing changed.
+func AuditDiff(before, after any, ignore ...string) (json.RawMessage, json.RawMessage, error) {
+	b, err := json.Marshal(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := json.Marshal(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var bm, am map[string]any
+	if json.Unmarshal(b, &bm) != nil || json.Unmarshal(a, &am) != nil || bm == nil || am == nil {
+		if bytes.Equal(b, a) {
+			return nil, nil, nil
+		}
+		return b, a, nil
+	}
+
+	bd, ad := map[string]any{}, map[string]any{}
+	for k, bv := range bm {
+		if av, ok := am[k]; (!ok || !reflect.DeepEqual(bv, av)) && !slices.Contains(ignore, k) {
+			bd[k] = bv
+			ad[k] = av
+		}
+	}
+	for k, av := range am {
+		if _, ok := bm[k]; !ok && !slices.Contains(ignore, k) {
+			bd[k] = nil
+			ad[k] = av
+		}
+	}
+	if len(bd) == 0 {
+		return nil, nil, nil
+	}
+	if b, err = json.Marshal(bd); err != nil {
+		return nil, nil, err
+	}
+	a, err = json.Marshal(ad)
+	return b, a, err
+}
+
+// InsertAuditEntry appends e to the audit log.
+func (s *Store) InsertAuditEntry(ctx context.Context, e AuditEntry) (AuditEntry, error) {
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO audit_log (actor_type, actor_id, action, target_type, target_id, tenant_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, e.ActorType, e.ActorID, e.Action, e.TargetType, e.TargetID, e.TenantID, nullJSON(e.Before), nullJSON(e.After)).Scan(&e.ID, &e.CreatedAt)
+	return e, err
+}
+
+// ListAuditEntries returns the newest audit entries matching the filter.
+func (s *Store) ListAuditEntries(ctx context.Context, f AuditFilter, limit int) ([]AuditEntry, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, actor_type, actor_id, action, target_type, target_id, tenant_id, before, after, created_at
+		FROM audit_log
+		WHERE ($1::uuid IS NULL OR tenant_id = $1)
+		  AND ($2::text = '' OR actor_type = $2)
+		  AND ($3::text = '' OR action = $3)
+		  AND ($4::text = '' OR target_type = $4)
+		  AND ($5::text = '' OR target_id = $5)
+		  AND ($6::timestamptz IS NULL OR created_at < $6)
+		ORDER BY created_at DESC
+		LIMIT $7
+	`, f.TenantID, f.ActorType, f.Action, f.TargetType, f.TargetID, f.Until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.ActorType, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.TenantID,
+			&before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if before != nil {
+			e.Before = before
+		}
+		if after != nil {
+			e.After = after
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// nullJSON returns nil (SQL NULL) for an empty or null JSON value.
+func nullJSON(v json.RawMessage) any {
+	if len(v) == 0 || string(v) == "null" {
+		return nil
+	}
+	return []byte(v)
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after any
+		ignore        []string
+		wantBefore    string
+		wantAfter     string
+	}{
+		{
+			name:       "changed fields only",
+			before:     map[string]any{"plan": "trial", "status": "active", "updated_at": "a"},
+			after:      map[string]any{"plan": "pro", "status": "active", "updated_at": "b"},
+			ignore:     []string{"updated_at"},
+			wantBefore: `{"plan":"trial"}`,
+			wantAfter:  `{"plan":"pro"}`,
+		},
+		{
+			name:       "added and removed fields",
+			before:     map[string]any{"a": 1},
+			after:      map[string]any{"b": 2},
+			wantBefore: `{"a":1,"b":null}`,
+			wantAfter:  `{"a":null,"b":2}`,
+		},
+		{
+			name:   "unchanged",
+			before: map[string]any{"a": 1},
+			after:  map[string]any{"a": 1},
+		},
+		{
+			name:       "lists kept whole",
+			before:     []string{"a"},
+			after:      []string{"a", "b"},
+			wantBefore: `["a"]`,
+			wantAfter:  `["a","b"]`,
+		},
+		{
+			name:       "deleted",
+			before:     map[string]any{"name": "Acme"},
+			after:      nil,
+			wantBefore: `{"name":"Acme"}`,
+			wantAfter:  `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, a, err := AuditDiff(tt.before, tt.after, tt.ignore...)
+			if err != nil {
+				t.Fatalf("AuditDiff failed: %v", err)
+			}
+			if string(b) != tt.wantBefore || string(a) != tt.wantAfter {
+				t.Errorf("AuditDiff = %s, %s, want %s, %s", b, a, tt.wantBefore, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Audit Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM audit_log WHERE tenant_id = $1", tenant.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+
+	before, after, err := AuditDiff(map[string]any{"plan": "trial"}, map[string]any{"plan": "pro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.InsertAuditEntry(ctx, AuditEntry{
+		ActorType: AuditActorAdmin, Action: AuditActionTenantUpdate,
+		TargetType: AuditTargetTenant, TargetID: tenant.ID, TenantID: &tenant.ID,
+		Before: before, After: after,
+	})
+	if err != nil {
+		t.Fatalf("InsertAuditEntry failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := s.InsertAuditEntry(ctx, AuditEntry{
+		ActorType: AuditActorUser, Action: AuditActionRetentionUpdate,
+		TargetType: AuditTargetTenant, TargetID: tenant.ID, TenantID: &tenant.ID,
+	}); err != nil {
+		t.Fatalf("InsertAuditEntry failed: %v", err)
+	}
+
+	entries, err := s.ListAuditEntries(ctx, AuditFilter{TenantID: &tenant.ID}, 10)
+	if err != nil {
+		t.Fatalf("ListAuditEntries failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != AuditActionRetentionUpdate || entries[1].ID != first.ID {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if string(entries[1].After) != `{"plan": "pro"}` || entries[0].Before != nil {
+		t.Errorf("unexpected diff %s / %s", entries[1].After, entries[0].Before)
+	}
+
+	entries, err = s.ListAuditEntries(ctx, AuditFilter{TenantID: &tenant.ID, Action: AuditActionTenantUpdate}, 10)
+	if err != nil || len(entries) != 1 || entries[0].ID != first.ID {
+		t.Errorf("filter by action = %+v, %v", entries, err)
+	}
+	until := entries[0].CreatedAt
+	entries, err = s.ListAuditEntries(ctx, AuditFilter{TenantID: &tenant.ID, Until: &until}, 10)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("entries before the first = %+v, %v", entries, err)
+	}
+}
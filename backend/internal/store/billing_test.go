@@ -755,6 +755,26 @@ func TestRecordAndGetCallCosts(t *testing.T) {
 			t.Errorf("CallDurationSeconds after update = %d, want 180", updated.CallDurationSeconds)
 		}
 	})
+
+	t.Run("add LLM usage after the call", func(t *testing.T) {
+		if err := s.AddCallLLMUsage(ctx, callID, 400, 150); err != nil {
+			t.Fatalf("AddCallLLMUsage failed: %v", err)
+		}
+
+		updated, err := s.GetCallCosts(ctx, callID)
+		if err != nil {
+			t.Fatalf("GetCallCosts failed: %v", err)
+		}
+		if updated.LLMInputTokens != 1000 || updated.LLMOutputTokens != 400 {
+			t.Errorf("LLM tokens = %d/%d, want 1000/400", updated.LLMInputTokens, updated.LLMOutputTokens)
+		}
+		if updated.CallDurationSeconds != 180 || updated.TTSCharacters != 500 {
+			t.Errorf("other usage changed: %+v", updated)
+		}
+		if updated.TotalCostCents != updated.TwilioCostCents+updated.STTCostCents+updated.LLMCostCents+updated.TTSCostCents {
+			t.Errorf("TotalCostCents = %d, not the sum of %+v", updated.TotalCostCents, updated)
+		}
+	})
 }
 
 func TestGetTenantCostSummary(t *testing.T) {
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// CallExportRow is a call as exported: the list item plus summary, parsed
+// entities and (optionally) the transcript.
+type CallExportRow struct {
+	CallListItem
+	Summary    *string
+	Urgency    *string
+	Entities   map[string]string
+	Transcript string // "speaker: text" lines, empty unless requested
+}
+
+// ListCallEntityKeys returns the distinct screening entity keys of the
+// tenant's calls matching the filter, sorted (the export's entity columns).
+func (s *Store) ListCallEntityKeys(ctx context.Context, tenantID string, f CallFilter) ([]string, error) {
+	conds, args, err := s.callConditions(ctx, tenantID, f, []any{tenantID})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT DISTINCT k
+		FROM calls c
+		JOIN call_screening_results r ON r.call_id = c.id
+		CROSS JOIN LATERAL jsonb_object_keys(CASE WHEN jsonb_typeof(r.entities_json) = 'object' THEN r.entities_json ELSE '{}' END) k
+		WHERE `+strings.Join(append([]string{"c.tenant_id = $1"}, conds...), " AND ")+`
+		ORDER BY k
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// ExportCalls calls fn for each of the tenant's calls matching the filter,
+// newest first, reading rows as they are streamed from the database. It
+// stops at the first error from fn.
+func (s *Store) ExportCalls(ctx context.Context, tenantID string, f CallFilter, withTranscript bool, fn func(CallExportRow) error) error {
+	conds, args, err := s.callConditions(ctx, tenantID, f, []any{tenantID, withTranscript})
+	if err != nil {
+		return err
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT c.provider, c.provider_call_id, c.from_number, c.to_number, c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
+		       c.first_viewed_at, c.resolved_at, c.resolved_by, c.tags,
+		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at,
+		       r.intent_text_enc, r.entities_enc,
+		       `+callFieldColumns+`,
+		       s.summary, s.urgency, s.summary_enc,
+		       t.speakers, t.texts, t.texts_enc
+		FROM calls c
+		LEFT JOIN call_screening_results r ON r.call_id = c.id
+		LEFT JOIN call_summaries s ON s.call_id = c.id
+		LEFT JOIN LATERAL (
+		    SELECT array_agg(u.speaker ORDER BY u.sequence) AS speakers,
+		           array_agg(u.text ORDER BY u.sequence) AS texts,
+		           array_agg(u.text_enc ORDER BY u.sequence) AS texts_enc
+		    FROM call_utterances u
+		    WHERE $2::boolean AND u.call_id = c.id
+		) t ON true
+		WHERE `+strings.Join(append([]string{"c.tenant_id = $1"}, conds...), " AND ")+`
+		ORDER BY c.started_at DESC, c.id DESC
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row CallExportRow
+		var speakers, texts []string
+		var textsEnc [][]byte
+		var summaryEnc []byte
+		item, err := s.scanCallListItem(ctx, rows, &row.Summary, &row.Urgency, &summaryEnc, &speakers, &texts, &textsEnc)
+		if err != nil {
+			return err
+		}
+		if summaryEnc != nil {
+			var c summaryContent
+			if err := s.openJSON(ctx, summaryEnc, &c); err != nil {
+				return err
+			}
+			row.Summary = &c.Summary
+		}
+		row.CallListItem = item
+		if row.Transcript, err = s.exportTranscript(ctx, speakers, texts, textsEnc); err != nil {
+			return err
+		}
+		if item.Screening != nil {
+			row.Entities = entityStrings(item.Screening.EntitiesJSON)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// exportTranscript joins the utterances into "speaker: text" lines,
+// decrypting encrypted ones.
+func (s *Store) exportTranscript(ctx context.Context, speakers, texts []string, textsEnc [][]byte) (string, error) {
+	lines := make([]string, len(speakers))
+	for i, speaker := range speakers {
+		text, err := s.openText(ctx, texts[i], textsEnc[i])
+		if err != nil {
+			return "", err
+		}
+		lines[i] = speaker + ": " + text
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// entityStrings returns the entity values that are strings or numbers (the
+// model uses null for unknown ones).
+func entityStrings(raw json.RawMessage) map[string]string {
+	var entities map[string]any
+	if err := json.Unmarshal(raw, &entities); err != nil {
+		return nil
+	}
+	out := make(map[string]string, len(entities))
+	for k, v := range entities {
+		switch v := v.(type) {
+		case string:
+			out[k] = v
+		case float64:
+			out[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEntityStrings(t *testing.T) {
+	got := entityStrings(json.RawMessage(`{"name": "Jan Novák", "company": null, "count": 3, "nested": {"a": 1}}`))
+	if len(got) != 2 || got["name"] != "Jan Novák" || got["count"] != "3" {
+		t.Errorf("entityStrings = %v", got)
+	}
+	if got := entityStrings(json.RawMessage(`null`)); len(got) != 0 {
+		t.Errorf("entityStrings(null) = %v", got)
+	}
+}
+
+func TestExportCalls(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Export Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM calls WHERE tenant_id = $1", tenant.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+
+	sid := "CAEXP" + time.Now().Format("20060102150405")
+	if err := s.UpsertCall(ctx, Call{
+		Provider: "twilio", ProviderCallID: sid, FromNumber: "+420777123456", ToNumber: "+420228883001",
+		Status: "completed", StartedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertCall failed: %v", err)
+	}
+	callID, _ := s.GetCallID(ctx, sid)
+	if _, err := db.Exec(ctx, "UPDATE calls SET tenant_id = $1 WHERE id = $2", tenant.ID, callID); err != nil {
+		t.Fatalf("set tenant failed: %v", err)
+	}
+	if err := s.InsertScreeningResult(ctx, callID, ScreeningResult{
+		LegitimacyLabel: "legitimní", LegitimacyConfidence: 0.9, LeadLabel: "follow_up", IntentCategory: "servis",
+		IntentText: "Oprava", EntitiesJSON: json.RawMessage(`{"name": "Jan", "company": null}`), CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("InsertScreeningResult failed: %v", err)
+	}
+	for i, text := range []string{"Dobrý den", "Ahoj"} {
+		speaker := "agent"
+		if i == 1 {
+			speaker = "caller"
+		}
+		if err := s.InsertUtterance(ctx, callID, Utterance{Speaker: speaker, Text: text, Sequence: i}); err != nil {
+			t.Fatalf("InsertUtterance failed: %v", err)
+		}
+	}
+
+	keys, err := s.ListCallEntityKeys(ctx, tenant.ID, CallFilter{})
+	if err != nil || len(keys) != 2 || keys[0] != "company" || keys[1] != "name" {
+		t.Errorf("ListCallEntityKeys = %v, %v", keys, err)
+	}
+
+	var rows []CallExportRow
+	err = s.ExportCalls(ctx, tenant.ID, CallFilter{}, true, func(row CallExportRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportCalls failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Entities["name"] != "Jan" || rows[0].Transcript != "agent: Dobrý den\ncaller: Ahoj" {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/slots"
+)
+
+// Call field filter operators.
+const (
+	FieldFilterEq      = "eq"      // Value equals (text case-insensitively)
+	FieldFilterMin     = "min"     // Value >= (number, date, time)
+	FieldFilterMax     = "max"     // Value <= (number, date, time)
+	FieldFilterPresent = "present" // Field was collected
+)
+
+// CallFieldFilter restricts a call list to calls with a matching collected
+// field value. Value is parsed with the field's type.
+type CallFieldFilter struct {
+	Key   string
+	Op    string
+	Value slots.Value
+}
+
+// GetCallFields returns the tenant's call field definitions (empty if none).
+func (s *Store) GetCallFields(ctx context.Context, tenantID string) ([]slots.Field, error) {
+	var raw []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT fields FROM tenant_call_fields WHERE tenant_id = $1
+	`, tenantID).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []slots.Field{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []slots.Field{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// SetCallFields replaces the tenant's call field definitions. Values stored
+// with earlier calls are kept.
+func (s *Store) SetCallFields(ctx context.Context, tenantID string, fields []slots.Field, updatedBy *string) error {
+	if fields == nil {
+		fields = []slots.Field{}
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO tenant_call_fields (tenant_id, fields, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET fields = EXCLUDED.fields, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, tenantID, raw, updatedBy)
+	return err
+}
+
+// callFieldColumns select the call's collected field values for
+// scanCallListItem: plaintext ones as a key → text object, encrypted ones as
+// a key → base64 object.
+const callFieldColumns = `(SELECT jsonb_object_agg(v.key, v.value_text) FROM call_field_values v WHERE v.call_id = c.id AND v.value_enc IS NULL),
+		       (SELECT jsonb_object_agg(v.key, encode(v.value_enc, 'base64')) FROM call_field_values v WHERE v.call_id = c.id AND v.value_enc IS NOT NULL)`
+
+// SaveCallFieldValues stores the values collected on a call, replacing
+// earlier values of the same fields.
+func (s *Store) SaveCallFieldValues(ctx context.Context, callID string, values []slots.Value) error {
+	if len(values) == 0 {
+		return nil
+	}
+	var tenantID *string
+	if s.crypt != nil {
+		var err error
+		if tenantID, err = s.callTenantID(ctx, callID); err != nil {
+			return err
+		}
+	}
+	batch := &pgx.Batch{}
+	for _, v := range values {
+		enc, err := s.sealJSON(ctx, tenantID, v)
+		if err != nil {
+			return err
+		}
+		if enc != nil {
+			v.Text, v.Number, v.Date = "", nil, nil
+		}
+		batch.Queue(`
+			INSERT INTO call_field_values (call_id, key, type, value_text, value_number, value_date, value_enc)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (call_id, key) DO UPDATE
+			SET type = EXCLUDED.type, value_text = EXCLUDED.value_text,
+			    value_number = EXCLUDED.value_number, value_date = EXCLUDED.value_date, value_enc = EXCLUDED.value_enc,
+			    created_at = NOW()
+		`, callID, v.Key, v.Type, v.Text, v.Number, v.Date, enc)
+	}
+	return s.db.SendBatch(ctx, batch).Close()
+}
+
+// openCallFields adds the decrypted values of enc (see callFieldColumns) to
+// fields.
+func (s *Store) openCallFields(ctx context.Context, fields map[string]string, enc map[string][]byte) (map[string]string, error) {
+	if len(enc) > 0 && fields == nil {
+		fields = make(map[string]string, len(enc))
+	}
+	for key, e := range enc {
+		var v slots.Value
+		if err := s.openJSON(ctx, e, &v); err != nil {
+			return nil, err
+		}
+		fields[key] = v.Text
+	}
+	return fields, nil
+}
+
+// fieldFilterSQL returns the WHERE condition for the filters, with
+// placeholders numbered from next, and its arguments.
+func fieldFilterSQL(filters []CallFieldFilter, next int) (string, []any, error) {
+	var conds []string
+	var args []any
+	for _, f := range filters {
+		cond := fmt.Sprintf("v.key = $%d", next)
+		args = append(args, f.Key)
+		next++
+
+		if f.Op != FieldFilterPresent {
+			column, value := "lower(v.value_text)", any(strings.ToLower(f.Value.Text))
+			switch {
+			case f.Value.Number != nil:
+				column, value = "v.value_number", *f.Value.Number
+			case f.Value.Date != nil:
+				column, value = "v.value_date", *f.Value.Date
+			}
+			var op string
+			switch f.Op {
+			case FieldFilterEq:
+				op = "="
+			case FieldFilterMin:
+				op = ">="
+			case FieldFilterMax:
+				op = "<="
+			default:
+				return "", nil, fmt.Errorf("unknown field filter operator %q", f.Op)
+			}
+			cond += fmt.Sprintf(" AND %s %s $%d", column, op, next)
+			args = append(args, value)
+			next++
+		}
+		conds = append(conds, "EXISTS (SELECT 1 FROM call_field_values v WHERE v.call_id = c.id AND "+cond+")")
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// listCallFieldValues returns the field values collected on a call.
+func (s *Store) listCallFieldValues(ctx context.Context, callID string) ([]slots.Value, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT key, type, value_text, value_number::float8, value_date, value_enc
+		FROM call_field_values
+		WHERE call_id = $1
+		ORDER BY created_at, key
+	`, callID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []slots.Value
+	for rows.Next() {
+		var v slots.Value
+		var enc []byte
+		if err := rows.Scan(&v.Key, &v.Type, &v.Text, &v.Number, &v.Date, &enc); err != nil {
+			return nil, err
+		}
+		if enc != nil {
+			if err := s.openJSON(ctx, enc, &v); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
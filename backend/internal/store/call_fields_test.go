@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/slots"
+)
+
+func TestFieldFilterSQL(t *testing.T) {
+	n := 10.0
+	cond, args, err := fieldFilterSQL([]CallFieldFilter{
+		{Key: "address", Op: FieldFilterEq, Value: slots.Value{Text: "Dlouhá"}},
+		{Key: "order_number", Op: FieldFilterMin, Value: slots.Value{Number: &n}},
+		{Key: "visit_date", Op: FieldFilterPresent},
+	}, 3)
+	if err != nil {
+		t.Fatalf("fieldFilterSQL() error = %v", err)
+	}
+	for _, want := range []string{
+		"v.key = $3 AND lower(v.value_text) = $4",
+		"v.key = $5 AND v.value_number >= $6",
+		"v.key = $7)",
+	} {
+		if !strings.Contains(cond, want) {
+			t.Errorf("condition doesn't contain %q: %s", want, cond)
+		}
+	}
+	if len(args) != 5 || args[1] != "dlouhá" || args[3] != 10.0 {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if _, _, err := fieldFilterSQL([]CallFieldFilter{{Key: "a", Op: "like"}}, 1); err == nil {
+		t.Error("expected error for unknown operator")
+	}
+}
+
+func TestCallFieldValues(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Call Fields Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID) }()
+
+	fields := []slots.Field{
+		{Key: "address", Label: "Adresa", Type: slots.TypeText, Required: true},
+		{Key: "order_number", Label: "Objednávka", Type: slots.TypeNumber},
+	}
+	if err := s.SetCallFields(ctx, tenant.ID, fields, nil); err != nil {
+		t.Fatalf("SetCallFields failed: %v", err)
+	}
+	got, err := s.GetCallFields(ctx, tenant.ID)
+	if err != nil || len(got) != 2 || !got[0].Required {
+		t.Fatalf("GetCallFields = %+v, %v", got, err)
+	}
+
+	callSid := "CAFIELDS" + time.Now().Format("20060102150405")
+	if err := s.UpsertCallWithTenant(ctx, Call{
+		TenantID: &tenant.ID, Provider: "twilio", ProviderCallID: callSid,
+		FromNumber: "+420777123456", ToNumber: "+420228883001", Status: "completed", StartedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertCallWithTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM calls WHERE provider_call_id = $1", callSid) }()
+	detail, err := s.GetCallDetail(ctx, callSid)
+	if err != nil {
+		t.Fatalf("GetCallDetail failed: %v", err)
+	}
+
+	address, _ := fields[0].Parse("Dlouhá 5, Praha")
+	order, _ := fields[1].Parse("1234")
+	if err := s.SaveCallFieldValues(ctx, detail.ID, []slots.Value{address, order}); err != nil {
+		t.Fatalf("SaveCallFieldValues failed: %v", err)
+	}
+
+	detail, _ = s.GetCallDetail(ctx, callSid)
+	if len(detail.Fields) != 2 || detail.Fields[1].Number == nil && detail.Fields[0].Number == nil {
+		t.Errorf("unexpected call detail fields %+v", detail.Fields)
+	}
+
+	minOrder, _ := fields[1].Parse("1000")
+	calls, err := s.ListCallsByTenantFiltered(ctx, tenant.ID, []CallFieldFilter{
+		{Key: "address", Op: FieldFilterEq, Value: slots.Value{Text: "DLOUHÁ 5, PRAHA"}},
+		{Key: "order_number", Op: FieldFilterMin, Value: minOrder},
+	}, 100)
+	if err != nil {
+		t.Fatalf("ListCallsByTenantFiltered failed: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Fields["order_number"] != "1234" {
+		t.Errorf("unexpected filtered calls %+v", calls)
+	}
+
+	maxOrder, _ := fields[1].Parse("100")
+	calls, _ = s.ListCallsByTenantFiltered(ctx, tenant.ID, []CallFieldFilter{{Key: "order_number", Op: FieldFilterMax, Value: maxOrder}}, 100)
+	if len(calls) != 0 {
+		t.Errorf("expected no calls below the max, got %d", len(calls))
+	}
+}
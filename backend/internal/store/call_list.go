@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CallFilter narrows call listings and searches. Zero values don't filter.
+type CallFilter struct {
+	From            *time.Time // Calls started at or after
+	To              *time.Time // Calls started before
+	LegitimacyLabel string
+	LeadLabel       string
+	IntentCategory  string
+	Resolved        *bool
+	Viewed          *bool
+	FromNumber      string
+	EndedBy         string
+	Status          string
+	Fields          []CallFieldFilter
+}
+
+// conditions returns the WHERE conditions of the filter (over calls c and
+// call_screening_results r), with placeholders numbered after args, and args
+// extended with their values.
+func (f CallFilter) conditions(args []any) ([]string, []any, error) {
+	var conds []string
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.From != nil {
+		add("c.started_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("c.started_at < $%d", *f.To)
+	}
+	if f.LegitimacyLabel != "" {
+		add("r.legitimacy_label = $%d", f.LegitimacyLabel)
+	}
+	if f.LeadLabel != "" {
+		add("r.lead_label = $%d", f.LeadLabel)
+	}
+	if f.IntentCategory != "" {
+		add("r.intent_category = $%d", f.IntentCategory)
+	}
+	if f.FromNumber != "" {
+		add("c.from_number = $%d", f.FromNumber)
+	}
+	if f.EndedBy != "" {
+		add("c.ended_by = $%d", f.EndedBy)
+	}
+	if f.Status != "" {
+		add("c.status = $%d", f.Status)
+	}
+	if f.Resolved != nil {
+		conds = append(conds, nullCondition("c.resolved_at", *f.Resolved))
+	}
+	if f.Viewed != nil {
+		conds = append(conds, nullCondition("c.first_viewed_at", *f.Viewed))
+	}
+	if len(f.Fields) > 0 {
+		cond, fieldArgs, err := fieldFilterSQL(f.Fields, len(args)+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		conds = append(conds, cond)
+		args = append(args, fieldArgs...)
+	}
+	return conds, args, nil
+}
+
+// ErrFieldFilterUnavailable is returned for call field value filters of
+// tenants with encryption enabled: encrypted values can't be compared in SQL.
+// Filtering by a field being collected still works.
+var ErrFieldFilterUnavailable = errors.New("store: call field value filters are unavailable for encrypted calls")
+
+// callConditions returns f.conditions, refusing field value filters if the
+// tenant has encryption enabled.
+func (s *Store) callConditions(ctx context.Context, tenantID string, f CallFilter, args []any) ([]string, []any, error) {
+	for _, ff := range f.Fields {
+		if ff.Op == FieldFilterPresent {
+			continue
+		}
+		encrypted, err := s.TenantEncrypted(ctx, tenantID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if encrypted {
+			return nil, nil, ErrFieldFilterUnavailable
+		}
+		break
+	}
+	return f.conditions(args)
+}
+
+func nullCondition(column string, set bool) string {
+	if set {
+		return column + " IS NOT NULL"
+	}
+	return column + " IS NULL"
+}
+
+// CallPage is one page of a call listing.
+type CallPage struct {
+	Calls      []CallListItem `json:"calls"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// ErrInvalidCursor is returned for a malformed page cursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// callCursor is the position after the last call of a page. Calls are sorted
+// by started_at, then id (both descending), so pages are stable while new
+// calls arrive.
+type callCursor struct {
+	StartedAt time.Time
+	ID        string
+}
+
+func (c callCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.StartedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// IsUUID reports whether s is a lowercase UUID as stored by Postgres.
+func IsUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+func decodeCallCursor(s string) (callCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return callCursor{}, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok || !uuidPattern.MatchString(id) {
+		return callCursor{}, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return callCursor{}, ErrInvalidCursor
+	}
+	return callCursor{StartedAt: t, ID: id}, nil
+}
+
+// ListCallsPage lists a page of the tenant's calls matching the filter, newest
+// first. cursor is the NextCursor of the previous page, or "" for the first.
+func (s *Store) ListCallsPage(ctx context.Context, tenantID string, f CallFilter, cursor string, limit int) (CallPage, error) {
+	conds, args, err := s.callConditions(ctx, tenantID, f, []any{tenantID, limit + 1})
+	if err != nil {
+		return CallPage{}, err
+	}
+	if cursor != "" {
+		cur, err := decodeCallCursor(cursor)
+		if err != nil {
+			return CallPage{}, err
+		}
+		args = append(args, cur.StartedAt, cur.ID)
+		conds = append(conds, fmt.Sprintf("(c.started_at, c.id) < ($%d, $%d::uuid)", len(args)-1, len(args)))
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT c.provider, c.provider_call_id, c.from_number, c.to_number, c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
+		       c.first_viewed_at, c.resolved_at, c.resolved_by, c.tags,
+		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at,
+		       r.intent_text_enc, r.entities_enc,
+		       `+callFieldColumns+`,
+		       c.id
+		FROM calls c
+		LEFT JOIN call_screening_results r ON r.call_id = c.id
+		WHERE `+strings.Join(append([]string{"c.tenant_id = $1"}, conds...), " AND ")+`
+		ORDER BY c.started_at DESC, c.id DESC
+		LIMIT $2
+	`, args...)
+	if err != nil {
+		return CallPage{}, err
+	}
+	defer rows.Close()
+
+	page := CallPage{Calls: []CallListItem{}}
+	var last callCursor
+	for rows.Next() {
+		var id string
+		item, err := s.scanCallListItem(ctx, rows, &id)
+		if err != nil {
+			return CallPage{}, err
+		}
+		if len(page.Calls) == limit {
+			// One more row than requested: there is a next page
+			page.NextCursor = last.encode()
+			break
+		}
+		page.Calls = append(page.Calls, item)
+		last = callCursor{StartedAt: item.StartedAt, ID: id}
+	}
+	return page, rows.Err()
+}
+
+// CallFacets are the numbers of calls matching a filter, in total and by
+// value of each facet: legitimacy_label, lead_label, intent_category,
+// resolved, viewed, ended_by and status. Calls without a value (e.g. not
+// screened yet) are not counted in that facet.
+type CallFacets struct {
+	Total  int                       `json:"total"`
+	Facets map[string]map[string]int `json:"facets"`
+}
+
+// CountCallFacets returns the facet counts of the tenant's calls matching the filter.
+func (s *Store) CountCallFacets(ctx context.Context, tenantID string, f CallFilter) (CallFacets, error) {
+	conds, args, err := s.callConditions(ctx, tenantID, f, []any{tenantID})
+	if err != nil {
+		return CallFacets{}, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		WITH f AS (
+			SELECT r.legitimacy_label, r.lead_label, r.intent_category,
+			       (c.resolved_at IS NOT NULL)::text AS resolved,
+			       (c.first_viewed_at IS NOT NULL)::text AS viewed,
+			       c.ended_by, c.status
+			FROM calls c
+			LEFT JOIN call_screening_results r ON r.call_id = c.id
+			WHERE `+strings.Join(append([]string{"c.tenant_id = $1"}, conds...), " AND ")+`
+		)
+		SELECT 'total', '', COUNT(*) FROM f
+		UNION ALL SELECT 'legitimacy_label', legitimacy_label, COUNT(*) FROM f WHERE legitimacy_label IS NOT NULL GROUP BY 2
+		UNION ALL SELECT 'lead_label', lead_label, COUNT(*) FROM f WHERE lead_label IS NOT NULL GROUP BY 2
+		UNION ALL SELECT 'intent_category', intent_category, COUNT(*) FROM f WHERE intent_category IS NOT NULL GROUP BY 2
+		UNION ALL SELECT 'resolved', resolved, COUNT(*) FROM f GROUP BY 2
+		UNION ALL SELECT 'viewed', viewed, COUNT(*) FROM f GROUP BY 2
+		UNION ALL SELECT 'ended_by', ended_by, COUNT(*) FROM f WHERE ended_by IS NOT NULL GROUP BY 2
+		UNION ALL SELECT 'status', status, COUNT(*) FROM f GROUP BY 2
+	`, args...)
+	if err != nil {
+		return CallFacets{}, err
+	}
+	defer rows.Close()
+
+	out := CallFacets{Facets: map[string]map[string]int{}}
+	for _, name := range []string{"legitimacy_label", "lead_label", "intent_category", "resolved", "viewed", "ended_by", "status"} {
+		out.Facets[name] = map[string]int{}
+	}
+	for rows.Next() {
+		var facet, value string
+		var count int
+		if err := rows.Scan(&facet, &value, &count); err != nil {
+			return CallFacets{}, err
+		}
+		if facet == "total" {
+			out.Total = count
+			continue
+		}
+		out.Facets[facet][value] = count
+	}
+	return out, rows.Err()
+}
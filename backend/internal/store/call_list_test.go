@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCallCursor(t *testing.T) {
+	cur := callCursor{StartedAt: time.Date(2025, 3, 14, 9, 30, 0, 123456000, time.UTC), ID: "5f0c3a52-8d4e-4f7b-9a51-0b6c2f1e7d90"}
+	got, err := decodeCallCursor(cur.encode())
+	if err != nil {
+		t.Fatalf("decodeCallCursor failed: %v", err)
+	}
+	if !got.StartedAt.Equal(cur.StartedAt) || got.ID != cur.ID {
+		t.Errorf("decoded %+v, want %+v", got, cur)
+	}
+
+	for _, bad := range []string{"", "not base64!", "bm8tc2VwYXJhdG9y", callCursor{StartedAt: cur.StartedAt, ID: "1; DROP TABLE calls"}.encode()} {
+		if _, err := decodeCallCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("decodeCallCursor(%q) = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestListCallsPage(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Paging Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM calls WHERE tenant_id = $1", tenant.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+
+	// Five calls, the two oldest share a start time
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	suffix := time.Now().Format("20060102150405")
+	for i := 0; i < 5; i++ {
+		startedAt := base.Add(time.Duration(max(i, 1)) * time.Minute)
+		sid := fmt.Sprintf("CAPAGE%d%s", i, suffix)
+		if err := s.UpsertCall(ctx, Call{
+			Provider: "twilio", ProviderCallID: sid, FromNumber: "+420777123456", ToNumber: "+420228883001",
+			Status: "completed", StartedAt: startedAt,
+		}); err != nil {
+			t.Fatalf("UpsertCall failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, "UPDATE calls SET tenant_id = $1 WHERE provider_call_id = $2", tenant.ID, sid); err != nil {
+			t.Fatalf("set tenant failed: %v", err)
+		}
+		if i%2 == 0 {
+			if _, err := db.Exec(ctx, "UPDATE calls SET resolved_at = NOW() WHERE provider_call_id = $1", sid); err != nil {
+				t.Fatalf("resolve failed: %v", err)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	pages := 0
+	for {
+		page, err := s.ListCallsPage(ctx, tenant.ID, CallFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("ListCallsPage failed: %v", err)
+		}
+		pages++
+		for _, c := range page.Calls {
+			if seen[c.ProviderCallID] {
+				t.Errorf("call %s returned twice", c.ProviderCallID)
+			}
+			seen[c.ProviderCallID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 5 || pages != 3 {
+		t.Errorf("got %d calls in %d pages, want 5 in 3", len(seen), pages)
+	}
+
+	resolved := true
+	page, err := s.ListCallsPage(ctx, tenant.ID, CallFilter{Resolved: &resolved}, "", 10)
+	if err != nil || len(page.Calls) != 3 {
+		t.Errorf("resolved calls = %d, %v; want 3", len(page.Calls), err)
+	}
+
+	facets, err := s.CountCallFacets(ctx, tenant.ID, CallFilter{})
+	if err != nil {
+		t.Fatalf("CountCallFacets failed: %v", err)
+	}
+	if facets.Total != 5 || facets.Facets["resolved"]["true"] != 3 || facets.Facets["resolved"]["false"] != 2 || facets.Facets["status"]["completed"] != 5 {
+		t.Errorf("unexpected facets %+v", facets)
+	}
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+
+	"github.com/lukasbauer/karen/internal/knowledge"
+)
+
+// CallSearchResult is a call matched by SearchCalls.
+type CallSearchResult struct {
+	CallListItem
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"` // HTML-escaped, matches wrapped in <mark>
+}
+
+// Snippet highlight markers; replaced with <mark> after escaping the text.
+const (
+	snippetStart = "\x01"
+	snippetStop  = "\x02"
+)
+
+// ErrSearchUnavailable is returned by SearchCalls for tenants with encryption
+// enabled: the search index would hold the plaintext of encrypted calls.
+var ErrSearchUnavailable = errors.New("store: search is unavailable for encrypted calls")
+
+// SearchCalls finds the tenant's calls whose transcript, intent text, entities
+// or summary contain all words of the query (Czech-normalized and stemmed, as
+// in the knowledge base), best matches first.
+func (s *Store) SearchCalls(ctx context.Context, tenantID, query string, f CallFilter, limit int) ([]CallSearchResult, error) {
+	encrypted, err := s.TenantEncrypted(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		return nil, ErrSearchUnavailable
+	}
+	terms := knowledge.Terms(query)
+	if len(terms) == 0 {
+		return []CallSearchResult{}, nil
+	}
+
+	conds, args, err := s.callConditions(ctx, tenantID, f, []any{tenantID, knowledge.TSQueryAll(terms), limit})
+	if err != nil {
+		return nil, err
+	}
+	where := strings.Join(append([]string{"c.tenant_id = $1", "d.tsv @@ q.query"}, conds...), " AND ")
+
+	rows, err := s.db.Query(ctx, `
+		WITH q AS (SELECT to_tsquery('czech_unaccent', $2) AS query)
+		SELECT c.provider, c.provider_call_id, c.from_number, c.to_number, c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
+		       c.first_viewed_at, c.resolved_at, c.resolved_by, c.tags,
+		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at,
+		       r.intent_text_enc, r.entities_enc,
+		       `+callFieldColumns+`,
+		       ts_rank_cd(d.tsv, q.query) AS rank,
+		       ts_headline('czech_unaccent', d.content, q.query,
+		                   'StartSel=`+snippetStart+`, StopSel=`+snippetStop+`, MaxWords=20, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "')
+		FROM calls c
+		JOIN call_search_documents d ON d.call_id = c.id
+		CROSS JOIN q
+		LEFT JOIN call_screening_results r ON r.call_id = c.id
+		WHERE `+where+`
+		ORDER BY rank DESC, c.started_at DESC
+		LIMIT $3
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []CallSearchResult{}
+	for rows.Next() {
+		var res CallSearchResult
+		var snippet string
+		item, err := s.scanCallListItem(ctx, rows, &res.Rank, &snippet)
+		if err != nil {
+			return nil, err
+		}
+		res.CallListItem = item
+		res.Snippet = highlightSnippet(snippet)
+		out = append(out, res)
+	}
+	return out, rows.Err()
+}
+
+// RefreshCallSearch rebuilds the call's search document with its transcript.
+// Utterance inserts don't update it, so this runs once when the call ends.
+func (s *Store) RefreshCallSearch(ctx context.Context, callID string) error {
+	_, err := s.db.Exec(ctx, `SELECT refresh_call_search_document($1)`, callID)
+	return err
+}
+
+// highlightSnippet escapes a ts_headline snippet and turns the match markers
+// into <mark> tags, so transcript text can't inject markup.
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer(snippetStart, "<mark>", snippetStop, "</mark>").Replace(html.EscapeString(snippet))
+}
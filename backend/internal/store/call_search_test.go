@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	got := highlightSnippet("oprava \x01střechy\x02 <script>")
+	want := "oprava <mark>střechy</mark> &lt;script&gt;"
+	if got != want {
+		t.Errorf("highlightSnippet = %q, want %q", got, want)
+	}
+}
+
+func TestSearchCalls(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Search Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID) }()
+
+	newCall := func(sid string, texts ...string) string {
+		t.Helper()
+		if err := s.UpsertCall(ctx, Call{
+			Provider: "twilio", ProviderCallID: sid, FromNumber: "+420777123456", ToNumber: "+420228883001",
+			Status: "completed", StartedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("UpsertCall failed: %v", err)
+		}
+		callID, err := s.GetCallID(ctx, sid)
+		if err != nil {
+			t.Fatalf("GetCallID failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, "UPDATE calls SET tenant_id = $1 WHERE id = $2", tenant.ID, callID); err != nil {
+			t.Fatalf("set tenant failed: %v", err)
+		}
+		for i, text := range texts {
+			if err := s.InsertUtterance(ctx, callID, Utterance{Speaker: "caller", Text: text, Sequence: i}); err != nil {
+				t.Fatalf("InsertUtterance failed: %v", err)
+			}
+		}
+		if err := s.RefreshCallSearch(ctx, callID); err != nil {
+			t.Fatalf("RefreshCallSearch failed: %v", err)
+		}
+		return callID
+	}
+
+	suffix := time.Now().Format("20060102150405")
+	roof := newCall("CASRCH1"+suffix, "Dobrý den, volám kvůli opravě střechy.", "Zatéká nám do podkroví.")
+	newCall("CASRCH2"+suffix, "Chtěl bych objednat pizzu.")
+
+	results, err := s.SearchCalls(ctx, tenant.ID, "střecha", CallFilter{}, 10)
+	if err != nil {
+		t.Fatalf("SearchCalls failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ProviderCallID != "CASRCH1"+suffix {
+		t.Fatalf("expected the roof call, got %+v", results)
+	}
+	if !strings.Contains(results[0].Snippet, "<mark>") {
+		t.Errorf("expected a highlighted snippet, got %q", results[0].Snippet)
+	}
+
+	// All words must match
+	if results, err := s.SearchCalls(ctx, tenant.ID, "střecha pizza", CallFilter{}, 10); err != nil || len(results) != 0 {
+		t.Errorf("SearchCalls = %d results, %v; want none", len(results), err)
+	}
+
+	// Summaries are searchable too
+	if err := s.UpsertCallSummary(ctx, roof, CallSummary{Summary: "Pan Novák chce nabídku na klempíře.", Urgency: "bezna"}); err != nil {
+		t.Fatalf("UpsertCallSummary failed: %v", err)
+	}
+	if results, err := s.SearchCalls(ctx, tenant.ID, "Novák", CallFilter{}, 10); err != nil || len(results) != 1 {
+		t.Errorf("SearchCalls by summary = %d results, %v; want 1", len(results), err)
+	}
+
+	resolved := true
+	if results, err := s.SearchCalls(ctx, tenant.ID, "střecha", CallFilter{Resolved: &resolved}, 10); err != nil || len(results) != 0 {
+		t.Errorf("SearchCalls resolved = %d results, %v; want none", len(results), err)
+	}
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+// CallSummary is the post-call summary shown to the owner.
+type CallSummary struct {
+	Summary     string           `json:"summary"`
+	ActionItems []llm.ActionItem `json:"action_items"`
+	Urgency     string           `json:"urgency"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// summaryContent is the part of a summary that is encrypted: both quote the
+// caller.
+type summaryContent struct {
+	Summary     string           `json:"summary"`
+	ActionItems []llm.ActionItem `json:"action_items"`
+}
+
+// UpsertCallSummary stores the call's summary, replacing an earlier one.
+func (s *Store) UpsertCallSummary(ctx context.Context, callID string, cs CallSummary) error {
+	items := cs.ActionItems
+	if items == nil {
+		items = []llm.ActionItem{}
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	summary := cs.Summary
+	var enc []byte
+	if s.crypt != nil {
+		tenantID, err := s.callTenantID(ctx, callID)
+		if err != nil {
+			return err
+		}
+		if enc, err = s.sealJSON(ctx, tenantID, summaryContent{Summary: cs.Summary, ActionItems: items}); err != nil {
+			return err
+		}
+		if enc != nil {
+			summary, raw = "", []byte(`[]`)
+		}
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO call_summaries (call_id, summary, action_items, urgency, summary_enc)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (call_id) DO UPDATE SET
+			summary = EXCLUDED.summary,
+			action_items = EXCLUDED.action_items,
+			urgency = EXCLUDED.urgency,
+			summary_enc = EXCLUDED.summary_enc,
+			updated_at = NOW()
+	`, callID, summary, raw, cs.Urgency, enc)
+	return err
+}
+
+// GetCallSummary returns the call's summary, or nil if it has none.
+func (s *Store) GetCallSummary(ctx context.Context, callID string) (*CallSummary, error) {
+	var cs CallSummary
+	var raw, enc []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT summary, action_items, urgency, created_at, updated_at, summary_enc
+		FROM call_summaries
+		WHERE call_id = $1
+	`, callID).Scan(&cs.Summary, &raw, &cs.Urgency, &cs.CreatedAt, &cs.UpdatedAt, &enc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enc != nil {
+		var c summaryContent
+		if err := s.openJSON(ctx, enc, &c); err != nil {
+			return nil, err
+		}
+		cs.Summary, cs.ActionItems = c.Summary, c.ActionItems
+		return &cs, nil
+	}
+	if err := json.Unmarshal(raw, &cs.ActionItems); err != nil {
+		return nil, err
+	}
+	return &cs, nil
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+func TestCallSummary(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	callSid := "CASUM" + time.Now().Format("20060102150405")
+	if err := s.UpsertCall(ctx, Call{
+		Provider:       "twilio",
+		ProviderCallID: callSid,
+		FromNumber:     "+420777123456",
+		ToNumber:       "+420228883001",
+		Status:         "completed",
+		StartedAt:      time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertCall failed: %v", err)
+	}
+	callID, err := s.GetCallID(ctx, callSid)
+	if err != nil {
+		t.Fatalf("GetCallID failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM calls WHERE id = $1", callID) }()
+
+	if got, err := s.GetCallSummary(ctx, callID); err != nil || got != nil {
+		t.Fatalf("GetCallSummary = %v, %v; want nil before a summary exists", got, err)
+	}
+
+	if err := s.UpsertCallSummary(ctx, callID, CallSummary{Summary: "První verze.", Urgency: llm.UrgencyUnknown}); err != nil {
+		t.Fatalf("UpsertCallSummary failed: %v", err)
+	}
+	err = s.UpsertCallSummary(ctx, callID, CallSummary{
+		Summary:     "Volal pan Novák kvůli nabídce.",
+		ActionItems: []llm.ActionItem{{Text: "Poslat nabídku", Owner: "majitel", Due: "do pátku"}},
+		Urgency:     llm.UrgencyHigh,
+	})
+	if err != nil {
+		t.Fatalf("UpsertCallSummary (update) failed: %v", err)
+	}
+
+	detail, err := s.GetCallDetail(ctx, callSid)
+	if err != nil {
+		t.Fatalf("GetCallDetail failed: %v", err)
+	}
+	got := detail.Summary
+	if got == nil {
+		t.Fatal("expected summary in call detail")
+	}
+	if got.Summary != "Volal pan Novák kvůli nabídce." || got.Urgency != llm.UrgencyHigh {
+		t.Errorf("unexpected summary %+v", got)
+	}
+	if len(got.ActionItems) != 1 || got.ActionItems[0].Due != "do pátku" {
+		t.Errorf("unexpected action items %+v", got.ActionItems)
+	}
+}
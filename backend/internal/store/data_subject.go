@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Data subject request actions.
+const (
+	SubjectActionExport    = "export"
+	SubjectActionDelete    = "delete"
+	SubjectActionAnonymize = "anonymize"
+)
+
+// AnonymizedNumber replaces the caller's number on anonymized calls.
+const AnonymizedNumber = "anonymized"
+
+// SubjectCall is one of the subject's calls with everything stored about it.
+type SubjectCall struct {
+	CallDetail
+	Events []CallEvent `json:"events"`
+}
+
+// SubjectExport is the data bundle returned for an export request.
+type SubjectExport struct {
+	PhoneNumber string        `json:"phone_number"`
+	TenantID    *string       `json:"tenant_id,omitempty"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Calls       []SubjectCall `json:"calls"`
+}
+
+// SubjectRequest is an entry of the append-only data subject request trail.
+type SubjectRequest struct {
+	ID          string    `json:"id"`
+	Action      string    `json:"action"`
+	PhoneHash   string    `json:"phone_hash"`
+	PhoneMasked string    `json:"phone_masked"`
+	TenantID    *string   `json:"tenant_id,omitempty"` // nil = all tenants
+	RequestedBy *string   `json:"requested_by,omitempty"`
+	Admin       bool      `json:"admin"`
+	Calls       int       `json:"calls"`
+	Utterances  int       `json:"utterances"`
+	Events      int       `json:"events"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewSubjectRequest starts an audit record for a request about phone. The
+// number itself is not stored, only its keyed hash and a masked form.
+func (s *Store) NewSubjectRequest(action, phone string, tenantID, requestedBy *string, admin bool) SubjectRequest {
+	return SubjectRequest{
+		Action:      action,
+		PhoneHash:   s.HashPhone(phone),
+		PhoneMasked: MaskPhone(phone),
+		TenantID:    tenantID,
+		RequestedBy: requestedBy,
+		Admin:       admin,
+	}
+}
+
+// SetPhoneHashKey sets the server secret phone numbers are hashed with.
+// Changing it makes earlier hashes unsearchable.
+func (s *Store) SetPhoneHashKey(key []byte) {
+	s.phoneKey = key
+}
+
+// HashPhone returns the hex HMAC-SHA256 of a phone number, used to find
+// audit records for a number without storing it. The key keeps the hash from
+// being reversed by hashing every possible number.
+func (s *Store) HashPhone(phone string) string {
+	mac := hmac.New(sha256.New, s.phoneKey)
+	mac.Write([]byte(phone))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// MaskPhone hides the middle digits of a phone number: +420777123456 →
+// +420777***456.
+func MaskPhone(phone string) string {
+	if len(phone) <= 6 {
+		return "***"
+	}
+	return phone[:len(phone)-6] + "***" + phone[len(phone)-3:]
+}
+
+// The functions below find the subject's calls by caller number, in one
+// tenant or in all tenants if tenantID is nil.
+
+// ExportSubjectData collects every call from phone with its transcript,
+// screening result, summary, call fields and events.
+func (s *Store) ExportSubjectData(ctx context.Context, phone string, tenantID *string) (SubjectExport, error) {
+	out := SubjectExport{PhoneNumber: phone, TenantID: tenantID, GeneratedAt: time.Now().UTC(), Calls: []SubjectCall{}}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT provider_call_id FROM calls
+		WHERE from_number = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+		ORDER BY started_at
+	`, phone, tenantID)
+	if err != nil {
+		return out, err
+	}
+	providerCallIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return out, err
+	}
+
+	for _, providerCallID := range providerCallIDs {
+		detail, err := s.GetCallDetail(ctx, providerCallID)
+		if err != nil {
+			return out, err
+		}
+		if detail.Utterances == nil {
+			detail.Utterances = []Utterance{}
+		}
+		events, err := s.ListCallEvents(ctx, detail.ID, 100000)
+		if err != nil {
+			return out, err
+		}
+		if events == nil {
+			events = []CallEvent{}
+		}
+		out.Calls = append(out.Calls, SubjectCall{CallDetail: detail, Events: events})
+	}
+	return out, nil
+}
+
+// EraseSubjectData erases the calls from phone and records req (action
+// delete or anonymize) with the counts, in one transaction. Delete removes
+// the calls with everything referencing them. Anonymize keeps the calls and
+// their labels for statistics and billing but replaces the number with
+// AnonymizedNumber and removes the transcript, summary, call fields, events
+// and the screening intent text and entities.
+func (s *Store) EraseSubjectData(ctx context.Context, phone string, req SubjectRequest) (SubjectRequest, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return req, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM calls
+		WHERE from_number = $1 AND ($2::uuid IS NULL OR tenant_id = $2)
+		FOR UPDATE
+	`, phone, req.TenantID)
+	if err != nil {
+		return req, err
+	}
+	callIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return req, err
+	}
+	req.Calls = len(callIDs)
+
+	if len(callIDs) > 0 {
+		err = tx.QueryRow(ctx, `
+			SELECT (SELECT COUNT(*) FROM call_utterances WHERE call_id = ANY($1::uuid[])),
+			       (SELECT COUNT(*) FROM call_events WHERE call_id = ANY($1::uuid[]))
+		`, callIDs).Scan(&req.Utterances, &req.Events)
+		if err != nil {
+			return req, err
+		}
+
+		var statements []string
+		if req.Action == SubjectActionAnonymize {
+			statements = []string{
+				`DELETE FROM call_utterances WHERE call_id = ANY($1::uuid[])`,
+				`DELETE FROM call_summaries WHERE call_id = ANY($1::uuid[])`,
+				`DELETE FROM call_field_values WHERE call_id = ANY($1::uuid[])`,
+				`DELETE FROM call_events WHERE call_id = ANY($1::uuid[])`,
+				`UPDATE call_screening_results SET intent_text = '', entities_json = '{}', intent_text_enc = NULL, entities_enc = NULL WHERE call_id = ANY($1::uuid[])`,
+				`UPDATE calls SET from_number = '` + AnonymizedNumber + `', transcript_purged_at = COALESCE(transcript_purged_at, NOW())
+				 WHERE id = ANY($1::uuid[])`,
+			}
+		} else {
+			statements = []string{`DELETE FROM calls WHERE id = ANY($1::uuid[])`}
+		}
+		for _, stmt := range statements {
+			if _, err := tx.Exec(ctx, stmt, callIDs); err != nil {
+				return req, err
+			}
+		}
+	}
+
+	if req, err = insertSubjectRequest(ctx, tx, req); err != nil {
+		return req, err
+	}
+	return req, tx.Commit(ctx)
+}
+
+// RecordSubjectRequest appends req to the data subject request trail.
+func (s *Store) RecordSubjectRequest(ctx context.Context, req SubjectRequest) (SubjectRequest, error) {
+	return insertSubjectRequest(ctx, s.db, req)
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertSubjectRequest(ctx context.Context, db queryRower, req SubjectRequest) (SubjectRequest, error) {
+	err := db.QueryRow(ctx, `
+		INSERT INTO data_subject_requests (action, phone_hmac, phone_masked, tenant_id, requested_by, admin, calls, utterances, events)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, req.Action, req.PhoneHash, req.PhoneMasked, req.TenantID, req.RequestedBy, req.Admin, req.Calls, req.Utterances, req.Events).Scan(&req.ID, &req.CreatedAt)
+	return req, err
+}
+
+// ListSubjectRequests returns the newest data subject requests, of one tenant
+// if tenantID is set, optionally only those about the number with phoneHash.
+func (s *Store) ListSubjectRequests(ctx context.Context, tenantID *string, phoneHash string, limit int) ([]SubjectRequest, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id, action, phone_hmac, phone_masked, tenant_id, requested_by, admin, calls, utterances, events, created_at
+		FROM data_subject_requests
+		WHERE ($1::uuid IS NULL OR tenant_id = $1) AND ($2::text = '' OR phone_hmac = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, tenantID, phoneHash, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []SubjectRequest{}
+	for rows.Next() {
+		var r SubjectRequest
+		if err := rows.Scan(&r.ID, &r.Action, &r.PhoneHash, &r.PhoneMasked, &r.TenantID, &r.RequestedBy, &r.Admin,
+			&r.Calls, &r.Utterances, &r.Events, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMaskPhone(t *testing.T) {
+	for phone, want := range map[string]string{
+		"+420777123456": "+420777***456",
+		"+4412345":      "+4***345",
+		"+123":          "***",
+	} {
+		if got := MaskPhone(phone); got != want {
+			t.Errorf("MaskPhone(%q) = %q, want %q", phone, got, want)
+		}
+	}
+}
+
+func TestHashPhone(t *testing.T) {
+	a, b := &Store{}, &Store{}
+	a.SetPhoneHashKey([]byte("secret-a"))
+	b.SetPhoneHashKey([]byte("secret-b"))
+
+	if a.HashPhone("+420777123456") == a.HashPhone("+420777123457") || len(a.HashPhone("+420777123456")) != 64 {
+		t.Error("HashPhone should return distinct hex digests")
+	}
+	if a.HashPhone("+420777123456") != a.HashPhone("+420777123456") {
+		t.Error("HashPhone should be stable for a key")
+	}
+	if a.HashPhone("+420777123456") == b.HashPhone("+420777123456") {
+		t.Error("HashPhone should depend on the key")
+	}
+	sum := sha256.Sum256([]byte("+420777123456"))
+	if a.HashPhone("+420777123456") == hex.EncodeToString(sum[:]) {
+		t.Error("HashPhone should not be a plain SHA-256 of the number")
+	}
+}
+
+func TestSubjectData(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	s.SetPhoneHashKey([]byte("test-key"))
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Subject Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM calls WHERE tenant_id = $1", tenant.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+
+	suffix := time.Now().Format("20060102150405")
+	phone := "+420700" + suffix[len(suffix)-6:]
+	for i := 0; i < 2; i++ {
+		sid := fmt.Sprintf("CASUBJ%d%s", i, suffix)
+		if err := s.UpsertCall(ctx, Call{
+			Provider: "twilio", ProviderCallID: sid, FromNumber: phone, ToNumber: "+420228883001",
+			Status: "completed", StartedAt: time.Now().Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("UpsertCall failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, "UPDATE calls SET tenant_id = $1 WHERE provider_call_id = $2", tenant.ID, sid); err != nil {
+			t.Fatalf("set tenant failed: %v", err)
+		}
+		id, _ := s.GetCallID(ctx, sid)
+		if err := s.InsertUtterance(ctx, id, Utterance{Speaker: "caller", Text: "Dobrý den", Sequence: 1}); err != nil {
+			t.Fatalf("InsertUtterance failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, "INSERT INTO call_events (call_id, event_type) VALUES ($1, 'test')", id); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+	}
+
+	tenantID := tenant.ID
+	data, err := s.ExportSubjectData(ctx, phone, &tenantID)
+	if err != nil {
+		t.Fatalf("ExportSubjectData failed: %v", err)
+	}
+	if len(data.Calls) != 2 || len(data.Calls[0].Utterances) != 1 || len(data.Calls[0].Events) != 1 {
+		t.Fatalf("unexpected export %+v", data)
+	}
+
+	req, err := s.EraseSubjectData(ctx, phone, s.NewSubjectRequest(SubjectActionAnonymize, phone, &tenantID, nil, false))
+	if err != nil {
+		t.Fatalf("EraseSubjectData failed: %v", err)
+	}
+	if req.ID == "" || req.Calls != 2 || req.Utterances != 2 || req.Events != 2 {
+		t.Errorf("unexpected audit record %+v", req)
+	}
+
+	var anonymized int
+	_ = db.QueryRow(ctx, "SELECT COUNT(*) FROM calls WHERE tenant_id = $1 AND from_number = $2", tenant.ID, AnonymizedNumber).Scan(&anonymized)
+	if anonymized != 2 {
+		t.Errorf("expected 2 anonymized calls, got %d", anonymized)
+	}
+	if data, _ := s.ExportSubjectData(ctx, phone, &tenantID); len(data.Calls) != 0 {
+		t.Errorf("expected no calls for the number after erasure, got %d", len(data.Calls))
+	}
+
+	requests, err := s.ListSubjectRequests(ctx, &tenantID, s.HashPhone(phone), 10)
+	if err != nil || len(requests) != 1 || requests[0].PhoneMasked != MaskPhone(phone) {
+		t.Errorf("ListSubjectRequests = %+v, %v", requests, err)
+	}
+	if _, err := db.Exec(ctx, "DELETE FROM data_subject_requests WHERE id = $1", req.ID); err == nil {
+		t.Error("data subject requests should be append-only")
+	}
+}
@@ -0,0 +1,683 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/envelope"
+	"github.com/lukasbauer/karen/internal/slots"
+)
+
+// Transcript encryption (migrations 029, 034 and 035). With encryption
+// enabled, utterance text, screening intent text and entities, summaries and
+// call field values of calls of tenants that opted in are written to the
+// *_enc columns, encrypted with the tenant's newest data key; the plaintext
+// columns keep '' (entities_json keeps the keys, see maskEntities). Values
+// are decrypted when read, so callers see plaintext either way. Other calls
+// are stored in plaintext. Encrypted values can't be searched or compared in
+// SQL.
+
+// activeKeyTTL is how long a tenant's encryption setting and newest data key
+// version are cached, so servers pick up changes made elsewhere (another
+// server, cmd/rotate-keys).
+const activeKeyTTL = 10 * time.Minute
+
+// ErrEncryptionDisabled is returned when encrypted data is read or keys are
+// managed without a master key configured.
+var ErrEncryptionDisabled = errors.New("store: encrypted data but no encryption key configured")
+
+type dataKeys struct {
+	kms envelope.KMS
+
+	mu     sync.Mutex
+	keys   map[envelope.KeyRef][]byte // Unwrapped data keys
+	active map[string]activeKey       // Newest version by tenant
+}
+
+// activeKey is a tenant's cached encryption state.
+type activeKey struct {
+	enabled  bool // Tenant opted in to encryption
+	version  int  // Newest data key version, 0 = none yet
+	loadedAt time.Time
+}
+
+// EnableEncryption makes the store encrypt transcripts with per-tenant data
+// keys wrapped by kms, and decrypt them on read.
+func (s *Store) EnableEncryption(kms envelope.KMS) {
+	s.crypt = &dataKeys{kms: kms, keys: map[envelope.KeyRef][]byte{}, active: map[string]activeKey{}}
+}
+
+// EncryptionEnabled reports whether a master key is configured, so tenants
+// can opt in to encryption.
+func (s *Store) EncryptionEnabled() bool {
+	return s.crypt != nil
+}
+
+// TenantEncrypted reports whether new calls of the tenant are encrypted.
+func (s *Store) TenantEncrypted(ctx context.Context, tenantID string) (bool, error) {
+	if s.crypt == nil {
+		return false, nil
+	}
+	state, err := s.tenantKeyState(ctx, tenantID)
+	return state.enabled, err
+}
+
+// GetTenantEncryption returns the tenant's encryption setting (false if it
+// has none).
+func (s *Store) GetTenantEncryption(ctx context.Context, tenantID string) (bool, error) {
+	var enabled bool
+	err := s.db.QueryRow(ctx, `
+		SELECT enabled FROM tenant_encryption_settings WHERE tenant_id = $1
+	`, tenantID).Scan(&enabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return enabled, err
+}
+
+// SetTenantEncryption turns encryption of the tenant's new calls on or off.
+// Calls stored earlier keep their form. Other servers pick the change up
+// within activeKeyTTL.
+func (s *Store) SetTenantEncryption(ctx context.Context, tenantID string, enabled bool, updatedBy *string) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO tenant_encryption_settings (tenant_id, enabled, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, tenantID, enabled, updatedBy)
+	if err != nil {
+		return err
+	}
+	if s.crypt != nil {
+		s.crypt.mu.Lock()
+		delete(s.crypt.active, tenantID)
+		s.crypt.mu.Unlock()
+	}
+	return nil
+}
+
+// tenantKeyState returns the tenant's encryption setting and newest data key
+// version, cached for activeKeyTTL.
+func (s *Store) tenantKeyState(ctx context.Context, tenantID string) (activeKey, error) {
+	s.crypt.mu.Lock()
+	state, ok := s.crypt.active[tenantID]
+	s.crypt.mu.Unlock()
+	if ok && time.Since(state.loadedAt) < activeKeyTTL {
+		return state, nil
+	}
+
+	err := s.db.QueryRow(ctx, `
+		SELECT COALESCE((SELECT enabled FROM tenant_encryption_settings WHERE tenant_id = $1), false),
+		       COALESCE((SELECT MAX(version) FROM tenant_data_keys WHERE tenant_id = $1), 0)
+	`, tenantID).Scan(&state.enabled, &state.version)
+	if err != nil {
+		return activeKey{}, err
+	}
+	state.loadedAt = time.Now()
+
+	s.crypt.mu.Lock()
+	s.crypt.active[tenantID] = state
+	s.crypt.mu.Unlock()
+	return state, nil
+}
+
+// dataKey returns the unwrapped data key ref names.
+func (s *Store) dataKey(ctx context.Context, ref envelope.KeyRef) ([]byte, error) {
+	if s.crypt == nil {
+		return nil, ErrEncryptionDisabled
+	}
+	s.crypt.mu.Lock()
+	key, ok := s.crypt.keys[ref]
+	s.crypt.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	var masterKeyID string
+	var wrapped []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT master_key_id, wrapped_key FROM tenant_data_keys WHERE tenant_id = $1 AND version = $2
+	`, ref.TenantID, ref.Version).Scan(&masterKeyID, &wrapped)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("store: data key %s v%d not found", ref.TenantID, ref.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err = s.crypt.kms.Unwrap(ctx, masterKeyID, wrapped, ref.AAD())
+	if err != nil {
+		return nil, err
+	}
+
+	s.crypt.mu.Lock()
+	s.crypt.keys[ref] = key
+	s.crypt.mu.Unlock()
+	return key, nil
+}
+
+// activeDataKey returns the tenant's newest data key, creating the first one
+// if the tenant has none.
+func (s *Store) activeDataKey(ctx context.Context, tenantID string) (envelope.KeyRef, []byte, error) {
+	state, err := s.tenantKeyState(ctx, tenantID)
+	if err != nil {
+		return envelope.KeyRef{}, nil, err
+	}
+	if state.version == 0 {
+		// Concurrent writers may both get here; the loser's key is discarded
+		// and the winner's read back.
+		if err := s.createDataKey(ctx, tenantID, 1); err != nil {
+			return envelope.KeyRef{}, nil, err
+		}
+		state.version = 1
+		s.crypt.mu.Lock()
+		s.crypt.active[tenantID] = state
+		s.crypt.mu.Unlock()
+	}
+
+	ref := envelope.KeyRef{TenantID: tenantID, Version: state.version}
+	key, err := s.dataKey(ctx, ref)
+	return ref, key, err
+}
+
+// createDataKey stores a new data key version for the tenant, wrapped with
+// the active master key. An existing version is left as it is.
+func (s *Store) createDataKey(ctx context.Context, tenantID string, version int) error {
+	ref := envelope.KeyRef{TenantID: tenantID, Version: version}
+	key, err := envelope.NewDataKey()
+	if err != nil {
+		return err
+	}
+	masterKeyID := s.crypt.kms.ActiveKeyID()
+	wrapped, err := s.crypt.kms.Wrap(ctx, masterKeyID, key, ref.AAD())
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO tenant_data_keys (tenant_id, version, master_key_id, wrapped_key)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, version) DO NOTHING
+	`, tenantID, version, masterKeyID, wrapped)
+	return err
+}
+
+// seal encrypts value for the tenant. Returns nil if encryption is disabled,
+// the call has no tenant or the tenant hasn't opted in (the value is stored
+// in plaintext).
+func (s *Store) seal(ctx context.Context, tenantID *string, value []byte) ([]byte, error) {
+	if s.crypt == nil || tenantID == nil {
+		return nil, nil
+	}
+	if encrypted, err := s.TenantEncrypted(ctx, *tenantID); err != nil || !encrypted {
+		return nil, err
+	}
+	ref, key, err := s.activeDataKey(ctx, *tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.Encrypt(key, ref, value)
+}
+
+// open decrypts a value written by seal.
+func (s *Store) open(ctx context.Context, enc []byte) ([]byte, error) {
+	ref, err := envelope.ParseRef(enc)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.dataKey(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.Decrypt(key, enc)
+}
+
+// openText returns the decrypted value of enc, or plaintext if enc is nil.
+func (s *Store) openText(ctx context.Context, plaintext string, enc []byte) (string, error) {
+	if enc == nil {
+		return plaintext, nil
+	}
+	b, err := s.open(ctx, enc)
+	return string(b), err
+}
+
+// sealJSON encrypts the JSON encoding of v for the tenant. Returns nil if the
+// value is stored in plaintext (see seal).
+func (s *Store) sealJSON(ctx context.Context, tenantID *string, v any) ([]byte, error) {
+	if s.crypt == nil || tenantID == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return s.seal(ctx, tenantID, b)
+}
+
+// openJSON decrypts a value written by sealJSON into v.
+func (s *Store) openJSON(ctx context.Context, enc []byte, v any) error {
+	b, err := s.open(ctx, enc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// sealedScreening holds the column values of a screening result as stored.
+type sealedScreening struct {
+	intentText  string
+	entities    json.RawMessage
+	intentEnc   []byte
+	entitiesEnc []byte
+}
+
+// sealScreening encrypts the intent text and entities of sr for the tenant.
+func (s *Store) sealScreening(ctx context.Context, tenantID *string, sr ScreeningResult) (sealedScreening, error) {
+	out := sealedScreening{intentText: sr.IntentText, entities: sr.EntitiesJSON}
+	intentEnc, err := s.seal(ctx, tenantID, []byte(sr.IntentText))
+	if err != nil || intentEnc == nil {
+		return out, err
+	}
+	entitiesEnc, err := s.seal(ctx, tenantID, sr.EntitiesJSON)
+	if err != nil {
+		return out, err
+	}
+	return sealedScreening{intentText: "", entities: maskEntities(sr.EntitiesJSON), intentEnc: intentEnc, entitiesEnc: entitiesEnc}, nil
+}
+
+// openScreening replaces the stored intent text and entities of sr with the
+// decrypted values, if they were encrypted.
+func (s *Store) openScreening(ctx context.Context, sr *ScreeningResult, intentEnc, entitiesEnc []byte) error {
+	var err error
+	if sr.IntentText, err = s.openText(ctx, sr.IntentText, intentEnc); err != nil {
+		return err
+	}
+	if entitiesEnc != nil {
+		entities, err := s.open(ctx, entitiesEnc)
+		if err != nil {
+			return err
+		}
+		sr.EntitiesJSON = json.RawMessage(entities)
+	}
+	return nil
+}
+
+// maskEntities returns the entity keys of raw with true for captured values
+// and null for missing ones, stored in place of encrypted entities.
+func maskEntities(raw json.RawMessage) json.RawMessage {
+	var entities map[string]any
+	if err := json.Unmarshal(raw, &entities); err != nil {
+		return json.RawMessage(`{}`)
+	}
+	masked := make(map[string]any, len(entities))
+	for k, v := range entities {
+		if v == nil || v == "" {
+			masked[k] = nil
+		} else {
+			masked[k] = true
+		}
+	}
+	out, _ := json.Marshal(masked)
+	return out
+}
+
+// eventSpeechKeys are the call event data fields that quote the caller or
+// the assistant's reply (migration 036).
+var eventSpeechKeys = []string{"text", "partial_text", "pending_text", "query", "response_text"}
+
+// SealEventData moves the speech fields of a call event's data into an
+// encrypted JSON object if the call's tenant has encryption enabled
+// (eventlog.Sealer).
+func (s *Store) SealEventData(ctx context.Context, callID string, data map[string]any) (map[string]any, []byte, error) {
+	speech, rest := splitEventSpeech(data)
+	if speech == nil || s.crypt == nil {
+		return data, nil, nil
+	}
+	tenantID, err := s.callTenantID(ctx, callID)
+	if err != nil {
+		return data, nil, err
+	}
+	enc, err := s.sealJSON(ctx, tenantID, speech)
+	if err != nil || enc == nil {
+		return data, nil, err
+	}
+	return rest, enc, nil
+}
+
+// splitEventSpeech splits event data into its speech fields (nil if none)
+// and the rest.
+func splitEventSpeech(data map[string]any) (speech, rest map[string]any) {
+	rest = make(map[string]any, len(data))
+	for k, v := range data {
+		if !slices.Contains(eventSpeechKeys, k) {
+			rest[k] = v
+			continue
+		}
+		if speech == nil {
+			speech = map[string]any{}
+		}
+		speech[k] = v
+	}
+	return speech, rest
+}
+
+// openEventData merges the decrypted speech fields of enc back into the
+// stored event data.
+func (s *Store) openEventData(ctx context.Context, data json.RawMessage, enc []byte) (json.RawMessage, error) {
+	if enc == nil {
+		return data, nil
+	}
+	var merged map[string]any
+	if err := json.Unmarshal(data, &merged); err != nil || merged == nil {
+		merged = map[string]any{}
+	}
+	if err := s.openJSON(ctx, enc, &merged); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+// callTenantID returns the tenant of the call with internal ID callID.
+func (s *Store) callTenantID(ctx context.Context, callID string) (*string, error) {
+	var tenantID *string
+	err := s.db.QueryRow(ctx, `SELECT tenant_id FROM calls WHERE id = $1`, callID).Scan(&tenantID)
+	return tenantID, err
+}
+
+// ============================================================================
+// Key management (cmd/rotate-keys)
+// ============================================================================
+
+// RewrapDataKeys re-wraps every data key not wrapped with the active master
+// key, so retired master keys can be removed. Returns the number re-wrapped.
+func (s *Store) RewrapDataKeys(ctx context.Context) (int, error) {
+	if s.crypt == nil {
+		return 0, ErrEncryptionDisabled
+	}
+	activeID := s.crypt.kms.ActiveKeyID()
+	rows, err := s.db.Query(ctx, `
+		SELECT tenant_id, version, master_key_id, wrapped_key
+		FROM tenant_data_keys
+		WHERE master_key_id <> $1
+		ORDER BY tenant_id, version
+	`, activeID)
+	if err != nil {
+		return 0, err
+	}
+	type wrappedKey struct {
+		ref         envelope.KeyRef
+		masterKeyID string
+		wrapped     []byte
+	}
+	keys, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (wrappedKey, error) {
+		var k wrappedKey
+		err := row.Scan(&k.ref.TenantID, &k.ref.Version, &k.masterKeyID, &k.wrapped)
+		return k, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, k := range keys {
+		key, err := s.crypt.kms.Unwrap(ctx, k.masterKeyID, k.wrapped, k.ref.AAD())
+		if err != nil {
+			return n, fmt.Errorf("unwrap %s v%d: %w", k.ref.TenantID, k.ref.Version, err)
+		}
+		wrapped, err := s.crypt.kms.Wrap(ctx, activeID, key, k.ref.AAD())
+		if err != nil {
+			return n, err
+		}
+		tag, err := s.db.Exec(ctx, `
+			UPDATE tenant_data_keys SET master_key_id = $4, wrapped_key = $5, rewrapped_at = NOW()
+			WHERE tenant_id = $1 AND version = $2 AND master_key_id = $3
+		`, k.ref.TenantID, k.ref.Version, k.masterKeyID, activeID, wrapped)
+		if err != nil {
+			return n, err
+		}
+		n += int(tag.RowsAffected())
+	}
+	return n, nil
+}
+
+// RotateDataKeys adds a new data key version for every tenant with a data
+// key. New values are encrypted with it; older ones keep their version.
+// Returns the number of tenants rotated.
+func (s *Store) RotateDataKeys(ctx context.Context) (int, error) {
+	if s.crypt == nil {
+		return 0, ErrEncryptionDisabled
+	}
+	rows, err := s.db.Query(ctx, `
+		SELECT tenant_id, MAX(version) FROM tenant_data_keys GROUP BY tenant_id ORDER BY tenant_id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	latest, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (envelope.KeyRef, error) {
+		var ref envelope.KeyRef
+		err := row.Scan(&ref.TenantID, &ref.Version)
+		return ref, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i, ref := range latest {
+		if err := s.createDataKey(ctx, ref.TenantID, ref.Version+1); err != nil {
+			return i, err
+		}
+	}
+	return len(latest), nil
+}
+
+// EncryptStoredTranscripts encrypts up to limit plaintext utterances,
+// screening results and call events quoting the caller of calls of tenants
+// that opted in (stored before they did). Returns how many of each it
+// encrypted; repeat until all come back short.
+func (s *Store) EncryptStoredTranscripts(ctx context.Context, limit int) (utterances, results, events int, err error) {
+	if s.crypt == nil {
+		return 0, 0, 0, ErrEncryptionDisabled
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT u.id, c.tenant_id, u.text
+		FROM call_utterances u
+		JOIN calls c ON c.id = u.call_id
+		WHERE u.text_enc IS NULL
+		  AND c.tenant_id IN (SELECT tenant_id FROM tenant_encryption_settings WHERE enabled)
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	type plainUtterance struct {
+		id, tenantID, text string
+	}
+	plainUtterances, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (plainUtterance, error) {
+		var u plainUtterance
+		err := row.Scan(&u.id, &u.tenantID, &u.text)
+		return u, err
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, u := range plainUtterances {
+		enc, err := s.seal(ctx, &u.tenantID, []byte(u.text))
+		if err != nil {
+			return utterances, results, 0, err
+		}
+		if _, err := s.db.Exec(ctx, `
+			UPDATE call_utterances SET text = '', text_enc = $2 WHERE id = $1 AND text_enc IS NULL
+		`, u.id, enc); err != nil {
+			return utterances, results, 0, err
+		}
+		utterances++
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT r.call_id, c.tenant_id, r.intent_text, r.entities_json
+		FROM call_screening_results r
+		JOIN calls c ON c.id = r.call_id
+		WHERE r.intent_text_enc IS NULL
+		  AND c.tenant_id IN (SELECT tenant_id FROM tenant_encryption_settings WHERE enabled)
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return utterances, 0, 0, err
+	}
+	type plainResult struct {
+		callID, tenantID string
+		sr               ScreeningResult
+	}
+	plainResults, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (plainResult, error) {
+		var r plainResult
+		err := row.Scan(&r.callID, &r.tenantID, &r.sr.IntentText, &r.sr.EntitiesJSON)
+		return r, err
+	})
+	if err != nil {
+		return utterances, 0, 0, err
+	}
+	for _, r := range plainResults {
+		sealed, err := s.sealScreening(ctx, &r.tenantID, r.sr)
+		if err != nil {
+			return utterances, results, 0, err
+		}
+		if _, err := s.db.Exec(ctx, `
+			UPDATE call_screening_results
+			SET intent_text = $2, entities_json = $3, intent_text_enc = $4, entities_enc = $5
+			WHERE call_id = $1 AND intent_text_enc IS NULL
+		`, r.callID, sealed.intentText, sealed.entities, sealed.intentEnc, sealed.entitiesEnc); err != nil {
+			return utterances, results, 0, err
+		}
+		results++
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT e.id, c.tenant_id, e.event_data
+		FROM call_events e
+		JOIN calls c ON c.id = e.call_id
+		WHERE e.event_data_enc IS NULL AND e.event_data ?| $2
+		  AND c.tenant_id IN (SELECT tenant_id FROM tenant_encryption_settings WHERE enabled)
+		LIMIT $1
+	`, limit, eventSpeechKeys)
+	if err != nil {
+		return utterances, results, 0, err
+	}
+	type plainEvent struct {
+		id, tenantID string
+		data         map[string]any
+	}
+	plainEvents, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (plainEvent, error) {
+		var e plainEvent
+		err := row.Scan(&e.id, &e.tenantID, &e.data)
+		return e, err
+	})
+	if err != nil {
+		return utterances, results, 0, err
+	}
+	for _, e := range plainEvents {
+		speech, rest := splitEventSpeech(e.data)
+		enc, err := s.sealJSON(ctx, &e.tenantID, speech)
+		if err != nil {
+			return utterances, results, events, err
+		}
+		if _, err := s.db.Exec(ctx, `
+			UPDATE call_events SET event_data = $2, event_data_enc = $3 WHERE id = $1 AND event_data_enc IS NULL
+		`, e.id, rest, enc); err != nil {
+			return utterances, results, events, err
+		}
+		events++
+	}
+	return utterances, results, events, nil
+}
+
+// EncryptStoredSummaries encrypts up to limit plaintext summaries and call
+// field values of calls of tenants that opted in (stored before they did).
+// Returns how many of each it encrypted; repeat until both come back short.
+func (s *Store) EncryptStoredSummaries(ctx context.Context, limit int) (summaries, fieldValues int, err error) {
+	if s.crypt == nil {
+		return 0, 0, ErrEncryptionDisabled
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT s.call_id, c.tenant_id, s.summary, s.action_items
+		FROM call_summaries s
+		JOIN calls c ON c.id = s.call_id
+		WHERE s.summary_enc IS NULL
+		  AND c.tenant_id IN (SELECT tenant_id FROM tenant_encryption_settings WHERE enabled)
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+	type plainSummary struct {
+		callID, tenantID string
+		content          summaryContent
+	}
+	plainSummaries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (plainSummary, error) {
+		var p plainSummary
+		var items []byte
+		if err := row.Scan(&p.callID, &p.tenantID, &p.content.Summary, &items); err != nil {
+			return p, err
+		}
+		err := json.Unmarshal(items, &p.content.ActionItems)
+		return p, err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, p := range plainSummaries {
+		enc, err := s.sealJSON(ctx, &p.tenantID, p.content)
+		if err != nil {
+			return summaries, fieldValues, err
+		}
+		if _, err := s.db.Exec(ctx, `
+			UPDATE call_summaries SET summary = '', action_items = '[]', summary_enc = $2
+			WHERE call_id = $1 AND summary_enc IS NULL
+		`, p.callID, enc); err != nil {
+			return summaries, fieldValues, err
+		}
+		summaries++
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT v.call_id, c.tenant_id, v.key, v.type, v.value_text, v.value_number::float8, v.value_date
+		FROM call_field_values v
+		JOIN calls c ON c.id = v.call_id
+		WHERE v.value_enc IS NULL
+		  AND c.tenant_id IN (SELECT tenant_id FROM tenant_encryption_settings WHERE enabled)
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return summaries, 0, err
+	}
+	type plainValue struct {
+		callID, tenantID string
+		v                slots.Value
+	}
+	plainValues, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (plainValue, error) {
+		var p plainValue
+		err := row.Scan(&p.callID, &p.tenantID, &p.v.Key, &p.v.Type, &p.v.Text, &p.v.Number, &p.v.Date)
+		return p, err
+	})
+	if err != nil {
+		return summaries, 0, err
+	}
+	for _, p := range plainValues {
+		enc, err := s.sealJSON(ctx, &p.tenantID, p.v)
+		if err != nil {
+			return summaries, fieldValues, err
+		}
+		if _, err := s.db.Exec(ctx, `
+			UPDATE call_field_values SET value_text = '', value_number = NULL, value_date = NULL, value_enc = $3
+			WHERE call_id = $1 AND key = $2 AND value_enc IS NULL
+		`, p.callID, p.v.Key, enc); err != nil {
+			return summaries, fieldValues, err
+		}
+		fieldValues++
+	}
+	return summaries, fieldValues, nil
+}
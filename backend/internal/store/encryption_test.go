@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/envelope"
+	"github.com/lukasbauer/karen/internal/eventlog"
+	"github.com/lukasbauer/karen/internal/llm"
+	"github.com/lukasbauer/karen/internal/slots"
+)
+
+func TestMaskEntities(t *testing.T) {
+	got := string(maskEntities(json.RawMessage(`{"name": "Jan Novák", "company": null, "email": "", "count": 3}`)))
+	if want := `{"company":null,"count":true,"email":null,"name":true}`; got != want {
+		t.Errorf("maskEntities = %s, want %s", got, want)
+	}
+	if got := string(maskEntities(json.RawMessage(`null`))); got != `{}` {
+		t.Errorf("maskEntities(null) = %s", got)
+	}
+}
+
+// testKMS returns a KMS with the master keys ids of keys, the first active.
+func testKMS(t *testing.T, keys map[string][]byte, ids ...string) *envelope.FileKMS {
+	t.Helper()
+	subset := map[string][]byte{}
+	for _, id := range ids {
+		subset[id] = keys[id]
+	}
+	kms, err := envelope.NewFileKMS(ids[0], subset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kms
+}
+
+func TestSplitEventSpeech(t *testing.T) {
+	speech, rest := splitEventSpeech(map[string]any{"turn_id": 3, "text": "Dobrý den", "query": "ceník"})
+	if len(speech) != 2 || speech["text"] != "Dobrý den" || speech["query"] != "ceník" {
+		t.Errorf("speech = %v", speech)
+	}
+	if len(rest) != 1 || rest["turn_id"] != 3 {
+		t.Errorf("rest = %v", rest)
+	}
+	if speech, _ := splitEventSpeech(map[string]any{"latency_ms": 120}); speech != nil {
+		t.Errorf("speech = %v, want nil", speech)
+	}
+}
+
+func TestTranscriptEncryption(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+	keys := map[string][]byte{}
+	for _, id := range []string{"k1", "k2"} {
+		key, err := envelope.NewDataKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[id] = key
+	}
+	s.EnableEncryption(testKMS(t, keys, "k1"))
+
+	tenant, err := s.CreateTenant(ctx, "Encryption Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM calls WHERE tenant_id = $1", tenant.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+	if err := s.SetTenantEncryption(ctx, tenant.ID, true, nil); err != nil {
+		t.Fatalf("SetTenantEncryption failed: %v", err)
+	}
+
+	sid := "CAENC" + time.Now().Format("20060102150405")
+	if err := s.UpsertCallWithTenant(ctx, Call{
+		TenantID: &tenant.ID, Provider: "twilio", ProviderCallID: sid, FromNumber: "+420777123456", ToNumber: "+420228883001",
+		Status: "completed", StartedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("UpsertCallWithTenant failed: %v", err)
+	}
+	callID, _ := s.GetCallID(ctx, sid)
+	if err := s.InsertUtterance(ctx, callID, Utterance{Speaker: "caller", Text: "Volám kvůli faktuře", Sequence: 0}); err != nil {
+		t.Fatalf("InsertUtterance failed: %v", err)
+	}
+	if err := s.InsertScreeningResult(ctx, callID, ScreeningResult{
+		LegitimacyLabel: "legitimní", LegitimacyConfidence: 0.9, LeadLabel: "follow_up", IntentCategory: "fakturace",
+		IntentText: "Dotaz na fakturu", EntitiesJSON: json.RawMessage(`{"name": "Jan", "company": null}`), CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("InsertScreeningResult failed: %v", err)
+	}
+	if err := s.UpsertCallSummary(ctx, callID, CallSummary{
+		Summary: "Jan volal kvůli faktuře", ActionItems: []llm.ActionItem{{Text: "Poslat fakturu"}}, Urgency: "normal",
+	}); err != nil {
+		t.Fatalf("UpsertCallSummary failed: %v", err)
+	}
+	if err := s.SaveCallFieldValues(ctx, callID, []slots.Value{{Key: "order", Type: slots.TypeText, Text: "FA-2024-17"}}); err != nil {
+		t.Fatalf("SaveCallFieldValues failed: %v", err)
+	}
+	events := eventlog.New(db)
+	events.SetSealer(s)
+	if err := events.Log(ctx, callID, eventlog.EventTurnFinalized, map[string]any{"turn_id": 1, "text": "Volám kvůli faktuře"}); err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+
+	// Stored encrypted
+	var text, intentText string
+	var entities []byte
+	if err := db.QueryRow(ctx, `
+		SELECT u.text, r.intent_text, r.entities_json
+		FROM call_utterances u JOIN call_screening_results r ON r.call_id = u.call_id
+		WHERE u.call_id = $1 AND u.text_enc IS NOT NULL AND r.intent_text_enc IS NOT NULL AND r.entities_enc IS NOT NULL
+	`, callID).Scan(&text, &intentText, &entities); err != nil {
+		t.Fatalf("expected encrypted rows: %v", err)
+	}
+	var masked map[string]any
+	_ = json.Unmarshal(entities, &masked)
+	if text != "" || intentText != "" || masked["name"] != true || masked["company"] != nil {
+		t.Errorf("plaintext columns should be cleared, got %q, %q, %s", text, intentText, entities)
+	}
+	var summary, valueText string
+	var actionItems []byte
+	if err := db.QueryRow(ctx, `
+		SELECT s.summary, s.action_items, v.value_text
+		FROM call_summaries s JOIN call_field_values v ON v.call_id = s.call_id
+		WHERE s.call_id = $1 AND s.summary_enc IS NOT NULL AND v.value_enc IS NOT NULL
+	`, callID).Scan(&summary, &actionItems, &valueText); err != nil {
+		t.Fatalf("expected encrypted summary and field values: %v", err)
+	}
+	if summary != "" || string(actionItems) != "[]" || valueText != "" {
+		t.Errorf("plaintext columns should be cleared, got %q, %s, %q", summary, actionItems, valueText)
+	}
+	var eventData []byte
+	if err := db.QueryRow(ctx, `
+		SELECT event_data FROM call_events WHERE call_id = $1 AND event_data_enc IS NOT NULL
+	`, callID).Scan(&eventData); err != nil {
+		t.Fatalf("expected an encrypted call event: %v", err)
+	}
+	if want := `{"turn_id": 1}`; string(eventData) != want {
+		t.Errorf("event_data = %s, want %s", eventData, want)
+	}
+	if evs, err := s.ListCallEvents(ctx, callID, 10); err != nil || len(evs) != 1 || !strings.Contains(string(evs[0].EventData), "Volám kvůli faktuře") {
+		t.Errorf("ListCallEvents = %+v, %v", evs, err)
+	}
+
+	// Read back decrypted
+	detail, err := s.GetCallDetail(ctx, sid)
+	if err != nil {
+		t.Fatalf("GetCallDetail failed: %v", err)
+	}
+	if len(detail.Utterances) != 1 || detail.Utterances[0].Text != "Volám kvůli faktuře" {
+		t.Errorf("unexpected utterances %+v", detail.Utterances)
+	}
+	if detail.Screening == nil || detail.Screening.IntentText != "Dotaz na fakturu" || !json.Valid(detail.Screening.EntitiesJSON) {
+		t.Errorf("unexpected screening %+v", detail.Screening)
+	}
+	if detail.Summary == nil || detail.Summary.Summary != "Jan volal kvůli faktuře" || len(detail.Summary.ActionItems) != 1 {
+		t.Errorf("unexpected summary %+v", detail.Summary)
+	}
+	if len(detail.Fields) != 1 || detail.Fields[0].Text != "FA-2024-17" {
+		t.Errorf("unexpected fields %+v", detail.Fields)
+	}
+	calls, err := s.ListCallsByTenant(ctx, tenant.ID, 10)
+	if err != nil || len(calls) != 1 || calls[0].Screening == nil || calls[0].Screening.IntentText != "Dotaz na fakturu" || calls[0].Fields["order"] != "FA-2024-17" {
+		t.Errorf("ListCallsByTenant = %+v, %v", calls, err)
+	}
+
+	// Encrypted values can't be searched or compared in SQL
+	if _, err := s.SearchCalls(ctx, tenant.ID, "faktura", CallFilter{}, 10); !errors.Is(err, ErrSearchUnavailable) {
+		t.Errorf("SearchCalls error = %v, want ErrSearchUnavailable", err)
+	}
+	valueFilter := CallFilter{Fields: []CallFieldFilter{{Key: "order", Op: FieldFilterEq, Value: slots.Value{Text: "FA-2024-17"}}}}
+	if _, err := s.ListCallsPage(ctx, tenant.ID, valueFilter, "", 10); !errors.Is(err, ErrFieldFilterUnavailable) {
+		t.Errorf("ListCallsPage error = %v, want ErrFieldFilterUnavailable", err)
+	}
+	presentFilter := CallFilter{Fields: []CallFieldFilter{{Key: "order", Op: FieldFilterPresent}}}
+	if page, err := s.ListCallsPage(ctx, tenant.ID, presentFilter, "", 10); err != nil || len(page.Calls) != 1 {
+		t.Errorf("ListCallsPage(present) = %+v, %v", page, err)
+	}
+	// Only for tenants that opted in
+	if err := s.SetTenantEncryption(ctx, tenant.ID, false, nil); err != nil {
+		t.Fatalf("SetTenantEncryption failed: %v", err)
+	}
+	if _, err := s.SearchCalls(ctx, tenant.ID, "faktura", CallFilter{}, 10); err != nil {
+		t.Errorf("SearchCalls after opting out: %v", err)
+	}
+	if _, err := s.ListCallsPage(ctx, tenant.ID, valueFilter, "", 10); err != nil {
+		t.Errorf("ListCallsPage after opting out: %v", err)
+	}
+	if err := s.SetTenantEncryption(ctx, tenant.ID, true, nil); err != nil {
+		t.Fatalf("SetTenantEncryption failed: %v", err)
+	}
+
+	// Without the key the data can't be read
+	if _, err := New(db).GetCallDetail(ctx, sid); err == nil {
+		t.Error("expected an error reading encrypted data without a key")
+	}
+
+	// Rotating the master key re-wraps the data key; data stays readable
+	rotated := New(db)
+	rotated.EnableEncryption(testKMS(t, keys, "k2", "k1"))
+	if n, err := rotated.RewrapDataKeys(ctx); err != nil || n != 1 {
+		t.Fatalf("RewrapDataKeys = %d, %v", n, err)
+	}
+	onlyNew := New(db)
+	onlyNew.EnableEncryption(testKMS(t, keys, "k2"))
+	if detail, err := onlyNew.GetCallDetail(ctx, sid); err != nil || len(detail.Utterances) != 1 || detail.Utterances[0].Text != "Volám kvůli faktuře" {
+		t.Errorf("GetCallDetail after re-wrap = %+v, %v", detail.Utterances, err)
+	}
+
+	// A new data key version encrypts new values; old ones stay readable
+	if _, err := onlyNew.RotateDataKeys(ctx); err != nil {
+		t.Fatalf("RotateDataKeys failed: %v", err)
+	}
+	fresh := New(db)
+	fresh.EnableEncryption(testKMS(t, keys, "k2"))
+	if err := fresh.InsertUtterance(ctx, callID, Utterance{Speaker: "agent", Text: "Rozumím", Sequence: 1}); err != nil {
+		t.Fatalf("InsertUtterance failed: %v", err)
+	}
+	var enc []byte
+	_ = db.QueryRow(ctx, `SELECT text_enc FROM call_utterances WHERE call_id = $1 AND sequence = 1`, callID).Scan(&enc)
+	if ref, err := envelope.ParseRef(enc); err != nil || ref.Version != 2 {
+		t.Errorf("new utterance key ref = %+v, %v", ref, err)
+	}
+	if detail, err := fresh.GetCallDetail(ctx, sid); err != nil || len(detail.Utterances) != 2 {
+		t.Errorf("GetCallDetail after data key rotation = %+v, %v", detail.Utterances, err)
+	}
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/experiments"
+)
+
+// Experiment is an A/B experiment over call configuration.
+type Experiment struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	TenantID    *string               `json:"tenant_id,omitempty"` // nil = all tenants
+	Status      string                `json:"status"`
+	Variants    []experiments.Variant `json:"variants"`
+	CreatedAt   time.Time             `json:"created_at"`
+	StartedAt   *time.Time            `json:"started_at,omitempty"`
+	StoppedAt   *time.Time            `json:"stopped_at,omitempty"`
+}
+
+// VariantOutcome holds outcome metrics of the calls assigned to one variant.
+type VariantOutcome struct {
+	Variant                 string   `json:"variant"`
+	Calls                   int      `json:"calls"`
+	Completed               int      `json:"completed"`
+	CompletedRate           float64  `json:"completed_rate"`
+	EarlyHangups            int      `json:"early_hangups"`
+	EarlyHangupRate         float64  `json:"early_hangup_rate"`
+	AvgBargeIns             float64  `json:"avg_barge_ins"`
+	NameCaptured            int      `json:"name_captured"`
+	NameCapturedRate        float64  `json:"name_captured_rate"`
+	AvgLegitimacyConfidence *float64 `json:"avg_legitimacy_confidence,omitempty"`
+}
+
+const experimentColumns = `id, name, description, tenant_id, status, variants, created_at, started_at, stopped_at`
+
+func scanExperiment(row interface{ Scan(...any) error }) (*Experiment, error) {
+	var e Experiment
+	var variants []byte
+	if err := row.Scan(&e.ID, &e.Name, &e.Description, &e.TenantID, &e.Status, &variants,
+		&e.CreatedAt, &e.StartedAt, &e.StoppedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variants, &e.Variants); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateExperiment creates an experiment in draft status.
+func (s *Store) CreateExperiment(ctx context.Context, name, description string, tenantID *string, variants []experiments.Variant) (*Experiment, error) {
+	variantsJSON, err := json.Marshal(variants)
+	if err != nil {
+		return nil, err
+	}
+	return scanExperiment(s.db.QueryRow(ctx, `
+		INSERT INTO experiments (name, description, tenant_id, variants)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+experimentColumns,
+		name, description, tenantID, variantsJSON))
+}
+
+// GetExperiment retrieves an experiment by ID.
+func (s *Store) GetExperiment(ctx context.Context, id string) (*Experiment, error) {
+	return scanExperiment(s.db.QueryRow(ctx, `
+		SELECT `+experimentColumns+` FROM experiments WHERE id = $1
+	`, id))
+}
+
+// ListExperiments returns all experiments, newest first.
+func (s *Store) ListExperiments(ctx context.Context) ([]Experiment, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+experimentColumns+` FROM experiments ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Experiment{}
+	for rows.Next() {
+		e, err := scanExperiment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+// UpdateExperimentStatus moves an experiment to running or stopped,
+// stamping started_at/stopped_at.
+func (s *Store) UpdateExperimentStatus(ctx context.Context, id, status string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE experiments
+		SET status = $2,
+		    started_at = CASE WHEN $2 = 'running' THEN COALESCE(started_at, NOW()) ELSE started_at END,
+		    stopped_at = CASE WHEN $2 = 'stopped' THEN NOW() ELSE stopped_at END
+		WHERE id = $1
+	`, id, status)
+	return err
+}
+
+// GetRunningExperimentForTenant returns the running experiment that applies to
+// a tenant's calls: a tenant-specific experiment takes precedence over one for
+// all tenants, then the most recently started wins. Returns pgx.ErrNoRows if none.
+func (s *Store) GetRunningExperimentForTenant(ctx context.Context, tenantID string) (*Experiment, error) {
+	return scanExperiment(s.db.QueryRow(ctx, `
+		SELECT `+experimentColumns+`
+		FROM experiments
+		WHERE status = 'running' AND (tenant_id = $1 OR tenant_id IS NULL)
+		ORDER BY tenant_id IS NULL, started_at DESC
+		LIMIT 1
+	`, tenantID))
+}
+
+// SetCallExperiment records the experiment variant a call was assigned to.
+// If the variant overrides the system prompt, the call's prompt_version is
+// cleared: the call wasn't answered with it (replay uses the variant prompt).
+func (s *Store) SetCallExperiment(ctx context.Context, providerCallID, experimentID, variant string, overridesPrompt bool) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE calls
+		SET experiment_id = $2, experiment_variant = $3,
+		    prompt_version = CASE WHEN $4 THEN NULL ELSE prompt_version END
+		WHERE provider = 'twilio' AND provider_call_id = $1
+	`, providerCallID, experimentID, variant, overridesPrompt)
+	return err
+}
+
+// GetExperimentOutcomes aggregates outcome metrics per variant. A caller hangup
+// within earlyHangupSeconds of the call start counts as an early hangup.
+func (s *Store) GetExperimentOutcomes(ctx context.Context, experimentID string, earlyHangupSeconds int) ([]VariantOutcome, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT c.experiment_variant,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE c.status = 'completed'),
+		       COUNT(*) FILTER (WHERE c.ended_by = 'caller' AND c.ended_at IS NOT NULL
+		                          AND c.ended_at - c.started_at < make_interval(secs => $2)),
+		       COALESCE(AVG(b.barge_ins), 0),
+		       COUNT(*) FILTER (WHERE COALESCE(r.entities_json->>'name', '') NOT IN ('', 'null')),
+		       AVG(r.legitimacy_confidence)
+		FROM calls c
+		LEFT JOIN call_screening_results r ON r.call_id = c.id
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS barge_ins FROM call_events e
+			WHERE e.call_id = c.id AND e.event_type = 'barge_in'
+		) b ON TRUE
+		WHERE c.experiment_id = $1
+		GROUP BY c.experiment_variant
+		ORDER BY c.experiment_variant
+	`, experimentID, earlyHangupSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []VariantOutcome{}
+	for rows.Next() {
+		var o VariantOutcome
+		if err := rows.Scan(&o.Variant, &o.Calls, &o.Completed, &o.EarlyHangups, &o.AvgBargeIns,
+			&o.NameCaptured, &o.AvgLegitimacyConfidence); err != nil {
+			return nil, err
+		}
+		if o.Calls > 0 {
+			o.CompletedRate = float64(o.Completed) / float64(o.Calls)
+			o.EarlyHangupRate = float64(o.EarlyHangups) / float64(o.Calls)
+			o.NameCapturedRate = float64(o.NameCaptured) / float64(o.Calls)
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/knowledge"
+)
+
+// knowledgeCandidateLimit caps the full-text matches ranked with BM25 per search.
+const knowledgeCandidateLimit = 50
+
+// KnowledgeDocument is a tenant knowledge base entry (FAQ or document).
+type KnowledgeDocument struct {
+	ID         string    `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	Kind       string    `json:"kind"` // faq, document
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	ChunkCount int       `json:"chunk_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// KnowledgeSnippet is a retrieved knowledge base chunk.
+type KnowledgeSnippet struct {
+	ChunkID    string  `json:"chunk_id"`
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	Content    string  `json:"content"`
+	Score      float64 `json:"score"`
+}
+
+// CreateKnowledgeDocument stores a knowledge base entry and indexes its chunks.
+func (s *Store) CreateKnowledgeDocument(ctx context.Context, tenantID, kind, title, content string) (*KnowledgeDocument, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	doc := KnowledgeDocument{TenantID: tenantID, Kind: kind, Title: title, Content: content}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO knowledge_documents (tenant_id, kind, title, content)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, tenantID, kind, title, content).Scan(&doc.ID, &doc.CreatedAt, &doc.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.ChunkCount, err = insertKnowledgeChunks(ctx, tx, &doc); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// UpdateKnowledgeDocument replaces an entry's title and content and
+// re-indexes it. Returns pgx.ErrNoRows if the entry doesn't belong to the tenant.
+func (s *Store) UpdateKnowledgeDocument(ctx context.Context, tenantID, id, title, content string) (*KnowledgeDocument, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	doc := KnowledgeDocument{ID: id, TenantID: tenantID, Title: title, Content: content}
+	err = tx.QueryRow(ctx, `
+		UPDATE knowledge_documents
+		SET title = $3, content = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING kind, created_at, updated_at
+	`, id, tenantID, title, content).Scan(&doc.Kind, &doc.CreatedAt, &doc.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM knowledge_chunks WHERE document_id = $1`, id); err != nil {
+		return nil, err
+	}
+	if doc.ChunkCount, err = insertKnowledgeChunks(ctx, tx, &doc); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// GetKnowledgeDocumentKind returns the kind of a tenant's knowledge base
+// entry, or pgx.ErrNoRows if it doesn't exist.
+func (s *Store) GetKnowledgeDocumentKind(ctx context.Context, tenantID, id string) (string, error) {
+	var kind string
+	err := s.db.QueryRow(ctx, `
+		SELECT kind FROM knowledge_documents WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID).Scan(&kind)
+	return kind, err
+}
+
+func insertKnowledgeChunks(ctx context.Context, tx pgx.Tx, doc *KnowledgeDocument) (int, error) {
+	chunks := knowledge.Chunks(doc.Kind, doc.Title, doc.Content)
+	for i, chunk := range chunks {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO knowledge_chunks (document_id, tenant_id, seq, content, token_count)
+			VALUES ($1, $2, $3, $4, $5)
+		`, doc.ID, doc.TenantID, i+1, chunk, len(knowledge.Tokens(chunk))); err != nil {
+			return 0, err
+		}
+	}
+	return len(chunks), nil
+}
+
+// DeleteKnowledgeDocument deletes an entry and its chunks. Returns false if
+// the entry doesn't belong to the tenant.
+func (s *Store) DeleteKnowledgeDocument(ctx context.Context, tenantID, id string) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM knowledge_documents WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListKnowledgeDocuments returns the tenant's knowledge base entries, newest first.
+func (s *Store) ListKnowledgeDocuments(ctx context.Context, tenantID string) ([]KnowledgeDocument, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT d.id, d.tenant_id, d.kind, d.title, d.content,
+		       (SELECT COUNT(*) FROM knowledge_chunks c WHERE c.document_id = d.id),
+		       d.created_at, d.updated_at
+		FROM knowledge_documents d
+		WHERE d.tenant_id = $1
+		ORDER BY d.created_at DESC
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []KnowledgeDocument{}
+	for rows.Next() {
+		var d KnowledgeDocument
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.Kind, &d.Title, &d.Content, &d.ChunkCount, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// HasKnowledge reports whether the tenant has any knowledge base entries.
+func (s *Store) HasKnowledge(ctx context.Context, tenantID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM knowledge_chunks WHERE tenant_id = $1)
+	`, tenantID).Scan(&exists)
+	return exists, err
+}
+
+// SearchKnowledge returns the tenant's chunks most relevant to query, ranked
+// with BM25 over the full-text matches.
+func (s *Store) SearchKnowledge(ctx context.Context, tenantID, query string, limit int) ([]KnowledgeSnippet, error) {
+	terms := knowledge.Terms(query)
+	if len(terms) == 0 {
+		return []KnowledgeSnippet{}, nil
+	}
+	tsquery := knowledge.TSQuery(terms)
+
+	rows, err := s.db.Query(ctx, `
+		SELECT c.id, c.document_id, d.title, c.content
+		FROM knowledge_chunks c
+		JOIN knowledge_documents d ON d.id = c.document_id
+		WHERE c.tenant_id = $1 AND c.tsv @@ to_tsquery('czech_unaccent', $2)
+		ORDER BY ts_rank(c.tsv, to_tsquery('czech_unaccent', $2)) DESC
+		LIMIT $3
+	`, tenantID, tsquery, knowledgeCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []knowledge.Candidate
+	byID := map[string]KnowledgeSnippet{}
+	for rows.Next() {
+		var sn KnowledgeSnippet
+		if err := rows.Scan(&sn.ChunkID, &sn.DocumentID, &sn.Title, &sn.Content); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		candidates = append(candidates, knowledge.Candidate{ID: sn.ChunkID, Content: sn.Content})
+		byID[sn.ChunkID] = sn
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []KnowledgeSnippet{}, nil
+	}
+
+	// Corpus statistics: chunk count, average length and per-term document frequency
+	stats := knowledge.Stats{DocFrequency: make(map[string]int, len(terms))}
+	cols := make([]string, len(terms))
+	args := []any{tenantID}
+	for i, term := range terms {
+		cols[i] = fmt.Sprintf("COUNT(*) FILTER (WHERE tsv @@ to_tsquery('czech_unaccent', $%d))", i+2)
+		args = append(args, knowledge.TSQuery([]string{term}))
+	}
+	dfs := make([]int, len(terms))
+	dest := []any{&stats.Chunks, &stats.AvgTokens}
+	for i := range dfs {
+		dest = append(dest, &dfs[i])
+	}
+	err = s.db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(AVG(token_count), 0)::float8, `+strings.Join(cols, ", ")+`
+		FROM knowledge_chunks
+		WHERE tenant_id = $1
+	`, args...).Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	for i, term := range terms {
+		stats.DocFrequency[term] = dfs[i]
+	}
+
+	ranked := knowledge.RankBM25(candidates, terms, stats, limit)
+	out := make([]KnowledgeSnippet, len(ranked))
+	for i, r := range ranked {
+		sn := byID[r.ID]
+		sn.Score = r.Score
+		out[i] = sn
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestKnowledgeBase(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Knowledge Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID) }()
+
+	if has, err := s.HasKnowledge(ctx, tenant.ID); err != nil || has {
+		t.Fatalf("HasKnowledge = %v, %v; want false", has, err)
+	}
+
+	hours, err := s.CreateKnowledgeDocument(ctx, tenant.ID, "faq", "Jaká je otevírací doba?", "Pondělí až pátek od 8 do 16 hodin.")
+	if err != nil {
+		t.Fatalf("CreateKnowledgeDocument failed: %v", err)
+	}
+	if hours.ChunkCount != 1 {
+		t.Errorf("FAQ chunk count = %d, want 1", hours.ChunkCount)
+	}
+	if _, err := s.CreateKnowledgeDocument(ctx, tenant.ID, "document", "Ceník", "Konzultace stojí 1500 Kč za hodinu."); err != nil {
+		t.Fatalf("CreateKnowledgeDocument failed: %v", err)
+	}
+
+	if has, err := s.HasKnowledge(ctx, tenant.ID); err != nil || !has {
+		t.Fatalf("HasKnowledge = %v, %v; want true", has, err)
+	}
+
+	// Inflected, unaccented query still matches
+	snippets, err := s.SearchKnowledge(ctx, tenant.ID, "kdy mate otevreno, jake jsou oteviraci hodiny", 3)
+	if err != nil {
+		t.Fatalf("SearchKnowledge failed: %v", err)
+	}
+	if len(snippets) == 0 || snippets[0].DocumentID != hours.ID {
+		t.Fatalf("expected the opening hours FAQ first, got %+v", snippets)
+	}
+
+	if kind, err := s.GetKnowledgeDocumentKind(ctx, tenant.ID, hours.ID); err != nil || kind != "faq" {
+		t.Errorf("GetKnowledgeDocumentKind = %q, %v; want faq", kind, err)
+	}
+
+	// Other tenants don't see or modify the entry
+	if _, err := s.GetKnowledgeDocumentKind(ctx, "00000000-0000-0000-0000-000000000000", hours.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetKnowledgeDocumentKind for another tenant: err = %v, want ErrNoRows", err)
+	}
+	if _, err := s.UpdateKnowledgeDocument(ctx, "00000000-0000-0000-0000-000000000000", hours.ID, "x", "y"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("UpdateKnowledgeDocument for another tenant: err = %v, want ErrNoRows", err)
+	}
+
+	updated, err := s.UpdateKnowledgeDocument(ctx, tenant.ID, hours.ID, "Parkování", "Parkovat můžete před budovou.")
+	if err != nil {
+		t.Fatalf("UpdateKnowledgeDocument failed: %v", err)
+	}
+	if updated.Kind != "faq" || updated.ChunkCount != 1 {
+		t.Errorf("unexpected updated document %+v", updated)
+	}
+	snippets, err = s.SearchKnowledge(ctx, tenant.ID, "otevírací doba", 3)
+	if err != nil {
+		t.Fatalf("SearchKnowledge failed: %v", err)
+	}
+	if len(snippets) != 0 {
+		t.Errorf("expected re-indexed entry not to match old content, got %+v", snippets)
+	}
+
+	docs, err := s.ListKnowledgeDocuments(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("ListKnowledgeDocuments failed: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Errorf("expected 2 documents, got %d", len(docs))
+	}
+
+	deleted, err := s.DeleteKnowledgeDocument(ctx, tenant.ID, hours.ID)
+	if err != nil || !deleted {
+		t.Fatalf("DeleteKnowledgeDocument = %v, %v", deleted, err)
+	}
+	if deleted, _ := s.DeleteKnowledgeDocument(ctx, tenant.ID, hours.ID); deleted {
+		t.Error("expected second delete to report not found")
+	}
+}
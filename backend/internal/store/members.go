@@ -0,0 +1,296 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Tenant roles, from most to least access.
+const (
+	RoleOwner  = "owner"  // Everything, including members, billing and settings
+	RoleMember = "member" // Works with calls and the knowledge base
+	RoleViewer = "viewer" // Read-only
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleMember: 2, RoleOwner: 3}
+
+// ValidRole reports whether role is a tenant role.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAtLeast reports whether role grants at least the access of min.
+func RoleAtLeast(role, min string) bool {
+	return ValidRole(role) && roleRank[role] >= roleRank[min]
+}
+
+// Call notification preferences of a member.
+const (
+	NotifyCallsAll        = "all"
+	NotifyCallsLegitimate = "legitimate" // All but spam
+	NotifyCallsNone       = "none"
+)
+
+// InvitationTTL is how long an invitation can be accepted.
+const InvitationTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrLastOwner is returned when a change would leave a tenant without an owner.
+	ErrLastOwner = errors.New("store: tenant must keep an owner")
+	// ErrAlreadyInTenant is returned when accepting an invitation while
+	// belonging to another tenant.
+	ErrAlreadyInTenant = errors.New("store: user already belongs to a tenant")
+)
+
+// MemberSettings are a member's own preferences.
+type MemberSettings struct {
+	NotifyCalls string `json:"notify_calls"` // all, legitimate or none
+	NotifyUsage bool   `json:"notify_usage"` // Usage limit warnings
+}
+
+// Invitation is an open invitation to join a tenant.
+type Invitation struct {
+	ID         string    `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	TenantName string    `json:"tenant_name,omitempty"`
+	Phone      string    `json:"phone"`
+	Role       string    `json:"role"`
+	InvitedBy  *string   `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+const invitationColumns = `i.id, i.tenant_id, t.name, i.phone, i.role, i.invited_by, i.expires_at, i.created_at`
+
+func scanInvitation(row pgx.Row) (Invitation, error) {
+	var inv Invitation
+	var tenantName *string
+	err := row.Scan(&inv.ID, &inv.TenantID, &tenantName, &inv.Phone, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt, &inv.CreatedAt)
+	if tenantName != nil {
+		inv.TenantName = *tenantName
+	}
+	return inv, err
+}
+
+func (s *Store) queryInvitations(ctx context.Context, sql string, args ...any) ([]Invitation, error) {
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+// CreateInvitation invites the phone number to the tenant with the role. An
+// open invitation for the same number is renewed instead.
+func (s *Store) CreateInvitation(ctx context.Context, tenantID, phone, role string, invitedBy *string) (Invitation, error) {
+	return scanInvitation(s.db.QueryRow(ctx, `
+		WITH i AS (
+			INSERT INTO tenant_invitations (tenant_id, phone, role, invited_by, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tenant_id, phone) WHERE accepted_at IS NULL AND revoked_at IS NULL
+			DO UPDATE SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by,
+			              expires_at = EXCLUDED.expires_at, created_at = NOW()
+			RETURNING *
+		)
+		SELECT `+invitationColumns+`
+		FROM i JOIN tenants t ON t.id = i.tenant_id
+	`, tenantID, phone, role, invitedBy, time.Now().Add(InvitationTTL)))
+}
+
+// ListTenantInvitations returns the tenant's open invitations, including
+// expired ones, newest first.
+func (s *Store) ListTenantInvitations(ctx context.Context, tenantID string) ([]Invitation, error) {
+	return s.queryInvitations(ctx, `
+		SELECT `+invitationColumns+`
+		FROM tenant_invitations i JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.tenant_id = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL
+		ORDER BY i.created_at DESC
+	`, tenantID)
+}
+
+// ListPhoneInvitations returns the open, unexpired invitations to the phone
+// number.
+func (s *Store) ListPhoneInvitations(ctx context.Context, phone string) ([]Invitation, error) {
+	return s.queryInvitations(ctx, `
+		SELECT `+invitationColumns+`
+		FROM tenant_invitations i JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.phone = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()
+		ORDER BY i.created_at DESC
+	`, phone)
+}
+
+// RevokeInvitation revokes an open invitation of the tenant. Returns
+// pgx.ErrNoRows if there is none with the ID.
+func (s *Store) RevokeInvitation(ctx context.Context, tenantID, id string) (Invitation, error) {
+	return scanInvitation(s.db.QueryRow(ctx, `
+		WITH i AS (
+			UPDATE tenant_invitations SET revoked_at = NOW()
+			WHERE id = $2 AND tenant_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+			RETURNING *
+		)
+		SELECT `+invitationColumns+`
+		FROM i JOIN tenants t ON t.id = i.tenant_id
+	`, tenantID, id))
+}
+
+// DeclineInvitation declines an open invitation to the phone number. Returns
+// pgx.ErrNoRows if there is none with the ID.
+func (s *Store) DeclineInvitation(ctx context.Context, id, phone string) error {
+	result, err := s.db.Exec(ctx, `
+		UPDATE tenant_invitations SET revoked_at = NOW()
+		WHERE id = $1 AND phone = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, id, phone)
+	if err == nil && result.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+	}
+	return err
+}
+
+// AcceptInvitation adds the user to the invitation's tenant with its role.
+// The invitation must be open, unexpired and for the user's phone number
+// (pgx.ErrNoRows otherwise), and the user must not belong to a tenant
+// (ErrAlreadyInTenant).
+func (s *Store) AcceptInvitation(ctx context.Context, id, userID string) (Invitation, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var phone string
+	var inTenant bool
+	if err := tx.QueryRow(ctx, `
+		SELECT phone, EXISTS(SELECT 1 FROM tenants t WHERE t.id = u.tenant_id)
+		FROM users u WHERE id = $1
+		FOR UPDATE
+	`, userID).Scan(&phone, &inTenant); err != nil {
+		return Invitation{}, err
+	}
+
+	inv, err := scanInvitation(tx.QueryRow(ctx, `
+		SELECT `+invitationColumns+`
+		FROM tenant_invitations i JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.id = $1 AND i.phone = $2 AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()
+		FOR UPDATE OF i
+	`, id, phone))
+	if err != nil {
+		return Invitation{}, err
+	}
+	if inTenant {
+		return Invitation{}, ErrAlreadyInTenant
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET tenant_id = $2, role = $3 WHERE id = $1`, userID, inv.TenantID, inv.Role); err != nil {
+		return Invitation{}, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE tenant_invitations SET accepted_at = NOW() WHERE id = $1`, id); err != nil {
+		return Invitation{}, err
+	}
+	return inv, tx.Commit(ctx)
+}
+
+// lockMembers locks the tenant's users and returns their roles by user ID.
+func lockMembers(ctx context.Context, tx pgx.Tx, tenantID string) (map[string]string, error) {
+	rows, err := tx.Query(ctx, `SELECT id, role FROM users WHERE tenant_id = $1 FOR UPDATE`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := map[string]string{}
+	for rows.Next() {
+		var id, role string
+		if err := rows.Scan(&id, &role); err != nil {
+			return nil, err
+		}
+		roles[id] = role
+	}
+	return roles, rows.Err()
+}
+
+// changeMember gives a member of the tenant the role, or removes them from
+// the tenant if role is "", unless that leaves the tenant without an owner
+// (ErrLastOwner). Returns the member's previous role, or pgx.ErrNoRows if the
+// user is not a member.
+func (s *Store) changeMember(ctx context.Context, tenantID, userID, role string) (string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	roles, err := lockMembers(ctx, tx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	prev, ok := roles[userID]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	if prev == RoleOwner && role != RoleOwner {
+		owners := 0
+		for _, r := range roles {
+			if r == RoleOwner {
+				owners++
+			}
+		}
+		if owners == 1 {
+			return prev, ErrLastOwner
+		}
+	}
+
+	if role == "" {
+		// Users without a tenant become the owner of the one they onboard
+		_, err = tx.Exec(ctx, `UPDATE users SET tenant_id = NULL, role = 'owner' WHERE id = $1`, userID)
+	} else {
+		_, err = tx.Exec(ctx, `UPDATE users SET role = $2 WHERE id = $1`, userID, role)
+	}
+	if err != nil {
+		return prev, err
+	}
+	return prev, tx.Commit(ctx)
+}
+
+// UpdateMemberRole changes the role of a member of the tenant and returns the
+// previous one. The last owner can't be demoted (ErrLastOwner).
+func (s *Store) UpdateMemberRole(ctx context.Context, tenantID, userID, role string) (string, error) {
+	return s.changeMember(ctx, tenantID, userID, role)
+}
+
+// RemoveMember removes a user from the tenant and returns their role. They
+// keep their account and can onboard a tenant of their own or accept another
+// invitation. The last owner can't be removed (ErrLastOwner).
+func (s *Store) RemoveMember(ctx context.Context, tenantID, userID string) (string, error) {
+	return s.changeMember(ctx, tenantID, userID, "")
+}
+
+// GetMemberSettings returns the user's own preferences.
+func (s *Store) GetMemberSettings(ctx context.Context, userID string) (MemberSettings, error) {
+	var ms MemberSettings
+	err := s.db.QueryRow(ctx, `
+		SELECT notify_calls, notify_usage FROM users WHERE id = $1
+	`, userID).Scan(&ms.NotifyCalls, &ms.NotifyUsage)
+	return ms, err
+}
+
+// SetMemberSettings saves the user's own preferences.
+func (s *Store) SetMemberSettings(ctx context.Context, userID string, ms MemberSettings) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE users SET notify_calls = $2, notify_usage = $3 WHERE id = $1
+	`, userID, ms.NotifyCalls, ms.NotifyUsage)
+	return err
+}
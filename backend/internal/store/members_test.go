@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		role, min string
+		want      bool
+	}{
+		{RoleOwner, RoleOwner, true},
+		{RoleOwner, RoleViewer, true},
+		{RoleMember, RoleMember, true},
+		{RoleMember, RoleOwner, false},
+		{RoleViewer, RoleMember, false},
+		{RoleViewer, RoleViewer, true},
+		{"admin", RoleViewer, false},
+		{"", RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := RoleAtLeast(tt.role, tt.min); got != tt.want {
+			t.Errorf("RoleAtLeast(%q, %q) = %v, want %v", tt.role, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestTenantMembers(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Members Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	suffix := time.Now().Format("150405")
+	owner, _, err := s.FindOrCreateUser(ctx, "+420771"+suffix)
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	invitee, _, err := s.FindOrCreateUser(ctx, "+420772"+suffix)
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM users WHERE id IN ($1, $2)", owner.ID, invitee.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+	if err := s.AssignUserToTenant(ctx, owner.ID, tenant.ID); err != nil {
+		t.Fatalf("AssignUserToTenant failed: %v", err)
+	}
+
+	// Inviting twice renews the open invitation
+	if _, err := s.CreateInvitation(ctx, tenant.ID, invitee.Phone, RoleMember, &owner.ID); err != nil {
+		t.Fatalf("CreateInvitation failed: %v", err)
+	}
+	inv, err := s.CreateInvitation(ctx, tenant.ID, invitee.Phone, RoleViewer, &owner.ID)
+	if err != nil {
+		t.Fatalf("CreateInvitation failed: %v", err)
+	}
+	pending, err := s.ListPhoneInvitations(ctx, invitee.Phone)
+	if err != nil || len(pending) != 1 || pending[0].Role != RoleViewer || pending[0].TenantName != "Members Tenant" {
+		t.Fatalf("ListPhoneInvitations = %+v, %v", pending, err)
+	}
+
+	// Only the invited number can accept
+	if _, err := s.AcceptInvitation(ctx, inv.ID, owner.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("accepting someone else's invitation: err = %v, want ErrNoRows", err)
+	}
+	if _, err := s.AcceptInvitation(ctx, inv.ID, invitee.ID); err != nil {
+		t.Fatalf("AcceptInvitation failed: %v", err)
+	}
+	if _, err := s.AcceptInvitation(ctx, inv.ID, invitee.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("accepting twice: err = %v, want ErrNoRows", err)
+	}
+	u, err := s.GetUserByID(ctx, invitee.ID)
+	if err != nil || u.TenantID == nil || *u.TenantID != tenant.ID || u.Role != RoleViewer {
+		t.Fatalf("invitee after accepting = %+v, %v", u, err)
+	}
+
+	// The tenant must keep an owner
+	if _, err := s.UpdateMemberRole(ctx, tenant.ID, owner.ID, RoleMember); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("demoting the last owner: err = %v, want ErrLastOwner", err)
+	}
+	if _, err := s.RemoveMember(ctx, tenant.ID, owner.ID); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("removing the last owner: err = %v, want ErrLastOwner", err)
+	}
+	if prev, err := s.UpdateMemberRole(ctx, tenant.ID, invitee.ID, RoleOwner); err != nil || prev != RoleViewer {
+		t.Fatalf("UpdateMemberRole = %q, %v", prev, err)
+	}
+	if prev, err := s.UpdateMemberRole(ctx, tenant.ID, owner.ID, RoleMember); err != nil || prev != RoleOwner {
+		t.Errorf("demoting one of two owners = %q, %v", prev, err)
+	}
+
+	// Removed members keep their account
+	if prev, err := s.RemoveMember(ctx, tenant.ID, owner.ID); err != nil || prev != RoleMember {
+		t.Fatalf("RemoveMember = %q, %v", prev, err)
+	}
+	if _, err := s.RemoveMember(ctx, tenant.ID, owner.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("removing a non-member: err = %v, want ErrNoRows", err)
+	}
+	members, err := s.ListUsersByTenant(ctx, tenant.ID)
+	if err != nil || len(members) != 1 || members[0].ID != invitee.ID {
+		t.Errorf("ListUsersByTenant = %+v, %v", members, err)
+	}
+
+	// Revoked invitations can't be accepted
+	inv, err = s.CreateInvitation(ctx, tenant.ID, owner.Phone, RoleMember, &invitee.ID)
+	if err != nil {
+		t.Fatalf("CreateInvitation failed: %v", err)
+	}
+	if _, err := s.RevokeInvitation(ctx, tenant.ID, inv.ID); err != nil {
+		t.Fatalf("RevokeInvitation failed: %v", err)
+	}
+	if _, err := s.AcceptInvitation(ctx, inv.ID, owner.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("accepting a revoked invitation: err = %v, want ErrNoRows", err)
+	}
+}
+
+func TestMemberSettingsAndPushTokens(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Member Settings Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	user, _, err := s.FindOrCreateUser(ctx, "+420773"+time.Now().Format("150405"))
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+	if err := s.AssignUserToTenant(ctx, user.ID, tenant.ID); err != nil {
+		t.Fatalf("AssignUserToTenant failed: %v", err)
+	}
+	if err := s.RegisterPushToken(ctx, user.ID, "members-test-token-"+user.ID, "ios"); err != nil {
+		t.Fatalf("RegisterPushToken failed: %v", err)
+	}
+
+	ms, err := s.GetMemberSettings(ctx, user.ID)
+	if err != nil || ms.NotifyCalls != NotifyCallsAll || !ms.NotifyUsage {
+		t.Fatalf("default settings = %+v, %v", ms, err)
+	}
+	if err := s.SetMemberSettings(ctx, user.ID, MemberSettings{NotifyCalls: NotifyCallsLegitimate, NotifyUsage: false}); err != nil {
+		t.Fatalf("SetMemberSettings failed: %v", err)
+	}
+
+	if tokens, err := s.GetCallPushTokens(ctx, tenant.ID, false); err != nil || len(tokens) != 1 {
+		t.Errorf("GetCallPushTokens(legitimate) = %d tokens, %v", len(tokens), err)
+	}
+	if tokens, err := s.GetCallPushTokens(ctx, tenant.ID, true); err != nil || len(tokens) != 0 {
+		t.Errorf("GetCallPushTokens(spam) = %d tokens, %v", len(tokens), err)
+	}
+	if tokens, err := s.GetUsagePushTokens(ctx, tenant.ID); err != nil || len(tokens) != 0 {
+		t.Errorf("GetUsagePushTokens = %d tokens, %v", len(tokens), err)
+	}
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PromptVersion is a saved version of a tenant's system prompt.
+type PromptVersion struct {
+	Version      int       `json:"version"`
+	SystemPrompt string    `json:"system_prompt"`
+	AuthorUserID *string   `json:"author_user_id,omitempty"` // nil = system
+	AuthorName   *string   `json:"author_name,omitempty"`
+	Reason       string    `json:"reason"`
+	Current      bool      `json:"current"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SaveTenantPrompt stores prompt as the tenant's next prompt version and makes it
+// the active system prompt. The tenant row is locked so concurrent saves get
+// consecutive version numbers. Returns the new version number.
+func (s *Store) SaveTenantPrompt(ctx context.Context, tenantID, prompt string, authorUserID *string, reason string) (int, error) {
+	return s.UpdateTenantWithPrompt(ctx, tenantID, nil, &prompt, authorUserID, reason)
+}
+
+// UpdateTenantWithPrompt applies updates to the tenant's settings (see
+// UpdateTenant) and, if prompt is set, saves it as the next prompt version
+// (see SaveTenantPrompt), in one transaction. Returns the new version number,
+// or 0 if prompt is nil.
+func (s *Store) UpdateTenantWithPrompt(ctx context.Context, tenantID string, updates map[string]any, prompt *string, authorUserID *string, reason string) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if len(updates) > 0 {
+		if err := updateTenant(ctx, tx, tenantID, updates); err != nil {
+			return 0, err
+		}
+	}
+	version := 0
+	if prompt != nil {
+		if version, err = savePromptVersion(ctx, tx, tenantID, *prompt, authorUserID, reason); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// savePromptVersion saves prompt as the tenant's next version in tx.
+func savePromptVersion(ctx context.Context, tx pgx.Tx, tenantID, prompt string, authorUserID *string, reason string) (int, error) {
+	var lockedID string
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM tenants WHERE id = $1 FOR UPDATE
+	`, tenantID).Scan(&lockedID)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version), 0) + 1 FROM tenant_prompt_versions WHERE tenant_id = $1
+	`, tenantID).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO tenant_prompt_versions (tenant_id, version, system_prompt, author_user_id, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, tenantID, version, prompt, authorUserID, reason)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE tenants SET system_prompt = $2, prompt_version = $3 WHERE id = $1
+	`, tenantID, prompt, version)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// ListPromptVersions returns all prompt versions of a tenant, newest first.
+func (s *Store) ListPromptVersions(ctx context.Context, tenantID string) ([]PromptVersion, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT v.version, v.system_prompt, v.author_user_id, COALESCE(u.name, u.phone), v.reason,
+		       v.version = COALESCE(t.prompt_version, 0), v.created_at
+		FROM tenant_prompt_versions v
+		JOIN tenants t ON t.id = v.tenant_id
+		LEFT JOIN users u ON u.id = v.author_user_id
+		WHERE v.tenant_id = $1
+		ORDER BY v.version DESC
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []PromptVersion{}
+	for rows.Next() {
+		var v PromptVersion
+		if err := rows.Scan(&v.Version, &v.SystemPrompt, &v.AuthorUserID, &v.AuthorName, &v.Reason, &v.Current, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// GetPromptVersion returns a single prompt version of a tenant.
+// Returns pgx.ErrNoRows if the version does not exist.
+func (s *Store) GetPromptVersion(ctx context.Context, tenantID string, version int) (*PromptVersion, error) {
+	var v PromptVersion
+	err := s.db.QueryRow(ctx, `
+		SELECT v.version, v.system_prompt, v.author_user_id, COALESCE(u.name, u.phone), v.reason,
+		       v.version = COALESCE(t.prompt_version, 0), v.created_at
+		FROM tenant_prompt_versions v
+		JOIN tenants t ON t.id = v.tenant_id
+		LEFT JOIN users u ON u.id = v.author_user_id
+		WHERE v.tenant_id = $1 AND v.version = $2
+	`, tenantID, version).Scan(&v.Version, &v.SystemPrompt, &v.AuthorUserID, &v.AuthorName, &v.Reason, &v.Current, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -67,12 +67,32 @@ func (s *Store) GetUserPushTokens(ctx context.Context, userID string) ([]DeviceP
 
 // GetTenantPushTokens returns all push tokens for all users in a tenant
 func (s *Store) GetTenantPushTokens(ctx context.Context, tenantID string) ([]DevicePushToken, error) {
+	return s.queryTenantPushTokens(ctx, tenantID, "TRUE")
+}
+
+// GetCallPushTokens returns the push tokens of the tenant's members who want
+// to be notified about a call, following their notify_calls setting.
+func (s *Store) GetCallPushTokens(ctx context.Context, tenantID string, spam bool) ([]DevicePushToken, error) {
+	if spam {
+		return s.queryTenantPushTokens(ctx, tenantID, "u.notify_calls = 'all'")
+	}
+	return s.queryTenantPushTokens(ctx, tenantID, "u.notify_calls IN ('all', 'legitimate')")
+}
+
+// GetUsagePushTokens returns the push tokens of the tenant's members who want
+// usage limit warnings.
+func (s *Store) GetUsagePushTokens(ctx context.Context, tenantID string) ([]DevicePushToken, error) {
+	return s.queryTenantPushTokens(ctx, tenantID, "u.notify_usage")
+}
+
+// queryTenantPushTokens returns the push tokens of the tenant's users matching
+// the SQL condition on users u.
+func (s *Store) queryTenantPushTokens(ctx context.Context, tenantID, cond string) ([]DevicePushToken, error) {
 	rows, err := s.db.Query(ctx, `
 		SELECT dpt.id, dpt.user_id, dpt.token, dpt.platform, dpt.created_at
 		FROM device_push_tokens dpt
 		JOIN users u ON u.id = dpt.user_id
-		WHERE u.tenant_id = $1
-	`, tenantID)
+		WHERE u.tenant_id = $1 AND `+cond, tenantID)
 	if err != nil {
 		return nil, err
 	}
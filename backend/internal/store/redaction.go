@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetRedactionKinds returns the PII kinds the tenant redacts, or nil if the
+// tenant uses the defaults. An empty (non-nil) slice turns redaction off.
+func (s *Store) GetRedactionKinds(ctx context.Context, tenantID string) ([]string, error) {
+	var kinds []string
+	err := s.db.QueryRow(ctx, `
+		SELECT kinds FROM tenant_redaction_settings WHERE tenant_id = $1
+	`, tenantID).Scan(&kinds)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if kinds == nil {
+		kinds = []string{}
+	}
+	return kinds, nil
+}
+
+// SetRedactionKinds saves the PII kinds the tenant redacts.
+func (s *Store) SetRedactionKinds(ctx context.Context, tenantID string, kinds []string, updatedBy *string) error {
+	if kinds == nil {
+		kinds = []string{}
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO tenant_redaction_settings (tenant_id, kinds, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET kinds = EXCLUDED.kinds, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, tenantID, kinds, updatedBy)
+	return err
+}
+
+// DeleteRedactionKinds reverts the tenant to the default redaction kinds.
+func (s *Store) DeleteRedactionKinds(ctx context.Context, tenantID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM tenant_redaction_settings WHERE tenant_id = $1`, tenantID)
+	return err
+}
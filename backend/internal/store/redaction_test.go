@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRedactionKinds(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Redaction Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID) }()
+
+	kinds, err := s.GetRedactionKinds(ctx, tenant.ID)
+	if err != nil || kinds != nil {
+		t.Fatalf("expected defaults (nil), got %v, %v", kinds, err)
+	}
+
+	if err := s.SetRedactionKinds(ctx, tenant.ID, nil, nil); err != nil {
+		t.Fatalf("SetRedactionKinds failed: %v", err)
+	}
+	kinds, err = s.GetRedactionKinds(ctx, tenant.ID)
+	if err != nil || kinds == nil || len(kinds) != 0 {
+		t.Errorf("expected redaction off (empty), got %v, %v", kinds, err)
+	}
+
+	if err := s.SetRedactionKinds(ctx, tenant.ID, []string{"card", "email"}, nil); err != nil {
+		t.Fatalf("SetRedactionKinds failed: %v", err)
+	}
+	kinds, _ = s.GetRedactionKinds(ctx, tenant.ID)
+	if len(kinds) != 2 || kinds[0] != "card" || kinds[1] != "email" {
+		t.Errorf("unexpected kinds %v", kinds)
+	}
+
+	if err := s.DeleteRedactionKinds(ctx, tenant.ID); err != nil {
+		t.Fatalf("DeleteRedactionKinds failed: %v", err)
+	}
+	if kinds, _ = s.GetRedactionKinds(ctx, tenant.ID); kinds != nil {
+		t.Errorf("expected defaults after reset, got %v", kinds)
+	}
+}
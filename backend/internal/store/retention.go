@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RetentionPolicy is a tenant's retention override in days. A nil field uses
+// the global default; 0 keeps the data forever.
+type RetentionPolicy struct {
+	TranscriptDays *int `json:"transcript_days"`
+	EventDays      *int `json:"event_days"`
+	CallDays       *int `json:"call_days"`
+}
+
+// TenantRetentionPolicy is a tenant with its retention override.
+type TenantRetentionPolicy struct {
+	TenantID   string          `json:"tenant_id"`
+	TenantName string          `json:"tenant_name"`
+	Policy     RetentionPolicy `json:"policy"`
+}
+
+// GetRetentionPolicy returns the tenant's retention override (all nil if the
+// tenant uses the defaults).
+func (s *Store) GetRetentionPolicy(ctx context.Context, tenantID string) (RetentionPolicy, error) {
+	var p RetentionPolicy
+	err := s.db.QueryRow(ctx, `
+		SELECT transcript_days, event_days, call_days FROM tenant_retention_policies WHERE tenant_id = $1
+	`, tenantID).Scan(&p.TranscriptDays, &p.EventDays, &p.CallDays)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return RetentionPolicy{}, nil
+	}
+	return p, err
+}
+
+// SetRetentionPolicy saves the tenant's retention override.
+func (s *Store) SetRetentionPolicy(ctx context.Context, tenantID string, p RetentionPolicy, updatedBy *string) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO tenant_retention_policies (tenant_id, transcript_days, event_days, call_days, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			transcript_days = EXCLUDED.transcript_days,
+			event_days = EXCLUDED.event_days,
+			call_days = EXCLUDED.call_days,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`, tenantID, p.TranscriptDays, p.EventDays, p.CallDays, updatedBy)
+	return err
+}
+
+// ListRetentionPolicies returns every tenant with its retention override.
+func (s *Store) ListRetentionPolicies(ctx context.Context) ([]TenantRetentionPolicy, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT t.id, t.name, p.transcript_days, p.event_days, p.call_days
+		FROM tenants t
+		LEFT JOIN tenant_retention_policies p ON p.tenant_id = t.id
+		ORDER BY t.name, t.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []TenantRetentionPolicy{}
+	for rows.Next() {
+		var t TenantRetentionPolicy
+		if err := rows.Scan(&t.TenantID, &t.TenantName, &t.Policy.TranscriptDays, &t.Policy.EventDays, &t.Policy.CallDays); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// The purge methods below work on the calls of one tenant, or on calls
+// without a tenant if tenantID is nil. Each removes at most limit calls
+// (events: rows) and returns how many it removed, so callers can repeat
+// until a batch comes back short.
+
+// PurgeTranscripts removes the transcripts of calls started before the
+// cutoff: utterances, summary and collected call fields are deleted and the
+// screening intent text and entities cleared (labels are kept).
+func (s *Store) PurgeTranscripts(ctx context.Context, tenantID *string, before time.Time, limit int) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		WITH batch AS (
+			SELECT id FROM calls
+			WHERE tenant_id IS NOT DISTINCT FROM $1::uuid AND started_at < $2 AND transcript_purged_at IS NULL
+			ORDER BY started_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		),
+		utterances AS (DELETE FROM call_utterances WHERE call_id IN (SELECT id FROM batch)),
+		summaries AS (DELETE FROM call_summaries WHERE call_id IN (SELECT id FROM batch)),
+		field_values AS (DELETE FROM call_field_values WHERE call_id IN (SELECT id FROM batch)),
+		screening AS (
+			UPDATE call_screening_results SET intent_text = '', entities_json = '{}', intent_text_enc = NULL, entities_enc = NULL
+			WHERE call_id IN (SELECT id FROM batch)
+		)
+		UPDATE calls SET transcript_purged_at = NOW() WHERE id IN (SELECT id FROM batch)
+	`, tenantID, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeCallEvents deletes call events logged before the cutoff.
+func (s *Store) PurgeCallEvents(ctx context.Context, tenantID *string, before time.Time, limit int) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM call_events WHERE id IN (
+			SELECT e.id FROM call_events e
+			LEFT JOIN calls c ON c.id = e.call_id
+			WHERE c.tenant_id IS NOT DISTINCT FROM $1::uuid AND e.created_at < $2
+			LIMIT $3
+		)
+	`, tenantID, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeCalls deletes calls started before the cutoff, with everything that
+// references them.
+func (s *Store) PurgeCalls(ctx context.Context, tenantID *string, before time.Time, limit int) (int64, error) {
+	tag, err := s.db.Exec(ctx, `
+		DELETE FROM calls WHERE id IN (
+			SELECT id FROM calls
+			WHERE tenant_id IS NOT DISTINCT FROM $1::uuid AND started_at < $2
+			ORDER BY started_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+	`, tenantID, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RetentionCounts are the numbers of calls (events: rows) past a retention cutoff.
+type RetentionCounts struct {
+	Transcripts int64 `json:"transcripts"`
+	Events      int64 `json:"events"`
+	Calls       int64 `json:"calls"`
+}
+
+// CountRetentionBacklog counts what the purge methods would remove for the
+// cutoffs (nil = kept forever, counted as 0).
+func (s *Store) CountRetentionBacklog(ctx context.Context, tenantID *string, transcriptsBefore, eventsBefore, callsBefore *time.Time) (RetentionCounts, error) {
+	var c RetentionCounts
+	err := s.db.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM calls
+			 WHERE $2::timestamptz IS NOT NULL AND tenant_id IS NOT DISTINCT FROM $1::uuid
+			   AND started_at < $2 AND transcript_purged_at IS NULL),
+			(SELECT COUNT(*) FROM call_events e LEFT JOIN calls c ON c.id = e.call_id
+			 WHERE $3::timestamptz IS NOT NULL AND c.tenant_id IS NOT DISTINCT FROM $1::uuid AND e.created_at < $3),
+			(SELECT COUNT(*) FROM calls
+			 WHERE $4::timestamptz IS NOT NULL AND tenant_id IS NOT DISTINCT FROM $1::uuid AND started_at < $4)
+	`, tenantID, transcriptsBefore, eventsBefore, callsBefore).Scan(&c.Transcripts, &c.Events, &c.Calls)
+	return c, err
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Retention Policy Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID) }()
+
+	p, err := s.GetRetentionPolicy(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("GetRetentionPolicy failed: %v", err)
+	}
+	if p.TranscriptDays != nil || p.EventDays != nil || p.CallDays != nil {
+		t.Errorf("expected no overrides, got %+v", p)
+	}
+
+	days := 30
+	if err := s.SetRetentionPolicy(ctx, tenant.ID, RetentionPolicy{TranscriptDays: &days}, nil); err != nil {
+		t.Fatalf("SetRetentionPolicy failed: %v", err)
+	}
+	p, err = s.GetRetentionPolicy(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("GetRetentionPolicy failed: %v", err)
+	}
+	if p.TranscriptDays == nil || *p.TranscriptDays != 30 || p.CallDays != nil {
+		t.Errorf("unexpected policy %+v", p)
+	}
+}
+
+func TestPurgeRetention(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Retention Purge Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() {
+		_, _ = db.Exec(ctx, "DELETE FROM calls WHERE tenant_id = $1", tenant.ID)
+		_, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID)
+	}()
+
+	// An old call and a recent one, each with a transcript and an event
+	suffix := time.Now().Format("20060102150405")
+	callIDs := map[string]string{}
+	for name, startedAt := range map[string]time.Time{"old": time.Now().AddDate(0, 0, -60), "new": time.Now()} {
+		sid := fmt.Sprintf("CARET%s%s", name, suffix)
+		if err := s.UpsertCall(ctx, Call{
+			Provider: "twilio", ProviderCallID: sid, FromNumber: "+420777123456", ToNumber: "+420228883001",
+			Status: "completed", StartedAt: startedAt,
+		}); err != nil {
+			t.Fatalf("UpsertCall failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, "UPDATE calls SET tenant_id = $1 WHERE provider_call_id = $2", tenant.ID, sid); err != nil {
+			t.Fatalf("set tenant failed: %v", err)
+		}
+		id, err := s.GetCallID(ctx, sid)
+		if err != nil {
+			t.Fatalf("GetCallID failed: %v", err)
+		}
+		callIDs[name] = id
+		if err := s.InsertUtterance(ctx, id, Utterance{Speaker: "caller", Text: "Jmenuji se Jan Novák", Sequence: 1}); err != nil {
+			t.Fatalf("InsertUtterance failed: %v", err)
+		}
+		if err := s.InsertScreeningResult(ctx, id, ScreeningResult{
+			LegitimacyLabel: "legitimní", IntentText: "Volá Jan Novák", EntitiesJSON: json.RawMessage(`{"name":"Jan Novák"}`), CreatedAt: startedAt,
+		}); err != nil {
+			t.Fatalf("InsertScreeningResult failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, "INSERT INTO call_events (call_id, event_type, created_at) VALUES ($1, 'test', $2)", id, startedAt); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+	}
+
+	tenantID := tenant.ID
+	cutoff := time.Now().AddDate(0, 0, -30)
+
+	counts, err := s.CountRetentionBacklog(ctx, &tenantID, &cutoff, &cutoff, nil)
+	if err != nil {
+		t.Fatalf("CountRetentionBacklog failed: %v", err)
+	}
+	if counts != (RetentionCounts{Transcripts: 1, Events: 1, Calls: 0}) {
+		t.Errorf("backlog = %+v", counts)
+	}
+
+	if n, err := s.PurgeTranscripts(ctx, &tenantID, cutoff, 10); err != nil || n != 1 {
+		t.Fatalf("PurgeTranscripts = %d, %v", n, err)
+	}
+	if n, err := s.PurgeTranscripts(ctx, &tenantID, cutoff, 10); err != nil || n != 0 {
+		t.Errorf("second PurgeTranscripts = %d, %v; purged calls should be skipped", n, err)
+	}
+	if n, err := s.PurgeCallEvents(ctx, &tenantID, cutoff, 10); err != nil || n != 1 {
+		t.Fatalf("PurgeCallEvents = %d, %v", n, err)
+	}
+
+	var utterances int
+	var intentText, entities string
+	_ = db.QueryRow(ctx, "SELECT COUNT(*) FROM call_utterances WHERE call_id = $1", callIDs["old"]).Scan(&utterances)
+	_ = db.QueryRow(ctx, "SELECT intent_text, entities_json::text FROM call_screening_results WHERE call_id = $1", callIDs["old"]).Scan(&intentText, &entities)
+	if utterances != 0 || intentText != "" || entities != "{}" {
+		t.Errorf("old call not purged: %d utterances, intent %q, entities %s", utterances, intentText, entities)
+	}
+	_ = db.QueryRow(ctx, "SELECT COUNT(*) FROM call_utterances WHERE call_id = $1", callIDs["new"]).Scan(&utterances)
+	if utterances != 1 {
+		t.Errorf("recent call transcript should be kept, got %d utterances", utterances)
+	}
+
+	if n, err := s.PurgeCalls(ctx, &tenantID, cutoff, 10); err != nil || n != 1 {
+		t.Fatalf("PurgeCalls = %d, %v", n, err)
+	}
+	var remaining int
+	_ = db.QueryRow(ctx, "SELECT COUNT(*) FROM calls WHERE tenant_id = $1", tenant.ID).Scan(&remaining)
+	if remaining != 1 {
+		t.Errorf("expected 1 remaining call, got %d", remaining)
+	}
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+// GetScreeningTaxonomy returns the tenant's custom screening taxonomy, or nil
+// if the tenant uses the built-in labels.
+func (s *Store) GetScreeningTaxonomy(ctx context.Context, tenantID string) (*llm.Taxonomy, error) {
+	var raw []byte
+	err := s.db.QueryRow(ctx, `
+		SELECT taxonomy FROM tenant_screening_taxonomies WHERE tenant_id = $1
+	`, tenantID).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t llm.Taxonomy
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SetScreeningTaxonomy saves the tenant's custom screening taxonomy.
+func (s *Store) SetScreeningTaxonomy(ctx context.Context, tenantID string, t llm.Taxonomy, updatedBy *string) error {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO tenant_screening_taxonomies (tenant_id, taxonomy, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET taxonomy = EXCLUDED.taxonomy, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`, tenantID, raw, updatedBy)
+	return err
+}
+
+// DeleteScreeningTaxonomy reverts the tenant to the built-in labels.
+func (s *Store) DeleteScreeningTaxonomy(ctx context.Context, tenantID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM tenant_screening_taxonomies WHERE tenant_id = $1`, tenantID)
+	return err
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lukasbauer/karen/internal/llm"
+)
+
+func TestScreeningTaxonomy(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	tenant, err := s.CreateTenant(ctx, "Taxonomy Tenant", "prompt", "")
+	if err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM tenants WHERE id = $1", tenant.ID) }()
+
+	if got, err := s.GetScreeningTaxonomy(ctx, tenant.ID); err != nil || got != nil {
+		t.Fatalf("GetScreeningTaxonomy = %v, %v; want nil for built-in labels", got, err)
+	}
+
+	custom := llm.Taxonomy{
+		Legitimacy: []llm.Label{{Name: "pacient", Description: "Pacient"}, {Name: "obchodník", Spam: true}},
+		Lead:       []llm.Label{{Name: "akutní"}, {Name: "ostatní", Default: true}},
+		Intent:     []llm.Label{{Name: "prohlídka"}},
+	}
+	if err := s.SetScreeningTaxonomy(ctx, tenant.ID, custom, nil); err != nil {
+		t.Fatalf("SetScreeningTaxonomy failed: %v", err)
+	}
+	custom.Intent = append(custom.Intent, llm.Label{Name: "ošetření"})
+	if err := s.SetScreeningTaxonomy(ctx, tenant.ID, custom, nil); err != nil {
+		t.Fatalf("SetScreeningTaxonomy (update) failed: %v", err)
+	}
+
+	got, err := s.GetScreeningTaxonomy(ctx, tenant.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetScreeningTaxonomy = %v, %v", got, err)
+	}
+	if len(got.Intent) != 2 || !got.Legitimacy[1].Spam || !got.Lead[1].Default {
+		t.Errorf("unexpected taxonomy %+v", got)
+	}
+
+	if err := s.DeleteScreeningTaxonomy(ctx, tenant.ID); err != nil {
+		t.Fatalf("DeleteScreeningTaxonomy failed: %v", err)
+	}
+	if got, _ := s.GetScreeningTaxonomy(ctx, tenant.ID); got != nil {
+		t.Error("expected built-in labels after reset")
+	}
+}
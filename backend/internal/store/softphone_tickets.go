@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// CreateSoftphoneTicket stores the hash of a single-use softphone ticket for
+// the user, and drops expired tickets.
+func (s *Store) CreateSoftphoneTicket(ctx context.Context, userID, ticketHash string, expiresAt time.Time) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM softphone_tickets WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO softphone_tickets (ticket_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)
+	`, ticketHash, userID, expiresAt)
+	return err
+}
+
+// RedeemSoftphoneTicket deletes the ticket and returns its user. Returns
+// pgx.ErrNoRows if the ticket doesn't exist, was already used or expired.
+func (s *Store) RedeemSoftphoneTicket(ctx context.Context, ticketHash string) (*User, error) {
+	var u User
+	err := s.db.QueryRow(ctx, `
+		WITH t AS (
+			DELETE FROM softphone_tickets WHERE ticket_hash = $1
+			RETURNING user_id, expires_at
+		)
+		SELECT u.id, u.tenant_id, u.phone, u.phone_verified, u.name, u.role, u.last_login_at, u.created_at, u.updated_at
+		FROM t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.expires_at > NOW()
+	`, ticketHash).Scan(
+		&u.ID, &u.TenantID, &u.Phone, &u.PhoneVerified, &u.Name, &u.Role,
+		&u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestSoftphoneTickets(t *testing.T) {
+	db := getTestDB(t)
+	defer db.Close()
+
+	s := New(db)
+	ctx := context.Background()
+
+	user, _, err := s.FindOrCreateUser(ctx, "+420776"+time.Now().Format("150405"))
+	if err != nil {
+		t.Fatalf("FindOrCreateUser failed: %v", err)
+	}
+	defer func() { _, _ = db.Exec(ctx, "DELETE FROM users WHERE id = $1", user.ID) }()
+
+	suffix := time.Now().Format("20060102150405")
+	if err := s.CreateSoftphoneTicket(ctx, user.ID, "ticket-"+suffix, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("CreateSoftphoneTicket failed: %v", err)
+	}
+	got, err := s.RedeemSoftphoneTicket(ctx, "ticket-"+suffix)
+	if err != nil || got.ID != user.ID {
+		t.Fatalf("RedeemSoftphoneTicket = %+v, %v; want user %s", got, err, user.ID)
+	}
+
+	// Single use
+	if _, err := s.RedeemSoftphoneTicket(ctx, "ticket-"+suffix); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second redeem: err = %v, want ErrNoRows", err)
+	}
+
+	// Expired tickets are rejected
+	if err := s.CreateSoftphoneTicket(ctx, user.ID, "expired-"+suffix, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("CreateSoftphoneTicket failed: %v", err)
+	}
+	if _, err := s.RedeemSoftphoneTicket(ctx, "expired-"+suffix); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expired redeem: err = %v, want ErrNoRows", err)
+	}
+}
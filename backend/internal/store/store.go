@@ -9,12 +9,16 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/lukasbauer/karen/internal/costs"
+	"github.com/lukasbauer/karen/internal/slots"
 )
 
 type Store struct {
-	db *pgxpool.Pool
+	db       *pgxpool.Pool
+	crypt    *dataKeys // nil = transcripts stored in plaintext
+	phoneKey []byte    // HMAC key of phone number hashes (SetPhoneHashKey)
 }
 
 func New(db *pgxpool.Pool) *Store {
@@ -48,6 +52,8 @@ type Tenant struct {
 	// Billing fields
 	TrialEndsAt        *time.Time `json:"trial_ends_at,omitempty"`
 	CurrentPeriodCalls int        `json:"current_period_calls"`
+	// Version of system_prompt in tenant_prompt_versions (0 = not versioned yet)
+	PromptVersion int `json:"prompt_version"`
 }
 
 // User represents an authenticated user
@@ -99,8 +105,17 @@ type Call struct {
 	FirstViewedAt   *time.Time `json:"first_viewed_at,omitempty"`
 	ResolvedAt      *time.Time `json:"resolved_at,omitempty"`
 	ResolvedBy      *string    `json:"resolved_by,omitempty"`
+	PromptVersion   *int       `json:"prompt_version,omitempty"` // Tenant prompt version the call was answered with
+	// A/B experiment the call was assigned to
+	ExperimentID      *string  `json:"experiment_id,omitempty"`
+	ExperimentVariant *string  `json:"experiment_variant,omitempty"`
+	Tags              []string `json:"tags,omitempty"` // e.g. "test" for browser softphone calls
 }
 
+// CallTagTest marks test calls (browser softphone), which are excluded from
+// tenant usage and cost summaries.
+const CallTagTest = "test"
+
 type ScreeningResult struct {
 	LegitimacyLabel      string          `json:"legitimacy_label"`
 	LegitimacyConfidence float64         `json:"legitimacy_confidence"`
@@ -113,7 +128,8 @@ type ScreeningResult struct {
 
 type CallListItem struct {
 	Call
-	Screening *ScreeningResult `json:"screening,omitempty"`
+	Screening *ScreeningResult  `json:"screening,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"` // Collected call field values (canonical text) by key
 }
 
 type Utterance struct {
@@ -130,6 +146,8 @@ type CallDetail struct {
 	Call
 	Screening  *ScreeningResult `json:"screening,omitempty"`
 	Utterances []Utterance      `json:"utterances"`
+	Fields     []slots.Value    `json:"fields,omitempty"`  // Collected call field values
+	Summary    *CallSummary     `json:"summary,omitempty"` // Post-call summary
 }
 
 func (s *Store) UpsertCall(ctx context.Context, c Call) error {
@@ -169,64 +187,17 @@ func (s *Store) UpdateCallEndedBy(ctx context.Context, providerCallID string, en
 }
 
 func (s *Store) ListCalls(ctx context.Context, limit int) ([]CallListItem, error) {
-	rows, err := s.db.Query(ctx, `
-		SELECT c.provider, c.provider_call_id, c.from_number, c.to_number, c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
-		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at
-		FROM calls c
-		LEFT JOIN call_screening_results r ON r.call_id = c.id
-		ORDER BY c.started_at DESC
-		LIMIT $1
-	`, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var out []CallListItem
-	for rows.Next() {
-		var item CallListItem
-		var legitimacyLabel *string
-		var legitimacyConfidence *float64
-		var leadLabel *string
-		var intentCategory *string
-		var intentText *string
-		var entities []byte
-		var screeningCreatedAt *time.Time
-
-		err := rows.Scan(
-			&item.Provider, &item.ProviderCallID, &item.FromNumber, &item.ToNumber, &item.Status, &item.RejectionReason, &item.StartedAt, &item.EndedAt, &item.EndedBy,
-			&legitimacyLabel, &legitimacyConfidence, &leadLabel, &intentCategory, &intentText, &entities, &screeningCreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if screeningCreatedAt != nil && legitimacyLabel != nil && legitimacyConfidence != nil && intentCategory != nil && intentText != nil {
-			sr := ScreeningResult{
-				LegitimacyLabel:      *legitimacyLabel,
-				LegitimacyConfidence: *legitimacyConfidence,
-				LeadLabel:            stringOrDefault(leadLabel, "nezjisteno"),
-				IntentCategory:       *intentCategory,
-				IntentText:           *intentText,
-				CreatedAt:            *screeningCreatedAt,
-			}
-			if len(entities) > 0 {
-				sr.EntitiesJSON = json.RawMessage(entities)
-			} else {
-				sr.EntitiesJSON = json.RawMessage(`{}`)
-			}
-			item.Screening = &sr
-		}
-		out = append(out, item)
-	}
-	return out, rows.Err()
+	return s.ListCallsFiltered(ctx, "", time.Time{}, limit)
 }
 
 // ListCallsFiltered returns calls with optional tenant_id and since filters.
 func (s *Store) ListCallsFiltered(ctx context.Context, tenantID string, since time.Time, limit int) ([]CallListItem, error) {
 	query := `
 		SELECT c.provider, c.provider_call_id, c.from_number, c.to_number, c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
-		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at
+		       c.first_viewed_at, c.resolved_at, c.resolved_by, c.tags,
+		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at,
+		       r.intent_text_enc, r.entities_enc,
+		       ` + callFieldColumns + `
 		FROM calls c
 		LEFT JOIN call_screening_results r ON r.call_id = c.id
 		WHERE 1=1`
@@ -254,45 +225,7 @@ func (s *Store) ListCallsFiltered(ctx context.Context, tenantID string, since ti
 		return nil, err
 	}
 	defer rows.Close()
-
-	var out []CallListItem
-	for rows.Next() {
-		var item CallListItem
-		var legitimacyLabel *string
-		var legitimacyConfidence *float64
-		var leadLabel *string
-		var intentCategory *string
-		var intentText *string
-		var entities []byte
-		var screeningCreatedAt *time.Time
-
-		err := rows.Scan(
-			&item.Provider, &item.ProviderCallID, &item.FromNumber, &item.ToNumber, &item.Status, &item.RejectionReason, &item.StartedAt, &item.EndedAt, &item.EndedBy,
-			&legitimacyLabel, &legitimacyConfidence, &leadLabel, &intentCategory, &intentText, &entities, &screeningCreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		if screeningCreatedAt != nil && legitimacyLabel != nil && legitimacyConfidence != nil && intentCategory != nil && intentText != nil {
-			sr := ScreeningResult{
-				LegitimacyLabel:      *legitimacyLabel,
-				LegitimacyConfidence: *legitimacyConfidence,
-				LeadLabel:            stringOrDefault(leadLabel, "nezjisteno"),
-				IntentCategory:       *intentCategory,
-				IntentText:           *intentText,
-				CreatedAt:            *screeningCreatedAt,
-			}
-			if len(entities) > 0 {
-				sr.EntitiesJSON = json.RawMessage(entities)
-			} else {
-				sr.EntitiesJSON = json.RawMessage(`{}`)
-			}
-			item.Screening = &sr
-		}
-		out = append(out, item)
-	}
-	return out, rows.Err()
+	return s.scanCallListItems(ctx, rows)
 }
 
 // GetCallID retrieves the internal call ID for a provider call ID.
@@ -306,18 +239,43 @@ func (s *Store) GetCallID(ctx context.Context, providerCallID string) (string, e
 
 // InsertUtterance inserts a new utterance for a call.
 func (s *Store) InsertUtterance(ctx context.Context, callID string, u Utterance) error {
+	text := u.Text
+	var textEnc []byte
+	if s.crypt != nil {
+		tenantID, err := s.callTenantID(ctx, callID)
+		if err != nil {
+			return err
+		}
+		if textEnc, err = s.seal(ctx, tenantID, []byte(u.Text)); err != nil {
+			return err
+		}
+		if textEnc != nil {
+			text = ""
+		}
+	}
 	_, err := s.db.Exec(ctx, `
-		INSERT INTO call_utterances (id, call_id, speaker, text, sequence, started_at, ended_at, stt_confidence, interrupted)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8)
-	`, callID, u.Speaker, u.Text, u.Sequence, u.StartedAt, u.EndedAt, u.STTConfidence, u.Interrupted)
+		INSERT INTO call_utterances (id, call_id, speaker, text, text_enc, sequence, started_at, ended_at, stt_confidence, interrupted)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, callID, u.Speaker, text, textEnc, u.Sequence, u.StartedAt, u.EndedAt, u.STTConfidence, u.Interrupted)
 	return err
 }
 
 // InsertScreeningResult inserts a screening result for a call.
 func (s *Store) InsertScreeningResult(ctx context.Context, callID string, sr ScreeningResult) error {
+	sealed := sealedScreening{intentText: sr.IntentText, entities: sr.EntitiesJSON}
+	if s.crypt != nil {
+		tenantID, err := s.callTenantID(ctx, callID)
+		if err != nil {
+			return err
+		}
+		if sealed, err = s.sealScreening(ctx, tenantID, sr); err != nil {
+			return err
+		}
+	}
 	_, err := s.db.Exec(ctx, `
-		INSERT INTO call_screening_results (call_id, legitimacy_label, legitimacy_confidence, lead_label, intent_category, intent_text, entities_json, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO call_screening_results (call_id, legitimacy_label, legitimacy_confidence, lead_label, intent_category, intent_text, entities_json,
+		                                    intent_text_enc, entities_enc, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (call_id) DO UPDATE SET
 			legitimacy_label = EXCLUDED.legitimacy_label,
 			legitimacy_confidence = EXCLUDED.legitimacy_confidence,
@@ -325,8 +283,11 @@ func (s *Store) InsertScreeningResult(ctx context.Context, callID string, sr Scr
 			intent_category = EXCLUDED.intent_category,
 			intent_text = EXCLUDED.intent_text,
 			entities_json = EXCLUDED.entities_json,
+			intent_text_enc = EXCLUDED.intent_text_enc,
+			entities_enc = EXCLUDED.entities_enc,
 			created_at = EXCLUDED.created_at
-	`, callID, sr.LegitimacyLabel, sr.LegitimacyConfidence, sr.LeadLabel, sr.IntentCategory, sr.IntentText, sr.EntitiesJSON, sr.CreatedAt)
+	`, callID, sr.LegitimacyLabel, sr.LegitimacyConfidence, sr.LeadLabel, sr.IntentCategory, sealed.intentText, sealed.entities,
+		sealed.intentEnc, sealed.entitiesEnc, sr.CreatedAt)
 	return err
 }
 
@@ -343,11 +304,11 @@ func (s *Store) GetCallDetailWithTenantCheck(ctx context.Context, providerCallID
 	var callID string
 	err := s.db.QueryRow(ctx, `
 		SELECT id, tenant_id, provider, provider_call_id, from_number, to_number, status, rejection_reason, started_at, ended_at, ended_by,
-		       first_viewed_at, resolved_at, resolved_by
+		       first_viewed_at, resolved_at, resolved_by, prompt_version, experiment_id, experiment_variant, tags
 		FROM calls
 		WHERE provider='twilio' AND provider_call_id=$1
 	`, providerCallID).Scan(&callID, &tenantID, &out.Provider, &out.ProviderCallID, &out.FromNumber, &out.ToNumber, &out.Status, &out.RejectionReason, &out.StartedAt, &out.EndedAt, &out.EndedBy,
-		&out.FirstViewedAt, &out.ResolvedAt, &out.ResolvedBy)
+		&out.FirstViewedAt, &out.ResolvedAt, &out.ResolvedBy, &out.PromptVersion, &out.ExperimentID, &out.ExperimentVariant, &out.Tags)
 	if err != nil {
 		return CallDetail{}, nil, err
 	}
@@ -357,21 +318,36 @@ func (s *Store) GetCallDetailWithTenantCheck(ctx context.Context, providerCallID
 	// Screening result (optional)
 	{
 		var sr ScreeningResult
-		var entities []byte
+		var entities, intentEnc, entitiesEnc []byte
 		err := s.db.QueryRow(ctx, `
-			SELECT legitimacy_label, legitimacy_confidence, lead_label, intent_category, intent_text, entities_json, created_at
+			SELECT legitimacy_label, legitimacy_confidence, lead_label, intent_category, intent_text, entities_json, created_at,
+			       intent_text_enc, entities_enc
 			FROM call_screening_results
 			WHERE call_id=$1
-		`, callID).Scan(&sr.LegitimacyLabel, &sr.LegitimacyConfidence, &sr.LeadLabel, &sr.IntentCategory, &sr.IntentText, &entities, &sr.CreatedAt)
+		`, callID).Scan(&sr.LegitimacyLabel, &sr.LegitimacyConfidence, &sr.LeadLabel, &sr.IntentCategory, &sr.IntentText, &entities, &sr.CreatedAt,
+			&intentEnc, &entitiesEnc)
 		if err == nil {
 			sr.EntitiesJSON = json.RawMessage(entities)
+			if err := s.openScreening(ctx, &sr, intentEnc, entitiesEnc); err != nil {
+				return CallDetail{}, nil, err
+			}
 			out.Screening = &sr
 		}
 	}
 
+	// Collected call field values (optional)
+	if values, err := s.listCallFieldValues(ctx, callID); err == nil {
+		out.Fields = values
+	}
+
+	// Post-call summary (optional)
+	if summary, err := s.GetCallSummary(ctx, callID); err == nil {
+		out.Summary = summary
+	}
+
 	// Utterances (optional)
 	rows, err := s.db.Query(ctx, `
-		SELECT speaker, text, sequence, started_at, ended_at, stt_confidence, interrupted
+		SELECT speaker, text, text_enc, sequence, started_at, ended_at, stt_confidence, interrupted
 		FROM call_utterances
 		WHERE call_id=$1
 		ORDER BY sequence ASC
@@ -383,9 +359,13 @@ func (s *Store) GetCallDetailWithTenantCheck(ctx context.Context, providerCallID
 
 	for rows.Next() {
 		var u Utterance
-		if err := rows.Scan(&u.Speaker, &u.Text, &u.Sequence, &u.StartedAt, &u.EndedAt, &u.STTConfidence, &u.Interrupted); err != nil {
+		var textEnc []byte
+		if err := rows.Scan(&u.Speaker, &u.Text, &textEnc, &u.Sequence, &u.StartedAt, &u.EndedAt, &u.STTConfidence, &u.Interrupted); err != nil {
 			return out, tenantID, nil
 		}
+		if u.Text, err = s.openText(ctx, u.Text, textEnc); err != nil {
+			return CallDetail{}, nil, err
+		}
 		out.Utterances = append(out.Utterances, u)
 	}
 
@@ -403,7 +383,7 @@ func (s *Store) GetTenantByTwilioNumber(ctx context.Context, twilioNumber string
 		SELECT t.id, t.name, t.system_prompt, t.greeting_text, t.voice_id, t.language,
 		       t.vip_names, t.marketing_email, t.forward_number, t.max_turn_timeout_ms,
 		       t.plan, t.status, t.created_at, t.updated_at,
-		       t.trial_ends_at, COALESCE(t.current_period_calls, 0)
+		       t.trial_ends_at, COALESCE(t.current_period_calls, 0), COALESCE(t.prompt_version, 0)
 		FROM tenants t
 		JOIN tenant_phone_numbers pn ON pn.tenant_id = t.id
 		WHERE pn.twilio_number = $1 AND t.status = 'active'
@@ -411,7 +391,7 @@ func (s *Store) GetTenantByTwilioNumber(ctx context.Context, twilioNumber string
 		&t.ID, &t.Name, &t.SystemPrompt, &t.GreetingText, &t.VoiceID, &t.Language,
 		&t.VIPNames, &t.MarketingEmail, &t.ForwardNumber, &t.MaxTurnTimeoutMs,
 		&t.Plan, &t.Status, &t.CreatedAt, &t.UpdatedAt,
-		&t.TrialEndsAt, &t.CurrentPeriodCalls,
+		&t.TrialEndsAt, &t.CurrentPeriodCalls, &t.PromptVersion,
 	)
 	if err != nil {
 		return nil, err
@@ -426,7 +406,7 @@ func (s *Store) GetTenantByForwardingSource(ctx context.Context, forwardingSourc
 		SELECT t.id, t.name, t.system_prompt, t.greeting_text, t.voice_id, t.language,
 		       t.vip_names, t.marketing_email, t.forward_number, t.max_turn_timeout_ms,
 		       t.plan, t.status, t.created_at, t.updated_at,
-		       t.trial_ends_at, COALESCE(t.current_period_calls, 0)
+		       t.trial_ends_at, COALESCE(t.current_period_calls, 0), COALESCE(t.prompt_version, 0)
 		FROM tenants t
 		JOIN tenant_phone_numbers pn ON pn.tenant_id = t.id
 		WHERE pn.forwarding_source = $1 AND t.status = 'active'
@@ -434,7 +414,7 @@ func (s *Store) GetTenantByForwardingSource(ctx context.Context, forwardingSourc
 		&t.ID, &t.Name, &t.SystemPrompt, &t.GreetingText, &t.VoiceID, &t.Language,
 		&t.VIPNames, &t.MarketingEmail, &t.ForwardNumber, &t.MaxTurnTimeoutMs,
 		&t.Plan, &t.Status, &t.CreatedAt, &t.UpdatedAt,
-		&t.TrialEndsAt, &t.CurrentPeriodCalls,
+		&t.TrialEndsAt, &t.CurrentPeriodCalls, &t.PromptVersion,
 	)
 	if err != nil {
 		return nil, err
@@ -449,14 +429,14 @@ func (s *Store) GetTenantByID(ctx context.Context, id string) (*Tenant, error) {
 		SELECT id, name, system_prompt, greeting_text, voice_id, language,
 		       vip_names, marketing_email, forward_number, max_turn_timeout_ms,
 		       plan, status, created_at, updated_at,
-		       trial_ends_at, COALESCE(current_period_calls, 0)
+		       trial_ends_at, COALESCE(current_period_calls, 0), COALESCE(prompt_version, 0)
 		FROM tenants
 		WHERE id = $1
 	`, id).Scan(
 		&t.ID, &t.Name, &t.SystemPrompt, &t.GreetingText, &t.VoiceID, &t.Language,
 		&t.VIPNames, &t.MarketingEmail, &t.ForwardNumber, &t.MaxTurnTimeoutMs,
 		&t.Plan, &t.Status, &t.CreatedAt, &t.UpdatedAt,
-		&t.TrialEndsAt, &t.CurrentPeriodCalls,
+		&t.TrialEndsAt, &t.CurrentPeriodCalls, &t.PromptVersion,
 	)
 	if err != nil {
 		return nil, err
@@ -474,11 +454,13 @@ func (s *Store) CreateTenant(ctx context.Context, name, systemPrompt, greetingTe
 		VALUES ($1, $2, $3, $4)
 		RETURNING id, name, system_prompt, greeting_text, voice_id, language,
 		          vip_names, marketing_email, forward_number, max_turn_timeout_ms,
-		          plan, status, created_at, updated_at, trial_ends_at, COALESCE(current_period_calls, 0)
+		          plan, status, created_at, updated_at, trial_ends_at, COALESCE(current_period_calls, 0),
+		          COALESCE(prompt_version, 0)
 	`, name, systemPrompt, greetingText, trialEndsAt).Scan(
 		&t.ID, &t.Name, &t.SystemPrompt, &t.GreetingText, &t.VoiceID, &t.Language,
 		&t.VIPNames, &t.MarketingEmail, &t.ForwardNumber, &t.MaxTurnTimeoutMs,
 		&t.Plan, &t.Status, &t.CreatedAt, &t.UpdatedAt, &t.TrialEndsAt, &t.CurrentPeriodCalls,
+		&t.PromptVersion,
 	)
 	if err != nil {
 		return nil, err
@@ -488,9 +470,17 @@ func (s *Store) CreateTenant(ctx context.Context, name, systemPrompt, greetingTe
 
 // UpdateTenant updates a tenant's settings.
 func (s *Store) UpdateTenant(ctx context.Context, id string, updates map[string]any) error {
+	return updateTenant(ctx, s.db, id, updates)
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func updateTenant(ctx context.Context, db execer, id string, updates map[string]any) error {
 	// Build dynamic UPDATE query
 	// Note: In production, use a proper query builder
-	_, err := s.db.Exec(ctx, `
+	_, err := db.Exec(ctx, `
 		UPDATE tenants
 		SET name = COALESCE($2, name),
 		    system_prompt = COALESCE($3, system_prompt),
@@ -593,10 +583,10 @@ func (s *Store) FindOrCreateUser(ctx context.Context, phone string) (*User, bool
 	return &u, isNew, nil
 }
 
-// AssignUserToTenant assigns a user to a tenant.
+// AssignUserToTenant assigns a user to a tenant as its owner.
 func (s *Store) AssignUserToTenant(ctx context.Context, userID, tenantID string) error {
 	_, err := s.db.Exec(ctx, `
-		UPDATE users SET tenant_id = $2 WHERE id = $1
+		UPDATE users SET tenant_id = $2, role = 'owner' WHERE id = $1
 	`, userID, tenantID)
 	return err
 }
@@ -619,17 +609,19 @@ func (s *Store) UpdateUserName(ctx context.Context, userID, name string) error {
 }
 
 // GetTenantOwnerPhone retrieves the phone number of the tenant owner (for call forwarding).
+// With several owners, the first one to join is used.
 func (s *Store) GetTenantOwnerPhone(ctx context.Context, tenantID string) (string, error) {
 	var phone string
 	err := s.db.QueryRow(ctx, `
-		SELECT phone FROM users WHERE tenant_id = $1 AND role = 'owner' LIMIT 1
+		SELECT phone FROM users WHERE tenant_id = $1 AND role = 'owner' ORDER BY created_at LIMIT 1
 	`, tenantID).Scan(&phone)
 	return phone, err
 }
 
 // ResetUserOnboarding performs a "smart reset" of a user's onboarding status.
 // It clears the user's tenant_id and name (allowing re-onboarding) and releases
-// any phone numbers associated with that tenant back to the pool.
+// any phone numbers associated with that tenant back to the pool, unless other
+// members remain in the tenant.
 // The tenant itself is preserved for call history purposes.
 // Returns the user's previous tenant_id (if any) for logging purposes.
 func (s *Store) ResetUserOnboarding(ctx context.Context, userID string) (*string, error) {
@@ -648,7 +640,8 @@ func (s *Store) ResetUserOnboarding(ctx context.Context, userID string) (*string
 			UPDATE tenant_phone_numbers
 			SET tenant_id = NULL, is_primary = false
 			WHERE tenant_id = $1
-		`, *previousTenantID)
+			  AND NOT EXISTS (SELECT 1 FROM users WHERE tenant_id = $1 AND id <> $2)
+		`, *previousTenantID, userID)
 		if err != nil {
 			return previousTenantID, err
 		}
@@ -656,7 +649,7 @@ func (s *Store) ResetUserOnboarding(ctx context.Context, userID string) (*string
 
 	// Clear the user's tenant_id and name (this makes them "not onboarded")
 	_, err = s.db.Exec(ctx, `
-		UPDATE users SET tenant_id = NULL, name = NULL WHERE id = $1
+		UPDATE users SET tenant_id = NULL, name = NULL, role = 'owner' WHERE id = $1
 	`, userID)
 	if err != nil {
 		return previousTenantID, err
@@ -764,6 +757,25 @@ func (s *Store) IsSessionValid(ctx context.Context, tokenHash string) (bool, err
 	return valid, err
 }
 
+// GetSessionUser returns the current data of the user a valid (not revoked,
+// not expired) session belongs to, or pgx.ErrNoRows.
+func (s *Store) GetSessionUser(ctx context.Context, tokenHash string) (*User, error) {
+	var u User
+	err := s.db.QueryRow(ctx, `
+		SELECT u.id, u.tenant_id, u.phone, u.phone_verified, u.name, u.role, u.last_login_at, u.created_at, u.updated_at
+		FROM user_sessions us
+		JOIN users u ON u.id = us.user_id
+		WHERE us.token_hash = $1 AND us.revoked_at IS NULL AND us.expires_at > NOW()
+	`, tokenHash).Scan(
+		&u.ID, &u.TenantID, &u.Phone, &u.PhoneVerified, &u.Name, &u.Role,
+		&u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 // ============================================================================
 // Call operations (tenant-aware)
 // ============================================================================
@@ -771,81 +783,97 @@ func (s *Store) IsSessionValid(ctx context.Context, tokenHash string) (bool, err
 // UpsertCallWithTenant creates or updates a call record with tenant ID.
 func (s *Store) UpsertCallWithTenant(ctx context.Context, c Call) error {
 	_, err := s.db.Exec(ctx, `
-		INSERT INTO calls (id, tenant_id, provider, provider_call_id, from_number, to_number, status, rejection_reason, started_at)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO calls (id, tenant_id, provider, provider_call_id, from_number, to_number, status, rejection_reason, started_at, prompt_version, tags)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10::text[], '{}'))
 		ON CONFLICT (provider, provider_call_id) DO UPDATE SET
 			tenant_id = COALESCE(EXCLUDED.tenant_id, calls.tenant_id),
 			from_number = EXCLUDED.from_number,
 			to_number = EXCLUDED.to_number,
 			status = EXCLUDED.status,
-			rejection_reason = EXCLUDED.rejection_reason
-	`, c.TenantID, c.Provider, c.ProviderCallID, c.FromNumber, c.ToNumber, c.Status, c.RejectionReason, c.StartedAt)
+			rejection_reason = EXCLUDED.rejection_reason,
+			prompt_version = COALESCE(EXCLUDED.prompt_version, calls.prompt_version),
+			tags = CASE WHEN cardinality(EXCLUDED.tags) > 0 THEN EXCLUDED.tags ELSE calls.tags END
+	`, c.TenantID, c.Provider, c.ProviderCallID, c.FromNumber, c.ToNumber, c.Status, c.RejectionReason, c.StartedAt, c.PromptVersion, c.Tags)
 	return err
 }
 
 // ListCallsByTenant lists calls for a specific tenant.
 func (s *Store) ListCallsByTenant(ctx context.Context, tenantID string, limit int) ([]CallListItem, error) {
-	rows, err := s.db.Query(ctx, `
-		SELECT c.provider, c.provider_call_id, c.from_number, c.to_number, c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
-		       c.first_viewed_at, c.resolved_at, c.resolved_by,
-		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at
-		FROM calls c
-		LEFT JOIN call_screening_results r ON r.call_id = c.id
-		WHERE c.tenant_id = $1
-		ORDER BY c.started_at DESC
-		LIMIT $2
-	`, tenantID, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	return s.ListCallsByTenantFiltered(ctx, tenantID, nil, limit)
+}
 
-	return scanCallListItems(rows)
+// ListCallsByTenantFiltered lists calls for a tenant that match all the call
+// field filters.
+func (s *Store) ListCallsByTenantFiltered(ctx context.Context, tenantID string, filters []CallFieldFilter, limit int) ([]CallListItem, error) {
+	page, err := s.ListCallsPage(ctx, tenantID, CallFilter{Fields: filters}, "", limit)
+	return page.Calls, err
 }
 
 // scanCallListItems is a helper to scan call list rows.
-func scanCallListItems(rows pgx.Rows) ([]CallListItem, error) {
+func (s *Store) scanCallListItems(ctx context.Context, rows pgx.Rows) ([]CallListItem, error) {
 	out := []CallListItem{}
 	for rows.Next() {
-		var item CallListItem
-		var legitimacyLabel *string
-		var legitimacyConfidence *float64
-		var leadLabel *string
-		var intentCategory *string
-		var intentText *string
-		var entities []byte
-		var screeningCreatedAt *time.Time
-
-		err := rows.Scan(
-			&item.Provider, &item.ProviderCallID, &item.FromNumber, &item.ToNumber, &item.Status, &item.RejectionReason, &item.StartedAt, &item.EndedAt, &item.EndedBy,
-			&item.FirstViewedAt, &item.ResolvedAt, &item.ResolvedBy,
-			&legitimacyLabel, &legitimacyConfidence, &leadLabel, &intentCategory, &intentText, &entities, &screeningCreatedAt,
-		)
+		item, err := s.scanCallListItem(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if screeningCreatedAt != nil && legitimacyLabel != nil && legitimacyConfidence != nil && intentCategory != nil && intentText != nil {
-			sr := ScreeningResult{
-				LegitimacyLabel:      *legitimacyLabel,
-				LegitimacyConfidence: *legitimacyConfidence,
-				LeadLabel:            stringOrDefault(leadLabel, "nezjisteno"),
-				IntentCategory:       *intentCategory,
-				IntentText:           *intentText,
-				CreatedAt:            *screeningCreatedAt,
-			}
-			if len(entities) > 0 {
-				sr.EntitiesJSON = json.RawMessage(entities)
-			} else {
-				sr.EntitiesJSON = json.RawMessage(`{}`)
-			}
-			item.Screening = &sr
-		}
 		out = append(out, item)
 	}
 	return out, rows.Err()
 }
 
+// scanCallListItem scans one call list row, decrypting the screening result;
+// extra receives the columns selected after the call list columns.
+func (s *Store) scanCallListItem(ctx context.Context, rows pgx.Rows, extra ...any) (CallListItem, error) {
+	var item CallListItem
+	var legitimacyLabel *string
+	var legitimacyConfidence *float64
+	var leadLabel *string
+	var intentCategory *string
+	var intentText *string
+	var entities []byte
+	var screeningCreatedAt *time.Time
+	var intentEnc, entitiesEnc []byte
+	var fieldsEnc map[string][]byte
+
+	dest := []any{
+		&item.Provider, &item.ProviderCallID, &item.FromNumber, &item.ToNumber, &item.Status, &item.RejectionReason, &item.StartedAt, &item.EndedAt, &item.EndedBy,
+		&item.FirstViewedAt, &item.ResolvedAt, &item.ResolvedBy, &item.Tags,
+		&legitimacyLabel, &legitimacyConfidence, &leadLabel, &intentCategory, &intentText, &entities, &screeningCreatedAt,
+		&intentEnc, &entitiesEnc,
+		&item.Fields, &fieldsEnc,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return CallListItem{}, err
+	}
+	fields, err := s.openCallFields(ctx, item.Fields, fieldsEnc)
+	if err != nil {
+		return CallListItem{}, err
+	}
+	item.Fields = fields
+
+	if screeningCreatedAt != nil && legitimacyLabel != nil && legitimacyConfidence != nil && intentCategory != nil && intentText != nil {
+		sr := ScreeningResult{
+			LegitimacyLabel:      *legitimacyLabel,
+			LegitimacyConfidence: *legitimacyConfidence,
+			LeadLabel:            stringOrDefault(leadLabel, "nezjisteno"),
+			IntentCategory:       *intentCategory,
+			IntentText:           *intentText,
+			CreatedAt:            *screeningCreatedAt,
+		}
+		if len(entities) > 0 {
+			sr.EntitiesJSON = json.RawMessage(entities)
+		} else {
+			sr.EntitiesJSON = json.RawMessage(`{}`)
+		}
+		if err := s.openScreening(ctx, &sr, intentEnc, entitiesEnc); err != nil {
+			return CallListItem{}, err
+		}
+		item.Screening = &sr
+	}
+	return item, nil
+}
+
 // ============================================================================
 // Admin operations
 // ============================================================================
@@ -889,6 +917,24 @@ func (s *Store) ListAllPhoneNumbers(ctx context.Context) ([]AdminPhoneNumber, er
 	return numbers, rows.Err()
 }
 
+// GetPhoneNumber returns a phone number with tenant info, or pgx.ErrNoRows.
+func (s *Store) GetPhoneNumber(ctx context.Context, id string) (*AdminPhoneNumber, error) {
+	var pn AdminPhoneNumber
+	err := s.db.QueryRow(ctx, `
+		SELECT
+			pn.id, pn.twilio_number, pn.twilio_sid, pn.forwarding_source,
+			pn.is_primary, pn.tenant_id, t.name, pn.created_at
+		FROM tenant_phone_numbers pn
+		LEFT JOIN tenants t ON t.id = pn.tenant_id
+		WHERE pn.id = $1
+	`, id).Scan(&pn.ID, &pn.TwilioNumber, &pn.TwilioSID, &pn.ForwardingSource,
+		&pn.IsPrimary, &pn.TenantID, &pn.TenantName, &pn.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &pn, nil
+}
+
 // AddPhoneNumberToPool adds a new phone number to the available pool.
 func (s *Store) AddPhoneNumberToPool(ctx context.Context, twilioNumber string, twilioSID *string) (*TenantPhoneNumber, error) {
 	var pn TenantPhoneNumber
@@ -1042,7 +1088,7 @@ type CallEvent struct {
 // ListCallEvents retrieves events for a specific call
 func (s *Store) ListCallEvents(ctx context.Context, callID string, limit int) ([]CallEvent, error) {
 	rows, err := s.db.Query(ctx, `
-		SELECT id, call_id, event_type, event_data, created_at
+		SELECT id, call_id, event_type, event_data, created_at, event_data_enc
 		FROM call_events
 		WHERE call_id = $1
 		ORDER BY created_at ASC
@@ -1056,11 +1102,13 @@ func (s *Store) ListCallEvents(ctx context.Context, callID string, limit int) ([
 	var events []CallEvent
 	for rows.Next() {
 		var e CallEvent
-		var eventData []byte
-		if err := rows.Scan(&e.ID, &e.CallID, &e.EventType, &eventData, &e.CreatedAt); err != nil {
+		var eventData, enc []byte
+		if err := rows.Scan(&e.ID, &e.CallID, &e.EventType, &eventData, &e.CreatedAt, &enc); err != nil {
+			return nil, err
+		}
+		if e.EventData, err = s.openEventData(ctx, json.RawMessage(eventData), enc); err != nil {
 			return nil, err
 		}
-		e.EventData = json.RawMessage(eventData)
 		events = append(events, e)
 	}
 	return events, rows.Err()
@@ -1235,7 +1283,8 @@ func (s *Store) ListCallsByTenantWithDetails(ctx context.Context, tenantID strin
 	rows, err := s.db.Query(ctx, `
 		SELECT c.id, c.tenant_id, c.provider, c.provider_call_id, c.from_number, c.to_number,
 		       c.status, c.rejection_reason, c.started_at, c.ended_at, c.ended_by,
-		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at
+		       r.legitimacy_label, r.legitimacy_confidence, r.lead_label, r.intent_category, r.intent_text, r.entities_json, r.created_at,
+		       r.intent_text_enc, r.entities_enc
 		FROM calls c
 		LEFT JOIN call_screening_results r ON r.call_id = c.id
 		WHERE c.tenant_id = $1
@@ -1260,11 +1309,13 @@ func (s *Store) ListCallsByTenantWithDetails(ctx context.Context, tenantID strin
 		var intentText *string
 		var entities []byte
 		var screeningCreatedAt *time.Time
+		var intentEnc, entitiesEnc []byte
 
 		err := rows.Scan(
 			&callID, &cd.TenantID, &cd.Provider, &cd.ProviderCallID, &cd.FromNumber, &cd.ToNumber,
 			&cd.Status, &cd.RejectionReason, &cd.StartedAt, &cd.EndedAt, &cd.EndedBy,
 			&legitimacyLabel, &legitimacyConfidence, &leadLabel, &intentCategory, &intentText, &entities, &screeningCreatedAt,
+			&intentEnc, &entitiesEnc,
 		)
 		if err != nil {
 			return nil, err
@@ -1284,6 +1335,9 @@ func (s *Store) ListCallsByTenantWithDetails(ctx context.Context, tenantID strin
 			} else {
 				sr.EntitiesJSON = json.RawMessage(`{}`)
 			}
+			if err := s.openScreening(ctx, &sr, intentEnc, entitiesEnc); err != nil {
+				return nil, err
+			}
 			cd.Screening = &sr
 		}
 
@@ -1305,7 +1359,7 @@ func (s *Store) ListCallsByTenantWithDetails(ctx context.Context, tenantID strin
 	// Fetch utterances for all calls
 	if len(callIDs) > 0 {
 		utteranceRows, err := s.db.Query(ctx, `
-			SELECT call_id, speaker, text, sequence, started_at, ended_at, stt_confidence, interrupted
+			SELECT call_id, speaker, text, text_enc, sequence, started_at, ended_at, stt_confidence, interrupted
 			FROM call_utterances
 			WHERE call_id = ANY($1)
 			ORDER BY call_id, sequence ASC
@@ -1319,9 +1373,13 @@ func (s *Store) ListCallsByTenantWithDetails(ctx context.Context, tenantID strin
 		for utteranceRows.Next() {
 			var callID string
 			var u Utterance
-			if err := utteranceRows.Scan(&callID, &u.Speaker, &u.Text, &u.Sequence, &u.StartedAt, &u.EndedAt, &u.STTConfidence, &u.Interrupted); err != nil {
+			var textEnc []byte
+			if err := utteranceRows.Scan(&callID, &u.Speaker, &u.Text, &textEnc, &u.Sequence, &u.StartedAt, &u.EndedAt, &u.STTConfidence, &u.Interrupted); err != nil {
 				continue
 			}
+			if u.Text, err = s.openText(ctx, u.Text, textEnc); err != nil {
+				return nil, err
+			}
 			if cd, ok := callMap[callID]; ok {
 				cd.Utterances = append(cd.Utterances, u)
 			}
@@ -1374,6 +1432,11 @@ func (s *Store) AdminUpdateTenantBilling(ctx context.Context, tenantID string, u
 	if v, ok := updates["admin_notes"]; ok {
 		query += fmt.Sprintf(", admin_notes = $%d", argNum)
 		args = append(args, v)
+		argNum++
+	}
+	if v, ok := updates["monthly_cost_budget_cents"]; ok {
+		query += fmt.Sprintf(", monthly_cost_budget_cents = $%d", argNum)
+		args = append(args, v)
 	}
 
 	query += " WHERE id = $1"
@@ -1381,6 +1444,28 @@ func (s *Store) AdminUpdateTenantBilling(ctx context.Context, tenantID string, u
 	return err
 }
 
+// TenantAdminSettings are the tenant fields admins change with
+// AdminUpdateTenantBilling and UpdateTenantPlanStatus.
+type TenantAdminSettings struct {
+	Plan                   string     `json:"plan"`
+	Status                 string     `json:"status"`
+	MaxTurnTimeoutMs       *int       `json:"max_turn_timeout_ms"`
+	TrialEndsAt            *time.Time `json:"trial_ends_at"`
+	CurrentPeriodCalls     int        `json:"current_period_calls"`
+	AdminNotes             *string    `json:"admin_notes"`
+	MonthlyCostBudgetCents *int       `json:"monthly_cost_budget_cents"`
+}
+
+// GetTenantAdminSettings returns the admin-managed fields of a tenant.
+func (s *Store) GetTenantAdminSettings(ctx context.Context, tenantID string) (TenantAdminSettings, error) {
+	var t TenantAdminSettings
+	err := s.db.QueryRow(ctx, `
+		SELECT plan, status, max_turn_timeout_ms, trial_ends_at, COALESCE(current_period_calls, 0), admin_notes, monthly_cost_budget_cents
+		FROM tenants WHERE id = $1
+	`, tenantID).Scan(&t.Plan, &t.Status, &t.MaxTurnTimeoutMs, &t.TrialEndsAt, &t.CurrentPeriodCalls, &t.AdminNotes, &t.MonthlyCostBudgetCents)
+	return t, err
+}
+
 // ============================================================================
 // Call resolution tracking
 // ============================================================================
@@ -1784,11 +1869,24 @@ type TenantCostSummary struct {
 	TotalLLMInputTokens  int `json:"total_llm_input_tokens"`
 	TotalLLMOutputTokens int `json:"total_llm_output_tokens"`
 	TotalTTSCharacters   int `json:"total_tts_characters"`
+	// Monthly provider-cost budget state (set by the admin handler)
+	Budget *costs.Budget `json:"budget,omitempty"`
+}
+
+// TenantCostBudget holds a tenant's budget settings.
+type TenantCostBudget struct {
+	Plan          string
+	BudgetCents   *int    // Per-tenant override; nil = plan default
+	AlertedPeriod *string // Period (YYYY-MM) of the last budget-exceeded alert
 }
 
 // RecordCallCosts saves the cost metrics for a call.
 func (s *Store) RecordCallCosts(ctx context.Context, callID string, metrics CallCostMetrics, costs CallCosts) error {
-	_, err := s.db.Exec(ctx, `
+	return recordCallCosts(ctx, s.db, callID, metrics, costs)
+}
+
+func recordCallCosts(ctx context.Context, db execer, callID string, metrics CallCostMetrics, costs CallCosts) error {
+	_, err := db.Exec(ctx, `
 		INSERT INTO call_costs (
 			call_id, twilio_cost_cents, stt_cost_cents, llm_cost_cents, tts_cost_cents,
 			total_cost_cents, call_duration_seconds, stt_duration_seconds,
@@ -1806,6 +1904,48 @@ func (s *Store) RecordCallCosts(ctx context.Context, callID string, metrics Call
 	return err
 }
 
+// AddCallLLMUsage adds LLM tokens used for a call after it ended (e.g.
+// regenerating its summary) to its recorded usage and recalculates its costs,
+// so they count towards the tenant's costs.
+func (s *Store) AddCallLLMUsage(ctx context.Context, callID string, inputTokens, outputTokens int) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var m CallCostMetrics
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(call_duration_seconds, 0), COALESCE(stt_duration_seconds, 0),
+		       COALESCE(llm_input_tokens, 0), COALESCE(llm_output_tokens, 0), COALESCE(tts_characters, 0)
+		FROM call_costs WHERE call_id = $1
+		FOR UPDATE
+	`, callID).Scan(&m.CallDurationSeconds, &m.STTDurationSeconds, &m.LLMInputTokens, &m.LLMOutputTokens, &m.TTSCharacters)
+	if err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+	m.LLMInputTokens += inputTokens
+	m.LLMOutputTokens += outputTokens
+
+	c := costs.CalculateCallCosts(costs.CallMetrics{
+		CallDurationSeconds: m.CallDurationSeconds,
+		STTDurationSeconds:  m.STTDurationSeconds,
+		LLMInputTokens:      m.LLMInputTokens,
+		LLMOutputTokens:     m.LLMOutputTokens,
+		TTSCharacters:       m.TTSCharacters,
+	})
+	if err := recordCallCosts(ctx, tx, callID, m, CallCosts{
+		TwilioCostCents: c.TwilioCostCents,
+		STTCostCents:    c.STTCostCents,
+		LLMCostCents:    c.LLMCostCents,
+		TTSCostCents:    c.TTSCostCents,
+		TotalCostCents:  c.TotalCostCents,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 // GetCallCosts retrieves the costs for a specific call.
 func (s *Store) GetCallCosts(ctx context.Context, callID string) (*CallCosts, error) {
 	var c CallCosts
@@ -1858,6 +1998,7 @@ func (s *Store) GetTenantCostSummary(ctx context.Context, tenantID string, perio
 		FROM call_costs cc
 		JOIN calls c ON c.id = cc.call_id
 		WHERE c.tenant_id = $1
+		  AND NOT ('test' = ANY(c.tags))
 		  AND cc.created_at >= $2
 		  AND cc.created_at < $3
 	`, tenantID, periodStart, periodEnd).Scan(
@@ -1896,6 +2037,34 @@ func (s *Store) GetTenantCostSummary(ctx context.Context, tenantID string, perio
 	return summary, nil
 }
 
+// GetTenantCostBudget retrieves a tenant's plan and budget settings.
+func (s *Store) GetTenantCostBudget(ctx context.Context, tenantID string) (*TenantCostBudget, error) {
+	var b TenantCostBudget
+	err := s.db.QueryRow(ctx, `
+		SELECT plan, monthly_cost_budget_cents, cost_budget_alerted_period
+		FROM tenants WHERE id = $1
+	`, tenantID).Scan(&b.Plan, &b.BudgetCents, &b.AlertedPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// MarkCostBudgetAlerted records that the budget-exceeded alert was sent for
+// the period. Returns false if it was already sent, so concurrent calls
+// ending over budget alert only once.
+func (s *Store) MarkCostBudgetAlerted(ctx context.Context, tenantID, period string) (bool, error) {
+	result, err := s.db.Exec(ctx, `
+		UPDATE tenants
+		SET cost_budget_alerted_period = $2
+		WHERE id = $1 AND cost_budget_alerted_period IS DISTINCT FROM $2
+	`, tenantID, period)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
 // GetTenantPhoneNumberCount returns the count of phone numbers assigned to a tenant.
 func (s *Store) GetTenantPhoneNumberCount(ctx context.Context, tenantID string) (int, error) {
 	var count int
@@ -1932,10 +2101,7 @@ func (s *Store) GetGlobalConfigInt(ctx context.Context, key string, defaultVal i
 	if err != nil {
 		return defaultVal
 	}
-	if i, err := strconv.Atoi(val); err == nil {
-		return i
-	}
-	return defaultVal
+	return ParseConfigInt(val, defaultVal)
 }
 
 // GetGlobalConfigBool retrieves a config value as bool, with fallback default.
@@ -1944,6 +2110,19 @@ func (s *Store) GetGlobalConfigBool(ctx context.Context, key string, defaultVal
 	if err != nil {
 		return defaultVal
 	}
+	return ParseConfigBool(val)
+}
+
+// ParseConfigInt parses a global config value as int, with fallback default.
+func ParseConfigInt(val string, defaultVal int) int {
+	if i, err := strconv.Atoi(val); err == nil {
+		return i
+	}
+	return defaultVal
+}
+
+// ParseConfigBool parses a global config value as bool.
+func ParseConfigBool(val string) bool {
 	return val == "true" || val == "1" || val == "yes"
 }
 
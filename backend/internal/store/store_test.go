@@ -2,10 +2,12 @@ package store
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -340,6 +342,10 @@ func TestSessionOperations(t *testing.T) {
 	if !valid {
 		t.Error("session should be valid")
 	}
+	sessionUser, err := s.GetSessionUser(ctx, tokenHash)
+	if err != nil || sessionUser.ID != user.ID || sessionUser.Role != "owner" {
+		t.Errorf("GetSessionUser = %+v, %v", sessionUser, err)
+	}
 
 	// Revoke session
 	err = s.RevokeSession(ctx, tokenHash)
@@ -355,6 +361,9 @@ func TestSessionOperations(t *testing.T) {
 	if valid2 {
 		t.Error("session should be invalid after revocation")
 	}
+	if _, err := s.GetSessionUser(ctx, tokenHash); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetSessionUser after revoke: err = %v, want ErrNoRows", err)
+	}
 
 	// Cleanup
 	_, _ = db.Exec(ctx, "DELETE FROM user_sessions WHERE user_id = $1", user.ID)
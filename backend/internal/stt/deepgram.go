@@ -4,15 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/lukasbauer/karen/internal/costs"
 )
 
 const deepgramWSURL = "wss://api.deepgram.com/v1/listen"
 
+// closeFlushTimeout bounds how long Close waits for Deepgram's final
+// Metadata message (which carries the billed audio duration).
+const closeFlushTimeout = 2 * time.Second
+
 // DeepgramClient implements the Client interface using Deepgram's streaming API.
 type DeepgramClient struct {
 	conn      *websocket.Conn
@@ -23,6 +29,8 @@ type DeepgramClient struct {
 	mu        sync.Mutex
 	wg        sync.WaitGroup // Wait for readLoop to finish
 	debug     bool           // Log raw Deepgram messages for debugging
+	logger    *slog.Logger
+	usage     *costs.Usage // Receives billed duration from Deepgram metadata
 }
 
 // DeepgramConfig holds configuration for the Deepgram client.
@@ -34,9 +42,12 @@ type DeepgramConfig struct {
 	Encoding       string // e.g., "mulaw" for Twilio
 	Channels       int    // e.g., 1 for mono
 	Punctuate      bool
-	Endpointing    int  // milliseconds of silence for endpointing, 0 for default
-	UtteranceEndMs int  // hard timeout after last speech, regardless of noise (0 for default)
-	Debug          bool // Log raw Deepgram messages for debugging
+	Endpointing    int          // milliseconds of silence for endpointing, 0 for default
+	UtteranceEndMs int          // hard timeout after last speech, regardless of noise (0 for default)
+	Debug          bool         // Log raw Deepgram messages for debugging
+	URL            string       // Optional streaming endpoint (default: Deepgram hosted API)
+	Headers        http.Header  // Optional extra handshake headers (e.g. trace context)
+	Logger         *slog.Logger // Optional logger (default: slog.Default())
 }
 
 // deepgramResponse represents a Deepgram WebSocket response.
@@ -47,6 +58,7 @@ type deepgramResponse struct {
 	Channel     json.RawMessage `json:"channel"`
 	IsFinal     bool            `json:"is_final"`
 	SpeechFinal bool            `json:"speech_final"`
+	Duration    float64         `json:"duration"` // Metadata: total audio processed (billed), in seconds
 }
 
 // deepgramChannel represents the channel object in a Deepgram Results message.
@@ -58,10 +70,16 @@ type deepgramChannel struct {
 }
 
 // NewDeepgramClient creates a new Deepgram streaming STT client.
+// Billed audio duration is reported to the usage accumulator in ctx, if any.
 func NewDeepgramClient(ctx context.Context, cfg DeepgramConfig) (*DeepgramClient, error) {
+	baseURL := cfg.URL
+	if baseURL == "" {
+		baseURL = deepgramWSURL
+	}
+
 	// Build WebSocket URL with query parameters
 	url := fmt.Sprintf("%s?model=%s&language=%s&encoding=%s&sample_rate=%d&channels=%d&punctuate=%t",
-		deepgramWSURL,
+		baseURL,
 		cfg.Model,
 		cfg.Language,
 		cfg.Encoding,
@@ -82,6 +100,9 @@ func NewDeepgramClient(ctx context.Context, cfg DeepgramConfig) (*DeepgramClient
 
 	// Set up headers with API key
 	headers := http.Header{}
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
 	headers.Set("Authorization", "Token "+cfg.APIKey)
 
 	// Connect to Deepgram
@@ -96,6 +117,11 @@ func NewDeepgramClient(ctx context.Context, cfg DeepgramConfig) (*DeepgramClient
 		errors:  make(chan error, 10),
 		done:    make(chan struct{}),
 		debug:   cfg.Debug,
+		logger:  cfg.Logger,
+		usage:   costs.UsageFromContext(ctx),
+	}
+	if client.logger == nil {
+		client.logger = slog.Default()
 	}
 
 	// Start reading responses
@@ -130,6 +156,8 @@ func (c *DeepgramClient) Errors() <-chan error {
 }
 
 // Close closes the Deepgram connection.
+// It asks Deepgram to flush the stream and waits briefly for the final
+// Metadata message so the billed duration is recorded.
 func (c *DeepgramClient) Close() error {
 	var err error
 	c.closeOnce.Do(func() {
@@ -141,27 +169,41 @@ func (c *DeepgramClient) Close() error {
 		_ = c.conn.WriteMessage(websocket.TextMessage, closeMsg)
 		c.mu.Unlock()
 
+		readDone := make(chan struct{})
+		go func() {
+			c.wg.Wait()
+			close(readDone)
+		}()
+		select {
+		case <-readDone:
+		case <-time.After(closeFlushTimeout):
+		}
+
 		err = c.conn.Close()
 
 		// Wait for readLoop to finish before closing channels
-		c.wg.Wait()
+		<-readDone
 		close(c.results)
 		close(c.errors)
 	})
 	return err
 }
 
+// emit delivers a result unless the client is closing (nobody reads then).
+func (c *DeepgramClient) emit(result TranscriptResult) {
+	select {
+	case <-c.done:
+	case c.results <- result:
+	}
+}
+
 // readLoop reads responses from Deepgram and sends them to the results channel.
 func (c *DeepgramClient) readLoop() {
 	defer c.wg.Done()
 
+	// Keeps reading after Close until Deepgram sends its Metadata message or
+	// the connection is closed.
 	for {
-		select {
-		case <-c.done:
-			return
-		default:
-		}
-
 		_, msg, err := c.conn.ReadMessage()
 		if err != nil {
 			select {
@@ -175,12 +217,12 @@ func (c *DeepgramClient) readLoop() {
 
 		// Log raw message in debug mode for diagnostics
 		if c.debug {
-			log.Printf("deepgram RAW: %s", string(msg))
+			c.logger.Info("deepgram: RAW", "raw", string(msg))
 		}
 
 		var resp deepgramResponse
 		if err := json.Unmarshal(msg, &resp); err != nil {
-			log.Printf("deepgram: failed to parse response: %v", err)
+			c.logger.Error("deepgram: failed to parse response", "error", err)
 			select {
 			case <-c.done:
 				return
@@ -192,27 +234,30 @@ func (c *DeepgramClient) readLoop() {
 
 		// Emit VAD events through the results channel for logging in event log.
 		if resp.Type == "SpeechStarted" {
-			log.Printf("deepgram: VAD speech started")
-			select {
-			case <-c.done:
-				return
-			case c.results <- TranscriptResult{VADSpeechStarted: true}:
-			}
+			c.logger.Debug("deepgram: VAD speech started")
+			c.emit(TranscriptResult{VADSpeechStarted: true})
 			continue
 		}
 		if resp.Type == "UtteranceEnd" {
-			log.Printf("deepgram: VAD utterance end")
+			c.logger.Debug("deepgram: VAD utterance end")
+			c.emit(TranscriptResult{VADUtteranceEnd: true})
+			continue
+		}
+
+		// Metadata arrives once the stream is closed; its duration is what Deepgram bills.
+		if resp.Type == "Metadata" {
+			c.usage.AddSTTSeconds(resp.Duration)
 			select {
 			case <-c.done:
 				return
-			case c.results <- TranscriptResult{VADUtteranceEnd: true}:
+			default:
 			}
 			continue
 		}
 
 		// Skip non-results messages
 		if resp.Type != "Results" {
-			log.Printf("deepgram: unknown message type: %q", resp.Type)
+			c.logger.Debug("deepgram: unknown message type", "type", resp.Type)
 			continue
 		}
 
@@ -221,7 +266,7 @@ func (c *DeepgramClient) readLoop() {
 		var confidence float64
 		var ch deepgramChannel
 		if err := json.Unmarshal(resp.Channel, &ch); err != nil {
-			log.Printf("deepgram: failed to parse channel in Results: %v", err)
+			c.logger.Error("deepgram: failed to parse channel in Results", "error", err)
 			continue
 		}
 		if len(ch.Alternatives) > 0 {
@@ -242,10 +287,6 @@ func (c *DeepgramClient) readLoop() {
 			continue
 		}
 
-		select {
-		case <-c.done:
-			return
-		case c.results <- result:
-		}
+		c.emit(result)
 	}
 }
@@ -0,0 +1,57 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/lukasbauer/karen/internal/costs"
+)
+
+// newFakeDeepgram starts a websocket server that answers CloseStream with a
+// Metadata message, like Deepgram does.
+func newFakeDeepgram(t *testing.T, duration string) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType == websocket.TextMessage && strings.Contains(string(msg), "CloseStream") {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"Metadata","duration":`+duration+`}`))
+				return
+			}
+		}
+	}))
+}
+
+func TestDeepgramClient_CloseRecordsBilledDuration(t *testing.T) {
+	srv := newFakeDeepgram(t, "12.4")
+	defer srv.Close()
+
+	usage := costs.NewUsage()
+	client, err := NewDeepgramClient(costs.WithUsage(context.Background(), usage), DeepgramConfig{
+		URL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+	})
+	if err != nil {
+		t.Fatalf("NewDeepgramClient() error = %v", err)
+	}
+	if err := client.StreamAudio(context.Background(), []byte{0xff, 0xff}); err != nil {
+		t.Fatalf("StreamAudio() error = %v", err)
+	}
+	_ = client.Close()
+
+	if got := usage.Metrics(60).STTDurationSeconds; got != 13 {
+		t.Errorf("STTDurationSeconds = %d, want 13", got)
+	}
+}
@@ -0,0 +1,95 @@
+// Package textdiff computes line-based diffs of short texts such as system prompts.
+package textdiff
+
+import "strings"
+
+// Op is the kind of a diff line.
+type Op string
+
+const (
+	OpEqual  Op = " "
+	OpInsert Op = "+"
+	OpDelete Op = "-"
+)
+
+// Line is a single line of a diff.
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns the line diff turning a into b, based on the longest common
+// subsequence of lines. Intended for texts of at most a few thousand lines.
+func Lines(a, b string) []Line {
+	al, bl := splitLines(a), splitLines(b)
+
+	// lcs[i][j] = length of the LCS of al[i:] and bl[j:]
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []Line
+	i, j := 0, 0
+	for i < len(al) && j < len(bl) {
+		switch {
+		case al[i] == bl[j]:
+			out = append(out, Line{Op: OpEqual, Text: al[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Op: OpDelete, Text: al[i]})
+			i++
+		default:
+			out = append(out, Line{Op: OpInsert, Text: bl[j]})
+			j++
+		}
+	}
+	for ; i < len(al); i++ {
+		out = append(out, Line{Op: OpDelete, Text: al[i]})
+	}
+	for ; j < len(bl); j++ {
+		out = append(out, Line{Op: OpInsert, Text: bl[j]})
+	}
+	return out
+}
+
+// Format renders a diff with "+", "-" and " " line prefixes.
+func Format(lines []Line) string {
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(string(l.Op))
+		sb.WriteString(l.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Stats counts inserted and deleted lines.
+func Stats(lines []Line) (inserted, deleted int) {
+	for _, l := range lines {
+		switch l.Op {
+		case OpInsert:
+			inserted++
+		case OpDelete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,31 @@
+package textdiff
+
+import "testing"
+
+func TestLines(t *testing.T) {
+	a := "Jsi Karen.\nMluv česky.\nBuď stručná."
+	b := "Jsi Karen.\nMluv slovensky.\nBuď stručná.\nNeprozrazuj čísla."
+
+	got := Format(Lines(a, b))
+	want := " Jsi Karen.\n-Mluv česky.\n+Mluv slovensky.\n Buď stručná.\n+Neprozrazuj čísla.\n"
+	if got != want {
+		t.Errorf("Format(Lines()) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLines_Empty(t *testing.T) {
+	if got := Lines("", ""); len(got) != 0 {
+		t.Errorf("Lines(empty, empty) = %v, want none", got)
+	}
+
+	got := Lines("", "a\nb")
+	if ins, del := Stats(got); ins != 2 || del != 0 {
+		t.Errorf("Stats() = %d/%d, want 2/0", ins, del)
+	}
+}
+
+func TestStats_Identical(t *testing.T) {
+	if ins, del := Stats(Lines("x\ny\n", "x\ny")); ins != 0 || del != 0 {
+		t.Errorf("Stats() = %d/%d, want 0/0", ins, del)
+	}
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup_NoneIsNoop(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone} {
+		shutdown, err := Setup(context.Background(), Config{Exporter: exporter})
+		if err != nil {
+			t.Fatalf("Setup(%q) error = %v", exporter, err)
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("shutdown error = %v", err)
+		}
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "jaeger"}); err == nil {
+		t.Error("Setup(jaeger) error = nil, want error")
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := newStdoutExporter(&buf)
+	if err != nil {
+		t.Fatalf("newStdoutExporter() error = %v", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "turn")
+	span.End()
+	_ = tp.Shutdown(context.Background())
+
+	if !strings.Contains(buf.String(), `"Name": "turn"`) {
+		t.Errorf("stdout output missing span name, got: %s", buf.String())
+	}
+}
+
+func TestInjectHeaders(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer shutdown(context.Background())
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "call")
+	defer span.End()
+
+	h := http.Header{}
+	InjectHeaders(ctx, h)
+	if got := h.Get("traceparent"); !strings.Contains(got, span.SpanContext().TraceID().String()) {
+		t.Errorf("traceparent = %q, want trace ID %s", got, span.SpanContext().TraceID())
+	}
+}
@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/lukasbauer/karen/internal/costs"
 )
 
 const elevenLabsAPIURL = "https://api-global-preview.elevenlabs.io/v1/text-to-speech"
 
 // ElevenLabsClient implements the Client interface using ElevenLabs' API.
 type ElevenLabsClient struct {
+	apiURL     string
 	apiKey     string
 	voiceID    string
 	modelID    string
@@ -30,6 +35,7 @@ type ElevenLabsConfig struct {
 	Stability  float64      // Voice stability (0.0-1.0, default 0.5). Use -1 for default.
 	Similarity float64      // Voice similarity boost (0.0-1.0, default 0.75). Use -1 for default.
 	HTTPClient *http.Client // Optional: shared HTTP client with connection pooling
+	BaseURL    string       // Optional text-to-speech endpoint (default: global preview endpoint)
 }
 
 // NewElevenLabsClient creates a new ElevenLabs client.
@@ -55,7 +61,12 @@ func NewElevenLabsClient(cfg ElevenLabsConfig) *ElevenLabsClient {
 	if httpClient == nil {
 		httpClient = &http.Client{}
 	}
+	apiURL := cfg.BaseURL
+	if apiURL == "" {
+		apiURL = elevenLabsAPIURL
+	}
 	return &ElevenLabsClient{
+		apiURL:     apiURL,
 		apiKey:     cfg.APIKey,
 		voiceID:    voiceID,
 		modelID:    modelID,
@@ -65,6 +76,21 @@ func NewElevenLabsClient(cfg ElevenLabsConfig) *ElevenLabsClient {
 	}
 }
 
+// recordCharacters reports the billed characters of a request to the call's
+// usage accumulator. ElevenLabs returns the billed count in the
+// character-cost header; without it the text length is used.
+func recordCharacters(ctx context.Context, resp *http.Response, text string) {
+	acc := costs.UsageFromContext(ctx)
+	if acc == nil {
+		return
+	}
+	if n, err := strconv.Atoi(resp.Header.Get("character-cost")); err == nil {
+		acc.AddTTSCharacters(n)
+		return
+	}
+	acc.AddTTSCharacters(utf8.RuneCountInString(text))
+}
+
 // ttsRequest represents an ElevenLabs TTS request.
 type ttsRequest struct {
 	Text          string        `json:"text"`
@@ -79,7 +105,7 @@ type voiceSettings struct {
 
 // Synthesize converts text to speech and returns audio data in μ-law format.
 func (c *ElevenLabsClient) Synthesize(ctx context.Context, text string) ([]byte, error) {
-	url := fmt.Sprintf("%s/%s?output_format=ulaw_8000", elevenLabsAPIURL, c.voiceID)
+	url := fmt.Sprintf("%s/%s?output_format=ulaw_8000", c.apiURL, c.voiceID)
 
 	req := ttsRequest{
 		Text:    text,
@@ -113,13 +139,14 @@ func (c *ElevenLabsClient) Synthesize(ctx context.Context, text string) ([]byte,
 		respBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("ElevenLabs API error: %s - %s", resp.Status, string(respBody))
 	}
+	recordCharacters(ctx, resp, text)
 
 	return io.ReadAll(resp.Body)
 }
 
 // SynthesizeStream converts text to speech and streams audio chunks.
 func (c *ElevenLabsClient) SynthesizeStream(ctx context.Context, text string) (<-chan []byte, error) {
-	url := fmt.Sprintf("%s/%s/stream?output_format=ulaw_8000", elevenLabsAPIURL, c.voiceID)
+	url := fmt.Sprintf("%s/%s/stream?output_format=ulaw_8000", c.apiURL, c.voiceID)
 
 	req := ttsRequest{
 		Text:    text,
@@ -153,6 +180,7 @@ func (c *ElevenLabsClient) SynthesizeStream(ctx context.Context, text string) (<
 		resp.Body.Close()
 		return nil, fmt.Errorf("ElevenLabs API error: %s - %s", resp.Status, string(respBody))
 	}
+	recordCharacters(ctx, resp, text)
 
 	ch := make(chan []byte, 100)
 
@@ -1,7 +1,12 @@
 package tts
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/lukasbauer/karen/internal/costs"
 )
 
 func TestNewElevenLabsClient_DefaultValues(t *testing.T) {
@@ -126,3 +131,42 @@ func TestNewElevenLabsClient_CustomVoiceAndModel(t *testing.T) {
 		t.Errorf("modelID = %q, want %q", client.modelID, "custom-model-id")
 	}
 }
+
+func TestSynthesizeStream_RecordsCharacterCost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("character-cost", "42")
+		_, _ = w.Write([]byte{0xff, 0xff})
+	}))
+	defer srv.Close()
+
+	client := NewElevenLabsClient(ElevenLabsConfig{APIKey: "k", BaseURL: srv.URL, Stability: -1, Similarity: -1})
+	usage := costs.NewUsage()
+	ch, err := client.SynthesizeStream(costs.WithUsage(context.Background(), usage), "Dobrý den")
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+	for range ch {
+	}
+
+	if got := usage.Metrics(0).TTSCharacters; got != 42 {
+		t.Errorf("TTSCharacters = %d, want 42 (from header)", got)
+	}
+}
+
+func TestSynthesize_CountsCharactersWithoutHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte{0xff})
+	}))
+	defer srv.Close()
+
+	client := NewElevenLabsClient(ElevenLabsConfig{APIKey: "k", BaseURL: srv.URL, Stability: -1, Similarity: -1})
+	usage := costs.NewUsage()
+	if _, err := client.Synthesize(costs.WithUsage(context.Background(), usage), "Dobrý den"); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	// Characters, not bytes: "ý" is one character
+	if got := usage.Metrics(0).TTSCharacters; got != 9 {
+		t.Errorf("TTSCharacters = %d, want 9", got)
+	}
+}
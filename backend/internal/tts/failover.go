@@ -0,0 +1,155 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/breaker"
+)
+
+// errEmptyStream is recorded when a provider accepts a request but closes
+// the stream without producing any audio.
+var errEmptyStream = errors.New("stream closed without audio")
+
+// Provider is a single TTS backend guarded by a circuit breaker.
+type Provider struct {
+	Client  Client
+	Breaker *breaker.Breaker
+}
+
+// FailoverFunc is called whenever a provider fails a request, before the
+// next provider is tried.
+type FailoverFunc func(provider string, err error)
+
+// FailoverClient implements Client over an ordered list of providers.
+// Requests go to the first provider whose breaker allows traffic; errors
+// and slow first chunks trip the breaker so subsequent sentences route to
+// the next provider. If every breaker is open, the primary is tried anyway.
+type FailoverClient struct {
+	providers  []Provider
+	onFailover FailoverFunc
+}
+
+// NewFailoverClient creates a failover client. providers must be non-empty
+// and ordered by preference.
+func NewFailoverClient(providers []Provider, onFailover FailoverFunc) *FailoverClient {
+	return &FailoverClient{
+		providers:  providers,
+		onFailover: onFailover,
+	}
+}
+
+// candidates yields the providers to try in order. A breaker is asked for
+// permission only when its provider is reached, so a half-open probe is never
+// reserved on a provider that an earlier one made unnecessary. If no breaker
+// allows traffic, the primary is tried anyway.
+func (c *FailoverClient) candidates() iter.Seq[Provider] {
+	return func(yield func(Provider) bool) {
+		allowed := false
+		for _, p := range c.providers {
+			if p.Breaker != nil && !p.Breaker.Allow() {
+				continue
+			}
+			allowed = true
+			if !yield(p) {
+				return
+			}
+		}
+		if !allowed && len(c.providers) > 0 {
+			yield(c.providers[0])
+		}
+	}
+}
+
+func (c *FailoverClient) recordFailure(p Provider, err error) {
+	if p.Breaker == nil {
+		return
+	}
+	p.Breaker.RecordFailure(err)
+	if c.onFailover != nil {
+		c.onFailover(p.Breaker.Name(), err)
+	}
+}
+
+// Synthesize converts text to speech using the first healthy provider.
+func (c *FailoverClient) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	var lastErr error
+	for p := range c.candidates() {
+		audio, err := p.Client.Synthesize(ctx, text)
+		if err == nil {
+			if p.Breaker != nil {
+				p.Breaker.RecordSuccess()
+			}
+			return audio, nil
+		}
+		if ctx.Err() != nil {
+			if p.Breaker != nil {
+				p.Breaker.Release()
+			}
+			return nil, err
+		}
+		c.recordFailure(p, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all TTS providers failed: %w", lastErr)
+}
+
+// SynthesizeStream streams audio from the first healthy provider.
+// The first-chunk latency of the chosen provider is reported to its breaker.
+func (c *FailoverClient) SynthesizeStream(ctx context.Context, text string) (<-chan []byte, error) {
+	var lastErr error
+	for p := range c.candidates() {
+		start := time.Now()
+		ch, err := p.Client.SynthesizeStream(ctx, text)
+		if err == nil {
+			if p.Breaker == nil {
+				return ch, nil
+			}
+			return c.watchStream(ctx, p, start, ch), nil
+		}
+		if ctx.Err() != nil {
+			if p.Breaker != nil {
+				p.Breaker.Release()
+			}
+			return nil, err
+		}
+		c.recordFailure(p, err)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("all TTS providers failed: %w", lastErr)
+}
+
+// watchStream forwards audio chunks and reports first-chunk latency (or an
+// empty stream) to the provider's breaker.
+func (c *FailoverClient) watchStream(ctx context.Context, p Provider, start time.Time, in <-chan []byte) <-chan []byte {
+	out := make(chan []byte, 100)
+	go func() {
+		defer close(out)
+		first := true
+		for chunk := range in {
+			if first {
+				first = false
+				p.Breaker.RecordLatency(time.Since(start))
+			}
+			select {
+			case <-ctx.Done():
+				// Drain so the provider goroutine can exit
+				for range in {
+				}
+				return
+			case out <- chunk:
+			}
+		}
+		if first {
+			if ctx.Err() != nil {
+				p.Breaker.Release()
+				return
+			}
+			c.recordFailure(p, errEmptyStream)
+		}
+	}()
+	return out
+}
@@ -0,0 +1,127 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lukasbauer/karen/internal/breaker"
+)
+
+// fakeClient is a scripted Client for failover tests.
+type fakeClient struct {
+	err    error
+	chunks [][]byte
+	calls  int
+}
+
+func (f *fakeClient) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte(text), nil
+}
+
+func (f *fakeClient) SynthesizeStream(ctx context.Context, text string) (<-chan []byte, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan []byte, len(f.chunks))
+	for _, c := range f.chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestFailoverClient_StreamFallsBackOnError(t *testing.T) {
+	primary := &fakeClient{err: errors.New("ElevenLabs API error: 500")}
+	secondary := &fakeClient{chunks: [][]byte{{0x7f}, {0xff}}}
+	pb := breaker.New("tts", "elevenlabs", breaker.Config{FailureThreshold: 1})
+
+	client := NewFailoverClient([]Provider{
+		{Client: primary, Breaker: pb},
+		{Client: secondary, Breaker: breaker.New("tts", "fallback", breaker.DefaultConfig())},
+	}, nil)
+
+	ch, err := client.SynthesizeStream(context.Background(), "Dobrý den")
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+	var n int
+	for range ch {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("chunks = %d, want 2", n)
+	}
+
+	// Breaker is open, so the next sentence goes straight to the secondary
+	if _, err := client.Synthesize(context.Background(), "Na shledanou"); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary calls = %d, want 1", primary.calls)
+	}
+}
+
+func TestFailoverClient_EmptyStreamCountsAsFailure(t *testing.T) {
+	pb := breaker.New("tts", "elevenlabs", breaker.Config{FailureThreshold: 1})
+	client := NewFailoverClient([]Provider{{Client: &fakeClient{}, Breaker: pb}}, nil)
+
+	ch, err := client.SynthesizeStream(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("SynthesizeStream() error = %v", err)
+	}
+	for range ch {
+	}
+
+	if got := pb.Snapshot().State; got != breaker.StateOpen {
+		t.Errorf("state = %q, want %q", got, breaker.StateOpen)
+	}
+}
+
+func TestFailoverClient_SecondaryRecoversAfterPrimaryRecovers(t *testing.T) {
+	primary := &fakeClient{err: errors.New("ElevenLabs API error: 503")}
+	secondary := &fakeClient{err: errors.New("ElevenLabs API error: 502")}
+	cfg := breaker.Config{FailureThreshold: 1, OpenDuration: 10 * time.Millisecond}
+	pb := breaker.New("tts", "elevenlabs", cfg)
+	sb := breaker.New("tts", "fallback", cfg)
+
+	client := NewFailoverClient([]Provider{
+		{Client: primary, Breaker: pb},
+		{Client: secondary, Breaker: sb},
+	}, nil)
+
+	// Both providers fail and trip
+	if _, err := client.Synthesize(context.Background(), "Dobrý den"); err == nil {
+		t.Fatal("Synthesize() error = nil, want error")
+	}
+
+	// After the cooldown the primary answers the probe; the secondary must
+	// not be left holding a probe it never sent
+	time.Sleep(20 * time.Millisecond)
+	primary.err = nil
+	if _, err := client.Synthesize(context.Background(), "Dobrý den"); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if got := sb.Snapshot().State; got != breaker.StateOpen {
+		t.Errorf("secondary state = %q, want %q", got, breaker.StateOpen)
+	}
+
+	// When the primary fails again, the recovered secondary takes over
+	primary.err = errors.New("ElevenLabs API error: 503")
+	secondary.err = nil
+	if _, err := client.Synthesize(context.Background(), "Dobrý den"); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if secondary.calls != 2 {
+		t.Errorf("secondary calls = %d, want 2", secondary.calls)
+	}
+	if got := sb.Snapshot().State; got != breaker.StateClosed {
+		t.Errorf("secondary state = %q, want %q", got, breaker.StateClosed)
+	}
+}
@@ -0,0 +1,6 @@
+-- Migration 015: Per-tenant debug logging
+-- Calls of listed tenants log at debug level regardless of LOG_LEVEL
+
+INSERT INTO global_config (key, value, description) VALUES
+    ('debug_log_tenants', '', 'Comma-separated tenant IDs whose calls log at debug level')
+ON CONFLICT (key) DO NOTHING;
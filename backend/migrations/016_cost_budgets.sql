@@ -0,0 +1,19 @@
+-- Migration 016: Per-tenant provider cost budgets
+-- Monthly provider-cost budgets per plan (global config) with optional per-tenant override.
+-- Calls degrade (no fillers, shorter max duration, optionally a cheaper TTS model) when a tenant approaches
+-- its budget; the admin is alerted once per month when it is exceeded.
+
+-- NULL = use the plan default from global config
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS monthly_cost_budget_cents INT;
+
+-- Period (YYYY-MM) for which the budget-exceeded alert was last sent
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS cost_budget_alerted_period TEXT;
+
+INSERT INTO global_config (key, value, description) VALUES
+    ('cost_budget_trial_cents', '200', 'Monthly provider cost budget for trial tenants (cents, 0 = unlimited)'),
+    ('cost_budget_basic_cents', '1500', 'Monthly provider cost budget for basic tenants (cents, 0 = unlimited)'),
+    ('cost_budget_pro_cents', '0', 'Monthly provider cost budget for pro tenants (cents, 0 = unlimited)'),
+    ('cost_budget_degrade_percent', '80', 'Budget usage (%) at which calls switch to degraded mode'),
+    ('cost_budget_degraded_tts_model', '', 'ElevenLabs model used for calls in degraded mode (empty = keep the normal model)'),
+    ('cost_budget_degraded_max_call_duration_ms', '120000', 'Maximum call duration in degraded mode (ms)')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,30 @@
+-- Migration 017: Tenant system prompt versions
+-- Every change of tenants.system_prompt (user edit, auto-regeneration, rollback) is kept
+-- as a numbered version; calls record the version they were answered with.
+
+CREATE TABLE IF NOT EXISTS tenant_prompt_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    system_prompt TEXT NOT NULL,
+    author_user_id UUID REFERENCES users(id) ON DELETE SET NULL,  -- NULL = system
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, version)
+);
+
+-- Current version of tenants.system_prompt
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS prompt_version INT;
+
+-- Prompt version the call was answered with
+ALTER TABLE calls ADD COLUMN IF NOT EXISTS prompt_version INT;
+
+-- Existing prompts become version 1
+INSERT INTO tenant_prompt_versions (tenant_id, version, system_prompt, reason, created_at)
+SELECT id, 1, system_prompt, 'initial version', COALESCE(updated_at, NOW())
+FROM tenants
+WHERE prompt_version IS NULL AND system_prompt IS NOT NULL
+ON CONFLICT (tenant_id, version) DO NOTHING;
+
+UPDATE tenants SET prompt_version = 1
+WHERE prompt_version IS NULL AND system_prompt IS NOT NULL;
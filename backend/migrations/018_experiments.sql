@@ -0,0 +1,23 @@
+-- Migration 018: A/B experiments
+-- Admin-defined experiments whose variants override tenant config fields and global
+-- config keys per call. Calls record the experiment and variant they were assigned to.
+
+CREATE TABLE IF NOT EXISTS experiments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE,  -- NULL = all tenants
+    status TEXT NOT NULL DEFAULT 'draft',                     -- draft, running, stopped
+    variants JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    stopped_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiments_status ON experiments(status);
+
+ALTER TABLE calls ADD COLUMN IF NOT EXISTS experiment_id UUID REFERENCES experiments(id) ON DELETE SET NULL;
+ALTER TABLE calls ADD COLUMN IF NOT EXISTS experiment_variant TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_calls_experiment ON calls(experiment_id, experiment_variant)
+    WHERE experiment_id IS NOT NULL;
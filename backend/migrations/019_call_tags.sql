@@ -0,0 +1,5 @@
+-- Migration 019: Call tags
+-- Free-form labels on calls. Browser softphone test calls are tagged 'test' and
+-- excluded from tenant usage and cost summaries.
+
+ALTER TABLE calls ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
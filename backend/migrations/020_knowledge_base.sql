@@ -0,0 +1,43 @@
+-- Migration 020: Tenant knowledge base
+-- FAQ entries and documents uploaded by tenants, split into chunks that are
+-- retrieved per call turn with full-text search and injected into the LLM context.
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- Czech text search configuration: accents removed, no stemming (Postgres has
+-- no Czech stemmer; queries are stemmed by the application and prefix-matched)
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'czech_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION czech_unaccent (COPY = simple);
+        ALTER TEXT SEARCH CONFIGURATION czech_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS knowledge_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'document',  -- faq (title = question), document
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_tenant ON knowledge_documents(tenant_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS knowledge_chunks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_id UUID NOT NULL REFERENCES knowledge_documents(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    seq INT NOT NULL,
+    content TEXT NOT NULL,
+    token_count INT NOT NULL DEFAULT 0,
+    tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('czech_unaccent', content)) STORED,
+    UNIQUE (document_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_chunks_tsv ON knowledge_chunks USING GIN(tsv);
+CREATE INDEX IF NOT EXISTS idx_knowledge_chunks_tenant ON knowledge_chunks(tenant_id);
@@ -0,0 +1,11 @@
+-- Migration 021: Tenant screening taxonomy
+-- Tenants can define their own legitimacy, lead and intent label sets (with descriptions)
+-- used to build the call analysis prompt. No row = built-in labels.
+-- call_screening_results label columns are free text, so custom labels need no schema change.
+
+CREATE TABLE IF NOT EXISTS tenant_screening_taxonomies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    taxonomy JSONB NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,26 @@
+-- Migration 022: Tenant call fields (slots)
+-- Tenants define structured fields the assistant collects on calls (address, order
+-- number, callback time, ...). Collected values are stored typed per call so the
+-- call list can show and filter them.
+
+CREATE TABLE IF NOT EXISTS tenant_call_fields (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    fields JSONB NOT NULL DEFAULT '[]',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS call_field_values (
+    call_id UUID NOT NULL REFERENCES calls(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    type TEXT NOT NULL,
+    value_text TEXT NOT NULL,       -- Canonical text form (all types)
+    value_number NUMERIC,           -- number fields
+    value_date DATE,                -- date fields
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (call_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_call_field_values_text ON call_field_values(key, lower(value_text));
+CREATE INDEX IF NOT EXISTS idx_call_field_values_number ON call_field_values(key, value_number) WHERE value_number IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_call_field_values_date ON call_field_values(key, value_date) WHERE value_date IS NOT NULL;
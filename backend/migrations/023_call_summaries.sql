@@ -0,0 +1,12 @@
+-- Migration 023: Post-call summaries
+-- A short Czech summary of each call with action items and the urgency the
+-- caller stated. Generated with the screening analysis and regenerated on demand.
+
+CREATE TABLE IF NOT EXISTS call_summaries (
+    call_id UUID PRIMARY KEY REFERENCES calls(id) ON DELETE CASCADE,
+    summary TEXT NOT NULL,
+    action_items JSONB NOT NULL DEFAULT '[]',
+    urgency TEXT NOT NULL DEFAULT 'nezjisteno',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,96 @@
+-- Migration 024: Full-text search over calls
+-- One search document per call with the intent text, entities and summary
+-- (weight A) and the transcript (weight B), in the czech_unaccent configuration
+-- from migration 020. Triggers keep it current as screening results and
+-- summaries are written and utterances are changed or deleted. Utterances are
+-- inserted one per turn, so inserts don't rebuild the document; it is
+-- refreshed once when the call ends (Store.RefreshCallSearch).
+
+CREATE TABLE IF NOT EXISTS call_search_documents (
+    call_id UUID PRIMARY KEY REFERENCES calls(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,  -- Searched text, used for highlighted snippets
+    tsv TSVECTOR NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_call_search_documents_tsv ON call_search_documents USING GIN(tsv);
+
+-- Rebuilds the search document of a call (no-op if the call no longer exists,
+-- e.g. while its rows are deleted by cascade).
+CREATE OR REPLACE FUNCTION refresh_call_search_document(p_call_id UUID) RETURNS void AS $$
+    INSERT INTO call_search_documents (call_id, content, tsv)
+    SELECT d.id,
+           concat_ws(E'\n', NULLIF(d.head, ''), NULLIF(d.body, '')),
+           setweight(to_tsvector('czech_unaccent', d.head), 'A') ||
+           setweight(to_tsvector('czech_unaccent', d.body), 'B')
+    FROM (
+        SELECT c.id,
+               concat_ws(E'\n',
+                   r.intent_text,
+                   (SELECT string_agg(e.value, ' ')
+                    FROM jsonb_each_text(CASE WHEN jsonb_typeof(r.entities_json) = 'object' THEN r.entities_json ELSE '{}' END) e),
+                   s.summary) AS head,
+               COALESCE((SELECT string_agg(u.text, E'\n' ORDER BY u.sequence)
+                         FROM call_utterances u WHERE u.call_id = c.id), '') AS body
+        FROM calls c
+        LEFT JOIN call_screening_results r ON r.call_id = c.id
+        LEFT JOIN call_summaries s ON s.call_id = c.id
+        WHERE c.id = p_call_id
+    ) d
+    ON CONFLICT (call_id) DO UPDATE SET
+        content = EXCLUDED.content,
+        tsv = EXCLUDED.tsv,
+        updated_at = NOW();
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION call_search_document_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_call_search_document(OLD.call_id);
+        RETURN OLD;
+    END IF;
+    PERFORM refresh_call_search_document(NEW.call_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Statement-level, so bulk updates and deletes (encryption backfill,
+-- retention, erasure) rebuild each affected call once
+CREATE OR REPLACE FUNCTION call_utterances_search_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_call_search_document(o.call_id) FROM (SELECT DISTINCT call_id FROM old_rows) o;
+    ELSE
+        PERFORM refresh_call_search_document(n.call_id) FROM (SELECT DISTINCT call_id FROM new_rows) n;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_call_utterances_search ON call_utterances;
+DROP TRIGGER IF EXISTS trg_call_utterances_search_update ON call_utterances;
+CREATE TRIGGER trg_call_utterances_search_update
+    AFTER UPDATE ON call_utterances
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION call_utterances_search_trigger();
+
+DROP TRIGGER IF EXISTS trg_call_utterances_search_delete ON call_utterances;
+CREATE TRIGGER trg_call_utterances_search_delete
+    AFTER DELETE ON call_utterances
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT EXECUTE FUNCTION call_utterances_search_trigger();
+
+DROP TRIGGER IF EXISTS trg_call_screening_results_search ON call_screening_results;
+CREATE TRIGGER trg_call_screening_results_search
+    AFTER INSERT OR UPDATE OR DELETE ON call_screening_results
+    FOR EACH ROW EXECUTE FUNCTION call_search_document_trigger();
+
+DROP TRIGGER IF EXISTS trg_call_summaries_search ON call_summaries;
+CREATE TRIGGER trg_call_summaries_search
+    AFTER INSERT OR UPDATE OR DELETE ON call_summaries
+    FOR EACH ROW EXECUTE FUNCTION call_search_document_trigger();
+
+-- Backfill calls without a search document (migrations are re-applied on deploy)
+SELECT refresh_call_search_document(c.id)
+FROM calls c
+WHERE NOT EXISTS (SELECT 1 FROM call_search_documents d WHERE d.call_id = c.id);
@@ -0,0 +1,4 @@
+-- Migration 025: Call list pagination
+-- The call list is paginated with a (started_at, id) keyset cursor per tenant.
+
+CREATE INDEX IF NOT EXISTS idx_calls_tenant_started_id ON calls(tenant_id, started_at DESC, id DESC);
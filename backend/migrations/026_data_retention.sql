@@ -0,0 +1,26 @@
+-- Migration 026: Data retention
+-- How long call data is kept, per tenant, with the defaults in global_config.
+-- A background purger removes expired data in batches:
+--   transcripts: utterances, summary, collected call fields; screening intent
+--                text and entities are cleared (labels are kept for statistics)
+--   events:      call_events rows
+--   calls:       the call itself, with everything that references it
+-- Days are counted from the call start (events: from the event); 0 keeps forever.
+
+CREATE TABLE IF NOT EXISTS tenant_retention_policies (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    transcript_days INT,  -- NULL = global default
+    event_days INT,       -- NULL = global default
+    call_days INT,        -- NULL = global default
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Set once a call's transcript has been purged, so later runs skip it
+ALTER TABLE calls ADD COLUMN IF NOT EXISTS transcript_purged_at TIMESTAMPTZ;
+
+INSERT INTO global_config (key, value, description) VALUES
+    ('retention_transcript_days', '0', 'Default days to keep call transcripts, summaries and extracted data (0 = forever)'),
+    ('retention_event_days', '0', 'Default days to keep call event logs (0 = forever)'),
+    ('retention_call_days', '0', 'Default days to keep calls (0 = forever)')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,35 @@
+-- Migration 027: Data subject requests
+-- GDPR export and erasure of a caller's data, looked up by phone number.
+-- Every request is recorded in data_subject_requests, which is append-only:
+-- a trigger rejects UPDATE and DELETE. The record keeps a masked form of the
+-- number rather than the number itself (and a keyed hash, added by migration
+-- 033), and has no foreign keys so deleting a tenant or user leaves the trail
+-- intact.
+
+CREATE INDEX IF NOT EXISTS idx_calls_from_number ON calls(from_number);
+
+CREATE TABLE IF NOT EXISTS data_subject_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    action TEXT NOT NULL,          -- export, delete, anonymize
+    phone_masked TEXT NOT NULL,    -- e.g. +420777***456
+    tenant_id UUID,                -- NULL = all tenants (admin request)
+    requested_by UUID,             -- user who made the request
+    admin BOOLEAN NOT NULL DEFAULT false,
+    calls INT NOT NULL DEFAULT 0,
+    utterances INT NOT NULL DEFAULT 0,
+    events INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_subject_requests_tenant ON data_subject_requests(tenant_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION reject_data_subject_request_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'data_subject_requests is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS data_subject_requests_immutable ON data_subject_requests;
+CREATE TRIGGER data_subject_requests_immutable
+    BEFORE UPDATE OR DELETE ON data_subject_requests
+    FOR EACH ROW EXECUTE FUNCTION reject_data_subject_request_change();
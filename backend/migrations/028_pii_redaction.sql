@@ -0,0 +1,12 @@
+-- Migration 028: PII redaction settings
+-- Personal data in transcripts and call event payloads is replaced with typed
+-- placeholders ([CARD], [BIRTH_NUMBER], ...) before it is stored. Tenants
+-- choose the kinds redacted: birth_number, bank_account, card, email, phone.
+-- No row = default kinds (birth_number, bank_account, card).
+
+CREATE TABLE IF NOT EXISTS tenant_redaction_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    kinds TEXT[] NOT NULL DEFAULT '{}',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
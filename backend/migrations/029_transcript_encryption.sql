@@ -0,0 +1,56 @@
+-- Migration 029: Per-tenant encryption of transcripts at rest
+-- With ENCRYPTION_KEY_FILE set, utterance text, screening intent text and
+-- entities are stored encrypted with a per-tenant data key (AES-256-GCM).
+-- Data keys are stored wrapped by a master key; rotating the master key
+-- re-wraps them (cmd/rotate-keys). A tenant's data key versions are kept
+-- so older values stay readable.
+--
+-- Encrypted rows keep '' in the plaintext column and the value in the *_enc
+-- column. entities_json keeps the entity keys (true = captured, null = not),
+-- so entity columns in exports and experiment stats work without the keys.
+-- Encrypted text is not indexed for full-text search.
+
+CREATE TABLE IF NOT EXISTS tenant_data_keys (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    master_key_id TEXT NOT NULL,  -- Master key the data key is wrapped with
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rewrapped_at TIMESTAMPTZ,
+    PRIMARY KEY (tenant_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_data_keys_master_key ON tenant_data_keys(master_key_id);
+
+ALTER TABLE call_utterances ADD COLUMN IF NOT EXISTS text_enc BYTEA;
+ALTER TABLE call_screening_results ADD COLUMN IF NOT EXISTS intent_text_enc BYTEA;
+ALTER TABLE call_screening_results ADD COLUMN IF NOT EXISTS entities_enc BYTEA;
+
+-- Search documents skip the masked entities of encrypted results (their
+-- intent text and utterances are already empty).
+CREATE OR REPLACE FUNCTION refresh_call_search_document(p_call_id UUID) RETURNS void AS $$
+    INSERT INTO call_search_documents (call_id, content, tsv)
+    SELECT d.id,
+           concat_ws(E'\n', NULLIF(d.head, ''), NULLIF(d.body, '')),
+           setweight(to_tsvector('czech_unaccent', d.head), 'A') ||
+           setweight(to_tsvector('czech_unaccent', d.body), 'B')
+    FROM (
+        SELECT c.id,
+               concat_ws(E'\n',
+                   r.intent_text,
+                   (SELECT string_agg(e.value, ' ')
+                    FROM jsonb_each_text(CASE WHEN jsonb_typeof(r.entities_json) = 'object' AND r.entities_enc IS NULL
+                                              THEN r.entities_json ELSE '{}' END) e),
+                   s.summary) AS head,
+               COALESCE((SELECT string_agg(u.text, E'\n' ORDER BY u.sequence)
+                         FROM call_utterances u WHERE u.call_id = c.id), '') AS body
+        FROM calls c
+        LEFT JOIN call_screening_results r ON r.call_id = c.id
+        LEFT JOIN call_summaries s ON s.call_id = c.id
+        WHERE c.id = p_call_id
+    ) d
+    ON CONFLICT (call_id) DO UPDATE SET
+        content = EXCLUDED.content,
+        tsv = EXCLUDED.tsv,
+        updated_at = NOW();
+$$ LANGUAGE sql;
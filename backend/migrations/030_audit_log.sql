@@ -0,0 +1,21 @@
+-- Migration 030: Audit log
+-- Administrative and tenant settings changes: who made them (tenant user,
+-- admin or the AI debug API key), what changed and the changed fields before
+-- and after. No foreign keys, so entries outlive deleted tenants and users.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_type TEXT NOT NULL,    -- user, admin, ai_key
+    actor_id UUID,               -- User who made the change (NULL for the AI key)
+    action TEXT NOT NULL,        -- e.g. tenant.update, config.update, retention.update
+    target_type TEXT NOT NULL,   -- tenant, global_config
+    target_id TEXT NOT NULL,     -- Tenant ID or config key
+    tenant_id UUID,              -- Tenant the change concerns (shown to the tenant)
+    before JSONB,                -- Changed fields before (NULL = created)
+    after JSONB,                 -- Changed fields after (NULL = deleted)
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant ON audit_log(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, created_at DESC);
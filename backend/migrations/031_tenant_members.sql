@@ -0,0 +1,30 @@
+-- Migration 031: Tenant members and invitations
+-- A tenant can have several users, each with a role: owner (everything,
+-- including members, billing and settings), member (works with calls and the
+-- knowledge base) or viewer (read-only). Users join a tenant by accepting an
+-- invitation sent to their phone number. Push notification preferences are
+-- per member.
+
+UPDATE users SET role = 'owner' WHERE role IS NULL OR role NOT IN ('owner', 'member', 'viewer');
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'owner';
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notify_calls TEXT NOT NULL DEFAULT 'all'; -- all, legitimate (no spam), none
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notify_usage BOOLEAN NOT NULL DEFAULT true; -- Usage limit warnings
+
+CREATE TABLE IF NOT EXISTS tenant_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    phone TEXT NOT NULL,            -- E.164 number of the invitee
+    role TEXT NOT NULL,             -- Role granted on acceptance: owner, member, viewer
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,         -- Revoked by an owner or declined by the invitee
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open invitation per tenant and phone; inviting again renews it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_invitations_open
+    ON tenant_invitations(tenant_id, phone) WHERE accepted_at IS NULL AND revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tenant_invitations_phone ON tenant_invitations(phone);
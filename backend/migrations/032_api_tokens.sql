@@ -0,0 +1,22 @@
+-- Migration 032: Personal API tokens
+-- Long-lived tokens tenants use for their own integrations instead of JWTs.
+-- A token acts for the user who created it, within its scopes (calls:read,
+-- calls:write, settings:read, settings:write, knowledge:read,
+-- knowledge:write) and the user's current role; it stops working when the
+-- user leaves the tenant. Only the SHA-256 of the token is stored.
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,     -- Hex SHA-256 of the token
+    token_prefix TEXT NOT NULL,          -- First characters, to recognise the token
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMPTZ,            -- Updated at most once a minute
+    expires_at TIMESTAMPTZ,              -- NULL = doesn't expire
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_tenant ON api_tokens(tenant_id, created_at DESC);
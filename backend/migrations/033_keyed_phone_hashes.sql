@@ -0,0 +1,10 @@
+-- Migration 033: Keyed phone hashes in the data subject request trail
+-- Plain SHA-256 hashes of phone numbers can be reversed by hashing every
+-- possible number, so the trail now keeps an HMAC-SHA256 keyed with a server
+-- secret (PHONE_HASH_KEY) in phone_hmac. The old hashes are dropped; those
+-- entries keep their masked number but can no longer be found by number.
+
+ALTER TABLE data_subject_requests ADD COLUMN IF NOT EXISTS phone_hmac TEXT NOT NULL DEFAULT ''; -- hex HMAC-SHA256 of the E.164 number
+ALTER TABLE data_subject_requests DROP COLUMN IF EXISTS phone_hash;
+
+CREATE INDEX IF NOT EXISTS idx_data_subject_requests_phone_hmac ON data_subject_requests(phone_hmac);
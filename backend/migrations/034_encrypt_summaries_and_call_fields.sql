@@ -0,0 +1,12 @@
+-- Migration 034: Encrypt call summaries and collected call fields at rest
+-- The summary, its action items and call field values quote the caller, so
+-- with ENCRYPTION_KEY_FILE set they are encrypted with the tenant's data key
+-- like transcripts (migration 029). Encrypted rows keep '' / '[]' / NULL in
+-- the plaintext columns; urgency and the field keys stay readable.
+--
+-- Full-text search and field value filters (other than "collected") can't
+-- see encrypted values; the API rejects them for tenants with encryption
+-- enabled (migration 035).
+
+ALTER TABLE call_summaries ADD COLUMN IF NOT EXISTS summary_enc BYTEA;   -- {"summary", "action_items"}
+ALTER TABLE call_field_values ADD COLUMN IF NOT EXISTS value_enc BYTEA;  -- slots.Value JSON
@@ -0,0 +1,24 @@
+-- Migration 035: Per-tenant encryption opt-in
+-- Encryption at rest (migrations 029 and 034) is a tenant setting: with
+-- ENCRYPTION_KEY_FILE set, only tenants with enabled = true get their calls
+-- encrypted. Encrypted values can't be searched or compared in SQL, so call
+-- search and call field value filters are unavailable to those tenants only.
+-- No row = not encrypted.
+--
+-- When the table is created, tenants that already have a data key (their
+-- calls were encrypted while encryption was deployment-wide) are opted in.
+
+DO $$
+BEGIN
+    IF to_regclass('tenant_encryption_settings') IS NULL THEN
+        CREATE TABLE tenant_encryption_settings (
+            tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+            enabled BOOLEAN NOT NULL DEFAULT false,
+            updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+
+        INSERT INTO tenant_encryption_settings (tenant_id, enabled)
+        SELECT DISTINCT tenant_id, true FROM tenant_data_keys;
+    END IF;
+END $$;
@@ -0,0 +1,8 @@
+-- Migration 036: Encrypt caller speech in call events
+-- Event payloads quote the caller (stt_result and turn_finalized text,
+-- knowledge_retrieved query, barge_in partial_text, max_turn_timeout
+-- pending_text, ...). For tenants with encryption enabled (migration 035)
+-- those fields are removed from event_data and stored encrypted with the
+-- tenant's data key in event_data_enc, a JSON object merged back on read.
+
+ALTER TABLE call_events ADD COLUMN IF NOT EXISTS event_data_enc BYTEA;
@@ -0,0 +1,12 @@
+-- Migration 037: Degraded TTS model opt-in
+-- cost_budget_degraded_tts_model was seeded with eleven_flash_v2_5, the model calls
+-- already use, so degraded mode never saved anything on TTS. Empty now means "keep the
+-- normal model"; admins opt in by setting a cheaper model. Only the untouched seed row
+-- is reset.
+
+UPDATE global_config
+SET value = '',
+    description = 'ElevenLabs model used for calls in degraded mode (empty = keep the normal model)'
+WHERE key = 'cost_budget_degraded_tts_model'
+  AND value = 'eleven_flash_v2_5'
+  AND description = 'ElevenLabs model used for calls in degraded mode';
@@ -0,0 +1,14 @@
+-- Migration 038: Softphone tickets
+-- Browsers can't set headers on websockets, so the softphone websocket is
+-- opened with a short-lived, single-use ticket from POST /api/softphone/ticket
+-- instead of the session JWT, which would end up in proxy logs and browser
+-- history. Only the SHA-256 of the ticket is stored; redeeming deletes it.
+
+CREATE TABLE IF NOT EXISTS softphone_tickets (
+    ticket_hash TEXT PRIMARY KEY,  -- Hex SHA-256 of the ticket
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_softphone_tickets_expires ON softphone_tickets(expires_at);